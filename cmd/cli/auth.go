@@ -0,0 +1,316 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+/*
+access es quién puede ejecutar un subcomando.
+
+Los subcomandos que operan sobre un cliente (carrito, checkout,
+pedidos) son accessAuthenticated y verifican después de leer sus
+opciones que el usuario pueda operar sobre ese cliente
+(domain.AuthorizeCustomer). El valor cero es accessStaff: un comando
+nuevo al que se le olvide indicar el acceso queda restringido.
+*/
+type access int
+
+const (
+	accessStaff         access = iota // admin o staff
+	accessAdmin                       // solo admin
+	accessAuthenticated               // cualquier usuario; el comando decide qué puede ver
+	accessSetup                       // admin, o cualquiera mientras no haya usuarios (primer administrador)
+	accessPublic                      // sin clave de API
+)
+
+/*
+authorizeCommand verifica la clave de API (a.apiKey) según el acceso del
+comando y devuelve el usuario autenticado.
+
+Con accessSetup y sin usuarios registrados no se pide clave: es la
+única forma de crear el primer administrador sin el menú interactivo.
+*/
+func authorizeCommand(a app, cmd command) (domain.User, error) {
+	switch cmd.access {
+	case accessPublic:
+		return domain.User{}, nil
+	case accessSetup:
+		users, err := usecase.ListUsers(a.users.Users)
+		if err != nil {
+			return domain.User{}, err
+		}
+		if len(users) == 0 {
+			return domain.User{}, nil
+		}
+	}
+
+	user, err := usecase.AuthenticateAPIKey(a.users.Users, a.apiKey)
+	if err != nil {
+		return domain.User{}, fmt.Errorf("%w: indica una clave de API con -api-key o ECOMMERCE_API_KEY (se obtiene con el subcomando login)", err)
+	}
+	switch cmd.access {
+	case accessStaff:
+		err = domain.AuthorizeStaff(user)
+	case accessAdmin, accessSetup:
+		err = domain.AuthorizeAdmin(user)
+	}
+	return user, err
+}
+
+/*
+readSecret lee una contraseña de la primera línea de la entrada estándar.
+
+Así no queda en el historial de la terminal ni en la lista de procesos,
+como pasaría con una opción. Si la entrada es una terminal, antes
+muestra la pregunta en la salida de errores (la contraseña se ve al
+escribirla: la biblioteca estándar no permite ocultarla).
+*/
+func readSecret(label string) (string, error) {
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		fmt.Fprint(os.Stderr, label)
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("%w: no se pudo leer la contraseña de la entrada estándar", errUsage)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+/*
+cmdLogin verifica usuario y contraseña y muestra una clave de API nueva
+(login). La salida estándar tiene solo la clave, para poder guardarla
+en una variable:
+
+	export ECOMMERCE_API_KEY=$(echo "$PASS" | cli login --username ana)
+
+Cada usuario tiene una sola clave vigente: la anterior deja de valer.
+*/
+func cmdLogin(a app, args []string) error {
+	fs := flag.NewFlagSet("login", flag.ContinueOnError)
+	username := fs.String("username", "", "nombre de usuario")
+	if err := parseFlags(fs, args, "username"); err != nil {
+		return err
+	}
+	password, err := readSecret("Contraseña: ")
+	if err != nil {
+		return err
+	}
+
+	user, err := usecase.Login(a.users.Users, *username, password)
+	if err != nil {
+		return err
+	}
+	key, err := usecase.IssueAPIKey(a.users.Users, user.Username)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Sesión iniciada como %s (%s). La clave anterior ya no es válida.\n", user.Username, user.Role)
+	fmt.Println(key)
+	return nil
+}
+
+/*
+cmdUserCreate crea un usuario (user create).
+
+Mientras no haya usuarios se puede ejecutar sin clave de API, pero solo
+para crear un administrador.
+*/
+func cmdUserCreate(a app, args []string) error {
+	fs := flag.NewFlagSet("user create", flag.ContinueOnError)
+	username := fs.String("username", "", "nombre de usuario")
+	role := fs.String("role", "", "rol: admin, staff o customer")
+	customerID := fs.Int("customer", 0, "ID del cliente (solo con --role customer)")
+	if err := parseFlags(fs, args, "username", "role"); err != nil {
+		return err
+	}
+	if a.user.Username == "" && domain.Role(*role) != domain.RoleAdmin {
+		return fmt.Errorf("%w: el primer usuario debe tener el rol admin", domain.ErrForbidden)
+	}
+	password, err := readSecret("Contraseña: ")
+	if err != nil {
+		return err
+	}
+
+	u, err := usecase.CreateUser(a.users, usecase.NewUserRequest{
+		Username:   *username,
+		Password:   password,
+		Role:       domain.Role(*role),
+		CustomerID: *customerID,
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Usuario %s creado (%s). Inicia sesión con el subcomando login.\n", u.Username, u.Role)
+	return nil
+}
+
+// cmdUserList lista los usuarios (user list).
+func cmdUserList(a app, args []string) error {
+	fs := flag.NewFlagSet("user list", flag.ContinueOnError)
+	outputFlag(fs, &a.out)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	users, err := usecase.ListUsers(a.users.Users)
+	if err != nil {
+		return err
+	}
+	a.out.Users(users)
+	return nil
+}
+
+// cmdUserKey emite una clave de API nueva para un usuario (user key); la anterior deja de valer.
+func cmdUserKey(a app, args []string) error {
+	fs := flag.NewFlagSet("user key", flag.ContinueOnError)
+	username := fs.String("username", "", "nombre de usuario")
+	if err := parseFlags(fs, args, "username"); err != nil {
+		return err
+	}
+	key, err := usecase.IssueAPIKey(a.users.Users, *username)
+	if err != nil {
+		return err
+	}
+	fmt.Println(key)
+	return nil
+}
+
+/*
+interactiveLogin identifica al usuario del menú interactivo.
+
+- Si no hay usuarios, pide crear el administrador y entra con él.
+- Si no, pide usuario y contraseña; tras maxLoginAttempts intentos
+  fallidos devuelve domain.ErrInvalidCredentials.
+*/
+func interactiveLogin(reader *bufio.Reader, deps usecase.UserDeps) (domain.User, error) {
+	users, err := usecase.ListUsers(deps.Users)
+	if err != nil {
+		return domain.User{}, err
+	}
+	if len(users) == 0 {
+		fmt.Println("\nNo hay usuarios registrados. Crea el usuario administrador.")
+		for {
+			u, err := usecase.CreateUser(deps, usecase.NewUserRequest{
+				Username: readString(reader, "Usuario: "),
+				Password: readString(reader, "Contraseña (mínimo 8 caracteres): "),
+				Role:     domain.RoleAdmin,
+			})
+			if err == nil {
+				return u, nil
+			}
+			fmt.Println("Error:", err)
+		}
+	}
+
+	for range maxLoginAttempts {
+		fmt.Println("\n=== Iniciar sesión ===")
+		u, err := usecase.Login(deps.Users, readString(reader, "Usuario: "), readString(reader, "Contraseña: "))
+		if err == nil {
+			return u, nil
+		}
+		if !errors.Is(err, domain.ErrInvalidCredentials) {
+			return domain.User{}, err
+		}
+		fmt.Println("Error:", err)
+	}
+	return domain.User{}, domain.ErrInvalidCredentials
+}
+
+// maxLoginAttempts es cuántas veces se puede equivocar la contraseña en el menú interactivo.
+const maxLoginAttempts = 3
+
+/*
+usersMenu gestiona los usuarios (solo admin): alta, listado y emisión
+de claves de API para los subcomandos y la API REST.
+*/
+func usersMenu(reader *bufio.Reader, out printer, deps usecase.UserDeps) {
+	for {
+		fmt.Println("\n--- Usuarios ---")
+		fmt.Println("1) Crear usuario")
+		fmt.Println("2) Listar usuarios")
+		fmt.Println("3) Emitir clave de API")
+		fmt.Println("0) Volver")
+		fmt.Print("Opción: ")
+
+		switch readLine(reader) {
+		case "1":
+			req := usecase.NewUserRequest{
+				Username: readString(reader, "Usuario: "),
+				Password: readString(reader, "Contraseña (mínimo 8 caracteres): "),
+				Role:     domain.Role(readString(reader, "Rol (admin, staff o customer): ")),
+			}
+			if req.Role == domain.RoleCustomer {
+				req.CustomerID = readInt(reader, "CustomerID: ")
+			}
+			u, err := usecase.CreateUser(deps, req)
+			if err != nil {
+				fmt.Println("Error:", err)
+				continue
+			}
+			fmt.Println("Usuario", u.Username, "creado.")
+
+		case "2":
+			users, err := usecase.ListUsers(deps.Users)
+			if err != nil {
+				fmt.Println("Error:", err)
+				continue
+			}
+			out.Users(users)
+
+		case "3":
+			key, err := usecase.IssueAPIKey(deps.Users, readString(reader, "Usuario: "))
+			if err != nil {
+				fmt.Println("Error:", err)
+				continue
+			}
+			fmt.Println("Clave de API (se muestra una sola vez; la anterior deja de valer):")
+			fmt.Println(key)
+
+		case "0":
+			return
+
+		default:
+			fmt.Println("Opción inválida.")
+		}
+	}
+}
+
+/*
+menuOption es una opción de un menú interactivo; solo se muestra (y
+solo se acepta) si allowed es true.
+*/
+type menuOption struct {
+	key, label string
+	allowed    bool
+}
+
+/*
+printMenu muestra las opciones permitidas de un menú y lee la elegida.
+
+Devuelve "" si se eligió una opción que no está permitida, así el menú
+la trata como una opción inválida.
+*/
+func printMenu(reader *bufio.Reader, title string, options []menuOption) string {
+	fmt.Println(title)
+	allowed := map[string]bool{}
+	for _, o := range options {
+		if o.allowed {
+			fmt.Printf("%s) %s\n", o.key, o.label)
+			allowed[o.key] = true
+		}
+	}
+	fmt.Print("Opción: ")
+
+	op := readLine(reader)
+	if !allowed[op] {
+		return ""
+	}
+	return op
+}
@@ -0,0 +1,692 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/csvfile"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/errclass"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/storage"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+/*
+Códigos de salida de los subcomandos.
+
+Permiten que un script (CI, cron) distinga el motivo de un fallo sin
+leer el mensaje de error. Los valores son estables: no se reordenan.
+*/
+const (
+	exitOK        = 0  // El comando terminó bien.
+	exitError     = 1  // Error inesperado (almacenamiento, archivos, ...).
+	exitUsage     = 2  // Comando u opciones inválidas.
+	exitInvalid   = 3  // Datos que no cumplen las reglas del dominio.
+	exitNotFound  = 4  // La entidad buscada no existe (producto, cliente, pedido, ...).
+	exitConflict  = 5  // El estado actual no permite la operación (duplicado, precio cambiado, ...).
+	exitNoStock   = 6  // Stock insuficiente.
+	exitEmptyCart = 7  // Checkout de un carrito vacío.
+	exitPayment   = 8  // El proveedor de pagos rechazó o no confirmó el cobro.
+	exitAuth      = 9  // Falta la clave de API o las credenciales son incorrectas.
+	exitForbidden = 10 // El usuario no tiene permiso para la operación.
+)
+
+// errUsage marca los errores de uso (comando desconocido, opción faltante).
+var errUsage = errors.New("uso inválido")
+
+// errRowsRejected marca una importación en la que alguna fila fue rechazada.
+var errRowsRejected = errors.New("filas rechazadas")
+
+/*
+exitCodes asocia los errores propios de la CLI con su código de salida.
+Los errores de dominio se traducen según su clasificación
+(errclass.Classify y kindExitCodes), la misma que usa la API para sus
+códigos HTTP.
+*/
+var exitCodes = []struct {
+	err  error
+	code int
+}{
+	{errUsage, exitUsage},
+	{errRowsRejected, exitInvalid},
+}
+
+// kindExitCodes es el código de salida de cada tipo de error de dominio.
+var kindExitCodes = map[errclass.Kind]int{
+	errclass.Invalid:         exitInvalid,
+	errclass.NotFound:        exitNotFound,
+	errclass.Conflict:        exitConflict,
+	errclass.NoStock:         exitNoStock,
+	errclass.EmptyCart:       exitEmptyCart,
+	errclass.PaymentFailed:   exitPayment,
+	errclass.PaymentTimeout:  exitPayment,
+	errclass.Unauthenticated: exitAuth,
+	errclass.Forbidden:       exitForbidden,
+}
+
+// exitCode devuelve el código de salida que corresponde a err.
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+	for _, ec := range exitCodes {
+		if errors.Is(err, ec.err) {
+			return ec.code
+		}
+	}
+	if c := errclass.Classify(err); c.Kind != errclass.Unknown {
+		return kindExitCodes[c.Kind]
+	}
+	return exitError
+}
+
+/*
+app agrupa las dependencias que usan los subcomandos; son las mismas
+que recibe el menú interactivo.
+
+apiKey es la clave indicada con -api-key; runCommand la verifica y deja
+en user al usuario autenticado.
+*/
+type app struct {
+	uow          usecase.UnitOfWork
+	products     storage.ProductStore
+	customers    storage.CustomerStore
+	customerDeps usecase.CustomerDeps
+	cart         usecase.CartDeps
+	checkout     usecase.CheckoutDeps
+	orders       usecase.OrderDeps
+	users        usecase.UserDeps
+	out          printer
+	apiKey       string
+	user         domain.User
+}
+
+/*
+command es un subcomando no interactivo, por ejemplo "product create".
+
+usage describe sus opciones; run recibe los argumentos que siguen al
+nombre del comando; access indica quién lo puede ejecutar.
+*/
+type command struct {
+	name   string
+	usage  string
+	run    func(a app, args []string) error
+	access access
+}
+
+// commands es la lista de subcomandos, en el orden en que se muestran en la ayuda.
+var commands = []command{
+	{"login", "--username NOMBRE (contraseña por la entrada estándar)", cmdLogin, accessPublic},
+	{"user create", "--username NOMBRE --role ROL [--customer N] (contraseña por la entrada estándar)", cmdUserCreate, accessSetup},
+	{"user list", "[--output FORMATO]", cmdUserList, accessAdmin},
+	{"user key", "--username NOMBRE", cmdUserKey, accessAdmin},
+	{"product create", "--id N --name TEXTO --price 12.50 --stock N", cmdProductCreate, accessStaff},
+	{"product list", "[--output FORMATO]", cmdProductList, accessAuthenticated},
+	{"product price", "--id N --price 12.50", cmdProductPrice, accessStaff},
+	{"product import", "--file ARCHIVO [--upsert] [--dry-run]", cmdProductImport, accessStaff},
+	{"product export", "[--file ARCHIVO]", cmdProductExport, accessStaff},
+	{"customer create", "--id N --name TEXTO --email CORREO", cmdCustomerCreate, accessStaff},
+	{"customer list", "[--output FORMATO]", cmdCustomerList, accessStaff},
+	{"customer show", "--id N [--output FORMATO]", cmdCustomerShow, accessAuthenticated},
+	{"customer search", "--query TEXTO [--output FORMATO]", cmdCustomerSearch, accessStaff},
+	{"customer update", "--id N [--name TEXTO] [--email CORREO]", cmdCustomerUpdate, accessStaff},
+	{"customer delete", "--id N [--anonymize]", cmdCustomerDelete, accessAdmin},
+	{"customer import", "--file ARCHIVO [--upsert] [--dry-run]", cmdCustomerImport, accessStaff},
+	{"customer export", "[--file ARCHIVO]", cmdCustomerExport, accessStaff},
+	{"cart show", "--customer N [--output FORMATO]", cmdCartShow, accessAuthenticated},
+	{"cart add", "--customer N --product N [--quantity N]", cmdCartAdd, accessAuthenticated},
+	{"cart set", "--customer N --product N --quantity N", cmdCartSet, accessAuthenticated},
+	{"cart remove", "--customer N --product N", cmdCartRemove, accessAuthenticated},
+	{"cart clear", "--customer N", cmdCartClear, accessAuthenticated},
+	{"checkout", "--customer N [--accept-prices] [--payment-code CÓDIGO] [--output FORMATO]", cmdCheckout, accessAuthenticated},
+	{"order show", "--id ID [--output FORMATO]", cmdOrderShow, accessAuthenticated},
+	{"order list", "--customer N [--output FORMATO]", cmdOrderList, accessAuthenticated},
+}
+
+/*
+runCommand ejecuta el subcomando indicado en args y devuelve el código
+de salida del programa.
+
+Los resultados se escriben en la salida estándar y los errores en la
+salida de errores, así un script puede redirigirlos por separado.
+*/
+func runCommand(a app, args []string) int {
+	cmd, rest, ok := findCommand(args)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Comando desconocido: %s\n\n", strings.Join(args, " "))
+		printCommandsUsage(os.Stderr)
+		return exitUsage
+	}
+
+	user, err := authorizeCommand(a, cmd)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return exitCode(err)
+	}
+	a.user = user
+
+	// Igual que en el menú del carrito: las reservas vencidas se liberan antes de operar.
+	if _, err := usecase.ReleaseExpiredReservations(a.cart.Reservations, a.cart.Clock); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		return exitCode(err)
+	}
+
+	err = cmd.run(a, rest)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		if hint, ok := stockHint(err); ok {
+			fmt.Fprintln(os.Stderr, hint)
+		}
+	}
+	return exitCode(err)
+}
+
+// findCommand busca el comando más largo que coincide con el comienzo de args.
+func findCommand(args []string) (command, []string, bool) {
+	for _, n := range []int{2, 1} {
+		if len(args) < n {
+			continue
+		}
+		name := strings.Join(args[:n], " ")
+		for _, c := range commands {
+			if c.name == name {
+				return c, args[n:], true
+			}
+		}
+	}
+	return command{}, nil, false
+}
+
+// printCommandsUsage lista los subcomandos disponibles y sus opciones.
+func printCommandsUsage(w io.Writer) {
+	fmt.Fprintln(w, "Subcomandos (sin subcomando se abre el menú interactivo):")
+	for _, c := range commands {
+		fmt.Fprintln(w, " ", strings.TrimSpace(c.name+" "+c.usage))
+	}
+	fmt.Fprintln(w, "\nFORMATO es text, json, csv o table (también se puede indicar antes del subcomando con -output).")
+	fmt.Fprintln(w, "\nSalvo login (y user create mientras no haya usuarios), los subcomandos piden la clave")
+	fmt.Fprintln(w, "de API de login, con -api-key o la variable ECOMMERCE_API_KEY.")
+	fmt.Fprintln(w, "\nCódigos de salida: 0 ok, 1 error inesperado, 2 uso inválido, 3 datos inválidos,")
+	fmt.Fprintln(w, "4 no encontrado, 5 conflicto, 6 sin stock, 7 carrito vacío, 8 pago no realizado,")
+	fmt.Fprintln(w, "9 no autenticado, 10 sin permiso.")
+}
+
+/*
+parseFlags interpreta las opciones de un subcomando.
+
+Reglas:
+- Las opciones listadas en required son obligatorias.
+- No se aceptan argumentos sueltos después de las opciones.
+- Cualquier problema se informa como errUsage.
+*/
+func parseFlags(fs *flag.FlagSet, args []string, required ...string) error {
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args); err != nil {
+		// Las opciones se listan tanto con -h como ante una opción desconocida.
+		fmt.Fprintf(os.Stderr, "Opciones de %s:\n", fs.Name())
+		fs.SetOutput(os.Stderr)
+		fs.PrintDefaults()
+		return fmt.Errorf("%w: %s: %v", errUsage, fs.Name(), err)
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("%w: %s: argumento inesperado %q", errUsage, fs.Name(), fs.Arg(0))
+	}
+
+	seen := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { seen[f.Name] = true })
+	for _, name := range required {
+		if !seen[name] {
+			return fmt.Errorf("%w: %s: falta la opción --%s", errUsage, fs.Name(), name)
+		}
+	}
+	return nil
+}
+
+// parseMoney convierte el valor de una opción de precio en un monto.
+func parseMoney(s string) (domain.Money, error) {
+	return domain.ParseMoney(s, domain.DefaultCurrency)
+}
+
+// cmdProductCreate crea un producto (product create).
+func cmdProductCreate(a app, args []string) error {
+	fs := flag.NewFlagSet("product create", flag.ContinueOnError)
+	id := fs.Int("id", 0, "ID del producto")
+	name := fs.String("name", "", "nombre")
+	price := fs.String("price", "", "precio (por ejemplo 12.50)")
+	stock := fs.Int("stock", 0, "stock inicial")
+	if err := parseFlags(fs, args, "id", "name", "price", "stock"); err != nil {
+		return err
+	}
+
+	m, err := parseMoney(*price)
+	if err != nil {
+		return err
+	}
+	p := domain.Product{ID: *id, Name: *name, Price: m, Stock: *stock}
+	if err := usecase.CreateProduct(a.products, p); err != nil {
+		return err
+	}
+	fmt.Println("Producto creado correctamente.")
+	return nil
+}
+
+// cmdProductList lista los productos (product list).
+func cmdProductList(a app, args []string) error {
+	fs := flag.NewFlagSet("product list", flag.ContinueOnError)
+	outputFlag(fs, &a.out)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	products, err := usecase.ListProducts(a.products)
+	if err != nil {
+		return err
+	}
+	a.out.Products(products)
+	return nil
+}
+
+// cmdProductPrice cambia el precio de un producto (product price).
+func cmdProductPrice(a app, args []string) error {
+	fs := flag.NewFlagSet("product price", flag.ContinueOnError)
+	id := fs.Int("id", 0, "ID del producto")
+	price := fs.String("price", "", "precio nuevo (por ejemplo 12.50)")
+	if err := parseFlags(fs, args, "id", "price"); err != nil {
+		return err
+	}
+
+	m, err := parseMoney(*price)
+	if err != nil {
+		return err
+	}
+	p, err := usecase.ChangeProductPrice(a.products, *id, m)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Precio actualizado:", p.Name, p.Price)
+	return nil
+}
+
+/*
+cmdProductImport importa productos desde un CSV (product import).
+
+Las filas inválidas no detienen la importación: se informan en la
+salida de errores con su número de línea y el comando termina con
+exitInvalid. Con --dry-run solo se valida, sin guardar.
+*/
+func cmdProductImport(a app, args []string) error {
+	fs := flag.NewFlagSet("product import", flag.ContinueOnError)
+	path, opts := importFlags(fs)
+	if err := parseFlags(fs, args, "file"); err != nil {
+		return err
+	}
+
+	report, err := importCSV(*path, csvfile.ReadProducts, func(rows []usecase.ImportRow[domain.Product]) usecase.ImportReport {
+		return usecase.ImportProducts(a.uow, rows, opts())
+	})
+	if err != nil {
+		return err
+	}
+	return importResult(report)
+}
+
+// cmdProductExport exporta los productos a CSV, ordenados por ID (product export).
+func cmdProductExport(a app, args []string) error {
+	fs := flag.NewFlagSet("product export", flag.ContinueOnError)
+	path := fs.String("file", "", "archivo de destino (por defecto, la salida estándar)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	products, err := usecase.ListProducts(a.products)
+	if err != nil {
+		return err
+	}
+	slices.SortFunc(products, func(x, y domain.Product) int { return cmp.Compare(x.ID, y.ID) })
+	return exportCSV(*path, func(w io.Writer) error { return csvfile.WriteProducts(w, products) })
+}
+
+// cmdCustomerCreate crea un cliente (customer create).
+func cmdCustomerCreate(a app, args []string) error {
+	fs := flag.NewFlagSet("customer create", flag.ContinueOnError)
+	id := fs.Int("id", 0, "ID del cliente")
+	name := fs.String("name", "", "nombre")
+	email := fs.String("email", "", "correo electrónico")
+	if err := parseFlags(fs, args, "id", "name", "email"); err != nil {
+		return err
+	}
+
+	c := domain.Customer{ID: *id, Name: *name, Email: *email}
+	if err := usecase.CreateCustomer(a.customers, c); err != nil {
+		return err
+	}
+	fmt.Println("Cliente creado correctamente.")
+	return nil
+}
+
+// cmdCustomerList lista los clientes (customer list).
+func cmdCustomerList(a app, args []string) error {
+	fs := flag.NewFlagSet("customer list", flag.ContinueOnError)
+	outputFlag(fs, &a.out)
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	customers, err := usecase.ListCustomers(a.customers)
+	if err != nil {
+		return err
+	}
+	a.out.Customers(customers)
+	return nil
+}
+
+// cmdCustomerShow muestra un cliente (customer show); un cliente solo puede ver el suyo.
+func cmdCustomerShow(a app, args []string) error {
+	fs := flag.NewFlagSet("customer show", flag.ContinueOnError)
+	id := fs.Int("id", 0, "ID del cliente")
+	outputFlag(fs, &a.out)
+	if err := parseFlags(fs, args, "id"); err != nil {
+		return err
+	}
+	if err := domain.AuthorizeCustomer(a.user, *id); err != nil {
+		return err
+	}
+
+	c, err := usecase.GetCustomer(a.customers, *id)
+	if err != nil {
+		return err
+	}
+	a.out.Customers([]domain.Customer{c})
+	return nil
+}
+
+// cmdCustomerSearch lista los clientes cuyo nombre o email contienen el texto (customer search).
+func cmdCustomerSearch(a app, args []string) error {
+	fs := flag.NewFlagSet("customer search", flag.ContinueOnError)
+	query := fs.String("query", "", "texto a buscar en el nombre o el email")
+	outputFlag(fs, &a.out)
+	if err := parseFlags(fs, args, "query"); err != nil {
+		return err
+	}
+	customers, err := usecase.SearchCustomers(a.customers, *query)
+	if err != nil {
+		return err
+	}
+	a.out.Customers(customers)
+	return nil
+}
+
+// cmdCustomerUpdate cambia el nombre y/o el email de un cliente (customer update).
+func cmdCustomerUpdate(a app, args []string) error {
+	fs := flag.NewFlagSet("customer update", flag.ContinueOnError)
+	id := fs.Int("id", 0, "ID del cliente")
+	name := fs.String("name", "", "nombre nuevo")
+	email := fs.String("email", "", "correo electrónico nuevo")
+	if err := parseFlags(fs, args, "id"); err != nil {
+		return err
+	}
+	if *name == "" && *email == "" {
+		return fmt.Errorf("%w: indica --name, --email o ambos", errUsage)
+	}
+
+	if _, err := usecase.UpdateCustomer(a.customers, *id, usecase.UpdateCustomerRequest{Name: *name, Email: *email}); err != nil {
+		return err
+	}
+	fmt.Println("Cliente actualizado correctamente.")
+	return nil
+}
+
+/*
+cmdCustomerDelete da de baja un cliente y sus usuarios (customer delete).
+
+Un cliente con pedidos solo se puede anonimizar, y hay que pedirlo con
+--anonymize; sin esa opción el comando falla con código 5.
+*/
+func cmdCustomerDelete(a app, args []string) error {
+	fs := flag.NewFlagSet("customer delete", flag.ContinueOnError)
+	id := fs.Int("id", 0, "ID del cliente")
+	anonymize := fs.Bool("anonymize", false, "si tiene pedidos, anonimizar sus datos en lugar de fallar")
+	if err := parseFlags(fs, args, "id"); err != nil {
+		return err
+	}
+
+	res, err := usecase.DeleteCustomer(a.customerDeps, *id, *anonymize)
+	if err != nil {
+		return err
+	}
+	printCustomerDeletion(res)
+	return nil
+}
+
+/*
+cmdCustomerImport importa clientes desde un CSV (customer import), con
+las mismas reglas que product import.
+*/
+func cmdCustomerImport(a app, args []string) error {
+	fs := flag.NewFlagSet("customer import", flag.ContinueOnError)
+	path, opts := importFlags(fs)
+	if err := parseFlags(fs, args, "file"); err != nil {
+		return err
+	}
+
+	report, err := importCSV(*path, csvfile.ReadCustomers, func(rows []usecase.ImportRow[domain.Customer]) usecase.ImportReport {
+		return usecase.ImportCustomers(a.uow, rows, opts())
+	})
+	if err != nil {
+		return err
+	}
+	return importResult(report)
+}
+
+// cmdCustomerExport exporta los clientes a CSV, ordenados por ID (customer export).
+func cmdCustomerExport(a app, args []string) error {
+	fs := flag.NewFlagSet("customer export", flag.ContinueOnError)
+	path := fs.String("file", "", "archivo de destino (por defecto, la salida estándar)")
+	if err := parseFlags(fs, args); err != nil {
+		return err
+	}
+	customers, err := usecase.ListCustomers(a.customers)
+	if err != nil {
+		return err
+	}
+	slices.SortFunc(customers, func(x, y domain.Customer) int { return cmp.Compare(x.ID, y.ID) })
+	return exportCSV(*path, func(w io.Writer) error { return csvfile.WriteCustomers(w, customers) })
+}
+
+/*
+importFlags define las opciones comunes de product import y customer
+import; opts arma las opciones del caso de uso después de parseFlags.
+*/
+func importFlags(fs *flag.FlagSet) (path *string, opts func() usecase.ImportOptions) {
+	path = fs.String("file", "", "archivo CSV a importar")
+	upsert := fs.Bool("upsert", false, "actualizar los IDs que ya existen (por defecto son un error)")
+	dryRun := fs.Bool("dry-run", false, "validar sin guardar nada")
+	return path, func() usecase.ImportOptions {
+		mode := usecase.ImportCreateOnly
+		if *upsert {
+			mode = usecase.ImportUpsert
+		}
+		return usecase.ImportOptions{Mode: mode, DryRun: *dryRun}
+	}
+}
+
+// importResult muestra el reporte de una importación y devuelve errRowsRejected si hubo filas rechazadas.
+func importResult(report usecase.ImportReport) error {
+	printImportReport(os.Stdout, os.Stderr, report)
+	if len(report.Errors) > 0 {
+		return fmt.Errorf("%w: %d", errRowsRejected, len(report.Errors))
+	}
+	return nil
+}
+
+// cmdCartShow muestra el carrito de un cliente con su total (cart show).
+func cmdCartShow(a app, args []string) error {
+	fs := flag.NewFlagSet("cart show", flag.ContinueOnError)
+	outputFlag(fs, &a.out)
+	customerID := fs.Int("customer", 0, "ID del cliente")
+	if err := parseFlags(fs, args, "customer"); err != nil {
+		return err
+	}
+	if err := domain.AuthorizeCustomer(a.user, *customerID); err != nil {
+		return err
+	}
+	return a.out.Cart(a.cart.Carts, *customerID)
+}
+
+// cmdCartAdd agrega unidades de un producto al carrito (cart add); por defecto 1.
+func cmdCartAdd(a app, args []string) error {
+	fs := flag.NewFlagSet("cart add", flag.ContinueOnError)
+	customerID := fs.Int("customer", 0, "ID del cliente")
+	productID := fs.Int("product", 0, "ID del producto")
+	quantity := fs.Int("quantity", 1, "unidades a agregar")
+	if err := parseFlags(fs, args, "customer", "product"); err != nil {
+		return err
+	}
+	if err := domain.AuthorizeCustomer(a.user, *customerID); err != nil {
+		return err
+	}
+
+	if _, err := usecase.AddProductToCart(a.cart, *customerID, *productID, *quantity); err != nil {
+		return err
+	}
+	fmt.Println("Producto agregado al carrito.")
+	return nil
+}
+
+// cmdCartSet fija la cantidad de un producto en el carrito (cart set).
+func cmdCartSet(a app, args []string) error {
+	fs := flag.NewFlagSet("cart set", flag.ContinueOnError)
+	customerID := fs.Int("customer", 0, "ID del cliente")
+	productID := fs.Int("product", 0, "ID del producto")
+	quantity := fs.Int("quantity", 0, "cantidad nueva (0 para quitar)")
+	if err := parseFlags(fs, args, "customer", "product", "quantity"); err != nil {
+		return err
+	}
+	if err := domain.AuthorizeCustomer(a.user, *customerID); err != nil {
+		return err
+	}
+
+	if _, err := usecase.SetCartItemQuantity(a.cart, *customerID, *productID, *quantity); err != nil {
+		return err
+	}
+	fmt.Println("Cantidad actualizada.")
+	return nil
+}
+
+// cmdCartRemove quita un producto del carrito (cart remove).
+func cmdCartRemove(a app, args []string) error {
+	fs := flag.NewFlagSet("cart remove", flag.ContinueOnError)
+	customerID := fs.Int("customer", 0, "ID del cliente")
+	productID := fs.Int("product", 0, "ID del producto")
+	if err := parseFlags(fs, args, "customer", "product"); err != nil {
+		return err
+	}
+	if err := domain.AuthorizeCustomer(a.user, *customerID); err != nil {
+		return err
+	}
+
+	if _, err := usecase.RemoveProductFromCart(a.cart, *customerID, *productID); err != nil {
+		return err
+	}
+	fmt.Println("Producto quitado.")
+	return nil
+}
+
+// cmdCartClear vacía el carrito (cart clear).
+func cmdCartClear(a app, args []string) error {
+	fs := flag.NewFlagSet("cart clear", flag.ContinueOnError)
+	customerID := fs.Int("customer", 0, "ID del cliente")
+	if err := parseFlags(fs, args, "customer"); err != nil {
+		return err
+	}
+	if err := domain.AuthorizeCustomer(a.user, *customerID); err != nil {
+		return err
+	}
+
+	if err := usecase.ClearCart(a.cart, *customerID); err != nil {
+		return err
+	}
+	fmt.Println("Carrito vaciado.")
+	return nil
+}
+
+/*
+cmdCheckout confirma la compra sin preguntas.
+
+Como no hay a quién preguntarle:
+- Con la política reject, los precios que cambiaron solo se aceptan si se
+  pasa --accept-prices; si no, el comando falla con exitConflict.
+- Si el pago pide verificación, el código se indica con --payment-code
+  (sin él, el comando falla con exitPayment).
+*/
+func cmdCheckout(a app, args []string) error {
+	fs := flag.NewFlagSet("checkout", flag.ContinueOnError)
+	outputFlag(fs, &a.out)
+	customerID := fs.Int("customer", 0, "ID del cliente")
+	acceptPrices := fs.Bool("accept-prices", false, "aceptar los precios que cambiaron")
+	paymentCode := fs.String("payment-code", "", "código de verificación del pago")
+	if err := parseFlags(fs, args, "customer"); err != nil {
+		return err
+	}
+	if err := domain.AuthorizeCustomer(a.user, *customerID); err != nil {
+		return err
+	}
+
+	if *acceptPrices {
+		if _, err := usecase.AcceptCartPrices(a.cart, *customerID); err != nil {
+			return err
+		}
+	}
+
+	order, err := usecase.Checkout(a.checkout, usecase.CheckoutRequest{
+		CustomerID:  *customerID,
+		PaymentCode: *paymentCode,
+	})
+	if err != nil {
+		return err
+	}
+	a.out.Order(order)
+	return nil
+}
+
+// cmdOrderShow muestra el comprobante de un pedido (order show); un cliente solo ve los suyos.
+func cmdOrderShow(a app, args []string) error {
+	fs := flag.NewFlagSet("order show", flag.ContinueOnError)
+	outputFlag(fs, &a.out)
+	id := fs.String("id", "", "ID del pedido")
+	if err := parseFlags(fs, args, "id"); err != nil {
+		return err
+	}
+
+	order, err := usecase.GetOrder(a.orders.Orders, *id)
+	if err != nil {
+		return err
+	}
+	if err := domain.AuthorizeCustomer(a.user, order.CustomerID); err != nil {
+		return err
+	}
+	a.out.Order(order)
+	return nil
+}
+
+// cmdOrderList lista los pedidos de un cliente (order list).
+func cmdOrderList(a app, args []string) error {
+	fs := flag.NewFlagSet("order list", flag.ContinueOnError)
+	outputFlag(fs, &a.out)
+	customerID := fs.Int("customer", 0, "ID del cliente")
+	if err := parseFlags(fs, args, "customer"); err != nil {
+		return err
+	}
+	if err := domain.AuthorizeCustomer(a.user, *customerID); err != nil {
+		return err
+	}
+	orders, err := usecase.ListOrdersByCustomer(a.orders.Orders, *customerID)
+	if err != nil {
+		return err
+	}
+	a.out.Orders(orders)
+	return nil
+}
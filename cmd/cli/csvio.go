@@ -0,0 +1,80 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+/*
+csvReader lee las filas de un archivo CSV de importación
+(csvfile.ReadProducts o csvfile.ReadCustomers).
+*/
+type csvReader[T any] func(io.Reader) ([]usecase.ImportRow[T], []*usecase.RowError, error)
+
+/*
+importCSV lee el archivo path y pasa sus filas a apply (el caso de uso
+de importación).
+
+Las filas que no se pudieron leer (por ejemplo, un stock que no es un
+número) se agregan a los errores del reporte, ordenados por línea, así
+el usuario ve un único reporte. Solo devuelve error si el archivo no se
+puede abrir o no es un CSV válido; en ese caso no se importa nada.
+*/
+func importCSV[T any](path string, read csvReader[T], apply func([]usecase.ImportRow[T]) usecase.ImportReport) (usecase.ImportReport, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return usecase.ImportReport{}, err
+	}
+	defer f.Close()
+
+	rows, rowErrs, err := read(f)
+	if err != nil {
+		return usecase.ImportReport{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	report := apply(rows)
+	report.Errors = append(rowErrs, report.Errors...)
+	slices.SortStableFunc(report.Errors, func(a, b *usecase.RowError) int { return cmp.Compare(a.Line, b.Line) })
+	return report, nil
+}
+
+/*
+printImportReport muestra los errores por fila en errw y el resumen en w.
+
+El menú usa la misma salida para ambos; los subcomandos separan los
+errores (stderr) del resumen (stdout).
+*/
+func printImportReport(w, errw io.Writer, r usecase.ImportReport) {
+	for _, e := range r.Errors {
+		fmt.Fprintln(errw, "Error:", e)
+	}
+	if r.DryRun {
+		fmt.Fprint(w, "Simulación (no se guardó nada). ")
+	}
+	fmt.Fprintf(w, "Creados: %d, actualizados: %d, rechazados: %d.\n", r.Created, r.Updated, len(r.Errors))
+}
+
+/*
+exportCSV escribe un CSV con write en el archivo path, o en la salida
+estándar si path está vacío o es "-".
+*/
+func exportCSV(path string, write func(io.Writer) error) error {
+	if path == "" || path == "-" {
+		return write(os.Stdout)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -2,14 +2,25 @@ package main
 
 import (
 	"bufio"   // Permite leer entradas del usuario desde la consola de forma eficiente
+	"cmp"     // Orden de los listados exportados (por ID)
+	"errors"  // Comparación de errores de dominio (errors.Is)
+	"flag"    // Opciones de línea de comandos (por ejemplo, modo del proveedor de pagos)
 	"fmt"     // Proporciona funciones para imprimir texto en consola
+	"io"      // Destino de las exportaciones CSV
 	"os"      // Acceso a stdin/stdout y utilidades del sistema
+	"slices"  // Orden de los listados exportados
 	"strconv" // Conversión de strings a tipos numéricos
 	"strings" // Manipulación de strings (trim, limpieza de saltos de línea)
+	"time"    // Fechas para filtrar pedidos por rango y duración de reservas
 
-	// Adaptadores: implementaciones concretas de repositorios en memoria.
-	// Representan la capa de infraestructura.
-	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/memory"
+	// Lectura y escritura de productos y clientes en CSV (importar/exportar).
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/csvfile"
+
+	// Proveedor de pagos falso: permite simular cada resultado del cobro.
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/fakepay"
+
+	// Repositorios de cada almacenamiento (opción -storage).
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/storage"
 
 	// Domain: entidades del negocio y reglas básicas (Product, Customer, Cart, errores).
 	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
@@ -23,49 +34,224 @@ import (
 Función principal del programa.
 
 Responsabilidades:
-- Inicializar dependencias (repositorios).
+- Leer las opciones de línea de comandos.
+- Inicializar dependencias (repositorios y proveedor de pagos).
 - Mostrar el menú principal.
+- Identificar al usuario (clave de API en los subcomandos, usuario y
+  contraseña en el menú interactivo).
 - Redirigir al usuario a los distintos submenús.
 */
 func main() {
+	// Modo del proveedor de pagos falso (no hay pagos reales en este proyecto).
+	paymentMode := flag.String("payment", "approve",
+		"resultado simulado de los pagos: approve, decline, timeout o verify")
+	// Tiempo que el stock queda apartado mientras el producto está en un carrito.
+	reservationTTL := flag.Duration("reservation-ttl", usecase.DefaultReservationTTL,
+		"duración de las reservas de stock de los carritos (por ejemplo 15m)")
+	// Qué hacer si el precio de un producto cambió desde que se agregó al carrito.
+	pricePolicyName := flag.String("price-policy", "reject",
+		"precios que cambiaron desde que se agregó el producto: honor, reprice o reject")
+	// Dónde se guardan los datos: en memoria (se pierden al salir), en archivos
+	// JSON, en una base SQLite o en un log de eventos.
+	storageKind := flag.String("storage", "memory", "almacenamiento: memory, file, sqlite o eventlog")
+	dataDir := flag.String("data-dir", "data", "directorio de datos (con -storage=file o -storage=eventlog)")
+	dbPath := flag.String("db", "ecommerce.db", "archivo de la base SQLite (con -storage=sqlite)")
+	// Formato de los listados: el de siempre o uno que puedan leer otros programas.
+	outputName := flag.String("output", string(outputText), "formato de los listados: text, json, csv o table")
+	// Clave de API de los subcomandos (se obtiene con el subcomando login).
+	apiKey := flag.String("api-key", os.Getenv("ECOMMERCE_API_KEY"),
+		"clave de API para los subcomandos (por defecto, la variable ECOMMERCE_API_KEY)")
+	// Inspección forense del log de eventos: muestra el estado en una fecha y sale.
+	replayUntil := flag.String("replay-until", "",
+		"con -storage=eventlog, muestra el estado al \"dd-mm-aaaa hh:mm:ss\" indicado y sale")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Uso: %s [opciones] [subcomando]\n\nOpciones:\n", os.Args[0])
+		flag.PrintDefaults()
+		fmt.Fprintln(flag.CommandLine.Output())
+		printCommandsUsage(flag.CommandLine.Output())
+	}
+	flag.Parse()
+
+	format, err := parseOutputFormat(*outputName)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(2)
+	}
+	out := printer{format: format, w: os.Stdout}
+
+	if *replayUntil != "" {
+		if *storageKind != "eventlog" {
+			fmt.Println("-replay-until requiere -storage=eventlog")
+			os.Exit(2)
+		}
+		if err := runReplay(out, *dataDir, *replayUntil); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	mode, ok := map[string]fakepay.Mode{
+		"approve": fakepay.Approve,
+		"decline": fakepay.Decline,
+		"timeout": fakepay.Timeout,
+		"verify":  fakepay.RequireVerification,
+	}[*paymentMode]
+	if !ok {
+		fmt.Println("Modo de pago inválido:", *paymentMode)
+		os.Exit(2)
+	}
+	pricePolicy, ok := map[string]usecase.PricePolicy{
+		"honor":   usecase.HonorSnapshotPrice,
+		"reprice": usecase.RepriceAtCheckout,
+		"reject":  usecase.RejectPriceChanges,
+	}[*pricePolicyName]
+	if !ok {
+		fmt.Println("Política de precios inválida:", *pricePolicyName)
+		os.Exit(2)
+	}
+
 	// Reader central para toda la CLI.
 	// Se reutiliza en todo el programa para leer entradas del usuario.
 	reader := bufio.NewReader(os.Stdin)
 
-	// Inicialización de repositorios en memoria.
+	// Inicialización de repositorios (en memoria, archivos o SQLite, según -storage).
 	// Estos repositorios implementan interfaces definidas en la capa usecase,
 	// lo que permite desacoplar la lógica del almacenamiento.
-	productRepo := memory.NewProductRepo()
-	customerRepo := memory.NewCustomerRepo()
-	cartRepo := memory.NewCartRepo()
+	store, err := storage.Open(*storageKind, *dataDir, *dbPath)
+	if err != nil {
+		fmt.Println("No se pudo abrir el almacenamiento:", err)
+		os.Exit(1)
+	}
+	// Los pagos se guardan junto a los datos, para poder cancelar o
+	// devolver pedidos de ejecuciones anteriores.
+	payments, err := fakepay.Open(storage.PaymentsFile(*storageKind, *dataDir, *dbPath), fakepay.Config{Mode: mode})
+	if err != nil {
+		fmt.Println("No se pudo abrir el archivo de pagos:", err)
+		os.Exit(1)
+	}
+	productRepo := store.Products
+	customerRepo := store.Customers
+	cartRepo := store.Carts
+	orderRepo := store.Orders
+	returnRepo := store.Returns
+	reservationRepo := store.Reservations
+
+	// Unidad de trabajo: agrupa los repositorios que
+	// se modifican juntos (stock, carrito, reservas, pedidos y devoluciones)
+	// para poder deshacerlos.
+	uow := store.UnitOfWork
+
+	// Dependencias del carrito: además de productos, administra reservas de stock.
+	cartDeps := usecase.CartDeps{
+		UnitOfWork:     uow,
+		Carts:          cartRepo,
+		Products:       productRepo,
+		Reservations:   reservationRepo,
+		ReservationTTL: *reservationTTL,
+	}
+
+	// Dependencias del checkout. OrderIDs y Clock quedan en nil para usar
+	// los valores por defecto (IDs ULID y hora del sistema).
+	checkoutDeps := usecase.CheckoutDeps{
+		UnitOfWork:   uow,
+		Carts:        cartRepo,
+		Products:     productRepo,
+		Customers:    customerRepo,
+		Orders:       orderRepo,
+		Payments:     payments,
+		Reservations: reservationRepo,
+		PricePolicy:  pricePolicy,
+	}
+
+	// Dependencias de los casos de uso que modifican pedidos ya confirmados
+	// (por ejemplo, la cancelación repone stock).
+	orderDeps := usecase.OrderDeps{
+		UnitOfWork: uow,
+		Orders:     orderRepo,
+		Payments:   payments,
+	}
+
+	// Dependencias de las devoluciones (RMA) sobre pedidos entregados.
+	returnDeps := usecase.ReturnDeps{
+		UnitOfWork: uow,
+		Orders:     orderRepo,
+		Returns:    returnRepo,
+		Payments:   payments,
+	}
+
+	// Dependencias de la baja de clientes: además del cliente, se borran
+	// su carrito, sus reservas y sus usuarios, y se anonimizan sus pedidos
+	// (todo con los repositorios de la unidad de trabajo).
+	customerDeps := usecase.CustomerDeps{
+		UnitOfWork: uow,
+	}
+
+	// Usuarios: autenticación de los subcomandos y del menú interactivo.
+	userDeps := usecase.UserDeps{
+		Users:     store.Users,
+		Customers: customerRepo,
+	}
+
+	// Con argumentos después de las opciones se ejecuta un subcomando
+	// (por ejemplo "product list") y el programa termina con su código de salida.
+	if flag.NArg() > 0 {
+		os.Exit(runCommand(app{
+			uow:          uow,
+			products:     productRepo,
+			customers:    customerRepo,
+			customerDeps: customerDeps,
+			cart:         cartDeps,
+			checkout:     checkoutDeps,
+			orders:       orderDeps,
+			users:        userDeps,
+			out:          out,
+			apiKey:       *apiKey,
+		}, flag.Args()))
+	}
+
+	// El menú interactivo pide usuario y contraseña; cada menú muestra
+	// solo las opciones que el rol del usuario permite.
+	user, err := interactiveLogin(reader, userDeps)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(exitCode(err))
+	}
+	fmt.Printf("Sesión iniciada como %s (%s).\n", user.Username, user.Role)
+	staff := user.Role.IsStaff()
 
 	// Bucle principal del sistema.
 	// Se ejecuta indefinidamente hasta que el usuario elija salir.
 	for {
-		fmt.Println("\n=== Sistema de Gestión de e-commerce (CLI) ===")
-		fmt.Println("1) Productos")
-		fmt.Println("2) Clientes")
-		fmt.Println("3) Carrito")
-		fmt.Println("0) Salir")
-		fmt.Print("Opción: ")
-
 		// Se lee la opción como string para evitar errores de parseo directo.
-		opcion := readLine(reader)
+		opcion := printMenu(reader, "\n=== Sistema de Gestión de e-commerce (CLI) ===", []menuOption{
+			{"1", "Productos", true},
+			{"2", "Clientes", staff},
+			{"3", "Carrito", true},
+			{"4", "Pedidos", true},
+			{"5", "Usuarios", user.Role == domain.RoleAdmin},
+			{"0", "Salir", true},
+		})
 
 		// Enrutador del menú principal.
 		switch opcion {
 		case "1":
-			productsMenu(reader, productRepo)
+			productsMenu(reader, out, user, productRepo, uow)
 
 		case "2":
-			customersMenu(reader, customerRepo)
+			customersMenu(reader, out, user, customerRepo, customerDeps)
 
 		case "3":
 			// El carrito necesita acceso a:
-			// - CartRepository (carrito del cliente)
-			// - ProductRepositoryForCart (validación de productos y stock)
-			// - CustomerRepositoryForCheckout (obtener nombre del cliente)
-			cartMenu(reader, cartRepo, productRepo, customerRepo)
+			// - CartDeps (carrito, productos y reservas de stock)
+			// - CheckoutDeps (cliente, pedidos, pagos y unidad de trabajo del checkout)
+			cartMenu(reader, out, user, cartDeps, checkoutDeps)
+
+		case "4":
+			ordersMenu(reader, out, user, orderDeps, returnDeps)
+
+		case "5":
+			usersMenu(reader, out, userDeps)
 
 		case "0":
 			fmt.Println("Saliendo del sistema...")
@@ -82,17 +268,23 @@ productsMenu gestiona todas las operaciones relacionadas con productos.
 
 Recibe:
 - reader: para leer entradas del usuario.
-- repo: interfaz ProductRepository (no depende de memory directamente).
+- out: para imprimir los listados en el formato elegido (-output).
+- user: un cliente solo puede listar productos; el resto es de admin y staff.
+- repo: interfaz ProductImportRepository (no depende de memory directamente):
+  crea, lista y modifica productos existentes.
+- uow: la unidad de trabajo con la que se guarda la importación por lotes.
 */
-func productsMenu(reader *bufio.Reader, repo usecase.ProductRepository) {
+func productsMenu(reader *bufio.Reader, out printer, user domain.User, repo usecase.ProductImportRepository, uow usecase.UnitOfWork) {
+	staff := user.Role.IsStaff()
 	for {
-		fmt.Println("\n--- Productos ---")
-		fmt.Println("1) Crear producto")
-		fmt.Println("2) Listar productos")
-		fmt.Println("0) Volver")
-		fmt.Print("Opción: ")
-
-		op := readLine(reader)
+		op := printMenu(reader, "\n--- Productos ---", []menuOption{
+			{"1", "Crear producto", staff},
+			{"2", "Listar productos", true},
+			{"3", "Cambiar precio", staff},
+			{"4", "Importar CSV", staff},
+			{"5", "Exportar CSV", staff},
+			{"0", "Volver", true},
+		})
 
 		switch op {
 		case "1":
@@ -101,7 +293,7 @@ func productsMenu(reader *bufio.Reader, repo usecase.ProductRepository) {
 			p := domain.Product{
 				ID:    readInt(reader, "ID: "),
 				Name:  readString(reader, "Nombre: "),
-				Price: readFloat(reader, "Precio: "),
+				Price: readMoney(reader, "Precio: "),
 				Stock: readInt(reader, "Stock: "),
 			}
 
@@ -114,17 +306,49 @@ func productsMenu(reader *bufio.Reader, repo usecase.ProductRepository) {
 
 		case "2":
 			// Caso de uso: obtiene la lista de productos.
-			products := usecase.ListProducts(repo)
-			if len(products) == 0 {
-				fmt.Println("No hay productos registrados.")
+			products, err := usecase.ListProducts(repo)
+			if err != nil {
+				fmt.Println("Error:", err)
+				continue
+			}
+			out.Products(products)
+
+		case "3":
+			productID := readInt(reader, "ID: ")
+			price := readMoney(reader, "Precio nuevo: ")
+
+			p, err := usecase.ChangeProductPrice(repo, productID, price)
+			if err != nil {
+				fmt.Println("Error:", err)
 				continue
 			}
+			fmt.Println("Precio actualizado:", p.Name, p.Price)
 
-			// Presentación en consola.
-			for _, p := range products {
-				fmt.Printf("ID:%d | %s | $%.2f | Stock:%d\n",
-					p.ID, p.Name, p.Price, p.Stock)
+		case "4":
+			path := readString(reader, "Archivo CSV: ")
+			opts := readImportOptions(reader)
+			report, err := importCSV(path, csvfile.ReadProducts, func(rows []usecase.ImportRow[domain.Product]) usecase.ImportReport {
+				return usecase.ImportProducts(uow, rows, opts)
+			})
+			if err != nil {
+				fmt.Println("Error:", err)
+				continue
+			}
+			printImportReport(os.Stdout, os.Stdout, report)
+
+		case "5":
+			path := readString(reader, "Archivo de destino: ")
+			products, err := usecase.ListProducts(repo)
+			if err != nil {
+				fmt.Println("Error:", err)
+				continue
+			}
+			slices.SortFunc(products, func(a, b domain.Product) int { return cmp.Compare(a.ID, b.ID) })
+			if err := exportCSV(path, func(w io.Writer) error { return csvfile.WriteProducts(w, products) }); err != nil {
+				fmt.Println("Error:", err)
+				continue
 			}
+			fmt.Printf("%d producto(s) exportado(s).\n", len(products))
 
 		case "0":
 			return
@@ -141,16 +365,22 @@ customersMenu gestiona las operaciones relacionadas con clientes.
 Mantiene el mismo patrón que productos:
 - La CLI solo captura datos.
 - La lógica se delega a la capa usecase.
+
+Solo lo ven admin y staff; eliminar clientes es solo de admin.
 */
-func customersMenu(reader *bufio.Reader, repo usecase.CustomerRepository) {
+func customersMenu(reader *bufio.Reader, out printer, user domain.User, repo usecase.CustomerImportRepository, deps usecase.CustomerDeps) {
 	for {
-		fmt.Println("\n--- Clientes ---")
-		fmt.Println("1) Crear cliente")
-		fmt.Println("2) Listar clientes")
-		fmt.Println("0) Volver")
-		fmt.Print("Opción: ")
-
-		op := readLine(reader)
+		op := printMenu(reader, "\n--- Clientes ---", []menuOption{
+			{"1", "Crear cliente", true},
+			{"2", "Listar clientes", true},
+			{"3", "Importar CSV", true},
+			{"4", "Exportar CSV", true},
+			{"5", "Ver cliente", true},
+			{"6", "Buscar clientes", true},
+			{"7", "Modificar cliente", true},
+			{"8", "Eliminar cliente", user.Role == domain.RoleAdmin},
+			{"0", "Volver", true},
+		})
 
 		switch op {
 		case "1":
@@ -167,16 +397,76 @@ func customersMenu(reader *bufio.Reader, repo usecase.CustomerRepository) {
 			fmt.Println("Cliente creado correctamente.")
 
 		case "2":
-			customers := usecase.ListCustomers(repo)
-			if len(customers) == 0 {
-				fmt.Println("No hay clientes registrados.")
+			customers, err := usecase.ListCustomers(repo)
+			if err != nil {
+				fmt.Println("Error:", err)
 				continue
 			}
+			out.Customers(customers)
 
-			for _, c := range customers {
-				fmt.Printf("ID:%d | %s | %s\n",
-					c.ID, c.Name, c.Email)
+		case "3":
+			path := readString(reader, "Archivo CSV: ")
+			opts := readImportOptions(reader)
+			report, err := importCSV(path, csvfile.ReadCustomers, func(rows []usecase.ImportRow[domain.Customer]) usecase.ImportReport {
+				return usecase.ImportCustomers(deps.UnitOfWork, rows, opts)
+			})
+			if err != nil {
+				fmt.Println("Error:", err)
+				continue
+			}
+			printImportReport(os.Stdout, os.Stdout, report)
+
+		case "4":
+			path := readString(reader, "Archivo de destino: ")
+			customers, err := usecase.ListCustomers(repo)
+			if err != nil {
+				fmt.Println("Error:", err)
+				continue
+			}
+			slices.SortFunc(customers, func(a, b domain.Customer) int { return cmp.Compare(a.ID, b.ID) })
+			if err := exportCSV(path, func(w io.Writer) error { return csvfile.WriteCustomers(w, customers) }); err != nil {
+				fmt.Println("Error:", err)
+				continue
 			}
+			fmt.Printf("%d cliente(s) exportado(s).\n", len(customers))
+
+		case "5":
+			c, err := usecase.GetCustomer(repo, readInt(reader, "ID: "))
+			if err != nil {
+				fmt.Println("Error:", err)
+				continue
+			}
+			out.Customers([]domain.Customer{c})
+
+		case "6":
+			customers, err := usecase.SearchCustomers(repo, readString(reader, "Texto a buscar (nombre o email): "))
+			if err != nil {
+				fmt.Println("Error:", err)
+				continue
+			}
+			out.Customers(customers)
+
+		case "7":
+			id := readInt(reader, "ID: ")
+			req := usecase.UpdateCustomerRequest{
+				Name:  readString(reader, "Nombre nuevo (vacío para no cambiarlo): "),
+				Email: readString(reader, "Email nuevo (vacío para no cambiarlo): "),
+			}
+			if _, err := usecase.UpdateCustomer(repo, id, req); err != nil {
+				fmt.Println("Error:", err)
+				continue
+			}
+			fmt.Println("Cliente actualizado correctamente.")
+
+		case "8":
+			id := readInt(reader, "ID: ")
+			anonymize := readString(reader, "Si tiene pedidos, ¿anonimizar sus datos? (s/n): ") == "s"
+			res, err := usecase.DeleteCustomer(deps, id, anonymize)
+			if err != nil {
+				fmt.Println("Error:", err)
+				continue
+			}
+			printCustomerDeletion(res)
 
 		case "0":
 			return
@@ -190,21 +480,33 @@ func customersMenu(reader *bufio.Reader, repo usecase.CustomerRepository) {
 /*
 cartMenu gestiona el carrito de compras y el proceso de checkout.
 
-Recibe los repositorios necesarios para:
-- Manipular el carrito
-- Validar productos y stock
-- Obtener información del cliente
+Recibe las dependencias necesarias para:
+- Manipular el carrito y reservar stock (CartDeps)
+- Confirmar la compra (CheckoutDeps)
+
+Un usuario con rol customer opera siempre sobre su propio carrito;
+admin y staff eligen el cliente (por ejemplo, para atender un pedido
+telefónico).
 */
 func cartMenu(
 	reader *bufio.Reader,
-	cartRepo usecase.CartRepository,
-	productRepo usecase.ProductRepositoryForCart,
-	customerRepo usecase.CustomerRepositoryForCheckout,
+	out printer,
+	user domain.User,
+	cartDeps usecase.CartDeps,
+	checkoutDeps usecase.CheckoutDeps,
 ) {
 	// Identificación del cliente que usará el carrito.
-	customerID := readInt(reader, "CustomerID: ")
+	customerID := user.CustomerID
+	if user.Role.IsStaff() {
+		customerID = readInt(reader, "CustomerID: ")
+	}
 
 	for {
+		// Las reservas vencidas se liberan antes de mostrar cada opción.
+		if _, err := usecase.ReleaseExpiredReservations(cartDeps.Reservations, cartDeps.Clock); err != nil {
+			fmt.Println("Error:", err)
+		}
+
 		fmt.Println("\n--- Carrito ---")
 		fmt.Println("1) Ver carrito")
 		fmt.Println("2) Agregar producto al carrito")
@@ -212,6 +514,7 @@ func cartMenu(
 		fmt.Println("4) Vaciar carrito")
 		fmt.Println("5) Total")
 		fmt.Println("6) Checkout (Pagar)")
+		fmt.Println("7) Cambiar cantidad de un producto")
 		fmt.Println("0) Volver")
 		fmt.Print("Opción: ")
 
@@ -219,78 +522,248 @@ func cartMenu(
 
 		switch op {
 		case "1":
-			cart := usecase.ViewCart(cartRepo, customerID)
-			if len(cart.Items) == 0 {
-				fmt.Println("Carrito vacío.")
-				continue
-			}
-
-			for _, it := range cart.Items {
-				fmt.Printf(
-					"ProdID:%d | %s | $%.2f | Cant:%d | Subtotal:$%.2f\n",
-					it.ProductID, it.Name, it.Price,
-					it.Quantity, it.Price*float64(it.Quantity),
-				)
+			if err := out.Cart(cartDeps.Carts, customerID); err != nil {
+				fmt.Println("Error:", err)
 			}
 
-			fmt.Printf("TOTAL: $%.2f\n",
-				usecase.CartTotal(cartRepo, customerID))
-
 		case "2":
 			productID := readInt(reader, "ProductID: ")
 			qty := readInt(reader, "Cantidad: ")
 
 			if _, err := usecase.AddProductToCart(
-				cartRepo, productRepo, customerID, productID, qty); err != nil {
-				fmt.Println("Error:", err)
+				cartDeps, customerID, productID, qty); err != nil {
+				printCartError(err)
 				continue
 			}
 			fmt.Println("Producto agregado al carrito.")
 
+		case "7":
+			productID := readInt(reader, "ProductID: ")
+			qty := readInt(reader, "Nueva cantidad (0 para quitar): ")
+
+			if _, err := usecase.SetCartItemQuantity(
+				cartDeps, customerID, productID, qty); err != nil {
+				printCartError(err)
+				continue
+			}
+			fmt.Println("Cantidad actualizada.")
+
 		case "3":
 			productID := readInt(reader, "ProductID a quitar: ")
-			usecase.RemoveProductFromCart(cartRepo, customerID, productID)
+			if _, err := usecase.RemoveProductFromCart(cartDeps, customerID, productID); err != nil {
+				fmt.Println("Error:", err)
+				continue
+			}
 			fmt.Println("Producto quitado.")
 
 		case "4":
-			usecase.ClearCart(cartRepo, customerID)
+			if err := usecase.ClearCart(cartDeps, customerID); err != nil {
+				fmt.Println("Error:", err)
+				continue
+			}
 			fmt.Println("Carrito vaciado.")
 
 		case "5":
-			fmt.Printf("TOTAL: $%.2f\n",
-				usecase.CartTotal(cartRepo, customerID))
+			printCartTotal(cartDeps.Carts, customerID)
 
 		case "6":
+			// Antes de cobrar se muestran los precios que cambiaron
+			// desde que se agregaron los productos y se pide confirmación
+			// (solo con la política reject; las otras no necesitan aceptarlos).
+			if checkoutDeps.PricePolicy == usecase.RejectPriceChanges &&
+				!confirmPriceChanges(reader, cartDeps, customerID) {
+				fmt.Println("Checkout cancelado.")
+				continue
+			}
+
 			// Checkout: confirma la compra y genera comprobante.
-			order, err := usecase.Checkout(
-				cartRepo, productRepo, customerRepo, customerID)
+			req := usecase.CheckoutRequest{CustomerID: customerID}
+			order, err := usecase.Checkout(checkoutDeps, req)
+			if errors.Is(err, domain.ErrPaymentActionRequired) {
+				// Segundo paso del pago: se pide el código y se reintenta.
+				req.PaymentCode = readString(reader, "Código de verificación del pago: ")
+				order, err = usecase.Checkout(checkoutDeps, req)
+			}
 			if err != nil {
 				fmt.Println("Error:", err)
 				continue
 			}
 
 			// Impresión del comprobante de pago.
-			fmt.Println("\n=== COMPROBANTE DE PAGO ===")
-			fmt.Println("Orden:", order.ID)
-			fmt.Println("Cliente:", order.CustomerName, "(ID:", order.CustomerID, ")")
-			fmt.Println("Fecha:", order.CreatedAt.Format("02-01-2006 15:04:05"))
-			fmt.Println("--------------------------------------------------")
-			fmt.Println("DETALLE:")
-
-			for _, it := range order.Items {
-				fmt.Printf(
-					"ProdID:%d | %-15s | Unit:$%6.2f | Cant:%3d | Subtotal:$%7.2f\n",
-					it.ProductID,
-					it.Name,
-					it.UnitPrice,
-					it.Quantity,
-					it.LineTotal,
-				)
-			}
-
-			fmt.Println("--------------------------------------------------")
-			fmt.Printf("TOTAL PAGADO: $%.2f\n", order.Total)
-			fmt.Println("==================================================")
+			out.Order(order)
+
+		case "0":
+			return
+
+		default:
+			fmt.Println("Opción inválida.")
+		}
+	}
+}
+
+/*
+ordersMenu permite consultar el historial de pedidos confirmados
+y avanzar cada pedido en su ciclo de vida.
+
+La CLI solo pide los datos: las reglas de qué cambio de estado
+está permitido viven en el dominio (domain.TransitionOrder).
+
+Un usuario con rol customer solo ve sus pedidos y puede pedir
+devoluciones sobre ellos; los cambios de estado y la decisión de las
+devoluciones son de admin y staff.
+*/
+func ordersMenu(reader *bufio.Reader, out printer, user domain.User, deps usecase.OrderDeps, returnDeps usecase.ReturnDeps) {
+	staff := user.Role.IsStaff()
+	for {
+		op := printMenu(reader, "\n--- Pedidos ---", []menuOption{
+			{"1", "Ver pedido por ID", true},
+			{"2", "Pedidos de un cliente", staff},
+			{"2", "Mis pedidos", !staff},
+			{"3", "Pedidos por rango de fechas", staff},
+			{"4", "Marcar pedido como pagado", staff},
+			{"5", "Marcar pedido como enviado", staff},
+			{"6", "Marcar pedido como entregado", staff},
+			{"7", "Cancelar pedido", staff},
+			{"8", "Solicitar devolución", true},
+			{"9", "Aprobar devolución", staff},
+			{"10", "Rechazar devolución", staff},
+			{"11", "Ver devoluciones de un pedido", true},
+			{"0", "Volver", true},
+		})
+
+		switch op {
+		case "1":
+			order, err := ownOrder(deps.Orders, user, readString(reader, "ID de pedido: "))
+			if err != nil {
+				fmt.Println("Error:", err)
+				continue
+			}
+			out.Order(order)
+
+		case "2":
+			customerID := user.CustomerID
+			if staff {
+				customerID = readInt(reader, "CustomerID: ")
+			}
+			orders, err := usecase.ListOrdersByCustomer(deps.Orders, customerID)
+			if err != nil {
+				fmt.Println("Error:", err)
+				continue
+			}
+			out.Orders(orders)
+
+		case "3":
+			from := readDate(reader, "Desde (dd-mm-aaaa): ")
+			// "Hasta" incluye el día completo: se busca hasta el inicio del día siguiente.
+			to := readDate(reader, "Hasta (dd-mm-aaaa): ").AddDate(0, 0, 1)
+
+			orders, err := usecase.ListOrdersByDateRange(deps.Orders, from, to)
+			if err != nil {
+				fmt.Println("Error:", err)
+				continue
+			}
+			out.Orders(orders)
+
+		case "4", "5", "6":
+			// Cada opción corresponde a un caso de uso de cambio de estado.
+			change := map[string]func(usecase.OrderDeps, string) (usecase.Order, error){
+				"4": usecase.MarkOrderPaid,
+				"5": usecase.MarkOrderShipped,
+				"6": usecase.MarkOrderDelivered,
+			}[op]
+
+			order, err := change(deps, readString(reader, "ID de pedido: "))
+			if err != nil {
+				fmt.Println("Error:", err)
+				continue
+			}
+			fmt.Println("Pedido", order.ID, "ahora está", statusLabel(order.Status)+".")
+
+		case "7":
+			// Cancelar repone el stock de cada producto del pedido.
+			orderID := readString(reader, "ID de pedido: ")
+			reason := readString(reader, "Motivo: ")
+
+			order, err := usecase.CancelOrder(deps, orderID, reason)
+			if err != nil {
+				fmt.Println("Error:", err)
+				continue
+			}
+			fmt.Println("Pedido", order.ID, "cancelado. Stock repuesto.")
+
+		case "8":
+			orderID := readString(reader, "ID de pedido: ")
+			if _, err := ownOrder(deps.Orders, user, orderID); err != nil {
+				fmt.Println("Error:", err)
+				continue
+			}
+
+			// Se piden las líneas a devolver hasta que el usuario ingrese 0.
+			fmt.Println("Ingresa los productos a devolver (ProductID 0 para terminar).")
+			var items []usecase.ReturnItem
+			for {
+				productID := readInt(reader, "ProductID: ")
+				if productID == 0 {
+					break
+				}
+				items = append(items, usecase.ReturnItem{
+					ProductID: productID,
+					Quantity:  readInt(reader, "Cantidad: "),
+				})
+			}
+			reason := readString(reader, "Motivo: ")
+
+			rma, err := usecase.RequestReturn(returnDeps, orderID, items, reason)
+			if err != nil {
+				fmt.Println("Error:", err)
+				continue
+			}
+			fmt.Println("Devolución", rma.ID, "registrada. Reembolso a aprobar:", rma.Refund)
+
+		case "9":
+			returnID := readString(reader, "ID de devolución: ")
+			restock := readString(reader, "¿Reponer stock? (s/n): ") == "s"
+
+			rma, err := usecase.ApproveReturn(returnDeps, returnID, restock)
+			if err != nil {
+				fmt.Println("Error:", err)
+				continue
+			}
+			fmt.Println("Devolución", rma.ID, "aprobada. Reembolso:", rma.Refund)
+
+		case "10":
+			rma, err := usecase.RejectReturn(returnDeps, readString(reader, "ID de devolución: "))
+			if err != nil {
+				fmt.Println("Error:", err)
+				continue
+			}
+			fmt.Println("Devolución", rma.ID, "rechazada.")
+
+		case "11":
+			orderID := readString(reader, "ID de pedido: ")
+			if _, err := ownOrder(deps.Orders, user, orderID); err != nil {
+				fmt.Println("Error:", err)
+				continue
+			}
+			returns, err := usecase.ListReturnsByOrder(returnDeps.Returns, orderID)
+			if err != nil {
+				fmt.Println("Error:", err)
+				continue
+			}
+			if len(returns) == 0 {
+				fmt.Println("El pedido no tiene devoluciones.")
+				continue
+			}
+
+			for _, rma := range returns {
+				fmt.Printf("Devolución:%s | %s | %s | Reembolso:%s | Motivo:%s\n",
+					rma.ID, rma.CreatedAt.Format("02-01-2006 15:04"),
+					returnStatusLabel(rma.Status), rma.Refund, rma.Reason)
+				for _, line := range rma.Lines {
+					fmt.Printf("  ProdID:%d | %s | Cant:%d | Reembolso:%s\n",
+						line.ProductID, line.Name, line.Quantity, line.Refund)
+				}
+			}
 
 		case "0":
 			return
@@ -301,6 +774,128 @@ func cartMenu(
 	}
 }
 
+// ownOrder devuelve un pedido si el usuario puede verlo (un cliente, solo los suyos).
+func ownOrder(orders usecase.OrderRepository, user domain.User, orderID string) (usecase.Order, error) {
+	order, err := usecase.GetOrder(orders, orderID)
+	if err != nil {
+		return usecase.Order{}, err
+	}
+	if err := domain.AuthorizeCustomer(user, order.CustomerID); err != nil {
+		return usecase.Order{}, err
+	}
+	return order, nil
+}
+
+// printCustomerDeletion informa el resultado de la baja de un cliente.
+func printCustomerDeletion(res usecase.CustomerDeletion) {
+	if res.Anonymized {
+		fmt.Printf("Cliente %d anonimizado (tiene pedidos, que se conservan).\n", res.CustomerID)
+	} else {
+		fmt.Printf("Cliente %d eliminado.\n", res.CustomerID)
+	}
+	if len(res.DeletedUsers) > 0 {
+		fmt.Println("Usuarios eliminados:", strings.Join(res.DeletedUsers, ", "))
+	}
+}
+
+// returnStatusLabel traduce el estado de una devolución para mostrarlo en consola.
+func returnStatusLabel(s domain.ReturnStatus) string {
+	switch s {
+	case domain.ReturnRequested:
+		return "pendiente"
+	case domain.ReturnApproved:
+		return "aprobada"
+	case domain.ReturnRejected:
+		return "rechazada"
+	default:
+		return string(s)
+	}
+}
+
+// statusLabel traduce el estado de un pedido para mostrarlo en consola.
+func statusLabel(s domain.OrderStatus) string {
+	switch s {
+	case domain.OrderPending:
+		return "pendiente"
+	case domain.OrderPaid:
+		return "pagado"
+	case domain.OrderShipped:
+		return "enviado"
+	case domain.OrderDelivered:
+		return "entregado"
+	case domain.OrderCancelled:
+		return "cancelado"
+	default:
+		return string(s)
+	}
+}
+
+/*
+confirmPriceChanges muestra las líneas del carrito cuyo precio cambió
+(precio anterior y precio nuevo) y pregunta si se aceptan.
+
+Devuelve true si no hubo cambios o si el cliente los aceptó; en ese
+caso el carrito queda actualizado con los precios nuevos.
+*/
+func confirmPriceChanges(reader *bufio.Reader, cartDeps usecase.CartDeps, customerID int) bool {
+	changes, err := usecase.CheckCartPrices(cartDeps, customerID)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return false
+	}
+	if len(changes) == 0 {
+		return true
+	}
+
+	fmt.Println("\nCambiaron los precios de algunos productos:")
+	for _, ch := range changes {
+		fmt.Printf("ProdID:%d | %-15s | Antes:%9s | Ahora:%9s | Cant:%3d\n",
+			ch.ProductID, ch.Name, ch.OldPrice, ch.NewPrice, ch.Quantity)
+	}
+
+	if readString(reader, "¿Confirmar con los precios nuevos? (s/n): ") != "s" {
+		return false
+	}
+	if _, err := usecase.AcceptCartPrices(cartDeps, customerID); err != nil {
+		fmt.Println("Error:", err)
+		return false
+	}
+	return true
+}
+
+/*
+printCartError muestra un error del carrito.
+
+Si el error es por falta de stock, además indica cuántas unidades
+más se pueden agregar, para que el cliente no tenga que adivinar.
+*/
+func printCartError(err error) {
+	fmt.Println("Error:", err)
+	if hint, ok := stockHint(err); ok {
+		fmt.Println(hint)
+	}
+}
+
+// stockHint explica cuántas unidades más se pueden agregar, si err es por falta de stock.
+func stockHint(err error) (string, bool) {
+	var stockErr *domain.InsufficientStockError
+	if !errors.As(err, &stockErr) {
+		return "", false
+	}
+	return fmt.Sprintf("Tienes %d en el carrito; puedes agregar %d unidades más.",
+		stockErr.InCart, stockErr.CanAdd()), true
+}
+
+// printCartTotal muestra el total del carrito o el error si no se pudo calcular.
+func printCartTotal(cartRepo usecase.CartRepository, customerID int) {
+	total, err := usecase.CartTotal(cartRepo, customerID)
+	if err != nil {
+		fmt.Println("Error:", err)
+		return
+	}
+	fmt.Printf("TOTAL: %s\n", total)
+}
+
 /*
 Funciones helper de entrada.
 
@@ -308,6 +903,16 @@ Centralizan la lectura y validación básica de datos,
 evitando duplicación de código y errores de entrada.
 */
 
+// Pregunta el modo de una importación CSV (upsert y simulación).
+func readImportOptions(r *bufio.Reader) usecase.ImportOptions {
+	opts := usecase.ImportOptions{Mode: usecase.ImportCreateOnly}
+	if readString(r, "¿Actualizar los IDs que ya existen? (s/n): ") == "s" {
+		opts.Mode = usecase.ImportUpsert
+	}
+	opts.DryRun = readString(r, "¿Solo validar, sin guardar? (s/n): ") == "s"
+	return opts
+}
+
 // Lee una línea completa y elimina espacios y saltos de línea.
 func readLine(r *bufio.Reader) string {
 	s, _ := r.ReadString('\n')
@@ -332,14 +937,27 @@ func readInt(r *bufio.Reader, label string) int {
 	}
 }
 
-// Solicita un decimal y repite hasta que sea válido.
-func readFloat(r *bufio.Reader, label string) float64 {
+// Solicita una fecha con formato dd-mm-aaaa y repite hasta que sea válida.
+func readDate(r *bufio.Reader, label string) time.Time {
 	for {
 		fmt.Print(label)
-		n, err := strconv.ParseFloat(readLine(r), 64)
+		d, err := time.ParseInLocation("02-01-2006", readLine(r), time.Local)
 		if err == nil {
-			return n
+			return d
+		}
+		fmt.Println("Ingresa una fecha válida (por ejemplo 31-12-2025).")
+	}
+}
+
+// Solicita un monto en la moneda por defecto y repite hasta que sea válido.
+// La conversión es exacta (no pasa por float64).
+func readMoney(r *bufio.Reader, label string) domain.Money {
+	for {
+		fmt.Print(label)
+		m, err := domain.ParseMoney(readLine(r), domain.DefaultCurrency)
+		if err == nil {
+			return m
 		}
-		fmt.Println("Ingresa un número decimal válido.")
+		fmt.Println("Ingresa un monto válido (por ejemplo 12.50).")
 	}
 }
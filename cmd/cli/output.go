@@ -0,0 +1,527 @@
+package main
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+/*
+outputFormat es el formato de los listados (opción -output).
+
+- text: el formato de siempre, pensado para leer en la consola.
+- json, csv y table: pensados para otros programas; los nombres de los
+  campos son estables y los montos se escriben sin símbolo ("12.50")
+  con la moneda en un campo aparte.
+*/
+type outputFormat string
+
+const (
+	outputText  outputFormat = "text"
+	outputJSON  outputFormat = "json"
+	outputCSV   outputFormat = "csv"
+	outputTable outputFormat = "table"
+)
+
+// parseOutputFormat valida el nombre de un formato de salida.
+func parseOutputFormat(s string) (outputFormat, error) {
+	switch f := outputFormat(s); f {
+	case outputText, outputJSON, outputCSV, outputTable:
+		return f, nil
+	}
+	return "", fmt.Errorf("formato de salida inválido: %s (use text, json, csv o table)", s)
+}
+
+// outputFlag agrega la opción --output a un subcomando; si se indica, reemplaza a la global.
+func outputFlag(fs *flag.FlagSet, p *printer) {
+	fs.Func("output", "formato de salida: text, json, csv o table", func(s string) error {
+		f, err := parseOutputFormat(s)
+		if err != nil {
+			return err
+		}
+		p.format = f
+		return nil
+	})
+}
+
+/*
+printer imprime los listados de la CLI (productos, clientes, carrito,
+pedidos y usuarios) en el formato elegido.
+
+El menú interactivo y los subcomandos usan el mismo printer, así un
+listado se ve igual por cualquiera de los dos caminos.
+*/
+type printer struct {
+	format outputFormat
+	w      io.Writer
+}
+
+/*
+Vistas de las entidades para los formatos json, csv y table.
+
+Los nombres de los campos (etiquetas json y encabezados csv) son parte
+del contrato con los scripts que leen la salida: no se renombran.
+*/
+type (
+	productView struct {
+		ID       int    `json:"id"`
+		Name     string `json:"name"`
+		Price    string `json:"price"`
+		Currency string `json:"currency"`
+		Stock    int    `json:"stock"`
+	}
+
+	customerView struct {
+		ID    int    `json:"id"`
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	}
+
+	lineView struct {
+		ProductID int    `json:"product_id"`
+		Name      string `json:"name"`
+		UnitPrice string `json:"unit_price"`
+		Quantity  int    `json:"quantity"`
+		Subtotal  string `json:"subtotal"`
+		Currency  string `json:"currency"`
+	}
+
+	cartView struct {
+		CustomerID int        `json:"customer_id"`
+		Items      []lineView `json:"items"`
+		Total      string     `json:"total"`
+		Currency   string     `json:"currency"`
+	}
+
+	statusChangeView struct {
+		Status string `json:"status"`
+		At     string `json:"at"`
+	}
+
+	orderView struct {
+		ID           string             `json:"id"`
+		CreatedAt    string             `json:"created_at"`
+		CustomerID   int                `json:"customer_id"`
+		CustomerName string             `json:"customer_name"`
+		Status       string             `json:"status"`
+		Items        []lineView         `json:"items"`
+		Total        string             `json:"total"`
+		Refunded     string             `json:"refunded"`
+		Currency     string             `json:"currency"`
+		PaymentID    string             `json:"payment_id"`
+		CancelReason string             `json:"cancel_reason"`
+		History      []statusChangeView `json:"history"`
+	}
+
+	returnView struct {
+		ID         string `json:"id"`
+		OrderID    string `json:"order_id"`
+		CustomerID int    `json:"customer_id"`
+		Status     string `json:"status"`
+		Refund     string `json:"refund"`
+		Currency   string `json:"currency"`
+		Restocked  bool   `json:"restocked"`
+		Reason     string `json:"reason"`
+		CreatedAt  string `json:"created_at"`
+		DecidedAt  string `json:"decided_at"`
+	}
+
+	// Los hashes de contraseña y clave de API nunca se muestran.
+	userView struct {
+		Username   string `json:"username"`
+		Role       string `json:"role"`
+		CustomerID int    `json:"customer_id,omitempty"`
+	}
+)
+
+// Encabezados de csv y table, en el mismo orden que las filas.
+var (
+	productHeader  = []string{"id", "name", "price", "currency", "stock"}
+	customerHeader = []string{"id", "name", "email"}
+	cartHeader     = []string{"customer_id", "product_id", "name", "unit_price", "quantity", "subtotal", "currency"}
+	orderHeader    = []string{"id", "created_at", "customer_id", "customer_name", "status", "items", "total", "refunded", "currency"}
+	orderLineHead  = []string{"order_id", "product_id", "name", "unit_price", "quantity", "subtotal", "currency"}
+	returnHeader   = []string{"id", "order_id", "customer_id", "status", "refund", "currency", "restocked", "reason", "created_at", "decided_at"}
+	userHeader     = []string{"username", "role", "customer_id"}
+)
+
+// formatTime escribe las fechas de los formatos para programas (RFC 3339, vacío si no hay fecha).
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// newProductView arma la vista de un producto.
+func newProductView(p domain.Product) productView {
+	return productView{p.ID, p.Name, domain.FormatAmount(p.Price), p.Price.Currency, p.Stock}
+}
+
+// newCartView arma la vista de un carrito con su total.
+func newCartView(cart domain.Cart, total domain.Money) cartView {
+	items := make([]lineView, 0, len(cart.Items))
+	for _, it := range cart.Items {
+		items = append(items, lineView{
+			it.ProductID, it.Name, domain.FormatAmount(it.Price), it.Quantity,
+			domain.FormatAmount(domain.LineTotal(it)), it.Price.Currency,
+		})
+	}
+	return cartView{cart.CustomerID, items, domain.FormatAmount(total), total.Currency}
+}
+
+// newOrderView arma la vista de un pedido con sus líneas e historial.
+func newOrderView(o usecase.Order) orderView {
+	items := make([]lineView, 0, len(o.Items))
+	for _, it := range o.Items {
+		items = append(items, lineView{
+			it.ProductID, it.Name, domain.FormatAmount(it.UnitPrice), it.Quantity,
+			domain.FormatAmount(it.LineTotal), it.UnitPrice.Currency,
+		})
+	}
+	history := make([]statusChangeView, 0, len(o.History))
+	for _, ch := range o.History {
+		history = append(history, statusChangeView{string(ch.To), formatTime(ch.At)})
+	}
+	return orderView{
+		ID:           o.ID,
+		CreatedAt:    formatTime(o.CreatedAt),
+		CustomerID:   o.CustomerID,
+		CustomerName: o.CustomerName,
+		Status:       string(o.Status),
+		Items:        items,
+		Total:        domain.FormatAmount(o.Total),
+		Refunded:     domain.FormatAmount(o.Refunded),
+		Currency:     o.Total.Currency,
+		PaymentID:    o.PaymentID,
+		CancelReason: o.CancelReason,
+		History:      history,
+	}
+}
+
+// newReturnView arma la vista de una devolución.
+func newReturnView(r usecase.ReturnRequest) returnView {
+	return returnView{
+		ID:         r.ID,
+		OrderID:    r.OrderID,
+		CustomerID: r.CustomerID,
+		Status:     string(r.Status),
+		Refund:     domain.FormatAmount(r.Refund),
+		Currency:   r.Refund.Currency,
+		Restocked:  r.Restocked,
+		Reason:     r.Reason,
+		CreatedAt:  formatTime(r.CreatedAt),
+		DecidedAt:  formatTime(r.DecidedAt),
+	}
+}
+
+// lineRows arma las filas de csv/table de las líneas de un carrito o pedido.
+func lineRows(key string, lines []lineView) [][]string {
+	rows := make([][]string, 0, len(lines))
+	for _, l := range lines {
+		rows = append(rows, []string{
+			key, strconv.Itoa(l.ProductID), l.Name, l.UnitPrice,
+			strconv.Itoa(l.Quantity), l.Subtotal, l.Currency,
+		})
+	}
+	return rows
+}
+
+/*
+emit escribe un listado en el formato del printer.
+
+- json: data tal cual (con sangría).
+- csv: header y rows.
+- table: header y rows alineados en columnas.
+- text: llama a text, que escribe el formato de siempre.
+*/
+func (p printer) emit(data any, header []string, rows [][]string, text func()) {
+	switch p.format {
+	case outputJSON:
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(data)
+
+	case outputCSV:
+		cw := csv.NewWriter(p.w)
+		_ = cw.Write(header)
+		_ = cw.WriteAll(rows)
+
+	case outputTable:
+		tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+		for _, row := range append([][]string{header}, rows...) {
+			for i, col := range row {
+				if i > 0 {
+					fmt.Fprint(tw, "\t")
+				}
+				fmt.Fprint(tw, col)
+			}
+			fmt.Fprintln(tw)
+		}
+		_ = tw.Flush()
+
+	default:
+		text()
+	}
+}
+
+// Products imprime la lista de productos, ordenada por ID.
+func (p printer) Products(products []domain.Product) {
+	products = slices.SortedFunc(slices.Values(products), func(a, b domain.Product) int { return cmp.Compare(a.ID, b.ID) })
+	views := make([]productView, 0, len(products))
+	rows := make([][]string, 0, len(products))
+	for _, prod := range products {
+		v := newProductView(prod)
+		views = append(views, v)
+		rows = append(rows, []string{strconv.Itoa(v.ID), v.Name, v.Price, v.Currency, strconv.Itoa(v.Stock)})
+	}
+
+	p.emit(views, productHeader, rows, func() {
+		if len(products) == 0 {
+			fmt.Fprintln(p.w, "No hay productos registrados.")
+			return
+		}
+		for _, prod := range products {
+			fmt.Fprintf(p.w, "ID:%d | %s | %s | Stock:%d\n",
+				prod.ID, prod.Name, prod.Price, prod.Stock)
+		}
+	})
+}
+
+// Customers imprime la lista de clientes, ordenada por ID.
+func (p printer) Customers(customers []domain.Customer) {
+	customers = slices.SortedFunc(slices.Values(customers), func(a, b domain.Customer) int { return cmp.Compare(a.ID, b.ID) })
+	views := make([]customerView, 0, len(customers))
+	rows := make([][]string, 0, len(customers))
+	for _, c := range customers {
+		views = append(views, customerView{c.ID, c.Name, c.Email})
+		rows = append(rows, []string{strconv.Itoa(c.ID), c.Name, c.Email})
+	}
+
+	p.emit(views, customerHeader, rows, func() {
+		if len(customers) == 0 {
+			fmt.Fprintln(p.w, "No hay clientes registrados.")
+			return
+		}
+		for _, c := range customers {
+			fmt.Fprintf(p.w, "ID:%d | %s | %s\n",
+				c.ID, c.Name, c.Email)
+		}
+	})
+}
+
+// Users imprime la lista de usuarios (ya ordenada por usecase.ListUsers).
+func (p printer) Users(users []domain.User) {
+	views := make([]userView, 0, len(users))
+	rows := make([][]string, 0, len(users))
+	for _, u := range users {
+		views = append(views, userView{u.Username, string(u.Role), u.CustomerID})
+		customer := ""
+		if u.CustomerID != 0 {
+			customer = strconv.Itoa(u.CustomerID)
+		}
+		rows = append(rows, []string{u.Username, string(u.Role), customer})
+	}
+
+	p.emit(views, userHeader, rows, func() {
+		if len(users) == 0 {
+			fmt.Fprintln(p.w, "No hay usuarios registrados.")
+			return
+		}
+		for _, u := range users {
+			if u.Role == domain.RoleCustomer {
+				fmt.Fprintf(p.w, "%s | %s | Cliente:%d\n", u.Username, u.Role, u.CustomerID)
+				continue
+			}
+			fmt.Fprintf(p.w, "%s | %s\n", u.Username, u.Role)
+		}
+	})
+}
+
+/*
+Cart imprime las líneas del carrito del cliente y su total.
+
+En csv y table hay una fila por línea; el total solo está en json
+(y en text), porque cada fila ya trae su subtotal.
+*/
+func (p printer) Cart(cartRepo usecase.CartRepository, customerID int) error {
+	cart, err := usecase.ViewCart(cartRepo, customerID)
+	if err != nil {
+		return err
+	}
+	total, totalErr := usecase.CartTotal(cartRepo, customerID)
+	view := newCartView(cart, total)
+
+	p.emit(view, cartHeader, lineRows(strconv.Itoa(customerID), view.Items), func() {
+		if len(cart.Items) == 0 {
+			fmt.Fprintln(p.w, "Carrito vacío.")
+			return
+		}
+		for _, it := range cart.Items {
+			fmt.Fprintf(p.w,
+				"ProdID:%d | %s | %s | Cant:%d | Subtotal:%s\n",
+				it.ProductID, it.Name, it.Price,
+				it.Quantity, domain.LineTotal(it),
+			)
+		}
+		if totalErr != nil {
+			fmt.Fprintln(p.w, "Error:", totalErr)
+			return
+		}
+		fmt.Fprintf(p.w, "TOTAL: %s\n", total)
+	})
+	return nil
+}
+
+/*
+Carts imprime varios carritos (los vacíos se omiten).
+
+En csv y table hay una fila por línea, con el cliente en la primera
+columna, igual que Cart.
+*/
+func (p printer) Carts(carts []domain.Cart) {
+	views := make([]cartView, 0, len(carts))
+	var rows [][]string
+	for _, c := range carts {
+		if domain.IsEmpty(c) {
+			continue
+		}
+		total, _ := domain.Total(c)
+		v := newCartView(c, total)
+		views = append(views, v)
+		rows = append(rows, lineRows(strconv.Itoa(c.CustomerID), v.Items)...)
+	}
+
+	p.emit(views, cartHeader, rows, func() {
+		if len(views) == 0 {
+			fmt.Fprintln(p.w, "No hay carritos con productos.")
+			return
+		}
+		for _, c := range carts {
+			if domain.IsEmpty(c) {
+				continue
+			}
+			fmt.Fprintf(p.w, "Cliente:%d\n", c.CustomerID)
+			for _, it := range c.Items {
+				fmt.Fprintf(p.w, "  ProdID:%d | %s | %s | Cant:%d\n", it.ProductID, it.Name, it.Price, it.Quantity)
+			}
+		}
+	})
+}
+
+/*
+Order imprime un pedido.
+
+En text es el comprobante completo; en csv y table, una fila por línea
+del pedido; en json, el pedido con sus líneas y su historial.
+*/
+func (p printer) Order(order usecase.Order) {
+	view := newOrderView(order)
+	p.emit(view, orderLineHead, lineRows(order.ID, view.Items), func() { p.receipt(order) })
+}
+
+// Orders imprime una línea resumen por pedido.
+func (p printer) Orders(orders []usecase.Order) {
+	views := make([]orderView, 0, len(orders))
+	rows := make([][]string, 0, len(orders))
+	for _, o := range orders {
+		v := newOrderView(o)
+		views = append(views, v)
+		rows = append(rows, []string{
+			v.ID, v.CreatedAt, strconv.Itoa(v.CustomerID), v.CustomerName, v.Status,
+			strconv.Itoa(len(v.Items)), v.Total, v.Refunded, v.Currency,
+		})
+	}
+
+	p.emit(views, orderHeader, rows, func() {
+		if len(orders) == 0 {
+			fmt.Fprintln(p.w, "No hay pedidos para mostrar.")
+			return
+		}
+		for _, o := range orders {
+			fmt.Fprintf(p.w, "Orden:%s | %s | Cliente:%d %s | Ítems:%d | Total:%s | %s\n",
+				o.ID, o.CreatedAt.Format("02-01-2006 15:04"),
+				o.CustomerID, o.CustomerName, len(o.Items), o.Total, statusLabel(o.Status))
+		}
+	})
+}
+
+// Returns imprime una línea resumen por devolución.
+func (p printer) Returns(returns []usecase.ReturnRequest) {
+	views := make([]returnView, 0, len(returns))
+	rows := make([][]string, 0, len(returns))
+	for _, r := range returns {
+		v := newReturnView(r)
+		views = append(views, v)
+		rows = append(rows, []string{
+			v.ID, v.OrderID, strconv.Itoa(v.CustomerID), v.Status, v.Refund, v.Currency,
+			strconv.FormatBool(v.Restocked), v.Reason, v.CreatedAt, v.DecidedAt,
+		})
+	}
+
+	p.emit(views, returnHeader, rows, func() {
+		if len(returns) == 0 {
+			fmt.Fprintln(p.w, "No hay devoluciones para mostrar.")
+			return
+		}
+		for _, r := range returns {
+			fmt.Fprintf(p.w, "%s | Pedido:%s | %s | Reembolso:%s\n",
+				r.ID, r.OrderID, returnStatusLabel(r.Status), r.Refund)
+		}
+	})
+}
+
+// receipt imprime el comprobante completo de un pedido (formato text).
+func (p printer) receipt(order usecase.Order) {
+	w := p.w
+	fmt.Fprintln(w, "\n=== COMPROBANTE DE PAGO ===")
+	fmt.Fprintln(w, "Orden:", order.ID)
+	fmt.Fprintln(w, "Cliente:", order.CustomerName, "(ID:", order.CustomerID, ")")
+	fmt.Fprintln(w, "Fecha:", order.CreatedAt.Format("02-01-2006 15:04:05"))
+	fmt.Fprintln(w, "Estado:", statusLabel(order.Status))
+	if order.PaymentID != "" {
+		fmt.Fprintln(w, "Pago:", order.PaymentID)
+	}
+	if order.CancelReason != "" {
+		fmt.Fprintln(w, "Motivo de cancelación:", order.CancelReason)
+	}
+	fmt.Fprintln(w, "--------------------------------------------------")
+	fmt.Fprintln(w, "DETALLE:")
+
+	for _, it := range order.Items {
+		fmt.Fprintf(w,
+			"ProdID:%d | %-15s | Unit:%9s | Cant:%3d | Subtotal:%10s\n",
+			it.ProductID,
+			it.Name,
+			it.UnitPrice,
+			it.Quantity,
+			it.LineTotal,
+		)
+	}
+
+	fmt.Fprintln(w, "--------------------------------------------------")
+	fmt.Fprintf(w, "TOTAL PAGADO: %s\n", order.Total)
+	if !domain.IsZeroMoney(order.Refunded) {
+		fmt.Fprintf(w, "REEMBOLSADO:  %s\n", order.Refunded)
+		if net, err := usecase.OrderNetTotal(order); err == nil {
+			fmt.Fprintf(w, "TOTAL NETO:   %s\n", net)
+		}
+	}
+	fmt.Fprintln(w, "--------------------------------------------------")
+	fmt.Fprintln(w, "HISTORIAL:")
+
+	for _, ch := range order.History {
+		fmt.Fprintf(w, "%s | %s\n", ch.At.Format("02-01-2006 15:04:05"), statusLabel(ch.To))
+	}
+	fmt.Fprintln(w, "==================================================")
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/eventlog"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+)
+
+// replayLayout es el formato de fecha y hora aceptado por -replay-until.
+const replayLayout = "02-01-2006 15:04:05"
+
+// replayView es el estado reconstruido en formato json (un solo documento).
+type replayView struct {
+	At        string         `json:"at"`
+	Products  []productView  `json:"products"`
+	Customers []customerView `json:"customers"`
+	Carts     []cartView     `json:"carts"`
+	Orders    []orderView    `json:"orders"`
+	Returns   []returnView   `json:"returns"`
+}
+
+/*
+runReplay muestra el estado del log de eventos en dir tal como estaba
+en el instante until (formato dd-mm-aaaa hh:mm:ss, hora local).
+
+Es de solo lectura: no modifica el log ni el snapshot.
+
+Formato (-output):
+- json: un solo documento con todas las secciones.
+- text, csv y table: una sección por entidad, cada una con su título y
+  en el formato de los listados de siempre.
+*/
+func runReplay(out printer, dir, until string) error {
+	t, err := time.ParseInLocation(replayLayout, until, time.Local)
+	if err != nil {
+		return fmt.Errorf("fecha inválida (use dd-mm-aaaa hh:mm:ss): %w", err)
+	}
+
+	state, err := eventlog.ReplayUntil(dir, t)
+	if err != nil {
+		return err
+	}
+
+	if out.format == outputJSON {
+		return writeReplayJSON(out, t, state)
+	}
+
+	fmt.Fprintln(out.w, "=== Estado al", t.Format(replayLayout), "===")
+
+	fmt.Fprintln(out.w, "\nProductos:")
+	out.Products(state.Products)
+
+	fmt.Fprintln(out.w, "\nClientes:")
+	out.Customers(state.Customers)
+
+	fmt.Fprintln(out.w, "\nCarritos:")
+	out.Carts(state.Carts)
+
+	fmt.Fprintln(out.w, "\nPedidos:")
+	out.Orders(state.Orders)
+
+	fmt.Fprintln(out.w, "\nDevoluciones:")
+	out.Returns(state.Returns)
+	return nil
+}
+
+// writeReplayJSON escribe el estado reconstruido como un solo documento json.
+func writeReplayJSON(out printer, at time.Time, state eventlog.State) error {
+	view := replayView{
+		At:        formatTime(at),
+		Products:  make([]productView, 0, len(state.Products)),
+		Customers: make([]customerView, 0, len(state.Customers)),
+		Carts:     make([]cartView, 0, len(state.Carts)),
+		Orders:    make([]orderView, 0, len(state.Orders)),
+		Returns:   make([]returnView, 0, len(state.Returns)),
+	}
+	for _, p := range state.Products {
+		view.Products = append(view.Products, newProductView(p))
+	}
+	for _, c := range state.Customers {
+		view.Customers = append(view.Customers, customerView{c.ID, c.Name, c.Email})
+	}
+	for _, c := range state.Carts {
+		if domain.IsEmpty(c) {
+			continue
+		}
+		total, _ := domain.Total(c)
+		view.Carts = append(view.Carts, newCartView(c, total))
+	}
+	for _, o := range state.Orders {
+		view.Orders = append(view.Orders, newOrderView(o))
+	}
+	for _, r := range state.Returns {
+		view.Returns = append(view.Returns, newReturnView(r))
+	}
+
+	enc := json.NewEncoder(out.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(view)
+}
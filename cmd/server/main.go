@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/fakepay"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/httpapi"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/storage"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+/*
+Servidor HTTP con la API REST del sistema.
+
+Responsabilidades:
+- Leer las opciones de línea de comandos (las mismas que la CLI para
+  almacenamiento, pagos, precios y reservas).
+- Inicializar dependencias y servir httpapi.NewHandler.
+- Crear el primer administrador si todavía no hay usuarios (ver bootstrapAdmin).
+- Terminar ordenadamente con Ctrl+C o SIGTERM: deja de aceptar pedidos
+  y espera a que terminen los que están en curso.
+*/
+func main() {
+	addr := flag.String("addr", ":8080", "dirección donde escucha el servidor")
+	paymentMode := flag.String("payment", "approve",
+		"resultado simulado de los pagos: approve, decline, timeout o verify")
+	reservationTTL := flag.Duration("reservation-ttl", usecase.DefaultReservationTTL,
+		"duración de las reservas de stock de los carritos (por ejemplo 15m)")
+	pricePolicyName := flag.String("price-policy", "reject",
+		"precios que cambiaron desde que se agregó el producto: honor, reprice o reject")
+	idempotencyTTL := flag.Duration("idempotency-ttl", usecase.DefaultIdempotencyTTL,
+		"tiempo durante el que se recuerda el resultado de un checkout con Idempotency-Key")
+	storageKind := flag.String("storage", "memory", "almacenamiento: memory, file, sqlite o eventlog")
+	dataDir := flag.String("data-dir", "data", "directorio de datos (con -storage=file o -storage=eventlog)")
+	dbPath := flag.String("db", "ecommerce.db", "archivo de la base SQLite (con -storage=sqlite)")
+	flag.Parse()
+
+	mode, ok := map[string]fakepay.Mode{
+		"approve": fakepay.Approve,
+		"decline": fakepay.Decline,
+		"timeout": fakepay.Timeout,
+		"verify":  fakepay.RequireVerification,
+	}[*paymentMode]
+	if !ok {
+		fmt.Fprintln(os.Stderr, "Modo de pago inválido:", *paymentMode)
+		os.Exit(2)
+	}
+	pricePolicy, ok := map[string]usecase.PricePolicy{
+		"honor":   usecase.HonorSnapshotPrice,
+		"reprice": usecase.RepriceAtCheckout,
+		"reject":  usecase.RejectPriceChanges,
+	}[*pricePolicyName]
+	if !ok {
+		fmt.Fprintln(os.Stderr, "Política de precios inválida:", *pricePolicyName)
+		os.Exit(2)
+	}
+
+	store, err := storage.Open(*storageKind, *dataDir, *dbPath)
+	if err != nil {
+		log.Fatalln("No se pudo abrir el almacenamiento:", err)
+	}
+	payments, err := fakepay.Open(storage.PaymentsFile(*storageKind, *dataDir, *dbPath), fakepay.Config{Mode: mode})
+	if err != nil {
+		log.Fatalln("No se pudo abrir el archivo de pagos:", err)
+	}
+
+	// Mismas dependencias que arma la CLI (ver cmd/cli/main.go).
+	deps := httpapi.Deps{
+		Products:  store.Products,
+		Customers: store.Customers,
+		Cart: usecase.CartDeps{
+			UnitOfWork:     store.UnitOfWork,
+			Carts:          store.Carts,
+			Products:       store.Products,
+			Reservations:   store.Reservations,
+			ReservationTTL: *reservationTTL,
+		},
+		Checkout: usecase.CheckoutDeps{
+			UnitOfWork:     store.UnitOfWork,
+			Carts:          store.Carts,
+			Products:       store.Products,
+			Customers:      store.Customers,
+			Orders:         store.Orders,
+			Payments:       payments,
+			Reservations:   store.Reservations,
+			PricePolicy:    pricePolicy,
+			Idempotency:    store.Idempotency,
+			IdempotencyTTL: *idempotencyTTL,
+		},
+		Orders: usecase.OrderDeps{
+			UnitOfWork: store.UnitOfWork,
+			Orders:     store.Orders,
+			Payments:   payments,
+		},
+		Users: store.Users,
+	}
+
+	if err := bootstrapAdmin(store.Users, store.Customers); err != nil {
+		log.Fatalln("No se pudo crear el usuario administrador:", err)
+	}
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           logRequests(httpapi.NewHandler(deps)),
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       15 * time.Second,
+		WriteTimeout:      30 * time.Second,
+		IdleTimeout:       60 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Println("Error al detener el servidor:", err)
+		}
+	}()
+
+	log.Printf("Escuchando en %s (almacenamiento %s)", *addr, *storageKind)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalln("Error del servidor:", err)
+	}
+	// ListenAndServe vuelve apenas empieza Shutdown; se espera a que terminen los pedidos en curso.
+	<-stopped
+	log.Println("Servidor detenido.")
+}
+
+/*
+bootstrapAdmin crea el usuario "admin" si el almacenamiento no tiene
+usuarios, con una contraseña y una clave de API aleatorias que se
+muestran una sola vez en el log.
+
+Sin esto nadie podría usar la API: todas las rutas que modifican datos
+piden una clave de API, y crear usuarios requiere ser admin.
+*/
+func bootstrapAdmin(users usecase.UserRepository, customers usecase.CustomerRepositoryForCheckout) error {
+	existing, err := usecase.ListUsers(users)
+	if err != nil || len(existing) > 0 {
+		return err
+	}
+
+	secret := make([]byte, 12)
+	if _, err := rand.Read(secret); err != nil {
+		return err
+	}
+	password := base64.RawURLEncoding.EncodeToString(secret)
+	deps := usecase.UserDeps{Users: users, Customers: customers}
+	if _, err := usecase.CreateUser(deps, usecase.NewUserRequest{Username: "admin", Password: password, Role: domain.RoleAdmin}); err != nil {
+		return err
+	}
+	key, err := usecase.IssueAPIKey(users, "admin")
+	if err != nil {
+		return err
+	}
+	log.Printf("No había usuarios: se creó \"admin\" con la contraseña %s y la clave de API %s (se muestran una sola vez)", password, key)
+	return nil
+}
+
+// statusRecorder guarda el código de respuesta para el log de pedidos.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader registra el código antes de enviarlo.
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+// logRequests registra cada pedido con su código de respuesta y duración.
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		log.Printf("%s %s %d %s", r.Method, r.URL.Path, rec.status, time.Since(start).Round(time.Microsecond))
+	})
+}
@@ -0,0 +1,212 @@
+/*
+Package csvfile lee y escribe productos y clientes en archivos CSV,
+para importaciones y exportaciones masivas.
+
+Formato:
+- La primera línea es el encabezado; las columnas pueden venir en
+  cualquier orden y no distinguen mayúsculas.
+- Productos: id, name, price, currency (opcional, por defecto
+  domain.DefaultCurrency) y stock.
+- Clientes: id, name y email.
+- Son los mismos encabezados que "product list --output csv" y
+  "customer list --output csv", así que una exportación se puede
+  volver a importar.
+
+Este paquete solo convierte texto en entidades: las reglas de negocio
+las aplican usecase.ImportProducts y usecase.ImportCustomers.
+*/
+package csvfile
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+// Encabezados que escriben WriteProducts y WriteCustomers.
+var (
+	productHeader  = []string{"id", "name", "price", "currency", "stock"}
+	customerHeader = []string{"id", "name", "email"}
+)
+
+/*
+ReadProducts lee productos de un CSV.
+
+Devuelve:
+- Las filas que se pudieron convertir, con su número de línea.
+- Un RowError por cada fila que no se pudo convertir (por ejemplo,
+  un stock que no es un número); esas filas no se devuelven.
+- Un error solo si el archivo completo es ilegible: encabezado
+  faltante, columnas desconocidas o repetidas, o CSV mal formado.
+
+Las filas devueltas todavía no están validadas contra el dominio.
+*/
+func ReadProducts(r io.Reader) ([]usecase.ImportRow[domain.Product], []*usecase.RowError, error) {
+	return read(r, []string{"id", "name", "price", "stock"}, []string{"currency"},
+		func(get func(string) string) (domain.Product, error) {
+			id, err := parseInt(get("id"), domain.ErrInvalidID)
+			if err != nil {
+				return domain.Product{}, err
+			}
+			stock, err := parseInt(get("stock"), domain.ErrInvalidStock)
+			if err != nil {
+				return domain.Product{}, err
+			}
+			currency := get("currency")
+			if currency == "" {
+				currency = domain.DefaultCurrency
+			}
+			price, err := domain.ParseMoney(get("price"), strings.ToUpper(currency))
+			if err != nil {
+				return domain.Product{}, err
+			}
+			return domain.Product{ID: id, Name: get("name"), Price: price, Stock: stock}, nil
+		})
+}
+
+/*
+ReadCustomers lee clientes de un CSV, con las mismas reglas que
+ReadProducts.
+*/
+func ReadCustomers(r io.Reader) ([]usecase.ImportRow[domain.Customer], []*usecase.RowError, error) {
+	return read(r, []string{"id", "name", "email"}, nil,
+		func(get func(string) string) (domain.Customer, error) {
+			id, err := parseInt(get("id"), domain.ErrInvalidCustomerID)
+			if err != nil {
+				return domain.Customer{}, err
+			}
+			return domain.Customer{ID: id, Name: get("name"), Email: get("email")}, nil
+		})
+}
+
+// WriteProducts escribe los productos en CSV, en el orden recibido.
+func WriteProducts(w io.Writer, products []domain.Product) error {
+	rows := make([][]string, 0, len(products))
+	for _, p := range products {
+		rows = append(rows, []string{
+			strconv.Itoa(p.ID), p.Name, domain.FormatAmount(p.Price), p.Price.Currency, strconv.Itoa(p.Stock),
+		})
+	}
+	return write(w, productHeader, rows)
+}
+
+// WriteCustomers escribe los clientes en CSV, en el orden recibido.
+func WriteCustomers(w io.Writer, customers []domain.Customer) error {
+	rows := make([][]string, 0, len(customers))
+	for _, c := range customers {
+		rows = append(rows, []string{strconv.Itoa(c.ID), c.Name, c.Email})
+	}
+	return write(w, customerHeader, rows)
+}
+
+// write escribe el encabezado y las filas, y devuelve el primer error de escritura.
+func write(w io.Writer, header []string, rows [][]string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return err
+	}
+	return cw.Error()
+}
+
+/*
+read es la lectura común de ReadProducts y ReadCustomers.
+
+required y optional son los nombres de columna aceptados; parse convierte
+una fila usando get(columna), que devuelve el valor sin espacios alrededor
+(o "" si la columna opcional no está en el archivo).
+*/
+func read[T any](r io.Reader, required, optional []string, parse func(get func(string) string) (T, error)) ([]usecase.ImportRow[T], []*usecase.RowError, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1 // la cantidad de columnas se controla por fila, sin abortar
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, errors.New("el archivo está vacío: falta el encabezado")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	columns, err := mapHeader(header, required, optional)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows := []usecase.ImportRow[T]{}
+	rowErrs := []*usecase.RowError{}
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		line, _ := cr.FieldPos(0)
+
+		if len(record) != len(header) {
+			rowErrs = append(rowErrs, &usecase.RowError{
+				Line: line,
+				Err:  fmt.Errorf("tiene %d columnas, el encabezado tiene %d", len(record), len(header)),
+			})
+			continue
+		}
+		get := func(name string) string {
+			i, ok := columns[name]
+			if !ok {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		value, err := parse(get)
+		if err != nil {
+			rowErrs = append(rowErrs, &usecase.RowError{Line: line, Err: err})
+			continue
+		}
+		rows = append(rows, usecase.ImportRow[T]{Line: line, Value: value})
+	}
+	return rows, rowErrs, nil
+}
+
+// mapHeader devuelve la posición de cada columna del encabezado.
+func mapHeader(header, required, optional []string) (map[string]int, error) {
+	known := map[string]bool{}
+	for _, name := range append(append([]string{}, required...), optional...) {
+		known[name] = true
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !known[name] {
+			return nil, fmt.Errorf("columna desconocida en el encabezado: %q", name)
+		}
+		if _, dup := columns[name]; dup {
+			return nil, fmt.Errorf("columna repetida en el encabezado: %q", name)
+		}
+		columns[name] = i
+	}
+	for _, name := range required {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("falta la columna %q en el encabezado", name)
+		}
+	}
+	return columns, nil
+}
+
+// parseInt convierte un entero; si no es un número devuelve errInvalid con el texto leído.
+func parseInt(s string, errInvalid error) (int, error) {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q no es un número", errInvalid, s)
+	}
+	return n, nil
+}
@@ -0,0 +1,147 @@
+package csvfile_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/csvfile"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+)
+
+// Un encabezado inválido hace ilegible el archivo completo: no se devuelve ninguna fila.
+func TestReadProductsHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		wantErr string // vacío: el encabezado es válido
+	}{
+		{"completo", "id,name,price,currency,stock\n", ""},
+		{"sin moneda (opcional)", "id,name,price,stock\n", ""},
+		{"otro orden y mayúsculas", "Stock, PRICE ,Name,ID\n", ""},
+		{"con BOM", "\ufeffid,name,price,stock\n", ""},
+		{"vacío", "", "falta el encabezado"},
+		{"falta una columna", "id,name,price\n", `falta la columna "stock"`},
+		{"columna desconocida", "id,name,price,stock,color\n", `columna desconocida en el encabezado: "color"`},
+		{"columna repetida", "id,name,price,stock,ID\n", `columna repetida en el encabezado: "id"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := tt.csv
+			if input != "" {
+				input += "1,Lápiz,2.50,5\n"
+			}
+			rows, rowErrs, err := csvfile.ReadProducts(strings.NewReader(input))
+			if tt.wantErr == "" {
+				// Las filas de 4 columnas solo coinciden con los encabezados de 4.
+				if err != nil {
+					t.Fatalf("ReadProducts: %v", err)
+				}
+				if len(rows)+len(rowErrs) != 1 {
+					t.Errorf("%d filas y %d errores, se esperaba una fila leída", len(rows), len(rowErrs))
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("ReadProducts devolvió %v, se esperaba %q", err, tt.wantErr)
+			}
+			if rows != nil || rowErrs != nil {
+				t.Errorf("con el encabezado inválido devolvió %d filas y %d errores", len(rows), len(rowErrs))
+			}
+		})
+	}
+}
+
+/*
+Cada fila que no se puede convertir se informa con su línea del archivo
+(contando el encabezado y las comillas que ocupan varias líneas) y su
+error de dominio; las demás se devuelven igual.
+*/
+func TestReadProductsRowErrors(t *testing.T) {
+	data := strings.Join([]string{
+		"id,name,price,stock",
+		"1,Lápiz,2.50,5",
+		"x,Goma,1.00,3",
+		"3,Regla,uno,3",
+		"4,Tijera,3.00,muchos",
+		"5,Cuaderno",
+		"6,\"Carpeta\nA4\",4.25,7",
+		"7,Compás,1.999,2",
+	}, "\n") + "\n"
+
+	rows, rowErrs, err := csvfile.ReadProducts(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantRows := map[int]domain.Product{
+		2: {ID: 1, Name: "Lápiz", Price: domain.NewMoney(250, "USD"), Stock: 5},
+		7: {ID: 6, Name: "Carpeta\nA4", Price: domain.NewMoney(425, "USD"), Stock: 7},
+		9: {ID: 7, Name: "Compás", Price: domain.NewMoney(200, "USD"), Stock: 2},
+	}
+	if len(rows) != len(wantRows) {
+		t.Fatalf("%d filas, se esperaban %d: %+v", len(rows), len(wantRows), rows)
+	}
+	for _, row := range rows {
+		if want, ok := wantRows[row.Line]; !ok || row.Value != want {
+			t.Errorf("línea %d: %+v, se esperaba %+v", row.Line, row.Value, want)
+		}
+	}
+
+	wantErrs := []struct {
+		line int
+		err  error
+	}{
+		{3, domain.ErrInvalidID},
+		{4, domain.ErrInvalidAmount},
+		{5, domain.ErrInvalidStock},
+		{6, nil}, // cantidad de columnas: no es un error de dominio
+	}
+	if len(rowErrs) != len(wantErrs) {
+		t.Fatalf("%d errores, se esperaban %d: %v", len(rowErrs), len(wantErrs), rowErrs)
+	}
+	for i, want := range wantErrs {
+		got := rowErrs[i]
+		if got.Line != want.line {
+			t.Errorf("error %d en la línea %d, se esperaba %d", i, got.Line, want.line)
+		}
+		if want.err != nil && !errors.Is(got, want.err) {
+			t.Errorf("línea %d: %v, se esperaba %v", got.Line, got, want.err)
+		}
+	}
+	if !strings.Contains(rowErrs[3].Error(), "tiene 2 columnas, el encabezado tiene 4") {
+		t.Errorf("línea 6: %v", rowErrs[3])
+	}
+}
+
+// Lo que escribe WriteProducts / WriteCustomers se vuelve a leer igual.
+func TestRoundTrip(t *testing.T) {
+	products := []domain.Product{
+		{ID: 1, Name: "Lápiz, negro", Price: domain.NewMoney(250, "USD"), Stock: 5},
+		{ID: 2, Name: "Goma", Price: domain.NewMoney(1500, "JPY"), Stock: 0},
+	}
+	var buf bytes.Buffer
+	if err := csvfile.WriteProducts(&buf, products); err != nil {
+		t.Fatal(err)
+	}
+	rows, rowErrs, err := csvfile.ReadProducts(&buf)
+	if err != nil || len(rowErrs) != 0 || len(rows) != len(products) {
+		t.Fatalf("ReadProducts: %d filas, errores %v, %v", len(rows), rowErrs, err)
+	}
+	for i, row := range rows {
+		if row.Value != products[i] || row.Line != i+2 {
+			t.Errorf("fila %d = %+v (línea %d), se esperaba %+v", i, row.Value, row.Line, products[i])
+		}
+	}
+
+	customers := []domain.Customer{{ID: 1, Name: "Ana \"la\" Pérez", Email: "ana@example.com"}}
+	buf.Reset()
+	if err := csvfile.WriteCustomers(&buf, customers); err != nil {
+		t.Fatal(err)
+	}
+	crows, cerrs, err := csvfile.ReadCustomers(&buf)
+	if err != nil || len(cerrs) != 0 || len(crows) != 1 || crows[0].Value != customers[0] {
+		t.Errorf("ReadCustomers = %+v, %v, %v", crows, cerrs, err)
+	}
+}
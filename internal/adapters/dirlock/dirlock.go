@@ -0,0 +1,81 @@
+/*
+Package dirlock reserva un directorio de datos para un solo proceso.
+
+Responsabilidad:
+- Que dos programas (por ejemplo, el servidor HTTP y la CLI) no abran a
+  la vez el mismo directorio de jsonfile o eventlog. Esos almacenamientos
+  guardan el estado en memoria y lo escriben completo (o numeran los
+  eventos) a partir de esa copia: un segundo proceso pisaría los
+  archivos del primero o repetiría números de evento.
+
+Funcionamiento:
+- Acquire toma un candado exclusivo sobre el archivo .lock del directorio
+  (flock en sistemas Unix). Si otro proceso lo tiene, falla enseguida
+  con ErrLocked en lugar de esperar.
+- El sistema operativo suelta el candado cuando el proceso termina,
+  aunque termine mal: un .lock que quedó en el directorio no bloquea a
+  nadie.
+- Wait toma el mismo tipo de candado sobre un archivo cualquiera, pero
+  espera a que se libere: sirve para operaciones cortas que varios
+  procesos hacen por turnos (por ejemplo, leer, cambiar y reescribir
+  un archivo).
+
+Nota:
+- En sistemas sin flock, Acquire no reserva nada (ver lock_other.go).
+*/
+package dirlock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ErrLocked indica que otro proceso ya tiene abierto el directorio de datos.
+var ErrLocked = errors.New("el directorio de datos está en uso por otro proceso")
+
+// Lock es el candado sobre un directorio de datos.
+type Lock struct {
+	file *os.File
+}
+
+/*
+Acquire reserva dir para este proceso.
+
+Devuelve un error que envuelve ErrLocked si otro proceso ya lo reservó.
+*/
+func Acquire(dir string) (*Lock, error) {
+	f, err := os.OpenFile(filepath.Join(dir, ".lock"), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := lock(f); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("%s: %w", dir, err)
+	}
+	return &Lock{file: f}, nil
+}
+
+// Release libera el directorio.
+func (l *Lock) Release() error {
+	return l.file.Close()
+}
+
+/*
+Wait reserva el archivo path (lo crea si no existe) para este proceso,
+esperando mientras otro proceso lo tenga.
+
+Conviene liberarlo enseguida: mientras tanto, los demás esperan.
+*/
+func Wait(path string) (*Lock, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := wait(f); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &Lock{file: f}, nil
+}
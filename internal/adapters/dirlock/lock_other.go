@@ -0,0 +1,15 @@
+//go:build !unix
+
+package dirlock
+
+import "os"
+
+// lock no hace nada: en estos sistemas no se detecta el uso desde varios procesos.
+func lock(*os.File) error {
+	return nil
+}
+
+// wait tampoco hace nada (ver lock).
+func wait(*os.File) error {
+	return nil
+}
@@ -0,0 +1,66 @@
+//go:build unix
+
+package dirlock_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/dirlock"
+)
+
+// Un directorio reservado no se puede volver a reservar hasta que se libera.
+func TestAcquire(t *testing.T) {
+	dir := t.TempDir()
+	first, err := dirlock.Acquire(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// flock se aplica por archivo abierto: otro Acquire del mismo proceso
+	// choca igual que el de otro proceso.
+	if _, err := dirlock.Acquire(dir); !errors.Is(err, dirlock.ErrLocked) {
+		t.Fatalf("segundo Acquire devolvió %v, se esperaba %v", err, dirlock.ErrLocked)
+	}
+
+	if err := first.Release(); err != nil {
+		t.Fatal(err)
+	}
+	second, err := dirlock.Acquire(dir)
+	if err != nil {
+		t.Fatalf("Acquire después de Release: %v", err)
+	}
+	_ = second.Release()
+}
+
+// Wait espera a que se libere el archivo en lugar de fallar.
+func TestWait(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "payments.lock")
+	first, err := dirlock.Wait(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acquired := make(chan *dirlock.Lock)
+	go func() {
+		second, err := dirlock.Wait(path)
+		if err != nil {
+			t.Error(err)
+		}
+		acquired <- second
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("el segundo Wait no esperó a que se liberara el archivo")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if err := first.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if second := <-acquired; second != nil {
+		_ = second.Release()
+	}
+}
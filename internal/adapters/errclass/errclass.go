@@ -0,0 +1,131 @@
+/*
+Package errclass clasifica los errores de dominio para los adaptadores
+de entrada (la API HTTP y la CLI).
+
+Responsabilidad:
+- Decir en un solo lugar qué significa cada error de dominio para quien
+  llama (datos inválidos, no existe, conflicto, ...). La API lo traduce a
+  códigos HTTP y la CLI a códigos de salida, así ambas responden igual
+  ante el mismo error.
+- Dar a cada error un código estable ("no_stock", "not_found", ...)
+  para los clientes de la API.
+
+El dominio no sabe nada de esto: sus errores solo dicen qué regla se
+rompió, no cómo se presenta.
+*/
+package errclass
+
+import (
+	"errors"
+	"slices"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+)
+
+// Kind agrupa los errores de dominio según lo que significan para quien llama.
+type Kind int
+
+const (
+	Unknown         Kind = iota // No es un error de dominio (falla técnica)
+	Invalid                     // Datos que no cumplen las reglas
+	NotFound                    // La entidad buscada no existe
+	Conflict                    // El estado actual no permite la operación
+	NoStock                     // Stock insuficiente
+	EmptyCart                   // Checkout de un carrito vacío
+	PaymentFailed               // El proveedor rechazó el pago o pide verificación
+	PaymentTimeout              // El proveedor no respondió a tiempo
+	Unauthenticated             // Falta la identificación o es incorrecta
+	Forbidden                   // El usuario no tiene permiso
+)
+
+/*
+Class es la clasificación de un error de dominio: su tipo y un código
+estable. Los códigos son parte del contrato de la API: no se renombran.
+*/
+type Class struct {
+	Err  error
+	Kind Kind
+	Code string
+}
+
+/*
+classes clasifica todos los errores de dominio.
+
+Se recorre en orden con errors.Is, así los errores que envuelven a
+otros (por ejemplo *domain.InsufficientStockError) también se reconocen.
+Un error nuevo se agrega aquí y lo reconocen la API y la CLI a la vez.
+*/
+var classes = []Class{
+	{domain.ErrUnauthenticated, Unauthenticated, "unauthenticated"},
+	{domain.ErrInvalidCredentials, Unauthenticated, "invalid_credentials"},
+	{domain.ErrForbidden, Forbidden, "forbidden"},
+
+	{domain.ErrNoStock, NoStock, "no_stock"},
+	{domain.ErrEmptyCart, EmptyCart, "empty_cart"},
+	{domain.ErrPriceChanged, Conflict, "price_changed"},
+
+	{domain.ErrProductNotFound, NotFound, "not_found"},
+	{domain.ErrOrderNotFound, NotFound, "not_found"},
+	{domain.ErrReturnNotFound, NotFound, "not_found"},
+	{domain.ErrPaymentNotFound, NotFound, "not_found"},
+	{domain.ErrUserNotFound, NotFound, "not_found"},
+	{domain.ErrCustomerNotFound, NotFound, "not_found"},
+
+	{domain.ErrConcurrentModification, Conflict, "conflict"},
+	{domain.ErrDuplicateOrderID, Conflict, "conflict"},
+	{domain.ErrInvalidStatusTransition, Conflict, "conflict"},
+	{domain.ErrOrderAlreadyShipped, Conflict, "conflict"},
+	{domain.ErrOrderNotDelivered, Conflict, "conflict"},
+	{domain.ErrReturnAlreadyDecided, Conflict, "conflict"},
+	{domain.ErrInvalidPaymentState, Conflict, "conflict"},
+	{domain.ErrUsernameTaken, Conflict, "conflict"},
+	{domain.ErrCustomerHasOrders, Conflict, "conflict"},
+	{domain.ErrCheckoutInProgress, Conflict, "checkout_in_progress"},
+
+	{domain.ErrIdempotencyKeyReused, Invalid, "idempotency_key_reused"},
+
+	{domain.ErrPaymentDeclined, PaymentFailed, "payment_declined"},
+	{domain.ErrPaymentActionRequired, PaymentFailed, "payment_action_required"},
+	{domain.ErrPaymentTimeout, PaymentTimeout, "payment_timeout"},
+
+	{domain.ErrInvalidID, Invalid, "invalid"},
+	{domain.ErrEmptyName, Invalid, "invalid"},
+	{domain.ErrInvalidPrice, Invalid, "invalid"},
+	{domain.ErrInvalidStock, Invalid, "invalid"},
+	{domain.ErrInvalidCustomerID, Invalid, "invalid"},
+	{domain.ErrEmptyCustomerName, Invalid, "invalid"},
+	{domain.ErrInvalidEmail, Invalid, "invalid"},
+	{domain.ErrInvalidQuantity, Invalid, "invalid"},
+	{domain.ErrInvalidDateRange, Invalid, "invalid"},
+	{domain.ErrInvalidOrderStatus, Invalid, "invalid"},
+	{domain.ErrEmptyReturn, Invalid, "invalid"},
+	{domain.ErrProductNotInOrder, Invalid, "invalid"},
+	{domain.ErrReturnQuantityExceeded, Invalid, "invalid"},
+	{domain.ErrInvalidAmount, Invalid, "invalid"},
+	{domain.ErrInvalidCurrency, Invalid, "invalid"},
+	{domain.ErrCurrencyMismatch, Invalid, "invalid"},
+	{domain.ErrInvalidIdempotencyKey, Invalid, "invalid"},
+	{domain.ErrInvalidUsername, Invalid, "invalid"},
+	{domain.ErrInvalidRole, Invalid, "invalid"},
+	{domain.ErrWeakPassword, Invalid, "invalid"},
+}
+
+/*
+Classify devuelve la clasificación de err.
+
+Si err no es (ni envuelve) un error de dominio, devuelve Unknown: para
+quien llama es una falla técnica (almacenamiento, red, ...).
+*/
+func Classify(err error) Class {
+	for _, c := range classes {
+		if errors.Is(err, c.Err) {
+			return c
+		}
+	}
+	return Class{Err: err, Kind: Unknown, Code: "internal"}
+}
+
+// Classes devuelve la clasificación completa, en el orden en que se evalúa.
+func Classes() []Class {
+	return slices.Clone(classes)
+}
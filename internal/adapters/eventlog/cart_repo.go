@@ -0,0 +1,55 @@
+package eventlog
+
+import (
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/memory"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+/*
+CartRepo es el repositorio de carritos de un Log.
+
+Implementa usecase.CartRepository. Agregar, quitar o cambiar la cantidad
+de un ítem registra CartSaved con el carrito completo.
+*/
+type CartRepo struct {
+	*memory.CartRepo
+	log *Log
+}
+
+// NewCartRepo devuelve el repositorio de carritos del log.
+func NewCartRepo(l *Log) *CartRepo {
+	return &CartRepo{CartRepo: l.carts, log: l}
+}
+
+// Save guarda el carrito y registra CartSaved.
+func (r *CartRepo) Save(cart domain.Cart) error {
+	return r.log.uow.Do(func(tx usecase.Repositories) error { return tx.Carts.Save(cart) })
+}
+
+// Clear vacía el carrito y registra CartCleared (Data es el ID del cliente).
+func (r *CartRepo) Clear(customerID int) error {
+	return r.log.uow.Do(func(tx usecase.Repositories) error { return tx.Carts.Clear(customerID) })
+}
+
+// cartTx son los carritos de una UnitOfWork del log: cada escritura deja pendiente su evento.
+type cartTx struct {
+	usecase.CartRepository
+	log *Log
+}
+
+// Save aplica el cambio en memoria y deja pendiente CartSaved.
+func (t cartTx) Save(cart domain.Cart) error {
+	if err := t.CartRepository.Save(cart); err != nil {
+		return err
+	}
+	return t.log.record(CartSaved, cart)
+}
+
+// Clear aplica el cambio en memoria y deja pendiente CartCleared.
+func (t cartTx) Clear(customerID int) error {
+	if err := t.CartRepository.Clear(customerID); err != nil {
+		return err
+	}
+	return t.log.record(CartCleared, customerID)
+}
@@ -0,0 +1,85 @@
+package eventlog_test
+
+import (
+	"testing"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/eventlog"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase/repotest"
+)
+
+// Los repositorios del log de eventos cumplen el mismo contrato que los de memoria.
+func TestContract(t *testing.T) {
+	suites := map[string]func() error{
+		"Products": func() error {
+			return repotest.Products(func() (repotest.ProductRepository, error) {
+				l, err := openLog(t)
+				if err != nil {
+					return nil, err
+				}
+				return eventlog.NewProductRepo(l), nil
+			})
+		},
+		"Customers": func() error {
+			return repotest.Customers(func() (repotest.CustomerRepository, error) {
+				l, err := openLog(t)
+				if err != nil {
+					return nil, err
+				}
+				return eventlog.NewCustomerRepo(l), nil
+			})
+		},
+		"Carts": func() error {
+			return repotest.Carts(func() (usecase.CartRepository, error) {
+				l, err := openLog(t)
+				if err != nil {
+					return nil, err
+				}
+				return eventlog.NewCartRepo(l), nil
+			})
+		},
+		"Reservations": func() error {
+			return repotest.Reservations(func() (usecase.ReservationRepository, error) {
+				l, err := openLog(t)
+				if err != nil {
+					return nil, err
+				}
+				return eventlog.NewReservationRepo(l), nil
+			})
+		},
+		"Orders": func() error {
+			return repotest.Orders(func() (usecase.OrderRepository, error) {
+				l, err := openLog(t)
+				if err != nil {
+					return nil, err
+				}
+				return eventlog.NewOrderRepo(l), nil
+			})
+		},
+		"Returns": func() error {
+			return repotest.Returns(func() (usecase.ReturnRepository, usecase.OrderRepository, error) {
+				l, err := openLog(t)
+				if err != nil {
+					return nil, nil, err
+				}
+				return eventlog.NewReturnRepo(l), eventlog.NewOrderRepo(l), nil
+			})
+		},
+		"Users": func() error {
+			return repotest.Users(func() (usecase.UserRepository, error) {
+				l, err := openLog(t)
+				if err != nil {
+					return nil, err
+				}
+				return eventlog.NewUserRepo(l), nil
+			})
+		},
+	}
+	for name, run := range suites {
+		t.Run(name, func(t *testing.T) {
+			if err := run(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
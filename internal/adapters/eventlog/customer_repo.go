@@ -0,0 +1,68 @@
+package eventlog
+
+import (
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/memory"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+/*
+CustomerRepo es el repositorio de clientes de un Log.
+
+Implementa usecase.CustomerRepository, usecase.CustomerRepositoryForCheckout
+y usecase.CustomerRepositoryForDelete.
+*/
+type CustomerRepo struct {
+	*memory.CustomerRepo
+	log *Log
+}
+
+// NewCustomerRepo devuelve el repositorio de clientes del log.
+func NewCustomerRepo(l *Log) *CustomerRepo {
+	return &CustomerRepo{CustomerRepo: l.customers, log: l}
+}
+
+// Create guarda un cliente nuevo y registra CustomerCreated.
+func (r *CustomerRepo) Create(c domain.Customer) error {
+	return r.log.uow.Do(func(tx usecase.Repositories) error { return tx.Customers.Create(c) })
+}
+
+// Update reemplaza un cliente y registra CustomerUpdated.
+func (r *CustomerRepo) Update(c domain.Customer) error {
+	return r.log.uow.Do(func(tx usecase.Repositories) error { return tx.Customers.Update(c) })
+}
+
+// Delete borra un cliente y registra CustomerDeleted (Data es el ID del cliente).
+func (r *CustomerRepo) Delete(id int) error {
+	return r.log.uow.Do(func(tx usecase.Repositories) error { return tx.Customers.Delete(id) })
+}
+
+// customerTx son los clientes de una UnitOfWork del log: cada escritura deja pendiente su evento.
+type customerTx struct {
+	txCustomers
+	log *Log
+}
+
+// Create aplica el cambio en memoria y deja pendiente CustomerCreated.
+func (t customerTx) Create(c domain.Customer) error {
+	if err := t.txCustomers.Create(c); err != nil {
+		return err
+	}
+	return t.log.record(CustomerCreated, t.log.sealCustomer(c))
+}
+
+// Update aplica el cambio en memoria y deja pendiente CustomerUpdated.
+func (t customerTx) Update(c domain.Customer) error {
+	if err := t.txCustomers.Update(c); err != nil {
+		return err
+	}
+	return t.log.record(CustomerUpdated, t.log.sealCustomer(c))
+}
+
+// Delete aplica el cambio en memoria y deja pendiente CustomerDeleted.
+func (t customerTx) Delete(id int) error {
+	if err := t.txCustomers.Delete(id); err != nil {
+		return err
+	}
+	return t.log.record(CustomerDeleted, id)
+}
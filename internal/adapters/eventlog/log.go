@@ -0,0 +1,669 @@
+package eventlog
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/dirlock"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/memory"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+/*
+EventType identifica qué cambio de estado registra un evento.
+*/
+type EventType string
+
+const (
+	ProductCreated  EventType = "product.created"
+	ProductUpdated  EventType = "product.updated" // stock, precio o nombre
+	CustomerCreated EventType = "customer.created"
+	CustomerUpdated EventType = "customer.updated" // nombre o email, o anonimización
+	CustomerDeleted EventType = "customer.deleted" // Data es el ID del cliente
+	CartSaved       EventType = "cart.saved"       // ítem agregado, quitado o cantidad cambiada
+	CartCleared     EventType = "cart.cleared"
+	OrderPlaced     EventType = "order.placed"
+	OrderUpdated    EventType = "order.updated" // cambio de estado, cancelación, reembolso
+	ReturnRequested EventType = "return.requested"
+	ReturnUpdated   EventType = "return.updated"
+	UserCreated     EventType = "user.created"
+	UserUpdated     EventType = "user.updated" // clave de API emitida
+	UserDeleted     EventType = "user.deleted" // baja del cliente asociado
+)
+
+/*
+Event es una línea del log: un cambio de estado ya confirmado.
+
+Data contiene la entidad completa después del cambio (un Product,
+un Cart, un Order, ...), así aplicar un evento es siempre "reemplazar".
+*/
+type Event struct {
+	Seq  uint64          `json:"seq"`
+	At   time.Time       `json:"at"`
+	Type EventType       `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// DefaultSnapshotEvery es cada cuántos eventos se guarda un snapshot si no se configura otro valor.
+const DefaultSnapshotEvery = 100
+
+/*
+Options configura un Log.
+
+- SnapshotEvery: cada cuántos eventos se guarda un snapshot (0 = DefaultSnapshotEvery).
+- Clock: hora de cada evento; si es nil se usa la del sistema.
+*/
+type Options struct {
+	SnapshotEvery int
+	Clock         usecase.Clock
+}
+
+/*
+Log es un almacenamiento basado en eventos.
+
+Archivos dentro del directorio de datos:
+- events.log: un evento JSON por línea. Solo se agregan líneas, nunca
+  se modifican ni se borran (salvo las de una escritura que falló, que
+  nunca llegaron a confirmarse; ver flush).
+- snapshot.json: el estado completo hasta cierto evento (Seq). Acota el
+  tiempo de arranque: solo se reproducen los eventos posteriores.
+- keys.json: la clave con la que se cifran los datos personales de cada
+  cliente, en los dos archivos anteriores (ver personal.go).
+
+Al abrirlo, el estado se reconstruye en repositorios en memoria
+(snapshot + eventos restantes); las lecturas se resuelven ahí.
+
+Escrituras:
+- Todas pasan por la misma memory.UnitOfWork (las sueltas, como una
+  unidad de trabajo de una sola operación), que retiene los candados de
+  escritura de los repositorios mientras dura.
+- Cada escritura se aplica en memoria y deja su evento pendiente; los
+  eventos de la unidad de trabajo se escriben juntos al confirmarla. Si
+  se deshace, la memoria vuelve atrás y no queda ningún evento en el log.
+- Las reservas de stock quedan solo en memoria (no generan eventos),
+  pero se deshacen junto con el resto.
+
+Un solo proceso:
+- El número de cada evento (Seq) sale del estado en memoria, así que dos
+  procesos sobre el mismo directorio repetirían números y cada uno
+  ignoraría los eventos del otro. Open reserva el directorio (ver
+  dirlock) y falla con dirlock.ErrLocked si otro proceso ya lo tiene.
+  ReplayUntil solo lee y no lo reserva.
+- Las versiones (domain.ErrConcurrentModification) protegen entre
+  goroutines de este proceso. Para compartir los datos entre varios
+  procesos hay que usar sqlstore: storage.Open lo indica en el error.
+*/
+type Log struct {
+	dir           string
+	lock          *dirlock.Lock
+	file          logFile
+	clock         usecase.Clock
+	snapshotEvery int
+
+	// seq, sinceSnapshot y pending solo se tocan dentro de uow (que
+	// retiene los candados de escritura), así que no necesitan otro candado.
+	seq           uint64
+	sinceSnapshot int
+
+	// end es dónde termina el último evento confirmado de events.log.
+	end int64
+
+	// broken es el error de una escritura fallida que no se pudo
+	// descartar: desde entonces no se escribe más (ver flush).
+	broken error
+
+	// keys son las claves de keys.json; keysDirty, si hay cambios sin
+	// guardar. También se tocan solo dentro de uow (o al abrir).
+	keys      map[int][]byte
+	keysDirty bool
+
+	// pending son los eventos de la UnitOfWork en curso.
+	pending []Event
+
+	products     *memory.ProductRepo
+	customers    *memory.CustomerRepo
+	carts        *memory.CartRepo
+	reservations *memory.ReservationRepo
+	orders       *memory.OrderRepo
+	returns      *memory.ReturnRepo
+	users        *memory.UserRepo
+
+	uow *memory.UnitOfWork
+}
+
+/*
+logFile es lo que Log usa de events.log: un *os.File abierto para
+agregar. Es una interfaz para que las pruebas puedan simular fallas.
+*/
+type logFile interface {
+	io.Writer
+	Sync() error
+	Truncate(size int64) error
+	Close() error
+}
+
+/*
+Open abre (o crea) el log en dir y reconstruye el estado.
+
+Pasos:
+1) Reservar el directorio para este proceso.
+2) Cargar snapshot.json, si existe.
+3) Reproducir los eventos de events.log con Seq mayor al del snapshot.
+4) Dejar events.log abierto para agregar eventos nuevos.
+*/
+func Open(dir string, opts Options) (*Log, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if opts.SnapshotEvery <= 0 {
+		opts.SnapshotEvery = DefaultSnapshotEvery
+	}
+	if opts.Clock == nil {
+		opts.Clock = usecase.SystemClock{}
+	}
+
+	lock, err := dirlock.Acquire(dir)
+	if err != nil {
+		return nil, err
+	}
+	l, err := open(dir, lock, opts)
+	if err != nil {
+		_ = lock.Release()
+		return nil, err
+	}
+	return l, nil
+}
+
+// open es Open con el directorio ya reservado.
+func open(dir string, lock *dirlock.Lock, opts Options) (*Log, error) {
+	l := &Log{
+		dir:           dir,
+		lock:          lock,
+		clock:         opts.Clock,
+		snapshotEvery: opts.SnapshotEvery,
+	}
+	l.reset()
+
+	keys, err := readKeys(dir)
+	if err != nil {
+		return nil, err
+	}
+	l.keys = keys
+	snap, err := readSnapshot(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.load(l.openState(snap.State)); err != nil {
+		return nil, err
+	}
+	l.seq = snap.Seq
+
+	end, err := readEvents(dir, func(e Event) (bool, error) {
+		if e.Seq <= snap.Seq {
+			return true, nil
+		}
+		if err := l.apply(e); err != nil {
+			return false, err
+		}
+		l.seq = e.Seq
+		l.sinceSnapshot++
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// Completa el olvido de un cliente si el proceso se cortó antes de borrar su clave.
+	if err := l.forgetErased(); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(filepath.Join(dir, "events.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	// Si la última escritura quedó a medias, se descarta antes de agregar
+	// eventos nuevos para no pegarlos a una línea incompleta.
+	if err := file.Truncate(end); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	l.file = file
+	l.end = end
+
+	l.uow = memory.NewUnitOfWork(memory.Repos{
+		Products:     l.products,
+		Customers:    l.customers,
+		Carts:        l.carts,
+		Reservations: l.reservations,
+		Orders:       l.orders,
+		Returns:      l.returns,
+		Users:        l.users,
+	}, memory.Hooks{
+		Wrap:     l.wrap,
+		Commit:   func([]any) error { return l.flush() },
+		Rollback: func() { l.pending = nil },
+	})
+	return l, nil
+}
+
+// Close cierra el archivo de eventos y libera el directorio.
+func (l *Log) Close() error {
+	return errors.Join(l.file.Close(), l.lock.Release())
+}
+
+/*
+State es el estado completo reconstruido a partir del log.
+
+Es lo que se guarda en snapshot.json y lo que devuelve ReplayUntil.
+*/
+type State struct {
+	Products  []domain.Product
+	Customers []domain.Customer
+	Carts     []domain.Cart
+	Orders    []usecase.Order
+	Returns   []usecase.ReturnRequest
+	Users     []domain.User
+}
+
+/*
+ReplayUntil reconstruye el estado tal como estaba en el instante until,
+sin modificar ningún archivo.
+
+Pensado para inspección forense ("¿qué stock había ayer a las 18:00?"):
+- Si el snapshot es anterior o igual a until, se parte de él.
+- Si no, se reproduce el log desde el principio (el log nunca se recorta).
+- Se aplican los eventos con At <= until.
+- Los clientes ya dados de baja aparecen anonimizados en cualquier
+  fecha: su clave ya no existe (ver personal.go).
+*/
+func ReplayUntil(dir string, until time.Time) (State, error) {
+	l := &Log{dir: dir}
+	l.reset()
+
+	keys, err := readKeys(dir)
+	if err != nil {
+		return State{}, err
+	}
+	l.keys = keys
+	snap, err := readSnapshot(dir)
+	if err != nil {
+		return State{}, err
+	}
+	var from uint64
+	if snap.Seq > 0 && !snap.At.After(until) {
+		if err := l.load(l.openState(snap.State)); err != nil {
+			return State{}, err
+		}
+		from = snap.Seq
+	}
+
+	_, err = readEvents(dir, func(e Event) (bool, error) {
+		if e.Seq <= from {
+			return true, nil
+		}
+		if e.At.After(until) {
+			return false, nil
+		}
+		return true, l.apply(e)
+	})
+	if err != nil {
+		return State{}, err
+	}
+	return l.state()
+}
+
+// NewUnitOfWork devuelve la unidad de trabajo del log (la misma que usan sus repositorios).
+func NewUnitOfWork(l *Log) usecase.UnitOfWork {
+	return l.uow
+}
+
+// NewReservationRepo devuelve el repositorio de reservas del log (solo en memoria, sin eventos).
+func NewReservationRepo(l *Log) *memory.ReservationRepo {
+	return l.reservations
+}
+
+// wrap decora los repositorios de una unidad de trabajo para que cada escritura registre su evento.
+func (l *Log) wrap(tx usecase.Repositories) usecase.Repositories {
+	tx.Products = productTx{tx.Products, l}
+	tx.Customers = customerTx{tx.Customers, l}
+	tx.Carts = cartTx{tx.Carts, l}
+	tx.Orders = orderTx{tx.Orders, l}
+	tx.Returns = returnTx{tx.Returns, l}
+	tx.Users = userTx{tx.Users, l}
+	return tx
+}
+
+/*
+record deja pendiente el evento de un cambio ya aplicado en memoria.
+
+Se escribe al confirmar la unidad de trabajo (ver flush).
+*/
+func (l *Log) record(t EventType, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	l.pending = append(l.pending, Event{At: l.clock.Now(), Type: t, Data: data})
+	return nil
+}
+
+/*
+flush escribe los eventos pendientes en una sola escritura y los
+fuerza a disco. Después, si corresponde, guarda un snapshot.
+
+Si la escritura falla, recorta events.log hasta el último evento
+confirmado. Si ni eso se puede, el Log deja de escribir (devuelve el
+mismo error en cada commit) hasta volver a abrirlo: Open descarta una
+última línea incompleta.
+*/
+func (l *Log) flush() error {
+	if len(l.pending) == 0 {
+		return nil
+	}
+	if l.broken != nil {
+		return l.broken
+	}
+	// Si la escritura falla, la unidad de trabajo se deshace y el
+	// Rollback descarta los pendientes.
+
+	// Las claves nuevas se guardan antes que los eventos que cifran.
+	if l.keysDirty {
+		if err := writeKeys(l.dir, l.keys); err != nil {
+			return err
+		}
+		l.keysDirty = false
+	}
+
+	seq := l.seq
+	var buf []byte
+	for i := range l.pending {
+		seq++
+		l.pending[i].Seq = seq
+		line, err := json.Marshal(l.pending[i])
+		if err != nil {
+			return err
+		}
+		buf = append(append(buf, line...), '\n')
+	}
+
+	if err := l.write(buf); err != nil {
+		// Lo que llegó a escribirse (quizás media línea) no se confirmó:
+		// se recorta para que el próximo evento no quede pegado a una
+		// línea incompleta, que rompería la lectura del log al abrirlo.
+		if truncErr := l.file.Truncate(l.end); truncErr != nil {
+			l.broken = fmt.Errorf("events.log quedó con una escritura a medias; hay que volver a abrirlo: %w", truncErr)
+			return errors.Join(err, l.broken)
+		}
+		return err
+	}
+	l.end += int64(len(buf))
+
+	l.sinceSnapshot += len(l.pending)
+	l.seq = seq
+	l.pending = nil
+
+	// Si falla, keys.json conserva claves que ya no hacen falta: se
+	// vuelve a intentar en el próximo commit (o al abrir).
+	_ = l.forgetErased()
+
+	if l.sinceSnapshot >= l.snapshotEvery {
+		// Un snapshot fallido no pierde datos (el log está completo):
+		// se vuelve a intentar con el próximo evento.
+		if state, err := l.state(); err == nil {
+			sealed := l.sealState(state)
+			if l.keysDirty {
+				// Un cliente de un log anterior al cifrado recibe su clave ahora.
+				if err := writeKeys(l.dir, l.keys); err != nil {
+					return nil
+				}
+				l.keysDirty = false
+			}
+			if err := writeSnapshot(l.dir, snapshotFile{Seq: l.seq, At: l.clock.Now(), State: sealed}); err == nil {
+				l.sinceSnapshot = 0
+			}
+		}
+	}
+	return nil
+}
+
+// write agrega buf a events.log y lo fuerza a disco.
+func (l *Log) write(buf []byte) error {
+	if _, err := l.file.Write(buf); err != nil {
+		return err
+	}
+	return l.file.Sync()
+}
+
+/*
+apply aplica un evento sobre los repositorios en memoria.
+
+Como Data trae la entidad completa, crear o actualizar es reemplazar.
+Los eventos ya pasaron el control de versiones al registrarse, así que
+se aplican sobre la versión actual (los logs escritos antes de que
+existieran las versiones las traen en 0).
+*/
+func (l *Log) apply(e Event) error {
+	var err error
+	switch e.Type {
+	case ProductCreated:
+		var p domain.Product
+		if err = json.Unmarshal(e.Data, &p); err == nil {
+			err = l.products.Create(p)
+		}
+	case ProductUpdated:
+		var p domain.Product
+		if err = json.Unmarshal(e.Data, &p); err == nil {
+			if current, getErr := l.products.GetByID(p.ID); getErr == nil {
+				p.Version = current.Version
+			}
+			err = l.products.Update(p)
+		}
+	case CustomerCreated:
+		var c domain.Customer
+		if err = json.Unmarshal(e.Data, &c); err == nil {
+			err = l.customers.Create(l.openCustomer(c))
+		}
+	case CustomerUpdated:
+		var c domain.Customer
+		if err = json.Unmarshal(e.Data, &c); err == nil {
+			c = l.openCustomer(c)
+			if current, getErr := l.customers.GetByID(c.ID); getErr == nil {
+				c.Version = current.Version
+			}
+			err = l.customers.Update(c)
+		}
+	case CustomerDeleted:
+		var id int
+		if err = json.Unmarshal(e.Data, &id); err == nil {
+			err = l.customers.Delete(id)
+		}
+	case CartSaved:
+		var c domain.Cart
+		if err = json.Unmarshal(e.Data, &c); err == nil {
+			var current domain.Cart
+			if current, err = l.carts.Get(c.CustomerID); err == nil {
+				c.Version = current.Version
+				err = l.carts.Save(c)
+			}
+		}
+	case CartCleared:
+		var customerID int
+		if err = json.Unmarshal(e.Data, &customerID); err == nil {
+			err = l.carts.Clear(customerID)
+		}
+	case OrderPlaced:
+		var o usecase.Order
+		if err = json.Unmarshal(e.Data, &o); err == nil {
+			err = l.orders.Create(l.openOrder(o))
+		}
+	case OrderUpdated:
+		var o usecase.Order
+		if err = json.Unmarshal(e.Data, &o); err == nil {
+			err = l.orders.Update(l.openOrder(o))
+		}
+	case ReturnRequested:
+		var r usecase.ReturnRequest
+		if err = json.Unmarshal(e.Data, &r); err == nil {
+			err = l.returns.Create(r)
+		}
+	case ReturnUpdated:
+		var r usecase.ReturnRequest
+		if err = json.Unmarshal(e.Data, &r); err == nil {
+			err = l.returns.Update(r)
+		}
+	case UserCreated:
+		var u domain.User
+		if err = json.Unmarshal(e.Data, &u); err == nil {
+			err = l.users.Create(u)
+		}
+	case UserUpdated:
+		var u domain.User
+		if err = json.Unmarshal(e.Data, &u); err == nil {
+			if current, getErr := l.users.GetByUsername(u.Username); getErr == nil {
+				u.Version = current.Version
+			}
+			err = l.users.Update(u)
+		}
+	case UserDeleted:
+		var username string
+		if err = json.Unmarshal(e.Data, &username); err == nil {
+			err = l.users.Delete(username)
+		}
+	default:
+		err = fmt.Errorf("tipo de evento desconocido: %s", e.Type)
+	}
+	if err != nil {
+		return fmt.Errorf("evento %d (%s): %w", e.Seq, e.Type, err)
+	}
+	return nil
+}
+
+// reset crea repositorios en memoria vacíos.
+func (l *Log) reset() {
+	l.products = memory.NewProductRepo()
+	l.customers = memory.NewCustomerRepo()
+	l.carts = memory.NewCartRepo()
+	l.reservations = memory.NewReservationRepo()
+	l.orders = memory.NewOrderRepo()
+	l.returns = memory.NewReturnRepo()
+	l.users = memory.NewUserRepo()
+}
+
+// load carga un estado completo (por ejemplo, el de un snapshot) en memoria.
+func (l *Log) load(s State) error {
+	for _, p := range s.Products {
+		if err := l.products.Create(p); err != nil {
+			return err
+		}
+	}
+	for _, c := range s.Customers {
+		if err := l.customers.Create(c); err != nil {
+			return err
+		}
+	}
+	for _, c := range s.Carts {
+		l.carts.Load(c)
+	}
+	for _, o := range s.Orders {
+		if err := l.orders.Create(o); err != nil {
+			return err
+		}
+	}
+	for _, r := range s.Returns {
+		if err := l.returns.Create(r); err != nil {
+			return err
+		}
+	}
+	for _, u := range s.Users {
+		if err := l.users.Create(u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// state devuelve una copia del estado actual en memoria.
+func (l *Log) state() (State, error) {
+	products, err := l.products.List()
+	if err != nil {
+		return State{}, err
+	}
+	customers, err := l.customers.List()
+	if err != nil {
+		return State{}, err
+	}
+	users, err := l.users.List()
+	if err != nil {
+		return State{}, err
+	}
+	return State{
+		Products:  products,
+		Customers: customers,
+		Carts:     l.carts.All(),
+		Orders:    l.orders.All(),
+		Returns:   l.returns.All(),
+		Users:     users,
+	}, nil
+}
+
+/*
+readEvents recorre events.log en orden y llama a fn con cada evento.
+
+fn devuelve false para dejar de leer. Si el archivo no existe no hay
+eventos. Una última línea incompleta (por ejemplo, si el proceso se cortó
+a mitad de una escritura) se ignora: nunca llegó a confirmarse.
+
+Devuelve la posición donde termina la última línea completa leída.
+*/
+func readEvents(dir string, fn func(Event) (bool, error)) (int64, error) {
+	f, err := os.Open(filepath.Join(dir, "events.log"))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var end int64
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			return end, nil
+		}
+		if err != nil {
+			return end, err
+		}
+
+		var e Event
+		if err := json.Unmarshal(line, &e); err != nil {
+			return end, fmt.Errorf("events.log: %w", err)
+		}
+		end += int64(len(line))
+
+		more, err := fn(e)
+		if err != nil || !more {
+			return end, err
+		}
+	}
+}
+
+// Tipos de los repositorios de usecase.Repositories que no tienen nombre, para embeberlos.
+type (
+	txProducts = interface {
+		usecase.ProductRepository
+		usecase.ProductRepositoryForCart
+	}
+	txCustomers = interface {
+		usecase.CustomerRepository
+		usecase.CustomerRepositoryForDelete
+	}
+)
@@ -0,0 +1,73 @@
+package eventlog
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+)
+
+var errDisk = errors.New("disco lleno")
+
+// halfWrite escribe la mitad de lo pedido y falla, como un disco que se llena a mitad de camino.
+type halfWrite struct {
+	*os.File
+}
+
+func (f halfWrite) Write(p []byte) (int, error) {
+	n, _ := f.File.Write(p[:len(p)/2])
+	return n, errDisk
+}
+
+/*
+Una escritura que falla a mitad de una línea no deja basura en
+events.log: los eventos siguientes se confirman bien y el log se puede
+volver a abrir con todos ellos.
+*/
+func TestFlushTruncatesFailedWrite(t *testing.T) {
+	dir := t.TempDir()
+	l, err := Open(dir, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	products := NewProductRepo(l)
+	price := domain.NewMoney(1000, domain.DefaultCurrency)
+	if err := products.Create(domain.Product{ID: 1, Name: "Lápiz", Price: price, Stock: 5}); err != nil {
+		t.Fatal(err)
+	}
+
+	file := l.file.(*os.File)
+	l.file = halfWrite{file}
+	if err := products.Create(domain.Product{ID: 2, Name: "Cuaderno", Price: price, Stock: 3}); !errors.Is(err, errDisk) {
+		t.Fatalf("Create devolvió %v, se esperaba %v", err, errDisk)
+	}
+	l.file = file
+	if err := products.Create(domain.Product{ID: 3, Name: "Goma", Price: price, Stock: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := ReplayUntil(dir, time.Now())
+	if err != nil {
+		t.Fatalf("ReplayUntil: %v", err)
+	}
+	var ids []int
+	for _, p := range state.Products {
+		ids = append(ids, p.ID)
+	}
+	slices.Sort(ids)
+	if !slices.Equal(ids, []int{1, 3}) {
+		t.Errorf("productos = %v, se esperaba [1 3]", ids)
+	}
+
+	reopened, err := Open(dir, Options{})
+	if err != nil {
+		t.Fatalf("Open después de la escritura fallida: %v", err)
+	}
+	_ = reopened.Close()
+}
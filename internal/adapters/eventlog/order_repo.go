@@ -0,0 +1,54 @@
+package eventlog
+
+import (
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/memory"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+/*
+OrderRepo es el repositorio de pedidos de un Log.
+
+Implementa usecase.OrderRepository. Un checkout registra OrderPlaced;
+los cambios de estado, cancelaciones y reembolsos registran OrderUpdated.
+*/
+type OrderRepo struct {
+	*memory.OrderRepo
+	log *Log
+}
+
+// NewOrderRepo devuelve el repositorio de pedidos del log.
+func NewOrderRepo(l *Log) *OrderRepo {
+	return &OrderRepo{OrderRepo: l.orders, log: l}
+}
+
+// Create guarda un pedido nuevo y registra OrderPlaced.
+func (r *OrderRepo) Create(o usecase.Order) error {
+	return r.log.uow.Do(func(tx usecase.Repositories) error { return tx.Orders.Create(o) })
+}
+
+// Update reemplaza un pedido y registra OrderUpdated.
+func (r *OrderRepo) Update(o usecase.Order) error {
+	return r.log.uow.Do(func(tx usecase.Repositories) error { return tx.Orders.Update(o) })
+}
+
+// orderTx son los pedidos de una UnitOfWork del log: cada escritura deja pendiente su evento.
+type orderTx struct {
+	usecase.OrderRepository
+	log *Log
+}
+
+// Create aplica el cambio en memoria y deja pendiente OrderPlaced.
+func (t orderTx) Create(o usecase.Order) error {
+	if err := t.OrderRepository.Create(o); err != nil {
+		return err
+	}
+	return t.log.record(OrderPlaced, t.log.sealOrder(o))
+}
+
+// Update aplica el cambio en memoria y deja pendiente OrderUpdated.
+func (t orderTx) Update(o usecase.Order) error {
+	if err := t.OrderRepository.Update(o); err != nil {
+		return err
+	}
+	return t.log.record(OrderUpdated, t.log.sealOrder(o))
+}
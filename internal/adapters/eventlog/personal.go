@@ -0,0 +1,259 @@
+package eventlog
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+/*
+Datos personales y derecho al olvido.
+
+El log nunca se reescribe, así que no se puede borrar de él el nombre o
+el email de un cliente. Por eso esos datos no se escriben en claro: se
+cifran con una clave propia de cada cliente, guardada aparte en
+keys.json. Olvidar al cliente es borrar su clave.
+
+Reglas:
+- Se cifran Customer.Name, Customer.Email y Order.CustomerName, en
+  events.log y en snapshot.json. El resto (IDs, montos, fechas) queda
+  en claro.
+- Al confirmar la baja de un cliente (borrado o anonimización), se borra
+  su clave. Desde entonces, sus datos viejos ya no se pueden leer: al
+  abrir el log o con ReplayUntil, en cualquier fecha, el cliente y sus
+  pedidos aparecen anonimizados (domain.AnonymizeCustomer).
+- Los datos ya anonimizados no son personales y se escriben en claro.
+- Un log escrito antes de este cambio conserva en claro lo que ya tenía;
+  solo lo nuevo se cifra.
+*/
+
+// sealedPrefix marca un dato personal cifrado.
+const sealedPrefix = "sealed:"
+
+// keysVersion es la versión del formato de keys.json.
+const keysVersion = 1
+
+/*
+keysFile es el contenido de keys.json: la clave AES-256 de cada cliente.
+
+	{"version": 1, "keys": {"1": "base64...", ...}}
+*/
+type keysFile struct {
+	Version int            `json:"version"`
+	Keys    map[int][]byte `json:"keys"`
+}
+
+// readKeys lee keys.json; si no existe, no hay claves.
+func readKeys(dir string) (map[int][]byte, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "keys.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return make(map[int][]byte), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var f keysFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("keys.json: %w", err)
+	}
+	if f.Version != keysVersion {
+		return nil, fmt.Errorf("keys.json: versión %d no soportada", f.Version)
+	}
+	if f.Keys == nil {
+		f.Keys = make(map[int][]byte)
+	}
+	return f.Keys, nil
+}
+
+// writeKeys guarda keys.json de forma atómica.
+func writeKeys(dir string, keys map[int][]byte) error {
+	data, err := json.Marshal(keysFile{Version: keysVersion, Keys: keys})
+	if err != nil {
+		return err
+	}
+	return writeFile(dir, "keys.json", data)
+}
+
+// keyFor devuelve la clave del cliente id y la crea si todavía no tiene.
+func (l *Log) keyFor(id int) []byte {
+	if key, ok := l.keys[id]; ok {
+		return key
+	}
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	l.keys[id] = key
+	l.keysDirty = true
+	return key
+}
+
+/*
+forgetErased borra las claves de los clientes que ya no existen o están
+anonimizados, y guarda keys.json si cambió.
+
+Se llama después de cada commit y al abrir: si el proceso se cortó
+entre el evento de la baja y el borrado de la clave, lo completa.
+*/
+func (l *Log) forgetErased() error {
+	for id := range l.keys {
+		c, err := l.customers.GetByID(id)
+		if err != nil || anonymized(c) {
+			delete(l.keys, id)
+			l.keysDirty = true
+		}
+	}
+	if !l.keysDirty {
+		return nil
+	}
+	if err := writeKeys(l.dir, l.keys); err != nil {
+		return err
+	}
+	l.keysDirty = false
+	return nil
+}
+
+// anonymized indica si c ya no tiene datos personales.
+func anonymized(c domain.Customer) bool {
+	return c == domain.AnonymizeCustomer(c)
+}
+
+// sealCustomer devuelve c con el nombre y el email cifrados (salvo que ya esté anonimizado).
+func (l *Log) sealCustomer(c domain.Customer) domain.Customer {
+	if anonymized(c) {
+		return c
+	}
+	key := l.keyFor(c.ID)
+	c.Name = seal(key, c.ID, c.Name)
+	c.Email = seal(key, c.ID, c.Email)
+	return c
+}
+
+// sealOrder devuelve o con el nombre del cliente cifrado (salvo que ya esté anonimizado).
+func (l *Log) sealOrder(o usecase.Order) usecase.Order {
+	if o.CustomerName == "" || o.CustomerName == domain.AnonymizedCustomerName {
+		return o
+	}
+	o.CustomerName = seal(l.keyFor(o.CustomerID), o.CustomerID, o.CustomerName)
+	return o
+}
+
+/*
+openCustomer descifra el nombre y el email de c.
+
+Si la clave ya no existe (el cliente se dio de baja) o no sirve,
+devuelve el cliente anonimizado.
+*/
+func (l *Log) openCustomer(c domain.Customer) domain.Customer {
+	if !isSealed(c.Name) && !isSealed(c.Email) {
+		return c
+	}
+	name, nameErr := unseal(l.keys[c.ID], c.ID, c.Name)
+	email, emailErr := unseal(l.keys[c.ID], c.ID, c.Email)
+	if nameErr != nil || emailErr != nil {
+		return domain.AnonymizeCustomer(c)
+	}
+	c.Name, c.Email = name, email
+	return c
+}
+
+// openOrder descifra el nombre del cliente de o (anonimizado si ya no se puede).
+func (l *Log) openOrder(o usecase.Order) usecase.Order {
+	if !isSealed(o.CustomerName) {
+		return o
+	}
+	name, err := unseal(l.keys[o.CustomerID], o.CustomerID, o.CustomerName)
+	if err != nil {
+		name = domain.AnonymizedCustomerName
+	}
+	o.CustomerName = name
+	return o
+}
+
+// sealState devuelve s con los datos personales cifrados, para guardarlo en snapshot.json.
+func (l *Log) sealState(s State) State {
+	customers := make([]domain.Customer, len(s.Customers))
+	for i, c := range s.Customers {
+		customers[i] = l.sealCustomer(c)
+	}
+	orders := make([]usecase.Order, len(s.Orders))
+	for i, o := range s.Orders {
+		orders[i] = l.sealOrder(o)
+	}
+	s.Customers, s.Orders = customers, orders
+	return s
+}
+
+// openState descifra los datos personales de un estado leído de snapshot.json.
+func (l *Log) openState(s State) State {
+	for i, c := range s.Customers {
+		s.Customers[i] = l.openCustomer(c)
+	}
+	for i, o := range s.Orders {
+		s.Orders[i] = l.openOrder(o)
+	}
+	return s
+}
+
+// isSealed indica si text es un dato cifrado con seal.
+func isSealed(text string) bool {
+	return strings.HasPrefix(text, sealedPrefix)
+}
+
+/*
+seal cifra text con AES-256-GCM.
+
+El ID del cliente va como dato asociado: un texto cifrado copiado a otro
+cliente no se puede descifrar con la clave de ese otro cliente.
+*/
+func seal(key []byte, id int, text string) string {
+	gcm, err := newGCM(key)
+	if err != nil {
+		// key siempre sale de keyFor (32 bytes): no puede fallar.
+		panic(err)
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	_, _ = rand.Read(nonce)
+	out := gcm.Seal(nonce, nonce, []byte(text), []byte(strconv.Itoa(id)))
+	return sealedPrefix + base64.StdEncoding.EncodeToString(out)
+}
+
+// unseal descifra un texto de seal; falla si la clave falta o no es la que se usó.
+func unseal(key []byte, id int, text string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(text, sealedPrefix))
+	if err != nil {
+		return "", err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", errors.New("dato cifrado incompleto")
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plain, err := gcm.Open(nil, nonce, ciphertext, []byte(strconv.Itoa(id)))
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+// newGCM crea el cifrador AES-GCM de key (nil o de otro largo es un error).
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
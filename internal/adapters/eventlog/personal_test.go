@@ -0,0 +1,139 @@
+package eventlog_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/eventlog"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+// containsAny indica si algún archivo de dir contiene alguno de los textos.
+func containsAny(t *testing.T, dir string, texts ...string) bool {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, text := range texts {
+			if bytes.Contains(data, []byte(text)) {
+				t.Logf("%s contiene %q", entry.Name(), text)
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// customerNamed devuelve el cliente id de state.
+func customerNamed(state eventlog.State, id int) domain.Customer {
+	for _, c := range state.Customers {
+		if c.ID == id {
+			return c
+		}
+	}
+	return domain.Customer{}
+}
+
+/*
+Los datos personales no quedan en claro en ningún archivo del log, y al
+dar de baja al cliente dejan de poder leerse también en los eventos y
+snapshots anteriores: ni al reabrir ni con ReplayUntil antes de la baja.
+Los demás clientes no se ven afectados.
+*/
+func TestCustomerErasure(t *testing.T) {
+	dir := t.TempDir()
+	clock := &stepClock{}
+	l, err := eventlog.Open(dir, eventlog.Options{SnapshotEvery: 3, Clock: clock})
+	if err != nil {
+		t.Fatal(err)
+	}
+	customers := eventlog.NewCustomerRepo(l)
+	for _, c := range []domain.Customer{
+		{ID: 1, Name: "Ana Pérez", Email: "ana@example.com"},
+		{ID: 2, Name: "Beto Gómez", Email: "beto@example.com"},
+	} {
+		if err := customers.Create(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+	zero := domain.ZeroMoney(domain.DefaultCurrency)
+	order := usecase.Order{ID: "ORD-1", CustomerID: 1, CustomerName: "Ana Pérez", Total: zero, Refunded: zero, CreatedAt: t0}
+	if err := eventlog.NewOrderRepo(l).Create(order); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := usecase.UpdateCustomer(customers, 1, usecase.UpdateCustomerRequest{Email: "ana.perez@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	personal := []string{"Ana Pérez", "ana@example.com", "ana.perez@example.com", "Beto Gómez", "beto@example.com"}
+	if containsAny(t, dir, personal...) {
+		t.Fatal("hay datos personales en claro en el directorio del log")
+	}
+	beforeErasure := clock.Now()
+
+	// Mientras el cliente existe, la historia se lee completa.
+	state, err := eventlog.ReplayUntil(dir, beforeErasure)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c := customerNamed(state, 1); c.Name != "Ana Pérez" || c.Email != "ana.perez@example.com" {
+		t.Fatalf("cliente 1 antes de la baja = %+v", c)
+	}
+
+	result, err := usecase.DeleteCustomer(usecase.CustomerDeps{UnitOfWork: eventlog.NewUnitOfWork(l)}, 1, true)
+	if err != nil || !result.Anonymized {
+		t.Fatalf("DeleteCustomer = %+v, %v; se esperaba anonimizado", result, err)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	anonymous := domain.AnonymizeCustomer(domain.Customer{ID: 1})
+	check := func(name string, state eventlog.State) {
+		t.Helper()
+		if c := customerNamed(state, 1); c.Name != anonymous.Name || c.Email != anonymous.Email {
+			t.Errorf("%s: cliente 1 = %+v, se esperaba anonimizado", name, c)
+		}
+		if c := customerNamed(state, 2); c.Name != "Beto Gómez" || c.Email != "beto@example.com" {
+			t.Errorf("%s: cliente 2 = %+v, no debería cambiar", name, c)
+		}
+		if len(state.Orders) != 1 || state.Orders[0].CustomerName != domain.AnonymizedCustomerName {
+			t.Errorf("%s: pedidos = %+v, se esperaba el pedido con el nombre anonimizado", name, state.Orders)
+		}
+	}
+
+	state, err = eventlog.ReplayUntil(dir, beforeErasure)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check("ReplayUntil antes de la baja", state)
+
+	state, err = eventlog.ReplayUntil(dir, t0.Add(24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	check("ReplayUntil después de la baja", state)
+
+	reopened, err := eventlog.Open(dir, eventlog.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	c, err := eventlog.NewCustomerRepo(reopened).GetByID(1)
+	if err != nil || c.Name != anonymous.Name {
+		t.Errorf("cliente 1 al reabrir = %+v (%v), se esperaba anonimizado", c, err)
+	}
+	if c, err := eventlog.NewCustomerRepo(reopened).GetByID(2); err != nil || c.Name != "Beto Gómez" {
+		t.Errorf("cliente 2 al reabrir = %+v (%v)", c, err)
+	}
+}
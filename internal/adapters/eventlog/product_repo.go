@@ -0,0 +1,56 @@
+package eventlog
+
+import (
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/memory"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+/*
+ProductRepo es el repositorio de productos de un Log.
+
+Implementa usecase.ProductRepository y usecase.ProductRepositoryForCart.
+Cada alta registra ProductCreated y cada actualización (stock, precio)
+registra ProductUpdated.
+*/
+type ProductRepo struct {
+	*memory.ProductRepo
+	log *Log
+}
+
+// NewProductRepo devuelve el repositorio de productos del log.
+func NewProductRepo(l *Log) *ProductRepo {
+	return &ProductRepo{ProductRepo: l.products, log: l}
+}
+
+// Create guarda un producto nuevo y registra ProductCreated.
+func (r *ProductRepo) Create(p domain.Product) error {
+	return r.log.uow.Do(func(tx usecase.Repositories) error { return tx.Products.Create(p) })
+}
+
+// Update reemplaza un producto y registra ProductUpdated.
+func (r *ProductRepo) Update(p domain.Product) error {
+	return r.log.uow.Do(func(tx usecase.Repositories) error { return tx.Products.Update(p) })
+}
+
+// productTx son los productos de una UnitOfWork del log: cada escritura deja pendiente su evento.
+type productTx struct {
+	txProducts
+	log *Log
+}
+
+// Create aplica el cambio en memoria y deja pendiente ProductCreated.
+func (t productTx) Create(p domain.Product) error {
+	if err := t.txProducts.Create(p); err != nil {
+		return err
+	}
+	return t.log.record(ProductCreated, p)
+}
+
+// Update aplica el cambio en memoria y deja pendiente ProductUpdated.
+func (t productTx) Update(p domain.Product) error {
+	if err := t.txProducts.Update(p); err != nil {
+		return err
+	}
+	return t.log.record(ProductUpdated, p)
+}
@@ -0,0 +1,53 @@
+package eventlog
+
+import (
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/memory"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+/*
+ReturnRepo es el repositorio de devoluciones de un Log.
+
+Implementa usecase.ReturnRepository.
+*/
+type ReturnRepo struct {
+	*memory.ReturnRepo
+	log *Log
+}
+
+// NewReturnRepo devuelve el repositorio de devoluciones del log.
+func NewReturnRepo(l *Log) *ReturnRepo {
+	return &ReturnRepo{ReturnRepo: l.returns, log: l}
+}
+
+// Create guarda una devolución nueva y registra ReturnRequested.
+func (r *ReturnRepo) Create(rma usecase.ReturnRequest) error {
+	return r.log.uow.Do(func(tx usecase.Repositories) error { return tx.Returns.Create(rma) })
+}
+
+// Update reemplaza una devolución y registra ReturnUpdated.
+func (r *ReturnRepo) Update(rma usecase.ReturnRequest) error {
+	return r.log.uow.Do(func(tx usecase.Repositories) error { return tx.Returns.Update(rma) })
+}
+
+// returnTx son los devoluciones de una UnitOfWork del log: cada escritura deja pendiente su evento.
+type returnTx struct {
+	usecase.ReturnRepository
+	log *Log
+}
+
+// Create aplica el cambio en memoria y deja pendiente ReturnRequested.
+func (t returnTx) Create(rma usecase.ReturnRequest) error {
+	if err := t.ReturnRepository.Create(rma); err != nil {
+		return err
+	}
+	return t.log.record(ReturnRequested, rma)
+}
+
+// Update aplica el cambio en memoria y deja pendiente ReturnUpdated.
+func (t returnTx) Update(rma usecase.ReturnRequest) error {
+	if err := t.ReturnRepository.Update(rma); err != nil {
+		return err
+	}
+	return t.log.record(ReturnUpdated, rma)
+}
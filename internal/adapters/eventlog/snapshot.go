@@ -0,0 +1,83 @@
+package eventlog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// snapshotVersion es la versión del formato de snapshot.json.
+const snapshotVersion = 1
+
+/*
+snapshotFile es el contenido de snapshot.json.
+
+Seq es el último evento incluido en State; At es cuándo se tomó.
+*/
+type snapshotFile struct {
+	Version int       `json:"version"`
+	Seq     uint64    `json:"seq"`
+	At      time.Time `json:"at"`
+	State   State     `json:"state"`
+}
+
+// readSnapshot lee snapshot.json; si no existe devuelve un snapshot vacío (Seq 0).
+func readSnapshot(dir string) (snapshotFile, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "snapshot.json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return snapshotFile{}, nil
+	}
+	if err != nil {
+		return snapshotFile{}, err
+	}
+
+	var snap snapshotFile
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return snapshotFile{}, fmt.Errorf("snapshot.json: %w", err)
+	}
+	if snap.Version != snapshotVersion {
+		return snapshotFile{}, fmt.Errorf("snapshot.json: versión %d no soportada", snap.Version)
+	}
+	return snap, nil
+}
+
+/*
+writeSnapshot guarda snapshot.json de forma atómica, para que un corte
+a mitad de camino deje el snapshot anterior intacto.
+*/
+func writeSnapshot(dir string, snap snapshotFile) error {
+	snap.Version = snapshotVersion
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+	return writeFile(dir, "snapshot.json", data)
+}
+
+// writeFile escribe el archivo name de dir de forma atómica (temporal + Sync + rename).
+func writeFile(dir, name string, data []byte) (err error) {
+	tmp, err := os.CreateTemp(dir, name+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, name))
+}
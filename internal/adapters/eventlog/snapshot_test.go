@@ -0,0 +1,174 @@
+package eventlog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/eventlog"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+)
+
+// t0 es la hora de partida de stepClock.
+var t0 = time.Date(2026, 10, 16, 18, 0, 0, 0, time.UTC)
+
+// stepClock avanza un minuto en cada llamada: la primera devuelve t0 + 1 minuto.
+type stepClock struct {
+	calls int
+}
+
+func (c *stepClock) Now() time.Time {
+	c.calls++
+	return t0.Add(time.Duration(c.calls) * time.Minute)
+}
+
+// createProducts crea los productos from..to, uno por evento.
+func createProducts(t *testing.T, l *eventlog.Log, from, to int) {
+	t.Helper()
+	products := eventlog.NewProductRepo(l)
+	for id := from; id <= to; id++ {
+		if err := products.Create(domain.Product{ID: id, Name: "Producto", Price: domain.NewMoney(100, domain.DefaultCurrency), Stock: 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// snapshotSeq devuelve el último evento incluido en snapshot.json, o 0 si no existe.
+func snapshotSeq(t *testing.T, dir string) uint64 {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, "snapshot.json"))
+	if os.IsNotExist(err) {
+		return 0
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	var snap struct {
+		Seq uint64 `json:"seq"`
+	}
+	if err := json.Unmarshal(data, &snap); err != nil {
+		t.Fatal(err)
+	}
+	return snap.Seq
+}
+
+/*
+dropEventsUntil borra de events.log los eventos hasta seq (inclusive).
+
+Si después de eso el estado sigue completo, es porque se leyó del snapshot.
+*/
+func dropEventsUntil(t *testing.T, dir string, seq uint64) {
+	t.Helper()
+	path := filepath.Join(dir, "events.log")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var kept []byte
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		var e eventlog.Event
+		if len(line) == 0 || json.Unmarshal(line, &e) != nil || e.Seq <= seq {
+			continue
+		}
+		kept = append(kept, line...)
+	}
+	if err := os.WriteFile(path, kept, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Con la configuración por defecto se guarda un snapshot cada 100 eventos, y Open parte de él.
+func TestSnapshotEvery100Events(t *testing.T) {
+	dir := t.TempDir()
+	l, err := eventlog.Open(dir, eventlog.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	createProducts(t, l, 1, 99)
+	if seq := snapshotSeq(t, dir); seq != 0 {
+		t.Fatalf("hay snapshot (seq %d) con 99 eventos", seq)
+	}
+	createProducts(t, l, 100, 100)
+	if seq := snapshotSeq(t, dir); seq != 100 {
+		t.Fatalf("snapshot seq = %d con 100 eventos, se esperaba 100", seq)
+	}
+	createProducts(t, l, 101, 150)
+	if seq := snapshotSeq(t, dir); seq != 100 {
+		t.Fatalf("snapshot seq = %d con 150 eventos, se esperaba 100 (el próximo, en el 200)", seq)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	dropEventsUntil(t, dir, 100)
+	reopened, err := eventlog.Open(dir, eventlog.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	products, err := eventlog.NewProductRepo(reopened).List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(products) != 150 {
+		t.Errorf("después de reabrir hay %d productos, se esperaban 150 (100 del snapshot y 50 del log)", len(products))
+	}
+}
+
+/*
+ReplayUntil con un snapshot guardado: si until es posterior al snapshot
+parte de él; si es anterior, lo ignora y reproduce el log desde el
+principio (el snapshot tiene cambios posteriores a until).
+*/
+func TestReplayUntilWithSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	l, err := eventlog.Open(dir, eventlog.Options{Clock: &stepClock{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Evento i (i <= 100) a t0+i min; el snapshot a t0+101 min; evento i (i > 100) a t0+(i+1) min.
+	createProducts(t, l, 1, 150)
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if seq := snapshotSeq(t, dir); seq != 100 {
+		t.Fatalf("snapshot seq = %d, se esperaba 100", seq)
+	}
+
+	tests := []struct {
+		name  string
+		until time.Time
+		want  int
+	}{
+		{"antes del snapshot", t0.Add(50 * time.Minute), 50},
+		{"hasta el último evento que incluye el snapshot", t0.Add(100 * time.Minute), 100},
+		{"después del snapshot", t0.Add(121 * time.Minute), 120},
+		{"después de todo", t0.Add(24 * time.Hour), 150},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state, err := eventlog.ReplayUntil(dir, tt.until)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(state.Products) != tt.want {
+				t.Errorf("%d productos, se esperaban %d", len(state.Products), tt.want)
+			}
+		})
+	}
+
+	// Sin los eventos que ya están en el snapshot, lo posterior al snapshot
+	// sale igual completo: se leyó del snapshot y no del log.
+	dropEventsUntil(t, dir, 100)
+	state, err := eventlog.ReplayUntil(dir, t0.Add(121*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Products) != 120 {
+		t.Errorf("sin los primeros 100 eventos: %d productos, se esperaban 120 (desde el snapshot)", len(state.Products))
+	}
+}
@@ -0,0 +1,91 @@
+package eventlog_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/eventlog"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase/repotest"
+)
+
+// openLog abre un Log nuevo en un directorio temporal (se cierra al terminar el test).
+func openLog(t *testing.T) (*eventlog.Log, error) {
+	l, err := eventlog.Open(t.TempDir(), eventlog.Options{})
+	if err != nil {
+		return nil, err
+	}
+	t.Cleanup(func() { _ = l.Close() })
+	return l, nil
+}
+
+// repos devuelve los repositorios de l.
+func repos(l *eventlog.Log) usecase.Repositories {
+	return usecase.Repositories{
+		Products:     eventlog.NewProductRepo(l),
+		Customers:    eventlog.NewCustomerRepo(l),
+		Carts:        eventlog.NewCartRepo(l),
+		Reservations: eventlog.NewReservationRepo(l),
+		Orders:       eventlog.NewOrderRepo(l),
+		Returns:      eventlog.NewReturnRepo(l),
+		Users:        eventlog.NewUserRepo(l),
+	}
+}
+
+// Ejecutar con -race: incluye checkouts simultáneos.
+func TestUnitOfWork(t *testing.T) {
+	err := repotest.UnitOfWork(func() (usecase.Repositories, usecase.UnitOfWork, error) {
+		l, err := openLog(t)
+		if err != nil {
+			return usecase.Repositories{}, nil, err
+		}
+		return repos(l), eventlog.NewUnitOfWork(l), nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+// Solo los eventos de las unidades de trabajo confirmadas quedan en el log.
+func TestUnitOfWorkPersists(t *testing.T) {
+	dir := t.TempDir()
+	l, err := eventlog.Open(dir, eventlog.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	uow := eventlog.NewUnitOfWork(l)
+
+	price := domain.NewMoney(1000, domain.DefaultCurrency)
+	err = uow.Do(func(tx usecase.Repositories) error {
+		if err := tx.Products.Create(domain.Product{ID: 1, Name: "Lápiz", Price: price, Stock: 5}); err != nil {
+			return err
+		}
+		return tx.Customers.Create(domain.Customer{ID: 1, Name: "Ana", Email: "ana@example.com"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	errAbort := errors.New("abortada")
+	err = uow.Do(func(tx usecase.Repositories) error {
+		if err := tx.Products.Create(domain.Product{ID: 2, Name: "Cuaderno", Price: price, Stock: 3}); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("Do devolvió %v, se esperaba %v", err, errAbort)
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := eventlog.ReplayUntil(dir, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Products) != 1 || state.Products[0].ID != 1 || len(state.Customers) != 1 {
+		t.Errorf("estado reconstruido = %+v, se esperaba solo el producto 1 y el cliente 1", state)
+	}
+}
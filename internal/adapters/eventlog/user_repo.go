@@ -0,0 +1,69 @@
+package eventlog
+
+import (
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/memory"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+/*
+UserRepo es el repositorio de usuarios de un Log.
+
+Implementa usecase.UserRepository. Los eventos guardan el usuario
+completo, con los hashes de su contraseña y de su clave de API (nunca
+los secretos en claro).
+*/
+type UserRepo struct {
+	*memory.UserRepo
+	log *Log
+}
+
+// NewUserRepo devuelve el repositorio de usuarios del log.
+func NewUserRepo(l *Log) *UserRepo {
+	return &UserRepo{UserRepo: l.users, log: l}
+}
+
+// Create guarda un usuario nuevo y registra UserCreated.
+func (r *UserRepo) Create(u domain.User) error {
+	return r.log.uow.Do(func(tx usecase.Repositories) error { return tx.Users.Create(u) })
+}
+
+// Update reemplaza un usuario y registra UserUpdated.
+func (r *UserRepo) Update(u domain.User) error {
+	return r.log.uow.Do(func(tx usecase.Repositories) error { return tx.Users.Update(u) })
+}
+
+// Delete borra un usuario y registra UserDeleted (Data es el nombre de usuario).
+func (r *UserRepo) Delete(username string) error {
+	return r.log.uow.Do(func(tx usecase.Repositories) error { return tx.Users.Delete(username) })
+}
+
+// userTx son los usuarios de una UnitOfWork del log: cada escritura deja pendiente su evento.
+type userTx struct {
+	usecase.UserRepository
+	log *Log
+}
+
+// Create aplica el cambio en memoria y deja pendiente UserCreated.
+func (t userTx) Create(u domain.User) error {
+	if err := t.UserRepository.Create(u); err != nil {
+		return err
+	}
+	return t.log.record(UserCreated, u)
+}
+
+// Update aplica el cambio en memoria y deja pendiente UserUpdated.
+func (t userTx) Update(u domain.User) error {
+	if err := t.UserRepository.Update(u); err != nil {
+		return err
+	}
+	return t.log.record(UserUpdated, u)
+}
+
+// Delete aplica el cambio en memoria y deja pendiente UserDeleted.
+func (t userTx) Delete(username string) error {
+	if err := t.UserRepository.Delete(username); err != nil {
+		return err
+	}
+	return t.log.record(UserDeleted, username)
+}
@@ -0,0 +1,404 @@
+package fakepay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/dirlock"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+/*
+Mode define cómo responde el proveedor falso al autorizar un pago.
+*/
+type Mode int
+
+const (
+	// Approve autoriza todos los pagos.
+	Approve Mode = iota
+
+	// Decline rechaza todos los pagos con domain.ErrPaymentDeclined.
+	Decline
+
+	// Timeout simula un proveedor que no responde (domain.ErrPaymentTimeout).
+	// No espera de verdad: devuelve el error al instante para que las
+	// pruebas sean rápidas y deterministas.
+	Timeout
+
+	// RequireVerification exige un segundo paso: sin código (o con un
+	// código incorrecto) responde domain.ErrPaymentActionRequired.
+	RequireVerification
+)
+
+// DefaultVerificationCode es el código que acepta RequireVerification si no se configura otro.
+const DefaultVerificationCode = "123456"
+
+/*
+Config configura el comportamiento del proveedor falso.
+*/
+type Config struct {
+	Mode             Mode
+	VerificationCode string // Solo para RequireVerification; vacío = DefaultVerificationCode
+}
+
+// paymentState es el estado interno de cada autorización.
+type paymentState string
+
+const (
+	stateAuthorized paymentState = "authorized"
+	stateCaptured   paymentState = "captured"
+	stateVoided     paymentState = "voided"
+)
+
+// payment guarda lo que el proveedor sabe de una autorización.
+type payment struct {
+	Amount   domain.Money            `json:"amount"` // Lo autorizado
+	State    paymentState            `json:"state"`
+	Captured domain.Money            `json:"captured"`
+	Refunded domain.Money            `json:"refunded"`
+	Refunds  map[string]domain.Money `json:"refunds"` // refundID -> monto, para no repetir reembolsos
+}
+
+// stateVersion es la versión del formato del archivo de Open.
+const stateVersion = 1
+
+/*
+stateFile es el contenido del archivo de Open:
+
+	{"version": 1, "payments": {"PAY-01J...": {...}, ...}}
+*/
+type stateFile struct {
+	Version  int                 `json:"version"`
+	Payments map[string]*payment `json:"payments"`
+}
+
+/*
+Gateway es un proveedor de pagos falso y determinista.
+
+Responsabilidad:
+- Implementar usecase.PaymentGateway sin salir del proceso.
+- Permitir probar cada resultado posible del pago (aprobado, rechazado,
+  timeout, verificación adicional) cambiando solo la configuración.
+- Llevar la cuenta de lo autorizado, capturado y reembolsado para
+  rechazar operaciones incoherentes igual que un proveedor real.
+
+Los IDs de autorización son ULID con prefijo "PAY-" (ver
+usecase.ULIDGenerator): no se repiten entre ejecuciones, así que un
+pago nuevo nunca se confunde con el de un pedido guardado antes.
+
+Dónde guarda los pagos:
+- NewGateway: solo en memoria. Sirve con el almacenamiento en memoria
+  (los pedidos también se pierden al salir) y en pruebas.
+- Open: en un archivo, para que los pedidos guardados en disco puedan
+  cancelarse o devolverse en otra ejecución. Cada operación relee el
+  archivo y lo reescribe de forma atómica, con un candado entre
+  procesos (ver dirlock.Wait): la CLI y el servidor pueden compartirlo.
+- Un pago que el proveedor no conoce (por ejemplo, de un pedido creado
+  con NewGateway en otra ejecución) responde domain.ErrPaymentNotFound.
+
+Es seguro para uso concurrente.
+*/
+type Gateway struct {
+	mu       sync.Mutex
+	cfg      Config
+	ids      usecase.IDGenerator
+	path     string // Vacío: solo en memoria
+	payments map[string]*payment
+}
+
+// NewGateway crea un proveedor falso en memoria con la configuración indicada.
+func NewGateway(cfg Config) *Gateway {
+	if cfg.VerificationCode == "" {
+		cfg.VerificationCode = DefaultVerificationCode
+	}
+	return &Gateway{cfg: cfg, ids: usecase.NewULIDGenerator("PAY-", nil), payments: make(map[string]*payment)}
+}
+
+/*
+Open crea un proveedor falso que guarda sus pagos en el archivo path
+(se crea con el primer pago). Con path vacío equivale a NewGateway.
+
+Falla si el archivo existe pero no se puede leer.
+*/
+func Open(path string, cfg Config) (*Gateway, error) {
+	g := NewGateway(cfg)
+	if path == "" {
+		return g, nil
+	}
+	g.path = path
+	if err := g.do(false, func() error { return nil }); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+/*
+do ejecuta fn con los pagos al día, con g.mu tomado.
+
+Con archivo, lo relee antes de fn (bajo el candado entre procesos) y,
+si write es true y fn no falló, lo reescribe después.
+*/
+func (g *Gateway) do(write bool, fn func() error) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.path == "" {
+		return fn()
+	}
+
+	lock, err := dirlock.Wait(g.path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	if err := g.load(); err != nil {
+		return err
+	}
+	if err := fn(); err != nil {
+		return err
+	}
+	if !write {
+		return nil
+	}
+	return g.save()
+}
+
+// load reemplaza los pagos en memoria por los del archivo (ninguno si no existe).
+func (g *Gateway) load() error {
+	data, err := os.ReadFile(g.path)
+	if errors.Is(err, os.ErrNotExist) {
+		g.payments = make(map[string]*payment)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var st stateFile
+	if err := json.Unmarshal(data, &st); err != nil {
+		return fmt.Errorf("%s: %w", g.path, err)
+	}
+	if st.Version != stateVersion {
+		return fmt.Errorf("%s: versión %d no soportada", g.path, st.Version)
+	}
+	if st.Payments == nil {
+		st.Payments = make(map[string]*payment)
+	}
+	g.payments = st.Payments
+	return nil
+}
+
+// save escribe los pagos en el archivo de forma atómica (temporal, Sync y rename).
+func (g *Gateway) save() (err error) {
+	data, err := json.MarshalIndent(stateFile{Version: stateVersion, Payments: g.payments}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(g.path), filepath.Base(g.path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+	if _, err = tmp.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), g.path)
+}
+
+/*
+SetMode cambia el comportamiento del proveedor en caliente.
+
+Útil en pruebas para, por ejemplo, aprobar un checkout y rechazar el siguiente.
+*/
+func (g *Gateway) SetMode(mode Mode) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.cfg.Mode = mode
+}
+
+/*
+Authorize responde según el Mode configurado.
+
+Si aprueba, registra la autorización en estado authorized.
+*/
+func (g *Gateway) Authorize(req usecase.PaymentRequest) (usecase.PaymentAuthorization, error) {
+	var auth usecase.PaymentAuthorization
+	err := g.do(true, func() error {
+		if !domain.IsPositive(req.Amount) {
+			return domain.ErrInvalidAmount
+		}
+
+		switch g.cfg.Mode {
+		case Decline:
+			return domain.ErrPaymentDeclined
+		case Timeout:
+			return domain.ErrPaymentTimeout
+		case RequireVerification:
+			if req.VerificationCode != g.cfg.VerificationCode {
+				return domain.ErrPaymentActionRequired
+			}
+		}
+
+		auth = usecase.PaymentAuthorization{ID: g.ids.NewID(), Amount: req.Amount}
+		g.payments[auth.ID] = &payment{
+			Amount:   req.Amount,
+			State:    stateAuthorized,
+			Captured: domain.ZeroMoney(req.Amount.Currency),
+			Refunded: domain.ZeroMoney(req.Amount.Currency),
+			Refunds:  make(map[string]domain.Money),
+		}
+		return nil
+	})
+	if err != nil {
+		return usecase.PaymentAuthorization{}, err
+	}
+	return auth, nil
+}
+
+/*
+Capture cobra una autorización.
+
+Reglas:
+- Solo se captura una autorización en estado authorized, una sola vez.
+- No se puede capturar más de lo autorizado.
+*/
+func (g *Gateway) Capture(authorizationID string, amount domain.Money) error {
+	return g.do(true, func() error {
+		p, ok := g.payments[authorizationID]
+		if !ok {
+			return domain.ErrPaymentNotFound
+		}
+		if p.State != stateAuthorized {
+			return domain.ErrInvalidPaymentState
+		}
+		cmp, err := domain.CompareMoney(amount, p.Amount)
+		if err != nil {
+			return err
+		}
+		if cmp > 0 || !domain.IsPositive(amount) {
+			return domain.ErrInvalidAmount
+		}
+
+		p.State = stateCaptured
+		p.Captured = amount
+		return nil
+	})
+}
+
+/*
+Void anula una autorización que todavía no se capturó.
+
+Anular dos veces la misma autorización no es error (idempotente).
+*/
+func (g *Gateway) Void(authorizationID string) error {
+	return g.do(true, func() error {
+		p, ok := g.payments[authorizationID]
+		if !ok {
+			return domain.ErrPaymentNotFound
+		}
+		switch p.State {
+		case stateVoided:
+			return nil
+		case stateCaptured:
+			return domain.ErrInvalidPaymentState
+		}
+		p.State = stateVoided
+		return nil
+	})
+}
+
+/*
+Refund devuelve al cliente parte o todo lo capturado.
+
+Reglas:
+- Solo se reembolsa un pago capturado.
+- La suma de reembolsos no puede superar lo capturado.
+- Repetir un refundID ya usado no reembolsa de nuevo: responde sin
+  error si el monto es el mismo, o domain.ErrInvalidAmount si no.
+*/
+func (g *Gateway) Refund(authorizationID, refundID string, amount domain.Money) error {
+	return g.do(true, func() error {
+		p, ok := g.payments[authorizationID]
+		if !ok {
+			return domain.ErrPaymentNotFound
+		}
+		if prev, done := p.Refunds[refundID]; done {
+			if prev != amount {
+				return domain.ErrInvalidAmount
+			}
+			return nil
+		}
+		if p.State != stateCaptured {
+			return domain.ErrInvalidPaymentState
+		}
+		if !domain.IsPositive(amount) {
+			return domain.ErrInvalidAmount
+		}
+
+		refunded, err := domain.AddMoney(p.Refunded, amount)
+		if err != nil {
+			return err
+		}
+		if cmp, _ := domain.CompareMoney(refunded, p.Captured); cmp > 0 {
+			return domain.ErrInvalidAmount
+		}
+
+		p.Refunded = refunded
+		p.Refunds[refundID] = amount
+		return nil
+	})
+}
+
+/*
+Refunded devuelve cuánto se reembolsó de una autorización.
+
+No forma parte de usecase.PaymentGateway: existe para que las pruebas
+puedan verificar lo que hizo el caso de uso.
+*/
+func (g *Gateway) Refunded(authorizationID string) (domain.Money, error) {
+	var refunded domain.Money
+	err := g.do(false, func() error {
+		p, ok := g.payments[authorizationID]
+		if !ok {
+			return domain.ErrPaymentNotFound
+		}
+		refunded = p.Refunded
+		return nil
+	})
+	return refunded, err
+}
+
+/*
+State devuelve el estado de una autorización: "authorized", "captured" o "voided".
+
+Igual que Refunded, es una ayuda para pruebas.
+*/
+func (g *Gateway) State(authorizationID string) (string, error) {
+	var state paymentState
+	err := g.do(false, func() error {
+		p, ok := g.payments[authorizationID]
+		if !ok {
+			return domain.ErrPaymentNotFound
+		}
+		state = p.State
+		return nil
+	})
+	return string(state), err
+}
@@ -0,0 +1,208 @@
+package fakepay_test
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/fakepay"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+// money devuelve cents en la moneda por defecto.
+func money(cents int64) domain.Money {
+	return domain.NewMoney(cents, domain.DefaultCurrency)
+}
+
+// authorize autoriza $10.00 en g y devuelve el ID de la autorización.
+func authorize(t *testing.T, g *fakepay.Gateway) string {
+	t.Helper()
+	auth, err := g.Authorize(usecase.PaymentRequest{OrderID: "ORD-1", CustomerID: 1, Amount: money(1000)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return auth.ID
+}
+
+// Cada Mode responde a Authorize con su resultado, sin registrar pagos rechazados.
+func TestAuthorizeModes(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  fakepay.Config
+		code string
+		want error
+	}{
+		{"aprobado", fakepay.Config{Mode: fakepay.Approve}, "", nil},
+		{"rechazado", fakepay.Config{Mode: fakepay.Decline}, "", domain.ErrPaymentDeclined},
+		{"timeout", fakepay.Config{Mode: fakepay.Timeout}, "", domain.ErrPaymentTimeout},
+		{"verificación sin código", fakepay.Config{Mode: fakepay.RequireVerification}, "", domain.ErrPaymentActionRequired},
+		{"verificación con código incorrecto", fakepay.Config{Mode: fakepay.RequireVerification}, "000000", domain.ErrPaymentActionRequired},
+		{"verificación con código por defecto", fakepay.Config{Mode: fakepay.RequireVerification}, fakepay.DefaultVerificationCode, nil},
+		{"verificación con código propio", fakepay.Config{Mode: fakepay.RequireVerification, VerificationCode: "4242"}, "4242", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := fakepay.NewGateway(tt.cfg)
+			auth, err := g.Authorize(usecase.PaymentRequest{OrderID: "ORD-1", CustomerID: 1, Amount: money(1000), VerificationCode: tt.code})
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Authorize devolvió %v, se esperaba %v", err, tt.want)
+			}
+			if err != nil {
+				if auth.ID != "" {
+					t.Errorf("una autorización rechazada tiene ID %q", auth.ID)
+				}
+				return
+			}
+			if auth.Amount != money(1000) {
+				t.Errorf("Amount = %v, se esperaba %v", auth.Amount, money(1000))
+			}
+			if state, err := g.State(auth.ID); err != nil || state != "authorized" {
+				t.Errorf("State = %q (%v), se esperaba authorized", state, err)
+			}
+		})
+	}
+}
+
+// Un monto cero o negativo no se autoriza, en ningún modo.
+func TestAuthorizeInvalidAmount(t *testing.T) {
+	g := fakepay.NewGateway(fakepay.Config{})
+	for _, amount := range []domain.Money{money(0), money(-100)} {
+		if _, err := g.Authorize(usecase.PaymentRequest{OrderID: "ORD-1", Amount: amount}); !errors.Is(err, domain.ErrInvalidAmount) {
+			t.Errorf("Authorize(%v) devolvió %v, se esperaba %v", amount, err, domain.ErrInvalidAmount)
+		}
+	}
+}
+
+// Anular una autorización la libera: anular otra vez no es error, capturar sí.
+func TestVoidAfterAuthorize(t *testing.T) {
+	g := fakepay.NewGateway(fakepay.Config{})
+	id := authorize(t, g)
+
+	for range 2 {
+		if err := g.Void(id); err != nil {
+			t.Fatalf("Void: %v", err)
+		}
+	}
+	if state, err := g.State(id); err != nil || state != "voided" {
+		t.Errorf("State = %q (%v), se esperaba voided", state, err)
+	}
+	if err := g.Capture(id, money(1000)); !errors.Is(err, domain.ErrInvalidPaymentState) {
+		t.Errorf("Capture después de Void devolvió %v, se esperaba %v", err, domain.ErrInvalidPaymentState)
+	}
+	if err := g.Refund(id, "R-1", money(100)); !errors.Is(err, domain.ErrInvalidPaymentState) {
+		t.Errorf("Refund después de Void devolvió %v, se esperaba %v", err, domain.ErrInvalidPaymentState)
+	}
+}
+
+// Una autorización se captura una sola vez, sin superar lo autorizado, y ya no se puede anular.
+func TestCapture(t *testing.T) {
+	g := fakepay.NewGateway(fakepay.Config{})
+	id := authorize(t, g)
+
+	if err := g.Capture(id, money(1001)); !errors.Is(err, domain.ErrInvalidAmount) {
+		t.Errorf("Capture de más devolvió %v, se esperaba %v", err, domain.ErrInvalidAmount)
+	}
+	if err := g.Capture(id, money(1000)); err != nil {
+		t.Fatalf("Capture: %v", err)
+	}
+	if err := g.Capture(id, money(1000)); !errors.Is(err, domain.ErrInvalidPaymentState) {
+		t.Errorf("segundo Capture devolvió %v, se esperaba %v", err, domain.ErrInvalidPaymentState)
+	}
+	if err := g.Void(id); !errors.Is(err, domain.ErrInvalidPaymentState) {
+		t.Errorf("Void después de Capture devolvió %v, se esperaba %v", err, domain.ErrInvalidPaymentState)
+	}
+	if err := g.Capture("PAY-NO-EXISTE", money(1000)); !errors.Is(err, domain.ErrPaymentNotFound) {
+		t.Errorf("Capture de un pago inexistente devolvió %v, se esperaba %v", err, domain.ErrPaymentNotFound)
+	}
+}
+
+// La suma de los reembolsos no puede superar lo capturado.
+func TestRefundOverCaptured(t *testing.T) {
+	g := fakepay.NewGateway(fakepay.Config{})
+	id := authorize(t, g)
+
+	if err := g.Refund(id, "R-0", money(100)); !errors.Is(err, domain.ErrInvalidPaymentState) {
+		t.Errorf("Refund antes de Capture devolvió %v, se esperaba %v", err, domain.ErrInvalidPaymentState)
+	}
+	if err := g.Capture(id, money(800)); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.Refund(id, "R-1", money(500)); err != nil {
+		t.Fatalf("Refund: %v", err)
+	}
+	if err := g.Refund(id, "R-2", money(301)); !errors.Is(err, domain.ErrInvalidAmount) {
+		t.Errorf("Refund por encima de lo capturado devolvió %v, se esperaba %v", err, domain.ErrInvalidAmount)
+	}
+	if err := g.Refund(id, "R-3", money(300)); err != nil {
+		t.Fatalf("Refund hasta lo capturado: %v", err)
+	}
+	if refunded, err := g.Refunded(id); err != nil || refunded != money(800) {
+		t.Errorf("Refunded = %v (%v), se esperaba %v", refunded, err, money(800))
+	}
+}
+
+// Repetir un refundID no reembolsa de nuevo; repetirlo con otro monto es un error.
+func TestRefundIdempotent(t *testing.T) {
+	g := fakepay.NewGateway(fakepay.Config{})
+	id := authorize(t, g)
+	if err := g.Capture(id, money(1000)); err != nil {
+		t.Fatal(err)
+	}
+
+	for range 3 {
+		if err := g.Refund(id, "CANCEL-ORD-1", money(400)); err != nil {
+			t.Fatalf("Refund: %v", err)
+		}
+	}
+	if err := g.Refund(id, "CANCEL-ORD-1", money(500)); !errors.Is(err, domain.ErrInvalidAmount) {
+		t.Errorf("Refund repetido con otro monto devolvió %v, se esperaba %v", err, domain.ErrInvalidAmount)
+	}
+	if refunded, err := g.Refunded(id); err != nil || refunded != money(400) {
+		t.Errorf("Refunded = %v (%v), se esperaba %v (una sola vez)", refunded, err, money(400))
+	}
+}
+
+/*
+Con Open, los pagos sobreviven a la ejecución: otro proveedor abierto
+sobre el mismo archivo (por ejemplo, la CLI la próxima vez) puede
+reembolsar un pago capturado antes, y sus IDs no chocan con los viejos.
+*/
+func TestOpenPersistsPayments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "payments.json")
+	first, err := fakepay.Open(path, fakepay.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := authorize(t, first)
+	if err := first.Capture(id, money(1000)); err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := fakepay.Open(path, fakepay.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := second.Refund(id, "CANCEL-ORD-1", money(1000)); err != nil {
+		t.Fatalf("Refund de un pago de la ejecución anterior: %v", err)
+	}
+	if other := authorize(t, second); other == id {
+		t.Errorf("el nuevo pago repite el ID %s", id)
+	}
+
+	// El primero ve lo que hizo el segundo: relee el archivo en cada operación.
+	if refunded, err := first.Refunded(id); err != nil || refunded != money(1000) {
+		t.Errorf("Refunded = %v (%v), se esperaba %v", refunded, err, money(1000))
+	}
+}
+
+// Sin archivo, un pago desconocido (de otra ejecución) responde ErrPaymentNotFound.
+func TestUnknownPayment(t *testing.T) {
+	g := fakepay.NewGateway(fakepay.Config{})
+	if err := g.Refund("PAY-000001", "CANCEL-ORD-1", money(1000)); !errors.Is(err, domain.ErrPaymentNotFound) {
+		t.Errorf("Refund devolvió %v, se esperaba %v", err, domain.ErrPaymentNotFound)
+	}
+	if err := g.Void("PAY-000001"); !errors.Is(err, domain.ErrPaymentNotFound) {
+		t.Errorf("Void devolvió %v, se esperaba %v", err, domain.ErrPaymentNotFound)
+	}
+}
@@ -0,0 +1,362 @@
+/*
+Package httpapi expone los casos de uso como una API REST con JSON.
+
+Es otro adaptador de entrada, igual que la CLI:
+- Traduce pedidos HTTP a llamadas a usecase y sus resultados a JSON.
+- No contiene reglas de negocio ni sabe cómo se guardan los datos.
+- Los errores de dominio se convierten en códigos HTTP con un cuerpo
+  uniforme (ver errors.go).
+
+Los nombres de los campos JSON son los mismos que usa la CLI con
+--output json: los montos van como texto ("12.50") con la moneda en un
+campo aparte.
+
+Autenticación: cada ruta indica quién la puede usar (ver access en
+auth.go); la clave de API se envía como "Authorization: Bearer <clave>"
+y se obtiene con POST /auth/login.
+*/
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/storage"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+// maxBodyBytes limita el tamaño del cuerpo de los pedidos.
+const maxBodyBytes = 1 << 20
+
+/*
+Deps agrupa las dependencias de la API; son las mismas que reciben los
+subcomandos de la CLI.
+*/
+type Deps struct {
+	Products  storage.ProductStore
+	Customers storage.CustomerStore
+	Cart      usecase.CartDeps
+	Checkout  usecase.CheckoutDeps
+	Orders    usecase.OrderDeps
+	Users     usecase.UserRepository
+}
+
+/*
+route es un endpoint de la API.
+
+pattern usa la sintaxis de http.ServeMux ("/products/{id}"). Las rutas
+se arman con endpoint, que toma los tipos del cuerpo del pedido y de la
+respuesta de la firma del handler: con esos tipos se genera también el
+documento OpenAPI (ver openapi.go), así la especificación no puede
+quedar desactualizada respecto de los handlers.
+*/
+type route struct {
+	method      string
+	pattern     string
+	summary     string
+	operationID string // nombre del handler
+	status      int    // código de la respuesta exitosa
+	errors      []int  // códigos de error propios de la ruta (además de 400, 401, 403, 413 y 500)
+
+	request  reflect.Type // nil si la ruta no recibe cuerpo
+	optional bool         // el cuerpo se puede omitir (ver bodyOptional)
+	response reflect.Type // nil si la respuesta no tiene cuerpo
+	headers  []header     // encabezados opcionales que lee el handler
+	query    []queryParam // parámetros opcionales de la query string que lee el handler
+	access   access       // quién puede usar la ruta (ver withAccess)
+
+	serve func(d Deps, r *http.Request) (any, error)
+}
+
+// header es un encabezado opcional del pedido, para el documento OpenAPI.
+type header struct {
+	name, description string
+}
+
+// withHeader agrega a la ruta un encabezado opcional que lee su handler.
+func (rt route) withHeader(name, description string) route {
+	rt.headers = append(slices.Clone(rt.headers), header{name, description})
+	return rt
+}
+
+/*
+queryParam es un parámetro opcional de la query string, para el
+documento OpenAPI; typ es su tipo JSON Schema ("string", "boolean").
+*/
+type queryParam struct {
+	name, typ, description string
+}
+
+// withQuery agrega a la ruta un parámetro opcional de la query string que lee su handler.
+func (rt route) withQuery(name, typ, description string) route {
+	rt.query = append(slices.Clone(rt.query), queryParam{name, typ, description})
+	return rt
+}
+
+// none es el tipo de cuerpo de las rutas sin cuerpo de pedido o de respuesta.
+type none struct{}
+
+/*
+bodyOptional lo implementan los cuerpos de pedido que se pueden omitir;
+en ese caso el handler recibe el valor cero.
+*/
+type bodyOptional interface {
+	bodyOptional()
+}
+
+/*
+endpoint arma una ruta a partir de un handler tipado.
+
+Req es el cuerpo JSON del pedido (none si no tiene) y Resp el de la
+respuesta (none para responder sin cuerpo, por ejemplo 204). El handler
+recibe el cuerpo ya decodificado.
+*/
+func endpoint[Req, Resp any](method, pattern, summary string, status int,
+	handle func(d Deps, r *http.Request, req Req) (Resp, error), errs ...int) route {
+	rt := route{method: method, pattern: pattern, summary: summary, status: status, errors: errs,
+		operationID: handlerName(handle)}
+	if t := reflect.TypeFor[Req](); t != reflect.TypeFor[none]() {
+		rt.request = t
+		_, rt.optional = any(new(Req)).(bodyOptional)
+	}
+	if t := reflect.TypeFor[Resp](); t != reflect.TypeFor[none]() {
+		rt.response = t
+	}
+
+	hasBody, optional, hasResponse := rt.request != nil, rt.optional, rt.response != nil
+	rt.serve = func(d Deps, r *http.Request) (any, error) {
+		var req Req
+		switch {
+		case hasBody && optional:
+			if err := decodeOptional(r, &req); err != nil {
+				return nil, err
+			}
+		case hasBody:
+			if err := decode(r, &req); err != nil {
+				return nil, err
+			}
+		}
+		resp, err := handle(d, r, req)
+		if err != nil || !hasResponse {
+			return nil, err
+		}
+		return resp, nil
+	}
+	return rt
+}
+
+// routes es la tabla de endpoints de la API.
+var routes = []route{
+	endpoint("POST", "/auth/login", "Inicia sesión y emite una clave de API nueva (invalida la anterior)",
+		http.StatusOK, login, http.StatusUnauthorized).
+		withAccess(accessPublic),
+	endpoint("GET", "/me", "Devuelve el usuario dueño de la clave de API",
+		http.StatusOK, me).
+		withAccess(accessAuthenticated),
+	endpoint("GET", "/users", "Lista los usuarios, ordenados por nombre",
+		http.StatusOK, listUsers).
+		withAccess(accessAdmin),
+	endpoint("POST", "/users", "Crea un usuario",
+		http.StatusCreated, createUser, http.StatusUnprocessableEntity, http.StatusConflict).
+		withAccess(accessAdmin),
+	endpoint("POST", "/users/{username}/api-key", "Emite una clave de API nueva para un usuario (invalida la anterior)",
+		http.StatusOK, issueUserAPIKey, http.StatusNotFound).
+		withAccess(accessAdmin),
+
+	endpoint("GET", "/products", "Lista los productos, ordenados por ID",
+		http.StatusOK, listProducts).
+		withAccess(accessAuthenticated),
+	endpoint("POST", "/products", "Crea un producto",
+		http.StatusCreated, createProduct, http.StatusUnprocessableEntity),
+	endpoint("GET", "/products/{id}", "Devuelve un producto",
+		http.StatusOK, getProduct, http.StatusNotFound).
+		withAccess(accessAuthenticated),
+	endpoint("PUT", "/products/{id}/price", "Cambia el precio de un producto",
+		http.StatusOK, changeProductPrice, http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusConflict),
+
+	endpoint("GET", "/customers", "Lista los clientes, ordenados por ID",
+		http.StatusOK, listCustomers).
+		withQuery("q", "string", "Solo los clientes cuyo nombre o email contienen este texto (sin distinguir mayúsculas)"),
+	endpoint("POST", "/customers", "Crea un cliente",
+		http.StatusCreated, createCustomer, http.StatusUnprocessableEntity),
+	endpoint("GET", "/customers/{id}", "Devuelve un cliente",
+		http.StatusOK, getCustomer, http.StatusNotFound).
+		withAccess(accessOwnCustomer),
+	endpoint("PATCH", "/customers/{id}", "Cambia el nombre y/o el email de un cliente",
+		http.StatusOK, updateCustomer, http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusConflict),
+	endpoint("DELETE", "/customers/{id}", "Da de baja un cliente y sus usuarios (con pedidos, solo anonimizándolo)",
+		http.StatusOK, deleteCustomer, http.StatusNotFound, http.StatusConflict).
+		withQuery("anonymize", "boolean", "Si el cliente tiene pedidos, reemplaza sus datos personales en lugar de responder 409").
+		withAccess(accessAdmin),
+
+	endpoint("GET", "/customers/{id}/cart", "Devuelve el carrito de un cliente con su total",
+		http.StatusOK, getCart, http.StatusUnprocessableEntity).
+		withAccess(accessOwnCustomer),
+	endpoint("DELETE", "/customers/{id}/cart", "Vacía el carrito",
+		http.StatusNoContent, clearCart, http.StatusConflict).
+		withAccess(accessOwnCustomer),
+	endpoint("POST", "/customers/{id}/cart/items", "Agrega unidades de un producto al carrito",
+		http.StatusOK, addCartItem, http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusConflict).
+		withAccess(accessOwnCustomer),
+	endpoint("PUT", "/customers/{id}/cart/items/{product_id}", "Fija la cantidad de un producto en el carrito (0 lo quita)",
+		http.StatusOK, setCartItem, http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusConflict).
+		withAccess(accessOwnCustomer),
+	endpoint("DELETE", "/customers/{id}/cart/items/{product_id}", "Quita un producto del carrito",
+		http.StatusOK, removeCartItem, http.StatusUnprocessableEntity, http.StatusConflict).
+		withAccess(accessOwnCustomer),
+	endpoint("POST", "/customers/{id}/cart/accept-prices", "Acepta los precios actuales de los productos del carrito",
+		http.StatusOK, acceptCartPrices, http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusConflict).
+		withAccess(accessOwnCustomer),
+	endpoint("POST", "/customers/{id}/checkout", "Confirma la compra del carrito",
+		http.StatusCreated, checkout, http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusConflict, http.StatusPaymentRequired, http.StatusGatewayTimeout).
+		withHeader(idempotencyKeyHeader, "Identifica el intento de compra: repetir el pedido con la misma clave devuelve el resultado del primero").
+		withAccess(accessOwnCustomer),
+
+	endpoint("GET", "/customers/{id}/orders", "Lista los pedidos de un cliente",
+		http.StatusOK, listCustomerOrders).
+		withAccess(accessOwnCustomer),
+	endpoint("GET", "/orders/{order_id}", "Devuelve un pedido",
+		http.StatusOK, getOrder, http.StatusNotFound, http.StatusForbidden).
+		withAccess(accessAuthenticated),
+}
+
+/*
+NewHandler arma el http.Handler de la API con todas las rutas.
+
+Además de las rutas, sirve el documento OpenAPI en GET /openapi.json
+(público, igual que POST /auth/login). Las rutas que no existen
+devuelven 404 y los métodos no permitidos 405 (con el encabezado Allow),
+ambos con el cuerpo de error habitual.
+
+Concurrencia:
+- Los pedidos se atienden en paralelo, sin un candado propio: los
+  almacenamientos son seguros para uso concurrente y los casos de uso
+  que cambian varias entidades lo hacen dentro de una unidad de trabajo.
+  Así un login lento (la contraseña se verifica con PBKDF2) no demora al
+  resto de los clientes.
+- Dos checkouts simultáneos con la misma Idempotency-Key: el segundo
+  responde 409 (checkout_in_progress) mientras el primero sigue en curso.
+*/
+func NewHandler(d Deps) http.Handler {
+	mux := http.NewServeMux()
+	allowed := map[string][]string{} // ruta -> métodos permitidos
+	for _, rt := range routes {
+		allowed[rt.pattern] = append(allowed[rt.pattern], rt.method)
+		mux.HandleFunc(rt.method+" "+rt.pattern, func(w http.ResponseWriter, r *http.Request) {
+			r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+
+			r, err := authorize(d, r, rt.access)
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+
+			// Igual que en la CLI: las reservas vencidas se liberan antes de operar.
+			if _, err := usecase.ReleaseExpiredReservations(d.Cart.Reservations, d.Cart.Clock); err != nil {
+				writeError(w, err)
+				return
+			}
+
+			body, err := rt.serve(d, r)
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+			writeJSON(w, rt.status, body)
+		})
+	}
+
+	spec := OpenAPI()
+	mux.HandleFunc("GET /openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, spec)
+	})
+
+	// Sin método, el patrón solo recibe los métodos que no tienen ruta propia.
+	for pattern, methods := range allowed {
+		mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Allow", strings.Join(methods, ", "))
+			writeError(w, errMethodNotAllowed)
+		})
+	}
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, errRouteNotFound)
+	})
+	return mux
+}
+
+// writeJSON escribe body como JSON con el código indicado (sin cuerpo si body es nil).
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	if body == nil {
+		w.WriteHeader(status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(body)
+}
+
+/*
+decode lee el cuerpo JSON del pedido en dst.
+
+Rechaza campos desconocidos y cuerpos con más de un valor, para que un
+error de tipeo en el cliente no se ignore en silencio.
+*/
+func decode(r *http.Request, dst any) error {
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dst); err != nil {
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("%w: falta el cuerpo JSON", errBadRequest)
+		}
+		return fmt.Errorf("%w: JSON inválido: %w", errBadRequest, err)
+	}
+	if dec.More() {
+		return fmt.Errorf("%w: el cuerpo tiene más de un valor JSON", errBadRequest)
+	}
+	return nil
+}
+
+// decodeOptional es como decode, pero un cuerpo vacío deja dst sin cambios.
+func decodeOptional(r *http.Request, dst any) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return fmt.Errorf("%w: %w", errBadRequest, err)
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		return nil
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	return decode(r, dst)
+}
+
+// queryBool devuelve un parámetro booleano de la query string (false si no se indicó).
+func queryBool(r *http.Request, name string) (bool, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("%w: %s debe ser true o false: %q", errBadRequest, name, v)
+	}
+	return b, nil
+}
+
+// pathInt devuelve un parámetro numérico de la ruta ("/products/{id}").
+func pathInt(r *http.Request, name string) (int, error) {
+	n, err := strconv.Atoi(r.PathValue(name))
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s debe ser un número: %q", errBadRequest, name, r.PathValue(name))
+	}
+	return n, nil
+}
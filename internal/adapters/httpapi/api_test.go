@@ -0,0 +1,340 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/fakepay"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/storage"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+/*
+newTestAPI levanta la API sobre almacenamiento en memoria, armada igual
+que en cmd/server, con un admin y un usuario del cliente 1.
+
+change permite reemplazar dependencias antes de crear el handler (puede
+ser nil). Devuelve el servidor y las claves de API del admin y del cliente.
+*/
+func newTestAPI(t *testing.T, change func(d *Deps)) (srv *httptest.Server, adminKey, customerKey string) {
+	t.Helper()
+	store, err := storage.Open("memory", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	payments := fakepay.NewGateway(fakepay.Config{Mode: fakepay.Approve})
+	d := Deps{
+		Products:  store.Products,
+		Customers: store.Customers,
+		Cart: usecase.CartDeps{
+			UnitOfWork:   store.UnitOfWork,
+			Carts:        store.Carts,
+			Products:     store.Products,
+			Reservations: store.Reservations,
+		},
+		Checkout: usecase.CheckoutDeps{
+			UnitOfWork:   store.UnitOfWork,
+			Carts:        store.Carts,
+			Products:     store.Products,
+			Customers:    store.Customers,
+			Orders:       store.Orders,
+			Payments:     payments,
+			Reservations: store.Reservations,
+			Idempotency:  store.Idempotency,
+		},
+		Orders: usecase.OrderDeps{
+			UnitOfWork: store.UnitOfWork,
+			Orders:     store.Orders,
+			Payments:   payments,
+		},
+		Users: store.Users,
+	}
+
+	if err := store.Products.Create(domain.Product{ID: 1, Name: "Lápiz", Price: domain.NewMoney(1000, domain.DefaultCurrency), Stock: 5}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Customers.Create(domain.Customer{ID: 1, Name: "Ana", Email: "ana@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	userDeps := usecase.UserDeps{Users: store.Users, Customers: store.Customers}
+	for _, u := range []usecase.NewUserRequest{
+		{Username: "admin", Password: "clave-segura-1", Role: domain.RoleAdmin},
+		{Username: "ana", Password: "clave-segura-2", Role: domain.RoleCustomer, CustomerID: 1},
+	} {
+		if _, err := usecase.CreateUser(userDeps, u); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if adminKey, err = usecase.IssueAPIKey(store.Users, "admin"); err != nil {
+		t.Fatal(err)
+	}
+	if customerKey, err = usecase.IssueAPIKey(store.Users, "ana"); err != nil {
+		t.Fatal(err)
+	}
+
+	if change != nil {
+		change(&d)
+	}
+	srv = httptest.NewServer(NewHandler(d))
+	t.Cleanup(srv.Close)
+	return srv, adminKey, customerKey
+}
+
+// call hace un pedido a srv con la clave de API indicada (ninguna si está vacía).
+func call(t *testing.T, srv *httptest.Server, method, path, key, body string, header ...string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	return resp
+}
+
+// readError decodifica el cuerpo de error de resp, fallando si no tiene la forma de errorBody.
+func readError(t *testing.T, resp *http.Response) errorInfo {
+	t.Helper()
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Fatalf("Content-Type = %q, se esperaba JSON", ct)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var body struct {
+		Error struct {
+			Code    string          `json:"code"`
+			Message string          `json:"message"`
+			Details json.RawMessage `json:"details"`
+		} `json:"error"`
+	}
+	if err := dec.Decode(&body); err != nil {
+		t.Fatalf("el cuerpo de error no tiene la forma esperada: %v\n%s", err, data)
+	}
+	if body.Error.Code == "" || body.Error.Message == "" {
+		t.Fatalf("el cuerpo de error no tiene code o message: %s", data)
+	}
+	info := errorInfo{Code: body.Error.Code, Message: body.Error.Message}
+	if len(body.Error.Details) > 0 {
+		var details map[string]any
+		if err := json.Unmarshal(body.Error.Details, &details); err != nil {
+			t.Fatalf("details no es un objeto: %s", data)
+		}
+		info.Details = details
+	}
+	return info
+}
+
+// Cada tipo de error responde con su código HTTP y el cuerpo de error uniforme.
+func TestErrorResponses(t *testing.T) {
+	srv, adminKey, customerKey := newTestAPI(t, nil)
+
+	tests := []struct {
+		name         string
+		method, path string
+		key, body    string
+		status       int
+		code         string
+	}{
+		{"sin clave", "GET", "/me", "", "", http.StatusUnauthorized, "unauthenticated"},
+		{"clave inválida", "GET", "/me", "no-existe", "", http.StatusUnauthorized, "unauthenticated"},
+		{"contraseña incorrecta", "POST", "/auth/login", "", `{"username":"admin","password":"otra-clave-1"}`, http.StatusUnauthorized, "invalid_credentials"},
+		{"rol insuficiente", "GET", "/users", customerKey, "", http.StatusForbidden, "forbidden"},
+		{"otro cliente", "GET", "/customers/2/cart", customerKey, "", http.StatusForbidden, "forbidden"},
+		{"JSON inválido", "POST", "/products", adminKey, `{"id":`, http.StatusBadRequest, "bad_request"},
+		{"campo desconocido", "POST", "/products", adminKey, `{"id":2,"nombre":"x"}`, http.StatusBadRequest, "bad_request"},
+		{"ID no numérico", "GET", "/products/abc", adminKey, "", http.StatusBadRequest, "bad_request"},
+		{"datos inválidos", "POST", "/products", adminKey, `{"id":2,"name":"","price":"1.00","stock":1}`, http.StatusUnprocessableEntity, "invalid"},
+		{"producto inexistente", "GET", "/products/99", adminKey, "", http.StatusNotFound, "not_found"},
+		{"checkout de cliente inexistente", "POST", "/customers/99/checkout", adminKey, "", http.StatusNotFound, "not_found"},
+		{"carrito de cliente inexistente", "POST", "/customers/99/cart/items", adminKey, `{"product_id":1}`, http.StatusNotFound, "not_found"},
+		{"carrito vacío", "POST", "/customers/1/checkout", customerKey, "", http.StatusConflict, "empty_cart"},
+		{"ruta inexistente", "GET", "/nada", adminKey, "", http.StatusNotFound, "not_found"},
+		{"método no permitido", "DELETE", "/products", adminKey, "", http.StatusMethodNotAllowed, "method_not_allowed"},
+		{"cuerpo demasiado grande", "POST", "/products", adminKey, `{"name":"` + strings.Repeat("x", maxBodyBytes) + `"}`, http.StatusRequestEntityTooLarge, "too_large"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := call(t, srv, tt.method, tt.path, tt.key, tt.body)
+			if resp.StatusCode != tt.status {
+				t.Errorf("status = %d, se esperaba %d", resp.StatusCode, tt.status)
+			}
+			if info := readError(t, resp); info.Code != tt.code {
+				t.Errorf("code = %q, se esperaba %q (message: %s)", info.Code, tt.code, info.Message)
+			}
+			switch tt.status {
+			case http.StatusUnauthorized:
+				if resp.Header.Get("WWW-Authenticate") == "" {
+					t.Error("falta el encabezado WWW-Authenticate")
+				}
+			case http.StatusMethodNotAllowed:
+				if resp.Header.Get("Allow") == "" {
+					t.Error("falta el encabezado Allow")
+				}
+			}
+		})
+	}
+}
+
+// La falta de stock lleva en details lo que pidió el cliente y lo que puede agregar.
+func TestNoStockDetails(t *testing.T) {
+	srv, _, customerKey := newTestAPI(t, nil)
+
+	resp := call(t, srv, "POST", "/customers/1/cart/items", customerKey, `{"product_id":1,"quantity":8}`)
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("status = %d, se esperaba %d", resp.StatusCode, http.StatusConflict)
+	}
+	info := readError(t, resp)
+	if info.Code != "no_stock" {
+		t.Fatalf("code = %q, se esperaba no_stock", info.Code)
+	}
+	details, _ := info.Details.(map[string]any)
+	want := map[string]any{"product_id": 1.0, "requested": 8.0, "in_cart": 0.0, "available": 5.0, "can_add": 5.0}
+	for k, v := range want {
+		if details[k] != v {
+			t.Errorf("details[%q] = %v, se esperaba %v (details: %v)", k, details[k], v, details)
+		}
+	}
+}
+
+// blockingUsers detiene GetByUsername de "lento" hasta que se cierra release.
+type blockingUsers struct {
+	usecase.UserRepository
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (u blockingUsers) GetByUsername(username string) (domain.User, error) {
+	if username == "lento" {
+		close(u.entered)
+		<-u.release
+	}
+	return u.UserRepository.GetByUsername(username)
+}
+
+// Un login en curso no demora los pedidos de los demás clientes.
+func TestLoginDoesNotBlockOtherRequests(t *testing.T) {
+	users := blockingUsers{entered: make(chan struct{}), release: make(chan struct{})}
+	srv, adminKey, _ := newTestAPI(t, func(d *Deps) {
+		users.UserRepository = d.Users
+		d.Users = users
+	})
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	defer close(users.release)
+	wg.Go(func() {
+		resp, err := srv.Client().Post(srv.URL+"/auth/login", "application/json", strings.NewReader(`{"username":"lento","password":"clave-segura-3"}`))
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+	})
+	<-users.entered
+
+	done := make(chan int)
+	go func() {
+		req, _ := http.NewRequest("GET", srv.URL+"/products", nil)
+		req.Header.Set("Authorization", "Bearer "+adminKey)
+		resp, err := srv.Client().Do(req)
+		if err != nil {
+			done <- 0
+			return
+		}
+		_ = resp.Body.Close()
+		done <- resp.StatusCode
+	}()
+	select {
+	case status := <-done:
+		if status != http.StatusOK {
+			t.Errorf("GET /products: status = %d, se esperaba %d", status, http.StatusOK)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("GET /products quedó esperando a que termine el login")
+	}
+}
+
+// blockingPayments detiene la primera autorización hasta que se cierra release.
+type blockingPayments struct {
+	usecase.PaymentGateway
+	once    *sync.Once
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (p blockingPayments) Authorize(req usecase.PaymentRequest) (usecase.PaymentAuthorization, error) {
+	p.once.Do(func() {
+		close(p.entered)
+		<-p.release
+	})
+	return p.PaymentGateway.Authorize(req)
+}
+
+// Repetir un checkout con la misma clave mientras el primero sigue en curso responde 409.
+func TestCheckoutInProgress(t *testing.T) {
+	payments := blockingPayments{once: new(sync.Once), entered: make(chan struct{}), release: make(chan struct{})}
+	srv, _, customerKey := newTestAPI(t, func(d *Deps) {
+		payments.PaymentGateway = d.Checkout.Payments
+		d.Checkout.Payments = payments
+	})
+	if resp := call(t, srv, "POST", "/customers/1/cart/items", customerKey, `{"product_id":1}`); resp.StatusCode != http.StatusOK {
+		t.Fatalf("agregar al carrito: status = %d", resp.StatusCode)
+	}
+
+	first := make(chan int, 1)
+	go func() {
+		req, _ := http.NewRequest("POST", srv.URL+"/customers/1/checkout", nil)
+		req.Header.Set("Authorization", "Bearer "+customerKey)
+		req.Header.Set(idempotencyKeyHeader, "compra-1")
+		resp, err := srv.Client().Do(req)
+		if err != nil {
+			first <- 0
+			return
+		}
+		_ = resp.Body.Close()
+		first <- resp.StatusCode
+	}()
+	<-payments.entered
+	release := sync.OnceFunc(func() { close(payments.release) })
+	defer release()
+
+	req, _ := http.NewRequest("POST", srv.URL+"/customers/1/checkout", nil)
+	req.Header.Set("Authorization", "Bearer "+customerKey)
+	req.Header.Set(idempotencyKeyHeader, "compra-1")
+	client := *srv.Client()
+	client.Timeout = 2 * time.Second
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("la repetición quedó esperando al primer checkout: %v", err)
+	}
+	defer resp.Body.Close()
+	release()
+	if resp.StatusCode != http.StatusConflict {
+		t.Errorf("status = %d, se esperaba %d", resp.StatusCode, http.StatusConflict)
+	}
+	if info := readError(t, resp); info.Code != "checkout_in_progress" {
+		t.Errorf("code = %q, se esperaba checkout_in_progress", info.Code)
+	}
+	if status := <-first; status != http.StatusCreated {
+		t.Errorf("primer checkout: status = %d, se esperaba %d", status, http.StatusCreated)
+	}
+}
@@ -0,0 +1,175 @@
+package httpapi
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+/*
+access es quién puede usar una ruta.
+
+Las rutas piden una clave de API en el encabezado
+"Authorization: Bearer <clave>", salvo las públicas. El valor cero es
+accessStaff: una ruta nueva a la que se le olvide indicar el acceso
+queda restringida, no abierta.
+*/
+type access int
+
+const (
+	accessStaff         access = iota // admin o staff (domain.AuthorizeStaff)
+	accessAdmin                       // solo admin (domain.AuthorizeAdmin)
+	accessOwnCustomer                 // admin, staff o el cliente {id} de la ruta (domain.AuthorizeCustomer)
+	accessAuthenticated               // cualquier usuario; el handler decide qué puede ver
+	accessPublic                      // sin clave de API
+)
+
+// withAccess indica quién puede usar la ruta (por defecto, admin y staff).
+func (rt route) withAccess(a access) route {
+	rt.access = a
+	return rt
+}
+
+// userKey es la clave del usuario autenticado en el contexto del pedido.
+type userKey struct{}
+
+/*
+authorize autentica el pedido según el acceso de la ruta y devuelve el
+pedido con el usuario en su contexto (ver currentUser).
+
+Errores:
+- domain.ErrUnauthenticated (401) si falta la clave o no es válida.
+- domain.ErrForbidden (403) si el usuario no tiene permiso.
+*/
+func authorize(d Deps, r *http.Request, a access) (*http.Request, error) {
+	if a == accessPublic {
+		return r, nil
+	}
+	user, err := usecase.AuthenticateAPIKey(d.Users, bearerToken(r))
+	if err != nil {
+		return nil, err
+	}
+
+	switch a {
+	case accessStaff:
+		err = domain.AuthorizeStaff(user)
+	case accessAdmin:
+		err = domain.AuthorizeAdmin(user)
+	case accessOwnCustomer:
+		var customerID int
+		if customerID, err = pathInt(r, "id"); err == nil {
+			err = domain.AuthorizeCustomer(user, customerID)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r.WithContext(context.WithValue(r.Context(), userKey{}, user)), nil
+}
+
+// bearerToken devuelve la clave del encabezado "Authorization: Bearer <clave>" (vacío si no hay).
+func bearerToken(r *http.Request) string {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// currentUser devuelve el usuario autenticado del pedido (vacío en las rutas públicas).
+func currentUser(r *http.Request) domain.User {
+	u, _ := r.Context().Value(userKey{}).(domain.User)
+	return u
+}
+
+/*
+Cuerpos de los pedidos y respuestas de usuarios.
+
+La contraseña solo viaja en los pedidos; las claves de API solo en las
+respuestas de login y de emisión de clave (no se pueden volver a leer).
+*/
+type (
+	loginRequest struct {
+		Username string `json:"username"`
+		Password string `json:"password" format:"password"`
+	}
+
+	userRequest struct {
+		Username   string `json:"username"`
+		Password   string `json:"password" format:"password"`
+		Role       string `json:"role"`
+		CustomerID int    `json:"customer_id,omitempty"`
+	}
+
+	apiKeyView struct {
+		APIKey string `json:"api_key"`
+	}
+
+	loginView struct {
+		APIKey string   `json:"api_key"`
+		User   userView `json:"user"`
+	}
+)
+
+/*
+login responde POST /auth/login.
+
+Verifica usuario y contraseña y emite una clave de API nueva para usar
+en el encabezado Authorization. Cada usuario tiene una sola clave
+vigente: iniciar sesión invalida la anterior.
+*/
+func login(d Deps, r *http.Request, req loginRequest) (loginView, error) {
+	user, err := usecase.Login(d.Users, req.Username, req.Password)
+	if err != nil {
+		return loginView{}, err
+	}
+	key, err := usecase.IssueAPIKey(d.Users, user.Username)
+	if err != nil {
+		return loginView{}, err
+	}
+	return loginView{APIKey: key, User: newUserView(user)}, nil
+}
+
+// me responde GET /me: el usuario dueño de la clave de API.
+func me(d Deps, r *http.Request, _ none) (userView, error) {
+	return newUserView(currentUser(r)), nil
+}
+
+// listUsers responde GET /users.
+func listUsers(d Deps, r *http.Request, _ none) ([]userView, error) {
+	users, err := usecase.ListUsers(d.Users)
+	if err != nil {
+		return nil, err
+	}
+	views := make([]userView, 0, len(users))
+	for _, u := range users {
+		views = append(views, newUserView(u))
+	}
+	return views, nil
+}
+
+// createUser responde POST /users.
+func createUser(d Deps, r *http.Request, req userRequest) (userView, error) {
+	u, err := usecase.CreateUser(usecase.UserDeps{Users: d.Users, Customers: d.Customers}, usecase.NewUserRequest{
+		Username:   req.Username,
+		Password:   req.Password,
+		Role:       domain.Role(req.Role),
+		CustomerID: req.CustomerID,
+	})
+	if err != nil {
+		return userView{}, err
+	}
+	return newUserView(u), nil
+}
+
+// issueUserAPIKey responde POST /users/{username}/api-key; invalida la clave anterior del usuario.
+func issueUserAPIKey(d Deps, r *http.Request, _ none) (apiKeyView, error) {
+	key, err := usecase.IssueAPIKey(d.Users, r.PathValue("username"))
+	if err != nil {
+		return apiKeyView{}, err
+	}
+	return apiKeyView{APIKey: key}, nil
+}
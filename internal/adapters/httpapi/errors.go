@@ -0,0 +1,138 @@
+package httpapi
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/errclass"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+)
+
+// Errores propios de la API (no son reglas del negocio).
+var (
+	// errBadRequest indica un pedido mal formado: JSON inválido o parámetro no numérico.
+	errBadRequest = errors.New("pedido inválido")
+
+	// errRouteNotFound indica que la ruta no existe.
+	errRouteNotFound = errors.New("ruta inexistente")
+
+	// errMethodNotAllowed indica que la ruta existe pero no acepta ese método.
+	errMethodNotAllowed = errors.New("método no permitido")
+)
+
+/*
+errorStatuses asocia los errores propios de la API con su código HTTP y
+su código de error. Los errores de dominio se traducen según su
+clasificación (errclass.Classify y kindStatuses), la misma que usa la CLI
+para sus códigos de salida.
+*/
+var errorStatuses = []struct {
+	err    error
+	status int
+	code   string
+}{
+	{errBadRequest, http.StatusBadRequest, "bad_request"},
+	{errRouteNotFound, http.StatusNotFound, "not_found"},
+	{errMethodNotAllowed, http.StatusMethodNotAllowed, "method_not_allowed"},
+}
+
+// kindStatuses es el código HTTP de cada tipo de error de dominio.
+var kindStatuses = map[errclass.Kind]int{
+	errclass.Invalid:         http.StatusUnprocessableEntity,
+	errclass.NotFound:        http.StatusNotFound,
+	errclass.Conflict:        http.StatusConflict,
+	errclass.NoStock:         http.StatusConflict,
+	errclass.EmptyCart:       http.StatusConflict,
+	errclass.PaymentFailed:   http.StatusPaymentRequired,
+	errclass.PaymentTimeout:  http.StatusGatewayTimeout,
+	errclass.Unauthenticated: http.StatusUnauthorized,
+	errclass.Forbidden:       http.StatusForbidden,
+}
+
+/*
+Cuerpo de las respuestas de error. Siempre tiene la forma
+
+	{"error": {"code": "no_stock", "message": "...", "details": {...}}}
+
+details solo aparece en los errores que tienen datos extra para el
+cliente (falta de stock y cambios de precio).
+*/
+type (
+	errorBody struct {
+		Error errorInfo `json:"error"`
+	}
+
+	errorInfo struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		Details any    `json:"details,omitempty"`
+	}
+
+	stockDetails struct {
+		ProductID int `json:"product_id"`
+		Requested int `json:"requested"`
+		InCart    int `json:"in_cart"`
+		Available int `json:"available"`
+		CanAdd    int `json:"can_add"`
+	}
+
+	priceChangesDetails struct {
+		Changes []priceChangeView `json:"changes"`
+	}
+)
+
+// errorStatus devuelve el código HTTP y el código de error que corresponden a err.
+func errorStatus(err error) (int, string) {
+	for _, es := range errorStatuses {
+		if errors.Is(err, es.err) {
+			return es.status, es.code
+		}
+	}
+	if c := errclass.Classify(err); c.Kind != errclass.Unknown {
+		return kindStatuses[c.Kind], c.Code
+	}
+	return http.StatusInternalServerError, "internal"
+}
+
+/*
+writeError responde con el error en el formato de errorBody.
+
+Los errores inesperados (500) se registran en el log y al cliente solo
+le llega un mensaje genérico, para no exponer detalles internos.
+*/
+func writeError(w http.ResponseWriter, err error) {
+	status, code := errorStatus(err)
+	info := errorInfo{Code: code, Message: err.Error()}
+
+	var maxBytes *http.MaxBytesError
+	switch {
+	case errors.As(err, &maxBytes):
+		status, info.Code = http.StatusRequestEntityTooLarge, "too_large"
+		info.Message = fmt.Sprintf("el cuerpo supera los %d bytes", maxBytes.Limit)
+	case status == http.StatusInternalServerError:
+		log.Printf("httpapi: error inesperado: %v", err)
+		info.Message = "error interno del servidor"
+	case status == http.StatusUnauthorized:
+		// Indica al cliente cómo autenticarse (RFC 9110).
+		w.Header().Set("WWW-Authenticate", `Bearer realm="s-gestion-ecommerce"`)
+	}
+
+	var stockErr *domain.InsufficientStockError
+	if errors.As(err, &stockErr) {
+		info.Details = stockDetails{
+			ProductID: stockErr.ProductID,
+			Requested: stockErr.Requested,
+			InCart:    stockErr.InCart,
+			Available: stockErr.Available,
+			CanAdd:    stockErr.CanAdd(),
+		}
+	}
+	var priceErr *domain.PriceChangedError
+	if errors.As(err, &priceErr) {
+		info.Details = priceChangesDetails{Changes: newPriceChangeViews(priceErr.Changes)}
+	}
+
+	writeJSON(w, status, errorBody{Error: info})
+}
@@ -0,0 +1,338 @@
+package httpapi
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+/*
+Cuerpos de los pedidos.
+
+Los montos se reciben como texto ("12.50"), igual que se devuelven;
+currency es opcional y por defecto es domain.DefaultCurrency. Los campos
+con omitempty son opcionales (así figuran en el documento OpenAPI).
+*/
+type (
+	productRequest struct {
+		ID       int    `json:"id"`
+		Name     string `json:"name"`
+		Price    string `json:"price" format:"decimal"`
+		Currency string `json:"currency,omitempty"`
+		Stock    int    `json:"stock"`
+	}
+
+	priceRequest struct {
+		Price    string `json:"price" format:"decimal"`
+		Currency string `json:"currency,omitempty"`
+	}
+
+	customerRequest struct {
+		ID    int    `json:"id"`
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	}
+
+	// Los campos que no se indican conservan su valor.
+	customerUpdateRequest struct {
+		Name  string `json:"name,omitempty"`
+		Email string `json:"email,omitempty"`
+	}
+
+	// Quantity es un puntero para distinguir "no se indicó" (1) de 0 (inválido).
+	cartItemRequest struct {
+		ProductID int  `json:"product_id"`
+		Quantity  *int `json:"quantity,omitempty"`
+	}
+
+	quantityRequest struct {
+		Quantity int `json:"quantity"`
+	}
+
+	checkoutRequest struct {
+		PaymentCode string `json:"payment_code,omitempty"`
+	}
+)
+
+// bodyOptional permite hacer el checkout sin cuerpo (sin código de verificación).
+func (checkoutRequest) bodyOptional() {}
+
+// parseMoney convierte un monto recibido como texto en la moneda indicada (o la de por defecto).
+func parseMoney(amount, currency string) (domain.Money, error) {
+	if currency == "" {
+		currency = domain.DefaultCurrency
+	}
+	return domain.ParseMoney(amount, currency)
+}
+
+// listProducts responde GET /products.
+func listProducts(d Deps, r *http.Request, _ none) ([]productView, error) {
+	products, err := usecase.ListProducts(d.Products)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(products, func(a, b domain.Product) int { return cmp.Compare(a.ID, b.ID) })
+	views := make([]productView, 0, len(products))
+	for _, p := range products {
+		views = append(views, newProductView(p))
+	}
+	return views, nil
+}
+
+// createProduct responde POST /products.
+func createProduct(d Deps, r *http.Request, req productRequest) (productView, error) {
+	price, err := parseMoney(req.Price, req.Currency)
+	if err != nil {
+		return productView{}, err
+	}
+
+	p := domain.Product{ID: req.ID, Name: req.Name, Price: price, Stock: req.Stock}
+	if err := usecase.CreateProduct(d.Products, p); err != nil {
+		return productView{}, err
+	}
+	return newProductView(p), nil
+}
+
+// getProduct responde GET /products/{id}.
+func getProduct(d Deps, r *http.Request, _ none) (productView, error) {
+	id, err := pathInt(r, "id")
+	if err != nil {
+		return productView{}, err
+	}
+	p, err := usecase.GetProduct(d.Products, id)
+	if err != nil {
+		return productView{}, err
+	}
+	return newProductView(p), nil
+}
+
+// changeProductPrice responde PUT /products/{id}/price.
+func changeProductPrice(d Deps, r *http.Request, req priceRequest) (productView, error) {
+	id, err := pathInt(r, "id")
+	if err != nil {
+		return productView{}, err
+	}
+	price, err := parseMoney(req.Price, req.Currency)
+	if err != nil {
+		return productView{}, err
+	}
+
+	p, err := usecase.ChangeProductPrice(d.Products, id, price)
+	if err != nil {
+		return productView{}, err
+	}
+	return newProductView(p), nil
+}
+
+// listCustomers responde GET /customers; con ?q= solo los que coinciden por nombre o email.
+func listCustomers(d Deps, r *http.Request, _ none) ([]customerView, error) {
+	customers, err := usecase.SearchCustomers(d.Customers, r.URL.Query().Get("q"))
+	if err != nil {
+		return nil, err
+	}
+	views := make([]customerView, 0, len(customers))
+	for _, c := range customers {
+		views = append(views, newCustomerView(c))
+	}
+	return views, nil
+}
+
+// createCustomer responde POST /customers.
+func createCustomer(d Deps, r *http.Request, req customerRequest) (customerView, error) {
+	c := domain.Customer{ID: req.ID, Name: req.Name, Email: req.Email}
+	if err := usecase.CreateCustomer(d.Customers, c); err != nil {
+		return customerView{}, err
+	}
+	return newCustomerView(c), nil
+}
+
+// getCustomer responde GET /customers/{id}.
+func getCustomer(d Deps, r *http.Request, _ none) (customerView, error) {
+	id, err := pathInt(r, "id")
+	if err != nil {
+		return customerView{}, err
+	}
+	c, err := usecase.GetCustomer(d.Customers, id)
+	if err != nil {
+		return customerView{}, err
+	}
+	return newCustomerView(c), nil
+}
+
+// updateCustomer responde PATCH /customers/{id}.
+func updateCustomer(d Deps, r *http.Request, req customerUpdateRequest) (customerView, error) {
+	id, err := pathInt(r, "id")
+	if err != nil {
+		return customerView{}, err
+	}
+	c, err := usecase.UpdateCustomer(d.Customers, id, usecase.UpdateCustomerRequest{Name: req.Name, Email: req.Email})
+	if err != nil {
+		return customerView{}, err
+	}
+	return newCustomerView(c), nil
+}
+
+/*
+deleteCustomer responde DELETE /customers/{id}.
+
+Un cliente con pedidos solo se puede anonimizar, y hay que pedirlo
+explícitamente con ?anonymize=true; si no, responde 409.
+*/
+func deleteCustomer(d Deps, r *http.Request, _ none) (customerDeletionView, error) {
+	id, err := pathInt(r, "id")
+	if err != nil {
+		return customerDeletionView{}, err
+	}
+	anonymize, err := queryBool(r, "anonymize")
+	if err != nil {
+		return customerDeletionView{}, err
+	}
+
+	res, err := usecase.DeleteCustomer(usecase.CustomerDeps{UnitOfWork: d.Orders.UnitOfWork}, id, anonymize)
+	if err != nil {
+		return customerDeletionView{}, err
+	}
+	return customerDeletionView{res.CustomerID, res.Anonymized, res.DeletedUsers}, nil
+}
+
+// cartResponse arma la respuesta de las operaciones que devuelven el carrito.
+func cartResponse(cart domain.Cart, err error) (cartView, error) {
+	if err != nil {
+		return cartView{}, err
+	}
+	return newCartView(cart)
+}
+
+// getCart responde GET /customers/{id}/cart.
+func getCart(d Deps, r *http.Request, _ none) (cartView, error) {
+	customerID, err := pathInt(r, "id")
+	if err != nil {
+		return cartView{}, err
+	}
+	return cartResponse(usecase.ViewCart(d.Cart.Carts, customerID))
+}
+
+// clearCart responde DELETE /customers/{id}/cart.
+func clearCart(d Deps, r *http.Request, _ none) (none, error) {
+	customerID, err := pathInt(r, "id")
+	if err != nil {
+		return none{}, err
+	}
+	return none{}, usecase.ClearCart(d.Cart, customerID)
+}
+
+// addCartItem responde POST /customers/{id}/cart/items; por defecto agrega 1 unidad.
+func addCartItem(d Deps, r *http.Request, req cartItemRequest) (cartView, error) {
+	customerID, err := pathInt(r, "id")
+	if err != nil {
+		return cartView{}, err
+	}
+	quantity := 1
+	if req.Quantity != nil {
+		quantity = *req.Quantity
+	}
+	return cartResponse(usecase.AddProductToCart(d.Cart, customerID, req.ProductID, quantity))
+}
+
+// setCartItem responde PUT /customers/{id}/cart/items/{product_id}.
+func setCartItem(d Deps, r *http.Request, req quantityRequest) (cartView, error) {
+	customerID, err := pathInt(r, "id")
+	if err != nil {
+		return cartView{}, err
+	}
+	productID, err := pathInt(r, "product_id")
+	if err != nil {
+		return cartView{}, err
+	}
+	return cartResponse(usecase.SetCartItemQuantity(d.Cart, customerID, productID, req.Quantity))
+}
+
+// removeCartItem responde DELETE /customers/{id}/cart/items/{product_id}.
+func removeCartItem(d Deps, r *http.Request, _ none) (cartView, error) {
+	customerID, err := pathInt(r, "id")
+	if err != nil {
+		return cartView{}, err
+	}
+	productID, err := pathInt(r, "product_id")
+	if err != nil {
+		return cartView{}, err
+	}
+	return cartResponse(usecase.RemoveProductFromCart(d.Cart, customerID, productID))
+}
+
+// acceptCartPrices responde POST /customers/{id}/cart/accept-prices.
+func acceptCartPrices(d Deps, r *http.Request, _ none) (cartView, error) {
+	customerID, err := pathInt(r, "id")
+	if err != nil {
+		return cartView{}, err
+	}
+	return cartResponse(usecase.AcceptCartPrices(d.Cart, customerID))
+}
+
+// idempotencyKeyHeader es el encabezado con la clave de idempotencia del checkout.
+const idempotencyKeyHeader = "Idempotency-Key"
+
+/*
+checkout responde POST /customers/{id}/checkout.
+
+El cuerpo es opcional: solo hace falta para enviar el código de
+verificación cuando el primer intento respondió payment_action_required.
+Si cambiaron precios (price_changed), el cliente los acepta con
+accept-prices y vuelve a intentar.
+
+Con el encabezado Idempotency-Key, un reintento del mismo pedido (por
+ejemplo, tras un corte de red) devuelve la misma orden sin cobrar de
+nuevo (ver usecase.Checkout).
+*/
+func checkout(d Deps, r *http.Request, req checkoutRequest) (orderView, error) {
+	customerID, err := pathInt(r, "id")
+	if err != nil {
+		return orderView{}, err
+	}
+
+	order, err := usecase.Checkout(d.Checkout, usecase.CheckoutRequest{
+		CustomerID:     customerID,
+		PaymentCode:    req.PaymentCode,
+		IdempotencyKey: r.Header.Get(idempotencyKeyHeader),
+	})
+	if err != nil {
+		return orderView{}, err
+	}
+	return newOrderView(order), nil
+}
+
+// listCustomerOrders responde GET /customers/{id}/orders.
+func listCustomerOrders(d Deps, r *http.Request, _ none) ([]orderView, error) {
+	customerID, err := pathInt(r, "id")
+	if err != nil {
+		return nil, err
+	}
+	orders, err := usecase.ListOrdersByCustomer(d.Orders.Orders, customerID)
+	if err != nil {
+		return nil, err
+	}
+	views := make([]orderView, 0, len(orders))
+	for _, o := range orders {
+		views = append(views, newOrderView(o))
+	}
+	return views, nil
+}
+
+/*
+getOrder responde GET /orders/{order_id}; los IDs de pedido son texto.
+
+Un usuario con rol customer solo puede ver sus propios pedidos.
+*/
+func getOrder(d Deps, r *http.Request, _ none) (orderView, error) {
+	order, err := usecase.GetOrder(d.Orders.Orders, r.PathValue("order_id"))
+	if err != nil {
+		return orderView{}, err
+	}
+	if err := domain.AuthorizeCustomer(currentUser(r), order.CustomerID); err != nil {
+		return orderView{}, err
+	}
+	return newOrderView(order), nil
+}
@@ -0,0 +1,300 @@
+package httpapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/errclass"
+)
+
+/*
+OpenAPI devuelve el documento OpenAPI 3 de la API (se sirve en
+GET /openapi.json).
+
+Se genera a partir de la tabla de rutas:
+- Cada ruta aporta método, patrón, resumen, código de éxito y errores.
+- Los esquemas salen de los tipos del pedido y de la respuesta de cada
+  handler (ver endpoint), leyendo sus etiquetas json: los campos con
+  omitempty o punteros son opcionales, y la etiqueta format indica el
+  formato de los textos (montos "decimal", fechas "date-time").
+- Las respuestas de error se arman con errorStatuses y la clasificación
+  de los errores de dominio (errclass.Classes y kindStatuses), así cada
+  código de error documentado es uno que writeError produce.
+- Las rutas que no son públicas piden la clave de API (esquema
+  bearerAuth) y pueden responder 401; las que restringen por rol, 403.
+
+Como handlers y documento salen de la misma tabla, agregar o cambiar
+una ruta actualiza la especificación sin pasos manuales.
+*/
+func OpenAPI() map[string]any {
+	schemas := map[string]any{}
+	paths := map[string]any{}
+	usedErrors := map[int]bool{}
+
+	for _, rt := range routes {
+		op := map[string]any{
+			"operationId": rt.operationID,
+			"summary":     rt.summary,
+			"tags":        []string{routeTag(rt.pattern)},
+		}
+
+		params := []any{}
+		intParams := false
+		for _, name := range pathParams(rt.pattern) {
+			schema := map[string]any{"type": "integer"}
+			if textParams[name] {
+				schema = map[string]any{"type": "string"}
+			} else {
+				intParams = true
+			}
+			params = append(params, map[string]any{"name": name, "in": "path", "required": true, "schema": schema})
+		}
+		for _, h := range rt.headers {
+			params = append(params, map[string]any{"name": h.name, "in": "header", "description": h.description,
+				"schema": map[string]any{"type": "string"}})
+		}
+		for _, q := range rt.query {
+			params = append(params, map[string]any{"name": q.name, "in": "query", "description": q.description,
+				"schema": map[string]any{"type": q.typ}})
+		}
+		if len(params) > 0 {
+			op["parameters"] = params
+		}
+
+		if rt.request != nil {
+			op["requestBody"] = map[string]any{
+				"required": !rt.optional,
+				"content":  jsonContent(schemaRef(rt.request, schemas)),
+			}
+		}
+
+		success := map[string]any{"description": http.StatusText(rt.status)}
+		if rt.response != nil {
+			success["content"] = jsonContent(schemaRef(rt.response, schemas))
+		}
+		responses := map[string]any{fmt.Sprint(rt.status): success}
+
+		errs := slices.Clone(rt.errors)
+		if rt.request != nil || intParams {
+			errs = append(errs, http.StatusBadRequest)
+		}
+		switch rt.access {
+		case accessPublic:
+			op["security"] = []any{}
+		case accessAuthenticated:
+			op["security"] = bearerSecurity
+			errs = append(errs, http.StatusUnauthorized)
+		default:
+			op["security"] = bearerSecurity
+			errs = append(errs, http.StatusUnauthorized, http.StatusForbidden)
+		}
+		if rt.request != nil {
+			errs = append(errs, http.StatusRequestEntityTooLarge)
+		}
+		errs = append(errs, http.StatusInternalServerError)
+		for _, status := range errs {
+			usedErrors[status] = true
+			responses[fmt.Sprint(status)] = map[string]any{"$ref": "#/components/responses/" + responseName(status)}
+		}
+		op["responses"] = responses
+
+		path, _ := paths[rt.pattern].(map[string]any)
+		if path == nil {
+			path = map[string]any{}
+			paths[rt.pattern] = path
+		}
+		path[strings.ToLower(rt.method)] = op
+	}
+
+	// details es de tipo any: se documentan las formas que arma writeError.
+	errorRef := schemaRef(reflect.TypeFor[errorBody](), schemas)
+	schemas["ErrorInfo"].(map[string]any)["properties"].(map[string]any)["details"] = map[string]any{
+		"description": "StockDetails en no_stock, PriceChangesDetails en price_changed",
+		"oneOf": []any{
+			schemaRef(reflect.TypeFor[stockDetails](), schemas),
+			schemaRef(reflect.TypeFor[priceChangesDetails](), schemas),
+		},
+	}
+	responses := map[string]any{}
+	for status := range usedErrors {
+		responses[responseName(status)] = map[string]any{
+			"description": errorDescription(status),
+			"content":     jsonContent(errorRef),
+		}
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":       "s-gestion-ecommerce API",
+			"version":     "1.0.0",
+			"description": "API REST sobre los casos de uso del sistema de gestión de e-commerce.",
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas":   schemas,
+			"responses": responses,
+			"securitySchemes": map[string]any{
+				"bearerAuth": map[string]any{
+					"type":        "http",
+					"scheme":      "bearer",
+					"description": "Clave de API obtenida con POST /auth/login",
+				},
+			},
+		},
+	}
+}
+
+// textParams son los parámetros de ruta que no son números (IDs de pedido y nombres de usuario).
+var textParams = map[string]bool{"order_id": true, "username": true}
+
+// bearerSecurity es el requisito de seguridad de las rutas que piden clave de API.
+var bearerSecurity = []any{map[string]any{"bearerAuth": []string{}}}
+
+var pathParamRe = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+// pathParams devuelve los nombres de los parámetros de un patrón ("/products/{id}" -> ["id"]).
+func pathParams(pattern string) []string {
+	names := []string{}
+	for _, m := range pathParamRe.FindAllStringSubmatch(pattern, -1) {
+		names = append(names, m[1])
+	}
+	return names
+}
+
+// routeTag agrupa las rutas por recurso: productos, clientes, carrito, checkout o pedidos.
+func routeTag(pattern string) string {
+	for _, tag := range []string{"checkout", "orders", "cart"} {
+		if strings.Contains(pattern, "/"+tag) {
+			return tag
+		}
+	}
+	return strings.Split(strings.TrimPrefix(pattern, "/"), "/")[0]
+}
+
+// handlerName devuelve el nombre de la función handler (para operationId).
+func handlerName(fn any) string {
+	name := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()).Name()
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// jsonContent arma el contenido application/json de un pedido o respuesta.
+func jsonContent(schema any) map[string]any {
+	return map[string]any{"application/json": map[string]any{"schema": schema}}
+}
+
+// responseName es el nombre de la respuesta de error en components ("NotFound").
+func responseName(status int) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(http.StatusText(status))
+}
+
+/*
+errorDescription describe qué códigos de error (y por qué errores de
+dominio) puede traer una respuesta con ese código HTTP.
+*/
+func errorDescription(status int) string {
+	var lines []string
+	switch status {
+	case http.StatusRequestEntityTooLarge:
+		lines = append(lines, fmt.Sprintf("too_large: el cuerpo supera los %d bytes", maxBodyBytes))
+	case http.StatusInternalServerError:
+		lines = append(lines, "internal: error inesperado (el detalle queda en el log del servidor)")
+	}
+	for _, es := range errorStatuses {
+		if es.status == status {
+			lines = append(lines, es.code+": "+es.err.Error())
+		}
+	}
+	for _, c := range errclass.Classes() {
+		if kindStatuses[c.Kind] == status {
+			lines = append(lines, c.Code+": "+c.Err.Error())
+		}
+	}
+	return http.StatusText(status) + ". Códigos de error posibles:\n\n- " + strings.Join(lines, "\n- ")
+}
+
+/*
+schemaRef devuelve el esquema de t; los structs se registran en schemas
+con su nombre (ver schemaName) y se devuelven como $ref.
+*/
+func schemaRef(t reflect.Type, schemas map[string]any) map[string]any {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaRef(t.Elem(), schemas)
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": schemaRef(t.Elem(), schemas)}
+	case reflect.Int, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Interface:
+		return map[string]any{}
+	case reflect.Struct:
+		name := schemaName(t)
+		if _, done := schemas[name]; !done {
+			schemas[name] = nil // evita recursión infinita con tipos que se referencian a sí mismos
+			schemas[name] = structSchema(t, schemas)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	}
+	panic(fmt.Sprintf("httpapi: tipo sin esquema OpenAPI: %s", t))
+}
+
+// structSchema arma el esquema de un struct a partir de sus etiquetas json y format.
+func structSchema(t reflect.Type, schemas map[string]any) map[string]any {
+	props := map[string]any{}
+	required := []string{}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		prop := schemaRef(f.Type, schemas)
+		if format := f.Tag.Get("format"); format != "" {
+			prop["format"] = format
+		}
+		props[name] = prop
+
+		if !strings.Contains(opts, "omitempty") && f.Type.Kind() != reflect.Pointer {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]any{"type": "object", "properties": props}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	if strings.HasSuffix(t.Name(), "Request") {
+		// decode rechaza los campos desconocidos.
+		schema["additionalProperties"] = false
+	}
+	return schema
+}
+
+/*
+schemaName es el nombre público de un tipo en el documento:
+productView -> Product, productRequest -> ProductInput,
+errorBody -> ErrorBody.
+*/
+func schemaName(t reflect.Type) string {
+	name := t.Name()
+	if base, ok := strings.CutSuffix(name, "View"); ok {
+		name = base
+	} else if base, ok := strings.CutSuffix(name, "Request"); ok {
+		name = base + "Input"
+	}
+	if name == "" {
+		panic(errors.New("httpapi: los cuerpos de pedido y respuesta deben ser tipos con nombre"))
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
@@ -0,0 +1,299 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// getJSON decodifica el cuerpo de resp (con json.Number para los números).
+func getJSON(t *testing.T, resp *http.Response) any {
+	t.Helper()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		t.Fatalf("el cuerpo no es JSON: %v\n%s", err, data)
+	}
+	return v
+}
+
+// servedSpec devuelve el documento de GET /openapi.json tal como lo recibe un cliente.
+func servedSpec(t *testing.T, srv *httptest.Server) map[string]any {
+	t.Helper()
+	resp := call(t, srv, "GET", "/openapi.json", "", "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET /openapi.json: status = %d", resp.StatusCode)
+	}
+	spec, _ := getJSON(t, resp).(map[string]any)
+	return spec
+}
+
+// operation devuelve la operación de method en pattern, o nil si el documento no la tiene.
+func operation(spec map[string]any, method, pattern string) map[string]any {
+	paths, _ := spec["paths"].(map[string]any)
+	path, _ := paths[pattern].(map[string]any)
+	op, _ := path[strings.ToLower(method)].(map[string]any)
+	return op
+}
+
+/*
+resolve sigue un $ref local ("#/components/schemas/Product") y devuelve
+el objeto al que apunta, o nil si no existe.
+*/
+func resolve(spec map[string]any, ref string) map[string]any {
+	var cur any = spec
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		m, _ := cur.(map[string]any)
+		cur = m[part]
+	}
+	m, _ := cur.(map[string]any)
+	return m
+}
+
+/*
+validate compara v con el esquema y devuelve las diferencias, con la
+ruta JSON donde aparecen. Cubre lo que genera OpenAPI: $ref, oneOf,
+object (propiedades obligatorias y desconocidas), array, integer,
+string y boolean.
+*/
+func validate(spec, schema map[string]any, v any, at string) []string {
+	if ref, ok := schema["$ref"].(string); ok {
+		target := resolve(spec, ref)
+		if target == nil {
+			return []string{fmt.Sprintf("%s: $ref %s no existe", at, ref)}
+		}
+		return validate(spec, target, v, at)
+	}
+	if oneOf, ok := schema["oneOf"].([]any); ok {
+		for _, s := range oneOf {
+			if sub, _ := s.(map[string]any); len(validate(spec, sub, v, at)) == 0 {
+				return nil
+			}
+		}
+		return []string{fmt.Sprintf("%s: no coincide con ninguno de oneOf: %v", at, v)}
+	}
+
+	switch schema["type"] {
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s: se esperaba un objeto, llegó %v", at, v)}
+		}
+		props, _ := schema["properties"].(map[string]any)
+		var problems []string
+		for _, name := range anySlice(schema["required"]) {
+			if _, ok := obj[name.(string)]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: falta la propiedad obligatoria %q", at, name))
+			}
+		}
+		for name, value := range obj {
+			prop, ok := props[name].(map[string]any)
+			if !ok {
+				problems = append(problems, fmt.Sprintf("%s: la propiedad %q no está documentada", at, name))
+				continue
+			}
+			problems = append(problems, validate(spec, prop, value, at+"."+name)...)
+		}
+		return problems
+	case "array":
+		list, ok := v.([]any)
+		if !ok {
+			return []string{fmt.Sprintf("%s: se esperaba un array, llegó %v", at, v)}
+		}
+		items, _ := schema["items"].(map[string]any)
+		var problems []string
+		for i, item := range list {
+			problems = append(problems, validate(spec, items, item, fmt.Sprintf("%s[%d]", at, i))...)
+		}
+		return problems
+	case "integer":
+		if n, ok := v.(json.Number); !ok || strings.ContainsAny(n.String(), ".eE") {
+			return []string{fmt.Sprintf("%s: se esperaba un entero, llegó %v", at, v)}
+		}
+	case "string":
+		if _, ok := v.(string); !ok {
+			return []string{fmt.Sprintf("%s: se esperaba un texto, llegó %v", at, v)}
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return []string{fmt.Sprintf("%s: se esperaba un booleano, llegó %v", at, v)}
+		}
+	}
+	return nil
+}
+
+// anySlice devuelve v como []any (vacío si no es un array).
+func anySlice(v any) []any {
+	s, _ := v.([]any)
+	return s
+}
+
+// El documento tiene exactamente las rutas de la tabla, y todas sus referencias existen.
+func TestOpenAPIMatchesRoutes(t *testing.T) {
+	srv, _, _ := newTestAPI(t, nil)
+	spec := servedSpec(t, srv)
+
+	want := 0
+	for _, rt := range routes {
+		want++
+		op := operation(spec, rt.method, rt.pattern)
+		if op == nil {
+			t.Errorf("%s %s no está en el documento", rt.method, rt.pattern)
+			continue
+		}
+		if op["operationId"] != rt.operationID {
+			t.Errorf("%s %s: operationId = %v, se esperaba %s", rt.method, rt.pattern, op["operationId"], rt.operationID)
+		}
+		responses, _ := op["responses"].(map[string]any)
+		for _, status := range append([]int{rt.status}, rt.errors...) {
+			if _, ok := responses[fmt.Sprint(status)]; !ok {
+				t.Errorf("%s %s: falta la respuesta %d", rt.method, rt.pattern, status)
+			}
+		}
+		_, hasBody := op["requestBody"]
+		if hasBody != (rt.request != nil) {
+			t.Errorf("%s %s: requestBody documentado = %v, la ruta recibe cuerpo = %v", rt.method, rt.pattern, hasBody, rt.request != nil)
+		}
+		for _, name := range pathParams(rt.pattern) {
+			if !slices.ContainsFunc(anySlice(op["parameters"]), func(p any) bool {
+				m, _ := p.(map[string]any)
+				return m["name"] == name && m["in"] == "path"
+			}) {
+				t.Errorf("%s %s: falta el parámetro de ruta %q", rt.method, rt.pattern, name)
+			}
+		}
+	}
+
+	got := 0
+	paths, _ := spec["paths"].(map[string]any)
+	for pattern, path := range paths {
+		for method := range path.(map[string]any) {
+			got++
+			if !slices.ContainsFunc(routes, func(rt route) bool {
+				return rt.pattern == pattern && strings.EqualFold(rt.method, method)
+			}) {
+				t.Errorf("el documento tiene %s %s, que no es una ruta", strings.ToUpper(method), pattern)
+			}
+		}
+	}
+	if got != want {
+		t.Errorf("el documento tiene %d operaciones, hay %d rutas", got, want)
+	}
+
+	// Toda referencia del documento apunta a algo que existe.
+	var walk func(v any, at string)
+	walk = func(v any, at string) {
+		switch v := v.(type) {
+		case map[string]any:
+			if ref, ok := v["$ref"].(string); ok && resolve(spec, ref) == nil {
+				t.Errorf("%s: $ref %s no existe", at, ref)
+			}
+			for k, child := range v {
+				walk(child, at+"/"+k)
+			}
+		case []any:
+			for i, child := range v {
+				walk(child, fmt.Sprintf("%s[%d]", at, i))
+			}
+		}
+	}
+	walk(spec, "#")
+}
+
+/*
+Recorre todas las rutas contra un servidor real: cada una responde un
+código documentado para esa operación, con un cuerpo que cumple el
+esquema documentado para ese código.
+
+Las rutas se llaman en el orden de la tabla, con el cuerpo de ejemplo
+de requestExamples; todas deben responder su código de éxito, salvo las
+de expectedStatus.
+*/
+func TestOpenAPIDescribesResponses(t *testing.T) {
+	srv, adminKey, customerKey := newTestAPI(t, nil)
+	spec := servedSpec(t, srv)
+
+	// Un pedido previo, para las rutas de pedidos.
+	if resp := call(t, srv, "POST", "/customers/1/cart/items", customerKey, `{"product_id":1}`); resp.StatusCode != http.StatusOK {
+		t.Fatalf("agregar al carrito: status = %d", resp.StatusCode)
+	}
+	resp := call(t, srv, "POST", "/customers/1/checkout", customerKey, "")
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("checkout: status = %d", resp.StatusCode)
+	}
+	order, _ := getJSON(t, resp).(map[string]any)
+
+	pathValues := map[string]string{
+		"id":         "1",
+		"product_id": "2",
+		"order_id":   fmt.Sprint(order["id"]),
+		"username":   "ana",
+	}
+	requestExamples := map[string]string{
+		"login":              `{"username":"ana","password":"clave-segura-2"}`,
+		"createUser":         `{"username":"beto","password":"clave-segura-4","role":"staff"}`,
+		"createProduct":      `{"id":2,"name":"Goma","price":"5.00","stock":3}`,
+		"changeProductPrice": `{"price":"12.00"}`,
+		"createCustomer":     `{"id":2,"name":"Beto","email":"beto@example.com"}`,
+		"updateCustomer":     `{"name":"Ana María"}`,
+		"addCartItem":        `{"product_id":1,"quantity":2}`,
+		"setCartItem":        `{"quantity":3}`,
+	}
+	expectedStatus := map[string]int{
+		"deleteCustomer": http.StatusConflict, // el cliente 1 tiene pedidos
+	}
+
+	for _, rt := range routes {
+		op := operation(spec, rt.method, rt.pattern)
+		if op == nil {
+			t.Errorf("%s %s no está en el documento", rt.method, rt.pattern)
+			continue
+		}
+		path := pathParamRe.ReplaceAllStringFunc(rt.pattern, func(m string) string {
+			return pathValues[m[1:len(m)-1]]
+		})
+
+		resp := call(t, srv, rt.method, path, adminKey, requestExamples[rt.operationID])
+		name := rt.method + " " + path
+		want := rt.status
+		if status, ok := expectedStatus[rt.operationID]; ok {
+			want = status
+		}
+		if resp.StatusCode != want {
+			t.Errorf("%s: status = %d, se esperaba %d", name, resp.StatusCode, want)
+		}
+
+		responses, _ := op["responses"].(map[string]any)
+		documented, ok := responses[fmt.Sprint(resp.StatusCode)].(map[string]any)
+		if !ok {
+			t.Errorf("%s: respondió %d, que no está documentado", name, resp.StatusCode)
+			continue
+		}
+		if ref, ok := documented["$ref"].(string); ok {
+			documented = resolve(spec, ref)
+		}
+		content, _ := documented["content"].(map[string]any)
+		media, _ := content["application/json"].(map[string]any)
+		schema, _ := media["schema"].(map[string]any)
+		if schema == nil {
+			if resp.ContentLength > 0 {
+				t.Errorf("%s: respondió con cuerpo, pero el documento dice que %d no tiene", name, resp.StatusCode)
+			}
+			continue
+		}
+		for _, problem := range validate(spec, schema, getJSON(t, resp), "$") {
+			t.Errorf("%s (%d): %s", name, resp.StatusCode, problem)
+		}
+	}
+}
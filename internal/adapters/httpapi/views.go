@@ -0,0 +1,173 @@
+package httpapi
+
+import (
+	"time"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+/*
+Vistas de las entidades en las respuestas.
+
+Usan los mismos nombres de campos que la salida json de la CLI; son
+parte del contrato con los clientes de la API: no se renombran.
+*/
+type (
+	productView struct {
+		ID       int    `json:"id"`
+		Name     string `json:"name"`
+		Price    string `json:"price" format:"decimal"`
+		Currency string `json:"currency"`
+		Stock    int    `json:"stock"`
+	}
+
+	customerView struct {
+		ID    int    `json:"id"`
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	}
+
+	// Anonymized es true si el cliente tenía pedidos y se anonimizó en lugar de borrarse.
+	customerDeletionView struct {
+		CustomerID   int      `json:"customer_id"`
+		Anonymized   bool     `json:"anonymized"`
+		DeletedUsers []string `json:"deleted_users"`
+	}
+
+	lineView struct {
+		ProductID int    `json:"product_id"`
+		Name      string `json:"name"`
+		UnitPrice string `json:"unit_price" format:"decimal"`
+		Quantity  int    `json:"quantity"`
+		Subtotal  string `json:"subtotal" format:"decimal"`
+		Currency  string `json:"currency"`
+	}
+
+	cartView struct {
+		CustomerID int        `json:"customer_id"`
+		Items      []lineView `json:"items"`
+		Total      string     `json:"total" format:"decimal"`
+		Currency   string     `json:"currency"`
+	}
+
+	statusChangeView struct {
+		Status string `json:"status"`
+		At     string `json:"at" format:"date-time"`
+	}
+
+	orderView struct {
+		ID           string             `json:"id"`
+		CreatedAt    string             `json:"created_at" format:"date-time"`
+		CustomerID   int                `json:"customer_id"`
+		CustomerName string             `json:"customer_name"`
+		Status       string             `json:"status"`
+		Items        []lineView         `json:"items"`
+		Total        string             `json:"total" format:"decimal"`
+		Refunded     string             `json:"refunded" format:"decimal"`
+		Currency     string             `json:"currency"`
+		PaymentID    string             `json:"payment_id"`
+		CancelReason string             `json:"cancel_reason"`
+		History      []statusChangeView `json:"history"`
+	}
+
+	priceChangeView struct {
+		ProductID int    `json:"product_id"`
+		Name      string `json:"name"`
+		OldPrice  string `json:"old_price" format:"decimal"`
+		NewPrice  string `json:"new_price" format:"decimal"`
+		Currency  string `json:"currency"`
+		Quantity  int    `json:"quantity"`
+	}
+
+	// Los hashes de contraseña y clave de API nunca salen en las respuestas.
+	userView struct {
+		Username   string `json:"username"`
+		Role       string `json:"role"`
+		CustomerID int    `json:"customer_id,omitempty"`
+	}
+)
+
+// formatTime escribe las fechas en RFC 3339 (vacío si no hay fecha).
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// newProductView arma la vista de un producto.
+func newProductView(p domain.Product) productView {
+	return productView{p.ID, p.Name, domain.FormatAmount(p.Price), p.Price.Currency, p.Stock}
+}
+
+// newCustomerView arma la vista de un cliente.
+func newCustomerView(c domain.Customer) customerView {
+	return customerView{c.ID, c.Name, c.Email}
+}
+
+// newUserView arma la vista de un usuario (sin sus hashes).
+func newUserView(u domain.User) userView {
+	return userView{u.Username, string(u.Role), u.CustomerID}
+}
+
+/*
+newCartView arma la vista de un carrito con su total.
+
+Devuelve domain.ErrCurrencyMismatch si el carrito mezclara monedas.
+*/
+func newCartView(cart domain.Cart) (cartView, error) {
+	total, err := domain.Total(cart)
+	if err != nil {
+		return cartView{}, err
+	}
+	items := make([]lineView, 0, len(cart.Items))
+	for _, it := range cart.Items {
+		items = append(items, lineView{
+			it.ProductID, it.Name, domain.FormatAmount(it.Price), it.Quantity,
+			domain.FormatAmount(domain.LineTotal(it)), it.Price.Currency,
+		})
+	}
+	return cartView{cart.CustomerID, items, domain.FormatAmount(total), total.Currency}, nil
+}
+
+// newOrderView arma la vista de un pedido con sus líneas e historial.
+func newOrderView(o usecase.Order) orderView {
+	items := make([]lineView, 0, len(o.Items))
+	for _, it := range o.Items {
+		items = append(items, lineView{
+			it.ProductID, it.Name, domain.FormatAmount(it.UnitPrice), it.Quantity,
+			domain.FormatAmount(it.LineTotal), it.UnitPrice.Currency,
+		})
+	}
+	history := make([]statusChangeView, 0, len(o.History))
+	for _, ch := range o.History {
+		history = append(history, statusChangeView{string(ch.To), formatTime(ch.At)})
+	}
+	return orderView{
+		ID:           o.ID,
+		CreatedAt:    formatTime(o.CreatedAt),
+		CustomerID:   o.CustomerID,
+		CustomerName: o.CustomerName,
+		Status:       string(o.Status),
+		Items:        items,
+		Total:        domain.FormatAmount(o.Total),
+		Refunded:     domain.FormatAmount(o.Refunded),
+		Currency:     o.Total.Currency,
+		PaymentID:    o.PaymentID,
+		CancelReason: o.CancelReason,
+		History:      history,
+	}
+}
+
+// newPriceChangeViews arma las vistas de los cambios de precio de un carrito.
+func newPriceChangeViews(changes []domain.PriceChange) []priceChangeView {
+	views := make([]priceChangeView, 0, len(changes))
+	for _, ch := range changes {
+		views = append(views, priceChangeView{
+			ch.ProductID, ch.Name, domain.FormatAmount(ch.OldPrice), domain.FormatAmount(ch.NewPrice),
+			ch.NewPrice.Currency, ch.Quantity,
+		})
+	}
+	return views
+}
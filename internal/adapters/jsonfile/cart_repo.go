@@ -0,0 +1,47 @@
+package jsonfile
+
+import (
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/memory"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+/*
+CartRepo es un repositorio de carritos guardado en un archivo JSON
+(carts.json dentro del directorio de datos).
+
+Implementa usecase.CartRepository: un cliente puede cerrar el programa
+y encontrar su carrito al volver.
+*/
+type CartRepo struct {
+	*memory.CartRepo
+	s *Store
+}
+
+// NewCartRepo devuelve el repositorio de carritos del Store.
+func NewCartRepo(s *Store) *CartRepo {
+	return &CartRepo{CartRepo: s.mem.Carts, s: s}
+}
+
+// Save guarda el carrito del cliente y reescribe el archivo.
+func (r *CartRepo) Save(cart domain.Cart) error {
+	return r.s.write(func(tx usecase.Repositories) error { return tx.Carts.Save(cart) })
+}
+
+// Clear vacía el carrito del cliente y reescribe el archivo.
+func (r *CartRepo) Clear(customerID int) error {
+	return r.s.write(func(tx usecase.Repositories) error { return tx.Carts.Clear(customerID) })
+}
+
+// loadCarts carga carts.json en memoria, con las versiones guardadas.
+func (s *Store) loadCarts() error {
+	return loadAll(s, "carts.json", func(c domain.Cart) error {
+		s.mem.Carts.Load(c)
+		return nil
+	})
+}
+
+// encodeCarts devuelve el documento con todos los carritos (ya ordenados por cliente).
+func (s *Store) encodeCarts() ([]byte, error) {
+	return encode(s.mem.Carts.All())
+}
@@ -0,0 +1,85 @@
+package jsonfile_test
+
+import (
+	"testing"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/jsonfile"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase/repotest"
+)
+
+// Los repositorios JSON cumplen el mismo contrato que los de memoria.
+func TestContract(t *testing.T) {
+	suites := map[string]func() error{
+		"Products": func() error {
+			return repotest.Products(func() (repotest.ProductRepository, error) {
+				s, err := openStore(t)
+				if err != nil {
+					return nil, err
+				}
+				return jsonfile.NewProductRepo(s), nil
+			})
+		},
+		"Customers": func() error {
+			return repotest.Customers(func() (repotest.CustomerRepository, error) {
+				s, err := openStore(t)
+				if err != nil {
+					return nil, err
+				}
+				return jsonfile.NewCustomerRepo(s), nil
+			})
+		},
+		"Carts": func() error {
+			return repotest.Carts(func() (usecase.CartRepository, error) {
+				s, err := openStore(t)
+				if err != nil {
+					return nil, err
+				}
+				return jsonfile.NewCartRepo(s), nil
+			})
+		},
+		"Reservations": func() error {
+			return repotest.Reservations(func() (usecase.ReservationRepository, error) {
+				s, err := openStore(t)
+				if err != nil {
+					return nil, err
+				}
+				return jsonfile.NewReservationRepo(s), nil
+			})
+		},
+		"Orders": func() error {
+			return repotest.Orders(func() (usecase.OrderRepository, error) {
+				s, err := openStore(t)
+				if err != nil {
+					return nil, err
+				}
+				return jsonfile.NewOrderRepo(s), nil
+			})
+		},
+		"Returns": func() error {
+			return repotest.Returns(func() (usecase.ReturnRepository, usecase.OrderRepository, error) {
+				s, err := openStore(t)
+				if err != nil {
+					return nil, nil, err
+				}
+				return jsonfile.NewReturnRepo(s), jsonfile.NewOrderRepo(s), nil
+			})
+		},
+		"Users": func() error {
+			return repotest.Users(func() (usecase.UserRepository, error) {
+				s, err := openStore(t)
+				if err != nil {
+					return nil, err
+				}
+				return jsonfile.NewUserRepo(s), nil
+			})
+		},
+	}
+	for name, run := range suites {
+		t.Run(name, func(t *testing.T) {
+			if err := run(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package jsonfile
+
+import (
+	"slices"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/memory"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+/*
+CustomerRepo es un repositorio de clientes guardado en un archivo JSON
+(customers.json dentro del directorio de datos).
+
+Implementa CustomerRepository, CustomerRepositoryForCheckout y
+CustomerRepositoryForDelete, igual que memory.CustomerRepo.
+*/
+type CustomerRepo struct {
+	*memory.CustomerRepo
+	s *Store
+}
+
+// NewCustomerRepo devuelve el repositorio de clientes del Store.
+func NewCustomerRepo(s *Store) *CustomerRepo {
+	return &CustomerRepo{CustomerRepo: s.mem.Customers, s: s}
+}
+
+// Create guarda un cliente nuevo (mismas reglas que memory.CustomerRepo).
+func (r *CustomerRepo) Create(c domain.Customer) error {
+	return r.s.write(func(tx usecase.Repositories) error { return tx.Customers.Create(c) })
+}
+
+// Update reemplaza un cliente (mismas reglas que memory.CustomerRepo) y reescribe el archivo.
+func (r *CustomerRepo) Update(c domain.Customer) error {
+	return r.s.write(func(tx usecase.Repositories) error { return tx.Customers.Update(c) })
+}
+
+// Delete borra un cliente (mismas reglas que memory.CustomerRepo) y reescribe el archivo.
+func (r *CustomerRepo) Delete(id int) error {
+	return r.s.write(func(tx usecase.Repositories) error { return tx.Customers.Delete(id) })
+}
+
+// loadCustomers carga customers.json en memoria.
+func (s *Store) loadCustomers() error {
+	return loadAll(s, "customers.json", s.mem.Customers.Create)
+}
+
+// encodeCustomers devuelve el documento con todos los clientes, ordenados por ID.
+func (s *Store) encodeCustomers() ([]byte, error) {
+	items, err := s.mem.Customers.List()
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(items, func(a, b domain.Customer) int { return a.ID - b.ID })
+	return encode(items)
+}
@@ -0,0 +1,43 @@
+package jsonfile
+
+import (
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/memory"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+/*
+OrderRepo es un repositorio de pedidos guardado en un archivo JSON
+(orders.json dentro del directorio de datos).
+
+Implementa usecase.OrderRepository. Las consultas (por cliente,
+por rango de fechas) las resuelve el repositorio en memoria embebido.
+*/
+type OrderRepo struct {
+	*memory.OrderRepo
+	s *Store
+}
+
+// NewOrderRepo devuelve el repositorio de pedidos del Store.
+func NewOrderRepo(s *Store) *OrderRepo {
+	return &OrderRepo{OrderRepo: s.mem.Orders, s: s}
+}
+
+// Create guarda un pedido nuevo (mismas reglas que memory.OrderRepo).
+func (r *OrderRepo) Create(o usecase.Order) error {
+	return r.s.write(func(tx usecase.Repositories) error { return tx.Orders.Create(o) })
+}
+
+// Update reemplaza un pedido existente (mismas reglas que memory.OrderRepo).
+func (r *OrderRepo) Update(o usecase.Order) error {
+	return r.s.write(func(tx usecase.Repositories) error { return tx.Orders.Update(o) })
+}
+
+// loadOrders carga orders.json en memoria.
+func (s *Store) loadOrders() error {
+	return loadAll(s, "orders.json", s.mem.Orders.Create)
+}
+
+// encodeOrders devuelve el documento con todos los pedidos (ya ordenados por fecha).
+func (s *Store) encodeOrders() ([]byte, error) {
+	return encode(s.mem.Orders.All())
+}
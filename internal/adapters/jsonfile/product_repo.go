@@ -0,0 +1,59 @@
+package jsonfile
+
+import (
+	"slices"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/memory"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+/*
+ProductRepo es un repositorio de productos guardado en un archivo JSON
+(products.json dentro del directorio de datos).
+
+Responsabilidad:
+- Implementar las mismas interfaces que memory.ProductRepo:
+  - ProductRepository (crear, listar)
+  - ProductRepositoryForCart (buscar por ID, actualizar)
+- Conservar los productos entre ejecuciones del programa.
+
+Funcionamiento:
+- Las lecturas se resuelven con el repositorio en memoria embebido.
+- Cada escritura actualiza la memoria y reescribe el archivo completo
+  (ver Store).
+*/
+type ProductRepo struct {
+	*memory.ProductRepo
+	s *Store
+}
+
+// NewProductRepo devuelve el repositorio de productos del Store.
+func NewProductRepo(s *Store) *ProductRepo {
+	return &ProductRepo{ProductRepo: s.mem.Products, s: s}
+}
+
+// Create guarda un producto nuevo (mismas reglas que memory.ProductRepo).
+func (r *ProductRepo) Create(p domain.Product) error {
+	return r.s.write(func(tx usecase.Repositories) error { return tx.Products.Create(p) })
+}
+
+// Update reemplaza un producto existente (mismas reglas que memory.ProductRepo).
+func (r *ProductRepo) Update(p domain.Product) error {
+	return r.s.write(func(tx usecase.Repositories) error { return tx.Products.Update(p) })
+}
+
+// loadProducts carga products.json en memoria.
+func (s *Store) loadProducts() error {
+	return loadAll(s, "products.json", s.mem.Products.Create)
+}
+
+// encodeProducts devuelve el documento con todos los productos, ordenados por ID para que el archivo sea estable.
+func (s *Store) encodeProducts() ([]byte, error) {
+	items, err := s.mem.Products.List()
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(items, func(a, b domain.Product) int { return a.ID - b.ID })
+	return encode(items)
+}
@@ -0,0 +1,42 @@
+package jsonfile
+
+import (
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/memory"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+/*
+ReturnRepo es un repositorio de devoluciones guardado en un archivo JSON
+(returns.json dentro del directorio de datos).
+
+Implementa usecase.ReturnRepository.
+*/
+type ReturnRepo struct {
+	*memory.ReturnRepo
+	s *Store
+}
+
+// NewReturnRepo devuelve el repositorio de devoluciones del Store.
+func NewReturnRepo(s *Store) *ReturnRepo {
+	return &ReturnRepo{ReturnRepo: s.mem.Returns, s: s}
+}
+
+// Create guarda una devolución nueva (mismas reglas que memory.ReturnRepo).
+func (r *ReturnRepo) Create(rma usecase.ReturnRequest) error {
+	return r.s.write(func(tx usecase.Repositories) error { return tx.Returns.Create(rma) })
+}
+
+// Update reemplaza una devolución existente (mismas reglas que memory.ReturnRepo).
+func (r *ReturnRepo) Update(rma usecase.ReturnRequest) error {
+	return r.s.write(func(tx usecase.Repositories) error { return tx.Returns.Update(rma) })
+}
+
+// loadReturns carga returns.json en memoria.
+func (s *Store) loadReturns() error {
+	return loadAll(s, "returns.json", s.mem.Returns.Create)
+}
+
+// encodeReturns devuelve el documento con todas las devoluciones (ya ordenadas por fecha).
+func (s *Store) encodeReturns() ([]byte, error) {
+	return encode(s.mem.Returns.All())
+}
@@ -0,0 +1,394 @@
+package jsonfile
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/dirlock"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/memory"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+/*
+SchemaVersion es la versión del formato de los archivos que escribe este paquete.
+
+Se guarda en cada archivo ("version"). Si el formato cambia en el futuro,
+se incrementa este número y el código de carga decide cómo migrar
+los archivos de versiones anteriores.
+*/
+const SchemaVersion = 1
+
+// ErrUnsupportedSchema indica un archivo escrito con una versión de esquema desconocida.
+var ErrUnsupportedSchema = errors.New("versión de esquema de datos no soportada")
+
+/*
+document es el contenido de cada archivo JSON:
+
+	{"version": 1, "items": [...]}
+*/
+type document[T any] struct {
+	Version int `json:"version"`
+	Items   []T `json:"items"`
+}
+
+/*
+load lee los ítems guardados en path.
+
+Comportamiento:
+- Si el archivo no existe, devuelve un slice vacío (primer uso).
+- Si el archivo tiene una versión mayor a SchemaVersion, devuelve
+  ErrUnsupportedSchema para no pisar datos de una versión más nueva.
+*/
+func load[T any](path string) ([]T, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var doc document[T]
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if doc.Version < 1 || doc.Version > SchemaVersion {
+		return nil, fmt.Errorf("%s: versión %d: %w", path, doc.Version, ErrUnsupportedSchema)
+	}
+	return doc.Items, nil
+}
+
+// encode devuelve el documento JSON (compacto) con items.
+func encode[T any](items []T) ([]byte, error) {
+	if items == nil {
+		items = []T{}
+	}
+	return json.Marshal(document[T]{Version: SchemaVersion, Items: items})
+}
+
+/*
+writeFile escribe data en path de forma atómica.
+
+Pasos:
+1) Escribir el contenido completo en un archivo temporal del mismo directorio.
+2) Forzar la escritura a disco (Sync).
+3) Renombrar el temporal sobre el archivo final.
+
+El rename es atómico dentro del mismo sistema de archivos: quien lea
+el archivo ve la versión anterior completa o la nueva completa, nunca
+una escritura a medias (por ejemplo si el proceso se corta).
+*/
+func writeFile(path string, data []byte) (err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// Si algo falló, el temporal no debe quedar en el directorio.
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// journalFile es el archivo donde se anota un commit antes de aplicarlo (ver Store).
+const journalFile = "journal.json"
+
+/*
+journal es el contenido de journalFile: el documento nuevo de cada
+archivo que cambia en un commit.
+
+	{"version": 1, "files": {"products.json": {"version": 1, "items": [...]}, ...}}
+*/
+type journal struct {
+	Version int                        `json:"version"`
+	Files   map[string]json.RawMessage `json:"files"`
+}
+
+/*
+Store es el directorio de datos de este paquete.
+
+Responsabilidad:
+- Cargar todos los archivos en repositorios en memoria al abrirlo.
+- Guardar en disco cada cambio confirmado.
+
+Funcionamiento:
+- Las lecturas se resuelven en memoria.
+- Todas las escrituras pasan por la misma memory.UnitOfWork (las sueltas,
+  como una unidad de trabajo de una sola operación): se aplican en
+  memoria y, al confirmarse, se reescriben los archivos de los
+  repositorios que cambiaron.
+- Si falla la escritura de un archivo, la memoria se deshace y los
+  archivos ya reescritos se vuelven a guardar con el estado anterior.
+
+Atomicidad ante cortes (el proceso muere o se corta la luz):
+- Cada archivo se reescribe de forma atómica (ver writeFile), pero un
+  commit puede cambiar varios. Por eso, antes de tocarlos, el commit
+  escribe todos los documentos nuevos juntos en journal.json (también
+  de forma atómica), y lo borra al terminar.
+- Si Open encuentra un journal.json, el commit anterior se cortó a
+  mitad de camino: vuelve a escribir sus archivos y recién después
+  carga los datos. Así se ve el commit completo o nada de él.
+
+Las reservas de stock quedan solo en memoria: duran minutos y no tiene
+sentido conservarlas entre ejecuciones.
+
+Un solo proceso:
+- La copia en memoria se carga al abrir y cada archivo se reescribe
+  completo a partir de ella, así que dos procesos sobre el mismo
+  directorio se pisarían los cambios. Open reserva el directorio (ver
+  dirlock) y falla con dirlock.ErrLocked si otro proceso ya lo tiene.
+- Las versiones (domain.ErrConcurrentModification) protegen entre
+  goroutines de este proceso. Para compartir los datos entre varios
+  procesos hay que usar sqlstore: storage.Open lo indica en el error.
+*/
+type Store struct {
+	dir  string
+	lock *dirlock.Lock
+	mem  memory.Repos
+	uow  *memory.UnitOfWork
+
+	// journaled son los repositorios del commit en curso, una vez
+	// escrito journal.json (rollback debe volver a guardarlos).
+	journaled []any
+}
+
+/*
+Open abre (o crea) el directorio de datos dir, lo reserva para este
+proceso y carga sus archivos.
+*/
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	lock, err := dirlock.Acquire(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Store{
+		dir:  dir,
+		lock: lock,
+		mem: memory.Repos{
+			Products:     memory.NewProductRepo(),
+			Customers:    memory.NewCustomerRepo(),
+			Carts:        memory.NewCartRepo(),
+			Reservations: memory.NewReservationRepo(),
+			Orders:       memory.NewOrderRepo(),
+			Returns:      memory.NewReturnRepo(),
+			Users:        memory.NewUserRepo(),
+		},
+	}
+	for _, load := range []func() error{
+		s.finishJournal, s.loadProducts, s.loadCustomers, s.loadCarts, s.loadOrders, s.loadReturns, s.loadUsers,
+	} {
+		if err := load(); err != nil {
+			_ = lock.Release()
+			return nil, err
+		}
+	}
+	s.uow = memory.NewUnitOfWork(s.mem, memory.Hooks{Commit: s.commit, Rollback: s.rollback})
+	return s, nil
+}
+
+// Close libera el directorio de datos (los cambios ya están guardados).
+func (s *Store) Close() error {
+	return s.lock.Release()
+}
+
+// NewUnitOfWork devuelve la unidad de trabajo del Store (la misma que usan sus repositorios).
+func NewUnitOfWork(s *Store) usecase.UnitOfWork {
+	return s.uow
+}
+
+// NewReservationRepo devuelve el repositorio de reservas del Store (solo en memoria).
+func NewReservationRepo(s *Store) *memory.ReservationRepo {
+	return s.mem.Reservations
+}
+
+/*
+commit guarda los archivos de los repositorios que cambiaron.
+
+Primero los anota juntos en journal.json y después reescribe cada uno:
+si el proceso se corta en el medio, Open completa el commit.
+*/
+func (s *Store) commit(changed []any) error {
+	j := journal{Version: SchemaVersion, Files: make(map[string]json.RawMessage)}
+	for _, repo := range changed {
+		name, data, err := s.encode(repo)
+		if err != nil {
+			return err
+		}
+		if name != "" {
+			j.Files[name] = data
+		}
+	}
+	if len(j.Files) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(j)
+	if err != nil {
+		return err
+	}
+	if err := writeFile(s.path(journalFile), data); err != nil {
+		return err
+	}
+	s.journaled = changed
+	if err := s.apply(j); err != nil {
+		return err
+	}
+	s.journaled = nil
+
+	// Si no se puede borrar, no pasa nada: repetirlo al abrir escribe
+	// lo mismo que ya tienen los archivos (el próximo commit lo reemplaza).
+	_ = os.Remove(s.path(journalFile))
+	return nil
+}
+
+/*
+rollback deshace en disco un commit que falló después de escribir
+journal.json (la memoria ya volvió al estado anterior): borra el
+journal, para que Open no lo complete, y vuelve a guardar los archivos
+con el estado anterior.
+
+Nota:
+- Si esas escrituras también fallan, el archivo queda con el cambio
+  deshecho; se corrige con la próxima escritura exitosa. Si lo que
+  falla es borrar el journal, al abrir de nuevo se completa el commit.
+*/
+func (s *Store) rollback() {
+	if s.journaled == nil {
+		return
+	}
+	_ = os.Remove(s.path(journalFile))
+	for _, repo := range s.journaled {
+		if name, data, err := s.encode(repo); err == nil && name != "" {
+			_ = writeFile(s.path(name), indent(data))
+		}
+	}
+	s.journaled = nil
+}
+
+// apply escribe cada archivo del journal.
+func (s *Store) apply(j journal) error {
+	for name, data := range j.Files {
+		if err := writeFile(s.path(name), indent(data)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/*
+finishJournal completa el commit que quedó a medias si hay un journal.json
+(ver Store). Sin journal no hace nada.
+*/
+func (s *Store) finishJournal() error {
+	data, err := os.ReadFile(s.path(journalFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var j journal
+	if err := json.Unmarshal(data, &j); err != nil {
+		return fmt.Errorf("%s: %w", journalFile, err)
+	}
+	if j.Version < 1 || j.Version > SchemaVersion {
+		return fmt.Errorf("%s: versión %d: %w", journalFile, j.Version, ErrUnsupportedSchema)
+	}
+	for name := range j.Files {
+		if !slices.Contains(dataFiles, name) {
+			return fmt.Errorf("%s: archivo desconocido %q", journalFile, name)
+		}
+	}
+	if err := s.apply(j); err != nil {
+		return err
+	}
+	return os.Remove(s.path(journalFile))
+}
+
+// dataFiles son los archivos que puede nombrar un journal.
+var dataFiles = []string{"products.json", "customers.json", "carts.json", "orders.json", "returns.json", "users.json"}
+
+// indent da formato legible a un documento (y agrega el salto de línea final).
+func indent(data []byte) []byte {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, data, "", "  "); err != nil {
+		return data
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+/*
+encode devuelve el archivo de un repositorio en memoria y su contenido
+(las reservas no tienen archivo: name vacío).
+*/
+func (s *Store) encode(repo any) (name string, data []byte, err error) {
+	switch repo {
+	case s.mem.Products:
+		data, err = s.encodeProducts()
+		return "products.json", data, err
+	case s.mem.Customers:
+		data, err = s.encodeCustomers()
+		return "customers.json", data, err
+	case s.mem.Carts:
+		data, err = s.encodeCarts()
+		return "carts.json", data, err
+	case s.mem.Orders:
+		data, err = s.encodeOrders()
+		return "orders.json", data, err
+	case s.mem.Returns:
+		data, err = s.encodeReturns()
+		return "returns.json", data, err
+	case s.mem.Users:
+		data, err = s.encodeUsers()
+		return "users.json", data, err
+	}
+	return "", nil, nil
+}
+
+// write ejecuta una escritura suelta como una unidad de trabajo de una sola operación.
+func (s *Store) write(fn func(tx usecase.Repositories) error) error {
+	return s.uow.Do(fn)
+}
+
+// path devuelve la ruta del archivo name dentro del directorio de datos.
+func (s *Store) path(name string) string {
+	return filepath.Join(s.dir, name)
+}
+
+// loadAll lee los ítems del archivo name y los agrega uno por uno con add.
+func loadAll[T any](s *Store, name string, add func(T) error) error {
+	items, err := load[T](s.path(name))
+	if err != nil {
+		return err
+	}
+	for _, it := range items {
+		if err := add(it); err != nil {
+			return err
+		}
+	}
+	return nil
+}
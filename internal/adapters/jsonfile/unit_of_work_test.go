@@ -0,0 +1,163 @@
+package jsonfile_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/jsonfile"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase/repotest"
+)
+
+// openStore abre un Store nuevo en un directorio temporal (se cierra al terminar el test).
+func openStore(t *testing.T) (*jsonfile.Store, error) {
+	s, err := jsonfile.Open(t.TempDir())
+	if err != nil {
+		return nil, err
+	}
+	t.Cleanup(func() { _ = s.Close() })
+	return s, nil
+}
+
+// repos devuelve los repositorios de s.
+func repos(s *jsonfile.Store) usecase.Repositories {
+	return usecase.Repositories{
+		Products:     jsonfile.NewProductRepo(s),
+		Customers:    jsonfile.NewCustomerRepo(s),
+		Carts:        jsonfile.NewCartRepo(s),
+		Reservations: jsonfile.NewReservationRepo(s),
+		Orders:       jsonfile.NewOrderRepo(s),
+		Returns:      jsonfile.NewReturnRepo(s),
+		Users:        jsonfile.NewUserRepo(s),
+	}
+}
+
+// Ejecutar con -race: incluye checkouts simultáneos.
+func TestUnitOfWork(t *testing.T) {
+	err := repotest.UnitOfWork(func() (usecase.Repositories, usecase.UnitOfWork, error) {
+		s, err := openStore(t)
+		if err != nil {
+			return usecase.Repositories{}, nil, err
+		}
+		return repos(s), jsonfile.NewUnitOfWork(s), nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+// Lo confirmado por una unidad de trabajo sobrevive a reabrir el directorio; lo deshecho no.
+func TestUnitOfWorkPersists(t *testing.T) {
+	dir := t.TempDir()
+	s, err := jsonfile.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uow := jsonfile.NewUnitOfWork(s)
+
+	price := domain.NewMoney(1000, domain.DefaultCurrency)
+	err = uow.Do(func(tx usecase.Repositories) error {
+		if err := tx.Products.Create(domain.Product{ID: 1, Name: "Lápiz", Price: price, Stock: 5}); err != nil {
+			return err
+		}
+		return tx.Customers.Create(domain.Customer{ID: 1, Name: "Ana", Email: "ana@example.com"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	errAbort := errors.New("abortada")
+	err = uow.Do(func(tx usecase.Repositories) error {
+		if err := tx.Products.Create(domain.Product{ID: 2, Name: "Cuaderno", Price: price, Stock: 3}); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("Do devolvió %v, se esperaba %v", err, errAbort)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := jsonfile.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	products, err := jsonfile.NewProductRepo(reopened).List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(products) != 1 || products[0].ID != 1 {
+		t.Errorf("productos después de reabrir = %+v, se esperaba solo el 1", products)
+	}
+	if _, err := jsonfile.NewCustomerRepo(reopened).GetByID(1); err != nil {
+		t.Errorf("GetByID del cliente después de reabrir: %v", err)
+	}
+}
+
+/*
+Un commit cortado a mitad de camino (quedó journal.json, pero solo se
+reescribió uno de sus archivos) se completa al abrir: se ven los dos
+cambios, no uno solo.
+*/
+func TestOpenFinishesInterruptedCommit(t *testing.T) {
+	dir := t.TempDir()
+	s, err := jsonfile.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	price := domain.NewMoney(1000, domain.DefaultCurrency)
+	if err := jsonfile.NewProductRepo(s).Create(domain.Product{ID: 1, Name: "Lápiz", Price: price, Stock: 5}); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// El commit cortado: stock 3 y un cliente nuevo; products.json ya se
+	// reescribió, customers.json todavía no.
+	products := `{"version":1,"items":[{"ID":1,"Name":"Lápiz","Price":{"Amount":1000,"Currency":"USD"},"Stock":3,"Version":2}]}`
+	customers := `{"version":1,"items":[{"ID":1,"Name":"Ana","Email":"ana@example.com"}]}`
+	if err := os.WriteFile(filepath.Join(dir, "products.json"), []byte(products), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	journal := `{"version":1,"files":{"products.json":` + products + `,"customers.json":` + customers + `}}`
+	if err := os.WriteFile(filepath.Join(dir, "journal.json"), []byte(journal), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := jsonfile.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	if p, err := jsonfile.NewProductRepo(reopened).GetByID(1); err != nil || p.Stock != 3 {
+		t.Errorf("Stock = %d (%v), se esperaba 3", p.Stock, err)
+	}
+	if _, err := jsonfile.NewCustomerRepo(reopened).GetByID(1); err != nil {
+		t.Errorf("el cliente del commit cortado no está: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "journal.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("journal.json sigue en el directorio (%v)", err)
+	}
+}
+
+// Un commit terminado no deja journal.json: al abrir no hay nada que completar.
+func TestCommitRemovesJournal(t *testing.T) {
+	dir := t.TempDir()
+	s, err := jsonfile.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if err := jsonfile.NewCustomerRepo(s).Create(domain.Customer{ID: 1, Name: "Ana", Email: "ana@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "journal.json")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("journal.json quedó en el directorio (%v)", err)
+	}
+}
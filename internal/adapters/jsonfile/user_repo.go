@@ -0,0 +1,58 @@
+package jsonfile
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/memory"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+/*
+UserRepo es un repositorio de usuarios guardado en un archivo JSON
+(users.json dentro del directorio de datos).
+
+El archivo tiene los hashes de contraseñas y claves de API, nunca los
+secretos en claro; aun así se crea con los mismos permisos que el resto
+de los datos, así que conviene proteger el directorio.
+*/
+type UserRepo struct {
+	*memory.UserRepo
+	s *Store
+}
+
+// NewUserRepo devuelve el repositorio de usuarios del Store.
+func NewUserRepo(s *Store) *UserRepo {
+	return &UserRepo{UserRepo: s.mem.Users, s: s}
+}
+
+// Create guarda un usuario nuevo (mismas reglas que memory.UserRepo).
+func (r *UserRepo) Create(u domain.User) error {
+	return r.s.write(func(tx usecase.Repositories) error { return tx.Users.Create(u) })
+}
+
+// Update reemplaza un usuario (mismas reglas que memory.UserRepo) y reescribe el archivo.
+func (r *UserRepo) Update(u domain.User) error {
+	return r.s.write(func(tx usecase.Repositories) error { return tx.Users.Update(u) })
+}
+
+// Delete borra un usuario (mismas reglas que memory.UserRepo) y reescribe el archivo.
+func (r *UserRepo) Delete(username string) error {
+	return r.s.write(func(tx usecase.Repositories) error { return tx.Users.Delete(username) })
+}
+
+// loadUsers carga users.json en memoria.
+func (s *Store) loadUsers() error {
+	return loadAll(s, "users.json", s.mem.Users.Create)
+}
+
+// encodeUsers devuelve el documento con todos los usuarios, ordenados por nombre.
+func (s *Store) encodeUsers() ([]byte, error) {
+	items, err := s.mem.Users.List()
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(items, func(a, b domain.User) int { return cmp.Compare(a.Username, b.Username) })
+	return encode(items)
+}
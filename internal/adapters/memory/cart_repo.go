@@ -1,6 +1,12 @@
 package memory
 
-import "github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+import (
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+)
 
 /*
 CartRepo es un repositorio en memoria para carritos.
@@ -13,6 +19,12 @@ Responsabilidad:
 Este repositorio implementa la interfaz usecase.CartRepository.
 */
 type CartRepo struct {
+	// mu protege el mapa: el repositorio es seguro para uso concurrente.
+	mu sync.RWMutex
+
+	// writer lo toma cada escritura, y una UnitOfWork mientras dura.
+	writer sync.Mutex
+
 	// Mapa que asocia un customerID con su carrito.
 	// Key: ID del cliente
 	// Value: domain.Cart (estado actual del carrito)
@@ -41,16 +53,19 @@ Comportamiento importante:
 
 Este método NO crea efectos secundarios (solo lectura).
 */
-func (r *CartRepo) Get(customerID int) domain.Cart {
+func (r *CartRepo) Get(customerID int) (domain.Cart, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	cart, ok := r.byCustomerID[customerID]
 	if !ok {
 		// Si no existe carrito previo, se devuelve uno nuevo y vacío.
 		return domain.Cart{
 			CustomerID: customerID,
 			Items:      []domain.CartItem{},
-		}
+		}, nil
 	}
-	return cart
+	return cart, nil
 }
 
 /*
@@ -63,9 +78,30 @@ Se utiliza después de:
 
 Este método sobreescribe el carrito anterior del cliente.
 No valida reglas: asume que el carrito ya fue validado en usecase/domain.
+
+Control de concurrencia:
+- cart.Version debe coincidir con la versión guardada (0 si el cliente
+  todavía no tiene carrito); si no, otro proceso lo modificó después de
+  leerlo y se devuelve domain.ErrConcurrentModification.
+- Al guardar, la versión se incrementa.
 */
-func (r *CartRepo) Save(cart domain.Cart) {
+func (r *CartRepo) Save(cart domain.Cart) error {
+	r.writer.Lock()
+	defer r.writer.Unlock()
+	return r.save(cart)
+}
+
+// save es Save sin tomar writer.
+func (r *CartRepo) save(cart domain.Cart) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.byCustomerID[cart.CustomerID].Version != cart.Version {
+		return domain.ErrConcurrentModification
+	}
+	cart.Version++
 	r.byCustomerID[cart.CustomerID] = cart
+	return nil
 }
 
 /*
@@ -77,10 +113,74 @@ Implementación:
 
 Esto mantiene un estado consistente y evita tener que
 manejar "carrito inexistente" en otros métodos.
+
+Vaciar no compara versiones (no depende de lo que se leyó antes),
+pero sí incrementa la versión: un Save posterior basado en el
+carrito anterior falla con domain.ErrConcurrentModification.
 */
-func (r *CartRepo) Clear(customerID int) {
+func (r *CartRepo) Clear(customerID int) error {
+	r.writer.Lock()
+	defer r.writer.Unlock()
+	return r.clear(customerID)
+}
+
+// clear es Clear sin tomar writer.
+func (r *CartRepo) clear(customerID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	r.byCustomerID[customerID] = domain.Cart{
 		CustomerID: customerID,
 		Items:      []domain.CartItem{},
+		Version:    r.byCustomerID[customerID].Version + 1,
 	}
+	return nil
+}
+
+/*
+Load guarda un carrito tal cual, sin comparar ni incrementar la versión.
+
+No forma parte de usecase.CartRepository: lo usan los adaptadores que
+reconstruyen el estado guardado (por ejemplo jsonfile al arrancar).
+*/
+func (r *CartRepo) Load(cart domain.Cart) {
+	r.writer.Lock()
+	defer r.writer.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.byCustomerID[cart.CustomerID] = cart
+}
+
+/*
+All devuelve todos los carritos guardados, ordenados por cliente.
+
+No forma parte de usecase.CartRepository: lo usan otros adaptadores
+(por ejemplo jsonfile) que necesitan recorrer el repositorio completo.
+*/
+func (r *CartRepo) All() []domain.Cart {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := slices.Collect(maps.Values(r.byCustomerID))
+	slices.SortFunc(out, func(a, b domain.Cart) int {
+		return a.CustomerID - b.CustomerID
+	})
+	return out
+}
+
+// cartTx es CartRepo dentro de una UnitOfWork (ver Repos.view).
+type cartTx struct {
+	*CartRepo
+	undo *undoLog
+}
+
+// Save es CartRepo.Save, anotando cómo deshacerlo.
+func (t cartTx) Save(cart domain.Cart) error {
+	return track(t.undo, t.CartRepo, &t.mu, t.byCustomerID, cart.CustomerID, func() error { return t.save(cart) })
+}
+
+// Clear es CartRepo.Clear, anotando cómo deshacerlo.
+func (t cartTx) Clear(customerID int) error {
+	return track(t.undo, t.CartRepo, &t.mu, t.byCustomerID, customerID, func() error { return t.clear(customerID) })
 }
@@ -0,0 +1,57 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/memory"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase/repotest"
+)
+
+// Los repositorios en memoria cumplen el contrato de repotest.
+func TestContract(t *testing.T) {
+	suites := map[string]func() error{
+		"Products": func() error {
+			return repotest.Products(func() (repotest.ProductRepository, error) {
+				return memory.NewProductRepo(), nil
+			})
+		},
+		"Customers": func() error {
+			return repotest.Customers(func() (repotest.CustomerRepository, error) {
+				return memory.NewCustomerRepo(), nil
+			})
+		},
+		"Carts": func() error {
+			return repotest.Carts(func() (usecase.CartRepository, error) {
+				return memory.NewCartRepo(), nil
+			})
+		},
+		"Reservations": func() error {
+			return repotest.Reservations(func() (usecase.ReservationRepository, error) {
+				return memory.NewReservationRepo(), nil
+			})
+		},
+		"Orders": func() error {
+			return repotest.Orders(func() (usecase.OrderRepository, error) {
+				return memory.NewOrderRepo(), nil
+			})
+		},
+		"Returns": func() error {
+			return repotest.Returns(func() (usecase.ReturnRepository, usecase.OrderRepository, error) {
+				return memory.NewReturnRepo(), memory.NewOrderRepo(), nil
+			})
+		},
+		"Users": func() error {
+			return repotest.Users(func() (usecase.UserRepository, error) {
+				return memory.NewUserRepo(), nil
+			})
+		},
+	}
+	for name, run := range suites {
+		t.Run(name, func(t *testing.T) {
+			if err := run(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
@@ -1,6 +1,10 @@
 package memory
 
-import "github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+import (
+	"sync"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+)
 
 /*
 CustomerRepo es un repositorio en memoria para clientes.
@@ -14,6 +18,12 @@ Este repositorio NO contiene lógica de negocio.
 Solo guarda, recupera y lista datos.
 */
 type CustomerRepo struct {
+	// mu protege el mapa: el repositorio es seguro para uso concurrente.
+	mu sync.RWMutex
+
+	// writer lo toma cada escritura, y una UnitOfWork mientras dura.
+	writer sync.Mutex
+
 	// byID almacena los clientes usando su ID como clave.
 	// Ejemplo: byID[10] = Customer{ID:10, Name:"Juan", Email:"..."}
 	byID map[int]domain.Customer
@@ -42,6 +52,16 @@ Reglas:
 Devuelve error si el ID ya existe.
 */
 func (r *CustomerRepo) Create(c domain.Customer) error {
+	r.writer.Lock()
+	defer r.writer.Unlock()
+	return r.create(c)
+}
+
+// create es Create sin tomar writer.
+func (r *CustomerRepo) create(c domain.Customer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	if _, exists := r.byID[c.ID]; exists {
 		return domain.ErrInvalidCustomerID
 	}
@@ -55,12 +75,15 @@ List devuelve todos los clientes registrados.
 Se retorna un slice para evitar exponer
 la estructura interna del mapa.
 */
-func (r *CustomerRepo) List() []domain.Customer {
+func (r *CustomerRepo) List() ([]domain.Customer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	out := make([]domain.Customer, 0, len(r.byID))
 	for _, c := range r.byID {
 		out = append(out, c)
 	}
-	return out
+	return out, nil
 }
 
 /*
@@ -73,9 +96,84 @@ Este método es CLAVE para el checkout:
 Devuelve error si el cliente no existe.
 */
 func (r *CustomerRepo) GetByID(id int) (domain.Customer, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	c, exists := r.byID[id]
 	if !exists {
 		return domain.Customer{}, domain.ErrInvalidCustomerID
 	}
 	return c, nil
 }
+
+/*
+Update reemplaza los datos de un cliente existente.
+
+Comportamiento (compare-and-swap por versión, igual que ProductRepo):
+- Si el cliente no existe, retorna domain.ErrInvalidCustomerID.
+- Si c.Version no coincide con la guardada, retorna
+  domain.ErrConcurrentModification y no cambia nada.
+- Si coincide, reemplaza el cliente e incrementa la versión.
+*/
+func (r *CustomerRepo) Update(c domain.Customer) error {
+	r.writer.Lock()
+	defer r.writer.Unlock()
+	return r.update(c)
+}
+
+// update es Update sin tomar writer.
+func (r *CustomerRepo) update(c domain.Customer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, exists := r.byID[c.ID]
+	if !exists {
+		return domain.ErrInvalidCustomerID
+	}
+	if current.Version != c.Version {
+		return domain.ErrConcurrentModification
+	}
+	c.Version++
+	r.byID[c.ID] = c
+	return nil
+}
+
+// Delete borra un cliente; retorna domain.ErrInvalidCustomerID si no existe.
+func (r *CustomerRepo) Delete(id int) error {
+	r.writer.Lock()
+	defer r.writer.Unlock()
+	return r.delete(id)
+}
+
+// delete es Delete sin tomar writer.
+func (r *CustomerRepo) delete(id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.byID[id]; !exists {
+		return domain.ErrInvalidCustomerID
+	}
+	delete(r.byID, id)
+	return nil
+}
+
+// customerTx es CustomerRepo dentro de una UnitOfWork (ver Repos.view).
+type customerTx struct {
+	*CustomerRepo
+	undo *undoLog
+}
+
+// Create es CustomerRepo.Create, anotando cómo deshacerlo.
+func (t customerTx) Create(c domain.Customer) error {
+	return track(t.undo, t.CustomerRepo, &t.mu, t.byID, c.ID, func() error { return t.create(c) })
+}
+
+// Update es CustomerRepo.Update, anotando cómo deshacerlo.
+func (t customerTx) Update(c domain.Customer) error {
+	return track(t.undo, t.CustomerRepo, &t.mu, t.byID, c.ID, func() error { return t.update(c) })
+}
+
+// Delete es CustomerRepo.Delete, anotando cómo deshacerlo.
+func (t customerTx) Delete(id int) error {
+	return track(t.undo, t.CustomerRepo, &t.mu, t.byID, id, func() error { return t.delete(id) })
+}
@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+/*
+IdempotencyRepo es un repositorio en memoria para los resultados de los
+checkouts con clave de idempotencia.
+
+Responsabilidad:
+- Guardar un registro por clave y reservarla de forma atómica (Claim).
+- NO decide qué es una repetición ni qué resultados se guardan: eso es usecase.
+
+Este repositorio implementa la interfaz usecase.IdempotencyRepository.
+*/
+type IdempotencyRepo struct {
+	// mu protege el mapa: el repositorio es seguro para uso concurrente.
+	mu sync.Mutex
+
+	byKey map[string]usecase.IdempotencyRecord
+}
+
+// NewIdempotencyRepo actúa como constructor del repositorio.
+func NewIdempotencyRepo() *IdempotencyRepo {
+	return &IdempotencyRepo{byKey: make(map[string]usecase.IdempotencyRecord)}
+}
+
+// Claim guarda rec si su clave está libre; si no, devuelve el registro existente.
+func (r *IdempotencyRepo) Claim(rec usecase.IdempotencyRecord) (usecase.IdempotencyRecord, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if prev, ok := r.byKey[rec.Key]; ok {
+		return prev, false
+	}
+	r.byKey[rec.Key] = rec
+	return rec, true
+}
+
+// Save reemplaza el registro de rec.Key.
+func (r *IdempotencyRepo) Save(rec usecase.IdempotencyRecord) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.byKey[rec.Key] = rec
+}
+
+// Delete libera una clave (si no existe, no hace nada).
+func (r *IdempotencyRepo) Delete(key string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.byKey, key)
+}
+
+/*
+DeleteExpired elimina los registros vencidos en el momento now
+y devuelve cuántos eliminó.
+*/
+func (r *IdempotencyRepo) DeleteExpired(now time.Time) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := 0
+	for k, rec := range r.byKey {
+		if !now.Before(rec.ExpiresAt) {
+			delete(r.byKey, k)
+			n++
+		}
+	}
+	return n
+}
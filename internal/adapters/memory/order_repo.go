@@ -0,0 +1,174 @@
+package memory
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+/*
+OrderRepo es un repositorio en memoria para pedidos confirmados.
+
+Responsabilidad:
+- Guardar los pedidos generados por el checkout.
+- Permitir consultarlos por ID, por cliente o por rango de fechas.
+- NO contiene lógica de negocio.
+
+Este repositorio implementa la interfaz usecase.OrderRepository.
+*/
+type OrderRepo struct {
+	// mu protege el mapa: el repositorio es seguro para uso concurrente.
+	mu sync.RWMutex
+
+	// writer lo toma cada escritura, y una UnitOfWork mientras dura.
+	writer sync.Mutex
+
+	// byID asocia el ID del pedido con el pedido completo.
+	byID map[string]usecase.Order
+}
+
+/*
+NewOrderRepo actúa como constructor del repositorio.
+
+Inicializa el mapa interno para permitir inserciones desde el primer uso.
+*/
+func NewOrderRepo() *OrderRepo {
+	return &OrderRepo{byID: make(map[string]usecase.Order)}
+}
+
+/*
+Create guarda un pedido nuevo.
+
+Devuelve domain.ErrDuplicateOrderID si ya existe un pedido con ese ID.
+*/
+func (r *OrderRepo) Create(o usecase.Order) error {
+	r.writer.Lock()
+	defer r.writer.Unlock()
+	return r.create(o)
+}
+
+// create es Create sin tomar writer.
+func (r *OrderRepo) create(o usecase.Order) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.byID[o.ID]; exists {
+		return domain.ErrDuplicateOrderID
+	}
+	r.byID[o.ID] = o
+	return nil
+}
+
+/*
+Update reemplaza un pedido existente.
+
+Devuelve domain.ErrOrderNotFound si el pedido no existe.
+*/
+func (r *OrderRepo) Update(o usecase.Order) error {
+	r.writer.Lock()
+	defer r.writer.Unlock()
+	return r.update(o)
+}
+
+// update es Update sin tomar writer.
+func (r *OrderRepo) update(o usecase.Order) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.byID[o.ID]; !exists {
+		return domain.ErrOrderNotFound
+	}
+	r.byID[o.ID] = o
+	return nil
+}
+
+/*
+GetByID busca un pedido por su ID.
+
+Devuelve domain.ErrOrderNotFound si no existe.
+*/
+func (r *OrderRepo) GetByID(id string) (usecase.Order, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	o, ok := r.byID[id]
+	if !ok {
+		return usecase.Order{}, domain.ErrOrderNotFound
+	}
+	return o, nil
+}
+
+/*
+ListByCustomer devuelve los pedidos de un cliente ordenados por fecha.
+
+Si el cliente no tiene pedidos, devuelve un slice vacío.
+*/
+func (r *OrderRepo) ListByCustomer(customerID int) ([]usecase.Order, error) {
+	return r.filter(func(o usecase.Order) bool {
+		return o.CustomerID == customerID
+	}), nil
+}
+
+/*
+ListByDateRange devuelve los pedidos creados en [from, to) ordenados por fecha.
+*/
+func (r *OrderRepo) ListByDateRange(from, to time.Time) ([]usecase.Order, error) {
+	return r.filter(func(o usecase.Order) bool {
+		return !o.CreatedAt.Before(from) && o.CreatedAt.Before(to)
+	}), nil
+}
+
+/*
+All devuelve todos los pedidos ordenados por fecha.
+
+No forma parte de usecase.OrderRepository: lo usan otros adaptadores
+(por ejemplo jsonfile) que necesitan recorrer el repositorio completo.
+*/
+func (r *OrderRepo) All() []usecase.Order {
+	return r.filter(func(usecase.Order) bool { return true })
+}
+
+/*
+filter recorre los pedidos y devuelve los que cumplen keep.
+
+Como los maps no tienen orden, el resultado se ordena por fecha
+de creación (y por ID para desempatar) para que sea estable.
+*/
+func (r *OrderRepo) filter(keep func(o usecase.Order) bool) []usecase.Order {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]usecase.Order, 0)
+	for _, o := range r.byID {
+		if keep(o) {
+			out = append(out, o)
+		}
+	}
+	slices.SortFunc(out, func(a, b usecase.Order) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return out
+}
+
+// orderTx es OrderRepo dentro de una UnitOfWork (ver Repos.view).
+type orderTx struct {
+	*OrderRepo
+	undo *undoLog
+}
+
+// Create es OrderRepo.Create, anotando cómo deshacerlo.
+func (t orderTx) Create(o usecase.Order) error {
+	return track(t.undo, t.OrderRepo, &t.mu, t.byID, o.ID, func() error { return t.create(o) })
+}
+
+// Update es OrderRepo.Update, anotando cómo deshacerlo.
+func (t orderTx) Update(o usecase.Order) error {
+	return track(t.undo, t.OrderRepo, &t.mu, t.byID, o.ID, func() error { return t.update(o) })
+}
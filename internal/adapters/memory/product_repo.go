@@ -1,6 +1,10 @@
 package memory
 
-import "github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+import (
+	"sync"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+)
 
 /*
 ProductRepo es un repositorio en memoria para productos.
@@ -14,6 +18,12 @@ Responsabilidad:
 Pertenece a la capa de infraestructura (adapters/memory).
 */
 type ProductRepo struct {
+	// mu protege el mapa: el repositorio es seguro para uso concurrente.
+	mu sync.RWMutex
+
+	// writer lo toma cada escritura, y una UnitOfWork mientras dura.
+	writer sync.Mutex
+
 	// Mapa que asocia un ID de producto con la entidad Product.
 	// Key: productID
 	// Value: domain.Product
@@ -42,6 +52,16 @@ Nota:
   deben ocurrir en la capa usecase/domain.
 */
 func (r *ProductRepo) Create(p domain.Product) error {
+	r.writer.Lock()
+	defer r.writer.Unlock()
+	return r.create(p)
+}
+
+// create es Create sin tomar writer.
+func (r *ProductRepo) create(p domain.Product) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	if _, exists := r.byID[p.ID]; exists {
 		return domain.ErrInvalidID
 	}
@@ -57,12 +77,15 @@ Detalles importantes:
 - El orden no está garantizado (los maps en Go no mantienen orden).
 - Si no hay productos, devuelve un slice vacío.
 */
-func (r *ProductRepo) List() []domain.Product {
+func (r *ProductRepo) List() ([]domain.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	out := make([]domain.Product, 0, len(r.byID))
 	for _, p := range r.byID {
 		out = append(out, p)
 	}
-	return out
+	return out, nil
 }
 
 /*
@@ -75,6 +98,9 @@ Este método es fundamental para el carrito:
 Si el producto no existe, retorna un error de dominio.
 */
 func (r *ProductRepo) GetByID(id int) (domain.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	p, ok := r.byID[id]
 	if !ok {
 		return domain.Product{}, domain.ErrInvalidID
@@ -88,14 +114,50 @@ Update actualiza un producto existente.
 Uso principal:
 - Descontar stock después de una compra o al reservar productos.
 
-Comportamiento:
+Comportamiento (compare-and-swap por versión):
 - Si el producto no existe, retorna error.
-- Si existe, reemplaza el valor completo en el repositorio.
+- Si p.Version no coincide con la versión guardada, alguien lo modificó
+  después de leerlo: retorna domain.ErrConcurrentModification y no cambia nada.
+- Si coincide, reemplaza el valor completo e incrementa la versión.
+
+Así, dos checkouts que leyeron el mismo stock no pueden descontarlo
+los dos: el segundo Update falla.
 */
 func (r *ProductRepo) Update(p domain.Product) error {
-	if _, exists := r.byID[p.ID]; !exists {
+	r.writer.Lock()
+	defer r.writer.Unlock()
+	return r.update(p)
+}
+
+// update es Update sin tomar writer.
+func (r *ProductRepo) update(p domain.Product) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, exists := r.byID[p.ID]
+	if !exists {
 		return domain.ErrInvalidID
 	}
+	if current.Version != p.Version {
+		return domain.ErrConcurrentModification
+	}
+	p.Version++
 	r.byID[p.ID] = p
 	return nil
 }
+
+// productTx es ProductRepo dentro de una UnitOfWork (ver Repos.view).
+type productTx struct {
+	*ProductRepo
+	undo *undoLog
+}
+
+// Create es ProductRepo.Create, anotando cómo deshacerlo.
+func (t productTx) Create(p domain.Product) error {
+	return track(t.undo, t.ProductRepo, &t.mu, t.byID, p.ID, func() error { return t.create(p) })
+}
+
+// Update es ProductRepo.Update, anotando cómo deshacerlo.
+func (t productTx) Update(p domain.Product) error {
+	return track(t.undo, t.ProductRepo, &t.mu, t.byID, p.ID, func() error { return t.update(p) })
+}
@@ -0,0 +1,190 @@
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+)
+
+// reservationKey identifica una reserva: un cliente y un producto.
+type reservationKey struct {
+	customerID int
+	productID  int
+}
+
+/*
+ReservationRepo es un repositorio en memoria para reservas de stock.
+
+Responsabilidad:
+- Guardar la reserva de cada cliente sobre cada producto.
+- NO decide si una reserva está vigente ni si hay stock: eso es dominio/usecase.
+
+Este repositorio implementa la interfaz usecase.ReservationRepository.
+*/
+type ReservationRepo struct {
+	// mu protege el mapa: el repositorio es seguro para uso concurrente.
+	mu sync.RWMutex
+
+	// writer lo toma cada escritura, y una UnitOfWork mientras dura.
+	writer sync.Mutex
+
+	byKey map[reservationKey]domain.Reservation
+}
+
+// NewReservationRepo actúa como constructor del repositorio.
+func NewReservationRepo() *ReservationRepo {
+	return &ReservationRepo{byKey: make(map[reservationKey]domain.Reservation)}
+}
+
+// Save crea o reemplaza la reserva de un cliente sobre un producto.
+func (r *ReservationRepo) Save(res domain.Reservation) error {
+	r.writer.Lock()
+	defer r.writer.Unlock()
+	return r.save(res)
+}
+
+// save es Save sin tomar writer.
+func (r *ReservationRepo) save(res domain.Reservation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.byKey[reservationKey{res.CustomerID, res.ProductID}] = res
+	return nil
+}
+
+// Get devuelve la reserva de un cliente sobre un producto, si existe.
+func (r *ReservationRepo) Get(customerID, productID int) (domain.Reservation, bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	res, ok := r.byKey[reservationKey{customerID, productID}]
+	return res, ok, nil
+}
+
+// Delete elimina la reserva de un cliente sobre un producto (si no existe, no hace nada).
+func (r *ReservationRepo) Delete(customerID, productID int) error {
+	r.writer.Lock()
+	defer r.writer.Unlock()
+	return r.delete(reservationKey{customerID, productID})
+}
+
+// delete borra una reserva sin tomar writer.
+func (r *ReservationRepo) delete(k reservationKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.byKey, k)
+	return nil
+}
+
+// DeleteByCustomer elimina todas las reservas de un cliente.
+func (r *ReservationRepo) DeleteByCustomer(customerID int) error {
+	r.writer.Lock()
+	defer r.writer.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for k := range r.byKey {
+		if k.customerID == customerID {
+			delete(r.byKey, k)
+		}
+	}
+	return nil
+}
+
+/*
+ListByProduct devuelve todas las reservas de un producto, vigentes o no.
+
+Si no hay reservas, devuelve un slice vacío.
+*/
+func (r *ReservationRepo) ListByProduct(productID int) ([]domain.Reservation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]domain.Reservation, 0)
+	for _, res := range r.byKey {
+		if res.ProductID == productID {
+			out = append(out, res)
+		}
+	}
+	return out, nil
+}
+
+/*
+DeleteExpired elimina las reservas vencidas en el momento now
+y devuelve cuántas eliminó.
+*/
+func (r *ReservationRepo) DeleteExpired(now time.Time) (int, error) {
+	r.writer.Lock()
+	defer r.writer.Unlock()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := 0
+	for k, res := range r.byKey {
+		if !domain.IsReservationActive(res, now) {
+			delete(r.byKey, k)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// keys devuelve las claves de las reservas que cumplen keep.
+func (r *ReservationRepo) keys(keep func(k reservationKey, res domain.Reservation) bool) []reservationKey {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]reservationKey, 0)
+	for k, res := range r.byKey {
+		if keep(k, res) {
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+/*
+reservationTx es ReservationRepo dentro de una UnitOfWork (ver Repos.view).
+
+Los borrados en bloque se hacen reserva por reserva, para anotar cómo
+deshacer cada una.
+*/
+type reservationTx struct {
+	*ReservationRepo
+	undo *undoLog
+}
+
+// Save es ReservationRepo.Save, anotando cómo deshacerlo.
+func (t reservationTx) Save(res domain.Reservation) error {
+	k := reservationKey{res.CustomerID, res.ProductID}
+	return track(t.undo, t.ReservationRepo, &t.mu, t.byKey, k, func() error { return t.save(res) })
+}
+
+// Delete es ReservationRepo.Delete, anotando cómo deshacerlo.
+func (t reservationTx) Delete(customerID, productID int) error {
+	k := reservationKey{customerID, productID}
+	return track(t.undo, t.ReservationRepo, &t.mu, t.byKey, k, func() error { return t.delete(k) })
+}
+
+// DeleteByCustomer es ReservationRepo.DeleteByCustomer, anotando cómo deshacerlo.
+func (t reservationTx) DeleteByCustomer(customerID int) error {
+	for _, k := range t.keys(func(k reservationKey, _ domain.Reservation) bool { return k.customerID == customerID }) {
+		if err := t.Delete(k.customerID, k.productID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteExpired es ReservationRepo.DeleteExpired, anotando cómo deshacerlo.
+func (t reservationTx) DeleteExpired(now time.Time) (int, error) {
+	expired := t.keys(func(_ reservationKey, res domain.Reservation) bool { return !domain.IsReservationActive(res, now) })
+	for _, k := range expired {
+		if err := t.Delete(k.customerID, k.productID); err != nil {
+			return 0, err
+		}
+	}
+	return len(expired), nil
+}
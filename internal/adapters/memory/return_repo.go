@@ -0,0 +1,151 @@
+package memory
+
+import (
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+/*
+ReturnRepo es un repositorio en memoria para devoluciones (RMA).
+
+Responsabilidad:
+- Guardar las solicitudes de devolución y sus decisiones.
+- Permitir consultarlas por ID o por pedido.
+- NO contiene lógica de negocio.
+
+Este repositorio implementa la interfaz usecase.ReturnRepository.
+*/
+type ReturnRepo struct {
+	// mu protege el mapa: el repositorio es seguro para uso concurrente.
+	mu sync.RWMutex
+
+	// writer lo toma cada escritura, y una UnitOfWork mientras dura.
+	writer sync.Mutex
+
+	// byID asocia el ID de la devolución con la solicitud completa.
+	byID map[string]usecase.ReturnRequest
+}
+
+// NewReturnRepo actúa como constructor del repositorio.
+func NewReturnRepo() *ReturnRepo {
+	return &ReturnRepo{byID: make(map[string]usecase.ReturnRequest)}
+}
+
+/*
+Create guarda una devolución nueva.
+
+Devuelve domain.ErrInvalidID si ya existe una devolución con ese ID.
+*/
+func (r *ReturnRepo) Create(rma usecase.ReturnRequest) error {
+	r.writer.Lock()
+	defer r.writer.Unlock()
+	return r.create(rma)
+}
+
+// create es Create sin tomar writer.
+func (r *ReturnRepo) create(rma usecase.ReturnRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.byID[rma.ID]; exists {
+		return domain.ErrInvalidID
+	}
+	r.byID[rma.ID] = rma
+	return nil
+}
+
+/*
+Update reemplaza una devolución existente.
+
+Devuelve domain.ErrReturnNotFound si no existe.
+*/
+func (r *ReturnRepo) Update(rma usecase.ReturnRequest) error {
+	r.writer.Lock()
+	defer r.writer.Unlock()
+	return r.update(rma)
+}
+
+// update es Update sin tomar writer.
+func (r *ReturnRepo) update(rma usecase.ReturnRequest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.byID[rma.ID]; !exists {
+		return domain.ErrReturnNotFound
+	}
+	r.byID[rma.ID] = rma
+	return nil
+}
+
+// GetByID busca una devolución por su ID o devuelve domain.ErrReturnNotFound.
+func (r *ReturnRepo) GetByID(id string) (usecase.ReturnRequest, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	rma, ok := r.byID[id]
+	if !ok {
+		return usecase.ReturnRequest{}, domain.ErrReturnNotFound
+	}
+	return rma, nil
+}
+
+/*
+ListByOrder devuelve las devoluciones de un pedido ordenadas por fecha.
+
+Si no hay devoluciones, devuelve un slice vacío.
+*/
+func (r *ReturnRepo) ListByOrder(orderID string) ([]usecase.ReturnRequest, error) {
+	return r.filter(func(rma usecase.ReturnRequest) bool {
+		return rma.OrderID == orderID
+	}), nil
+}
+
+/*
+All devuelve todas las devoluciones ordenadas por fecha.
+
+No forma parte de usecase.ReturnRepository: lo usan otros adaptadores
+(por ejemplo jsonfile) que necesitan recorrer el repositorio completo.
+*/
+func (r *ReturnRepo) All() []usecase.ReturnRequest {
+	return r.filter(func(usecase.ReturnRequest) bool { return true })
+}
+
+// filter devuelve las devoluciones que cumplen keep, ordenadas por fecha y por ID.
+func (r *ReturnRepo) filter(keep func(rma usecase.ReturnRequest) bool) []usecase.ReturnRequest {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]usecase.ReturnRequest, 0)
+	for _, rma := range r.byID {
+		if keep(rma) {
+			out = append(out, rma)
+		}
+	}
+	slices.SortFunc(out, func(a, b usecase.ReturnRequest) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return out
+}
+
+// returnTx es ReturnRepo dentro de una UnitOfWork (ver Repos.view).
+type returnTx struct {
+	*ReturnRepo
+	undo *undoLog
+}
+
+// Create es ReturnRepo.Create, anotando cómo deshacerlo.
+func (t returnTx) Create(rma usecase.ReturnRequest) error {
+	return track(t.undo, t.ReturnRepo, &t.mu, t.byID, rma.ID, func() error { return t.create(rma) })
+}
+
+// Update es ReturnRepo.Update, anotando cómo deshacerlo.
+func (t returnTx) Update(rma usecase.ReturnRequest) error {
+	return track(t.undo, t.ReturnRepo, &t.mu, t.byID, rma.ID, func() error { return t.update(rma) })
+}
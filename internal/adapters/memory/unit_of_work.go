@@ -0,0 +1,209 @@
+package memory
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+/*
+Repos son los repositorios en memoria que comparte una UnitOfWork.
+
+Todos son obligatorios: la unidad de trabajo toma el candado de
+escritura de cada uno (ver UnitOfWork).
+*/
+type Repos struct {
+	Products     *ProductRepo
+	Customers    *CustomerRepo
+	Carts        *CartRepo
+	Reservations *ReservationRepo
+	Orders       *OrderRepo
+	Returns      *ReturnRepo
+	Users        *UserRepo
+}
+
+/*
+Hooks permiten que otros adaptadores (jsonfile, eventlog) guarden los
+cambios de una UnitOfWork en su propio almacenamiento.
+
+Todos son opcionales:
+- Wrap decora los repositorios que recibe fn (por ejemplo, para
+  registrar un evento por cada escritura).
+- Commit se llama cuando fn termina bien, con los repositorios que
+  cambiaron (los punteros de Repos, en el orden del primer cambio).
+  Si devuelve error, la unidad de trabajo se deshace igual que si
+  hubiera fallado fn.
+- Rollback se llama después de deshacer los cambios en memoria.
+*/
+type Hooks struct {
+	Wrap     func(tx usecase.Repositories) usecase.Repositories
+	Commit   func(changed []any) error
+	Rollback func()
+}
+
+/*
+UnitOfWork es la implementación en memoria de usecase.UnitOfWork.
+
+Funcionamiento:
+- Do toma el candado de escritura de todos los repositorios y lo
+  retiene hasta terminar: las escrituras hechas fuera de la unidad de
+  trabajo (y las otras unidades de trabajo) esperan.
+- Dentro de fn, cada escritura anota cómo deshacerse (el valor
+  anterior de esa entidad).
+- Si fn falla (error o panic), se deshacen las anotaciones en orden
+  inverso; como nadie más pudo escribir mientras tanto, no se pisa
+  ningún cambio ajeno.
+
+Esto alcanza para un programa de un solo proceso; un backend real
+usaría las transacciones propias de la base de datos.
+
+Lecturas:
+- Las lecturas no esperan: fuera de la unidad de trabajo se pueden ver
+  sus cambios antes de que termine (y que después se deshagan). Quien
+  escriba a partir de esa lectura falla con
+  domain.ErrConcurrentModification al comparar versiones.
+
+Nota:
+- Dentro de fn solo se usan los repositorios de tx: escribir con los
+  repositorios comunes esperaría el candado que tiene la propia
+  unidad de trabajo.
+*/
+type UnitOfWork struct {
+	repos Repos
+	hooks Hooks
+}
+
+// NewUnitOfWork crea una unidad de trabajo sobre los repositorios indicados.
+func NewUnitOfWork(repos Repos, hooks Hooks) *UnitOfWork {
+	return &UnitOfWork{repos: repos, hooks: hooks}
+}
+
+/*
+Do ejecuta fn y deshace sus cambios si fn (o hooks.Commit) devuelve
+error o entra en pánico.
+*/
+func (u *UnitOfWork) Do(fn func(tx usecase.Repositories) error) (err error) {
+	// Los candados se toman siempre en el mismo orden, así dos unidades
+	// de trabajo nunca se esperan mutuamente.
+	writers := []*sync.Mutex{
+		&u.repos.Products.writer,
+		&u.repos.Customers.writer,
+		&u.repos.Carts.writer,
+		&u.repos.Reservations.writer,
+		&u.repos.Orders.writer,
+		&u.repos.Returns.writer,
+		&u.repos.Users.writer,
+	}
+	for _, w := range writers {
+		w.Lock()
+	}
+	defer func() {
+		for _, w := range slices.Backward(writers) {
+			w.Unlock()
+		}
+	}()
+
+	undo := &undoLog{}
+	tx := u.repos.view(undo)
+	if u.hooks.Wrap != nil {
+		tx = u.hooks.Wrap(tx)
+	}
+
+	rollback := func() {
+		undo.rollback()
+		if u.hooks.Rollback != nil {
+			u.hooks.Rollback()
+		}
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			rollback()
+			panic(r)
+		}
+	}()
+
+	if err = fn(tx); err != nil {
+		rollback()
+		return err
+	}
+	if u.hooks.Commit != nil {
+		if err = u.hooks.Commit(undo.changed()); err != nil {
+			rollback()
+			return err
+		}
+	}
+	return nil
+}
+
+// view devuelve los repositorios de una unidad de trabajo, que anotan sus cambios en undo.
+func (r Repos) view(undo *undoLog) usecase.Repositories {
+	return usecase.Repositories{
+		Products:     productTx{r.Products, undo},
+		Customers:    customerTx{r.Customers, undo},
+		Carts:        cartTx{r.Carts, undo},
+		Reservations: reservationTx{r.Reservations, undo},
+		Orders:       orderTx{r.Orders, undo},
+		Returns:      returnTx{r.Returns, undo},
+		Users:        userTx{r.Users, undo},
+	}
+}
+
+// undoLog es la lista de cambios de una unidad de trabajo, con cómo deshacer cada uno.
+type undoLog struct {
+	entries []undoEntry
+}
+
+// undoEntry deshace un cambio sobre repo.
+type undoEntry struct {
+	repo    any
+	restore func()
+}
+
+// rollback deshace todos los cambios, del último al primero.
+func (l *undoLog) rollback() {
+	for _, e := range slices.Backward(l.entries) {
+		e.restore()
+	}
+	l.entries = nil
+}
+
+// changed devuelve los repositorios modificados, sin repetir, en el orden del primer cambio.
+func (l *undoLog) changed() []any {
+	out := make([]any, 0)
+	for _, e := range l.entries {
+		if !slices.Contains(out, e.repo) {
+			out = append(out, e.repo)
+		}
+	}
+	return out
+}
+
+/*
+track ejecuta write (que modifica m[key]) y, si no falla, anota en undo
+cómo volver al valor anterior: reponerlo o, si no existía, borrarlo.
+
+Se llama con el candado de escritura del repositorio tomado, así que
+m solo cambia en esta goroutine; mu se toma para no chocar con las
+lecturas de las demás.
+*/
+func track[K comparable, V any](undo *undoLog, repo any, mu *sync.RWMutex, m map[K]V, key K, write func() error) error {
+	mu.RLock()
+	old, existed := m[key]
+	mu.RUnlock()
+
+	if err := write(); err != nil {
+		return err
+	}
+	undo.entries = append(undo.entries, undoEntry{repo: repo, restore: func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if existed {
+			m[key] = old
+		} else {
+			delete(m, key)
+		}
+	}})
+	return nil
+}
@@ -0,0 +1,36 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/memory"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase/repotest"
+)
+
+// Ejecutar con -race: incluye checkouts simultáneos.
+func TestUnitOfWork(t *testing.T) {
+	err := repotest.UnitOfWork(func() (usecase.Repositories, usecase.UnitOfWork, error) {
+		repos := memory.Repos{
+			Products:     memory.NewProductRepo(),
+			Customers:    memory.NewCustomerRepo(),
+			Carts:        memory.NewCartRepo(),
+			Reservations: memory.NewReservationRepo(),
+			Orders:       memory.NewOrderRepo(),
+			Returns:      memory.NewReturnRepo(),
+			Users:        memory.NewUserRepo(),
+		}
+		return usecase.Repositories{
+			Products:     repos.Products,
+			Customers:    repos.Customers,
+			Carts:        repos.Carts,
+			Reservations: repos.Reservations,
+			Orders:       repos.Orders,
+			Returns:      repos.Returns,
+			Users:        repos.Users,
+		}, memory.NewUnitOfWork(repos, memory.Hooks{}), nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,166 @@
+package memory
+
+import (
+	"sync"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+)
+
+/*
+UserRepo es un repositorio en memoria para usuarios.
+
+Implementa usecase.UserRepository. Igual que los demás repositorios de
+este paquete, solo guarda y recupera datos: no hashea ni valida.
+*/
+type UserRepo struct {
+	// mu protege el mapa: el repositorio es seguro para uso concurrente.
+	mu sync.RWMutex
+
+	// writer lo toma cada escritura, y una UnitOfWork mientras dura.
+	writer sync.Mutex
+
+	// byName almacena los usuarios usando su nombre como clave.
+	byName map[string]domain.User
+}
+
+// NewUserRepo crea un repositorio de usuarios vacío.
+func NewUserRepo() *UserRepo {
+	return &UserRepo{
+		byName: make(map[string]domain.User),
+	}
+}
+
+// Create guarda un usuario nuevo; devuelve domain.ErrUsernameTaken si el nombre ya existe.
+func (r *UserRepo) Create(u domain.User) error {
+	r.writer.Lock()
+	defer r.writer.Unlock()
+	return r.create(u)
+}
+
+// create es Create sin tomar writer.
+func (r *UserRepo) create(u domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.byName[u.Username]; exists {
+		return domain.ErrUsernameTaken
+	}
+	r.byName[u.Username] = u
+	return nil
+}
+
+/*
+Update reemplaza un usuario existente.
+
+Comportamiento (compare-and-swap por versión, igual que CustomerRepo):
+- Si el usuario no existe, retorna domain.ErrUserNotFound.
+- Si u.Version no coincide con la guardada, retorna
+  domain.ErrConcurrentModification y no cambia nada.
+- Si coincide, reemplaza el usuario e incrementa la versión.
+*/
+func (r *UserRepo) Update(u domain.User) error {
+	r.writer.Lock()
+	defer r.writer.Unlock()
+	return r.update(u)
+}
+
+// update es Update sin tomar writer.
+func (r *UserRepo) update(u domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, exists := r.byName[u.Username]
+	if !exists {
+		return domain.ErrUserNotFound
+	}
+	if current.Version != u.Version {
+		return domain.ErrConcurrentModification
+	}
+	u.Version++
+	r.byName[u.Username] = u
+	return nil
+}
+
+// Delete borra un usuario; devuelve domain.ErrUserNotFound si no existe.
+func (r *UserRepo) Delete(username string) error {
+	r.writer.Lock()
+	defer r.writer.Unlock()
+	return r.delete(username)
+}
+
+// delete es Delete sin tomar writer.
+func (r *UserRepo) delete(username string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.byName[username]; !exists {
+		return domain.ErrUserNotFound
+	}
+	delete(r.byName, username)
+	return nil
+}
+
+// GetByUsername busca un usuario; devuelve domain.ErrUserNotFound si no existe.
+func (r *UserRepo) GetByUsername(username string) (domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	u, exists := r.byName[username]
+	if !exists {
+		return domain.User{}, domain.ErrUserNotFound
+	}
+	return u, nil
+}
+
+/*
+GetByAPIKeyHash busca al dueño de una clave de API.
+
+Recorre todos los usuarios: son pocos (cuentas de la tienda y de sus
+clientes) y así no hace falta mantener un segundo índice.
+*/
+func (r *UserRepo) GetByAPIKeyHash(hash string) (domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if hash != "" {
+		for _, u := range r.byName {
+			if u.APIKeyHash == hash {
+				return u, nil
+			}
+		}
+	}
+	return domain.User{}, domain.ErrUserNotFound
+}
+
+// List devuelve todos los usuarios registrados.
+func (r *UserRepo) List() ([]domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	out := make([]domain.User, 0, len(r.byName))
+	for _, u := range r.byName {
+		out = append(out, u)
+	}
+	return out, nil
+}
+
+// userTx es UserRepo dentro de una UnitOfWork (ver Repos.view).
+type userTx struct {
+	*UserRepo
+	undo *undoLog
+}
+
+// Create es UserRepo.Create, anotando cómo deshacerlo.
+func (t userTx) Create(u domain.User) error {
+	return track(t.undo, t.UserRepo, &t.mu, t.byName, u.Username, func() error { return t.create(u) })
+}
+
+// Update es UserRepo.Update, anotando cómo deshacerlo.
+func (t userTx) Update(u domain.User) error {
+	return track(t.undo, t.UserRepo, &t.mu, t.byName, u.Username, func() error { return t.update(u) })
+}
+
+// Delete es UserRepo.Delete, anotando cómo deshacerlo.
+func (t userTx) Delete(username string) error {
+	return track(t.undo, t.UserRepo, &t.mu, t.byName, username, func() error { return t.delete(username) })
+}
@@ -0,0 +1,134 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+)
+
+/*
+CartRepo es el repositorio de carritos sobre la tabla cart_items.
+
+Implementa usecase.CartRepository. Un carrito es el conjunto de líneas
+de un cliente (cart_items), en el orden en que se agregaron (columna
+position); la tabla carts solo guarda su versión.
+*/
+type CartRepo struct {
+	s *Store
+}
+
+// NewCartRepo crea el repositorio sobre el Store indicado.
+func NewCartRepo(s *Store) *CartRepo {
+	return &CartRepo{s: s}
+}
+
+// Get devuelve el carrito del cliente (vacío y con versión 0 si nunca se guardó).
+func (r *CartRepo) Get(customerID int) (domain.Cart, error) {
+	cart := domain.Cart{CustomerID: customerID, Items: []domain.CartItem{}}
+	version, err := r.version(customerID)
+	if err != nil {
+		return domain.Cart{}, err
+	}
+	cart.Version = version
+
+	rows, err := r.s.conn().Query(`
+		SELECT product_id, name, price_amount, price_currency, quantity
+		FROM cart_items WHERE customer_id = ? ORDER BY position`, customerID)
+	if err != nil {
+		return domain.Cart{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var it domain.CartItem
+		if err := rows.Scan(&it.ProductID, &it.Name, &it.Price.Amount, &it.Price.Currency, &it.Quantity); err != nil {
+			return domain.Cart{}, err
+		}
+		cart.Items = append(cart.Items, it)
+	}
+	if err := rows.Err(); err != nil {
+		return domain.Cart{}, err
+	}
+	return cart, nil
+}
+
+/*
+Save reemplaza todas las líneas del carrito del cliente.
+
+Borra e inserta dentro de una transacción (la de la unidad de trabajo
+si ya hay una abierta), así nunca queda un carrito a medio guardar.
+
+Si cart.Version no es la guardada devuelve domain.ErrConcurrentModification.
+La versión se compara e incrementa en una sola sentencia, así dos
+procesos no pueden guardar a la vez sobre la misma versión.
+*/
+func (r *CartRepo) Save(cart domain.Cart) error {
+	return r.s.inTx(func(s *Store) error {
+		r := NewCartRepo(s) // el mismo repositorio, dentro de la transacción
+		var res sql.Result
+		var err error
+		if cart.Version == 0 {
+			res, err = r.s.conn().Exec(`
+				INSERT INTO carts (customer_id, version) VALUES (?, 1)
+				ON CONFLICT (customer_id) DO NOTHING`, cart.CustomerID)
+		} else {
+			res, err = r.s.conn().Exec(`
+				UPDATE carts SET version = version + 1
+				WHERE customer_id = ? AND version = ?`, cart.CustomerID, cart.Version)
+		}
+		if err != nil {
+			return err
+		}
+		if err := expectOne(res, domain.ErrConcurrentModification); err != nil {
+			return err
+		}
+
+		if err := r.deleteItems(cart.CustomerID); err != nil {
+			return err
+		}
+		for i, it := range cart.Items {
+			if _, err := r.s.conn().Exec(`
+				INSERT INTO cart_items
+					(customer_id, position, product_id, name, price_amount, price_currency, quantity)
+				VALUES (?, ?, ?, ?, ?, ?, ?)`,
+				cart.CustomerID, i, it.ProductID, it.Name, it.Price.Amount, it.Price.Currency, it.Quantity); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+/*
+Clear borra todas las líneas del carrito del cliente e incrementa su versión
+(sin compararla, igual que memory.CartRepo).
+*/
+func (r *CartRepo) Clear(customerID int) error {
+	return r.s.inTx(func(s *Store) error {
+		r := NewCartRepo(s) // el mismo repositorio, dentro de la transacción
+		if err := r.deleteItems(customerID); err != nil {
+			return err
+		}
+		_, err := r.s.conn().Exec(`
+			INSERT INTO carts (customer_id, version) VALUES (?, 1)
+			ON CONFLICT (customer_id) DO UPDATE SET version = version + 1`, customerID)
+		return err
+	})
+}
+
+// deleteItems borra las líneas del carrito sin tocar su versión.
+func (r *CartRepo) deleteItems(customerID int) error {
+	_, err := r.s.conn().Exec(`DELETE FROM cart_items WHERE customer_id = ?`, customerID)
+	return err
+}
+
+// version devuelve la versión guardada del carrito (0 si nunca se guardó).
+func (r *CartRepo) version(customerID int) (int, error) {
+	var v int
+	err := r.s.conn().QueryRow(`SELECT version FROM carts WHERE customer_id = ?`, customerID).Scan(&v)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	return v, err
+}
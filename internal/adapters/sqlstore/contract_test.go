@@ -0,0 +1,58 @@
+package sqlstore_test
+
+import (
+	"testing"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/sqlstore"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase/repotest"
+)
+
+// Los repositorios SQL cumplen el mismo contrato que los de memoria.
+func TestContract(t *testing.T) {
+	suites := map[string]func() error{
+		"Products": func() error {
+			return repotest.Products(func() (repotest.ProductRepository, error) {
+				return sqlstore.NewProductRepo(openStore(t)), nil
+			})
+		},
+		"Customers": func() error {
+			return repotest.Customers(func() (repotest.CustomerRepository, error) {
+				return sqlstore.NewCustomerRepo(openStore(t)), nil
+			})
+		},
+		"Carts": func() error {
+			return repotest.Carts(func() (usecase.CartRepository, error) {
+				return sqlstore.NewCartRepo(openStore(t)), nil
+			})
+		},
+		"Reservations": func() error {
+			return repotest.Reservations(func() (usecase.ReservationRepository, error) {
+				return sqlstore.NewReservationRepo(openStore(t)), nil
+			})
+		},
+		"Orders": func() error {
+			return repotest.Orders(func() (usecase.OrderRepository, error) {
+				return sqlstore.NewOrderRepo(openStore(t)), nil
+			})
+		},
+		"Returns": func() error {
+			return repotest.Returns(func() (usecase.ReturnRepository, usecase.OrderRepository, error) {
+				s := openStore(t)
+				return sqlstore.NewReturnRepo(s), sqlstore.NewOrderRepo(s), nil
+			})
+		},
+		"Users": func() error {
+			return repotest.Users(func() (usecase.UserRepository, error) {
+				return sqlstore.NewUserRepo(openStore(t)), nil
+			})
+		},
+	}
+	for name, run := range suites {
+		t.Run(name, func(t *testing.T) {
+			if err := run(); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+}
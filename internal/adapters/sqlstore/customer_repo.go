@@ -0,0 +1,100 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+)
+
+/*
+CustomerRepo es el repositorio de clientes sobre la tabla customers.
+
+Implementa usecase.CustomerRepository, usecase.CustomerRepositoryForCheckout
+y usecase.CustomerRepositoryForDelete.
+*/
+type CustomerRepo struct {
+	s *Store
+}
+
+// NewCustomerRepo crea el repositorio sobre el Store indicado.
+func NewCustomerRepo(s *Store) *CustomerRepo {
+	return &CustomerRepo{s: s}
+}
+
+// Create inserta un cliente; devuelve domain.ErrInvalidCustomerID si el ID ya existe.
+func (r *CustomerRepo) Create(c domain.Customer) error {
+	res, err := r.s.conn().Exec(`
+		INSERT INTO customers (id, name, email, version) VALUES (?, ?, ?, ?)
+		ON CONFLICT (id) DO NOTHING`,
+		c.ID, c.Name, c.Email, c.Version)
+	if err != nil {
+		return err
+	}
+	return expectOne(res, domain.ErrInvalidCustomerID)
+}
+
+// List devuelve todos los clientes ordenados por ID.
+func (r *CustomerRepo) List() ([]domain.Customer, error) {
+	rows, err := r.s.conn().Query(`SELECT id, name, email, version FROM customers ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]domain.Customer, 0)
+	for rows.Next() {
+		var c domain.Customer
+		if err := rows.Scan(&c.ID, &c.Name, &c.Email, &c.Version); err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+/*
+Update reemplaza un cliente si c.Version sigue siendo la guardada,
+e incrementa la versión.
+
+Errores:
+- domain.ErrInvalidCustomerID si el cliente no existe.
+- domain.ErrConcurrentModification si otra operación lo modificó
+  después de leerlo.
+*/
+func (r *CustomerRepo) Update(c domain.Customer) error {
+	res, err := r.s.conn().Exec(`
+		UPDATE customers SET name = ?, email = ?, version = version + 1
+		WHERE id = ? AND version = ?`,
+		c.Name, c.Email, c.ID, c.Version)
+	if err != nil {
+		return err
+	}
+	return r.s.expectVersioned(res, `SELECT 1 FROM customers WHERE id = ?`, c.ID, domain.ErrInvalidCustomerID)
+}
+
+// Delete borra un cliente; devuelve domain.ErrInvalidCustomerID si no existe.
+func (r *CustomerRepo) Delete(id int) error {
+	res, err := r.s.conn().Exec(`DELETE FROM customers WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	return expectOne(res, domain.ErrInvalidCustomerID)
+}
+
+// GetByID busca un cliente; devuelve domain.ErrInvalidCustomerID si no existe.
+func (r *CustomerRepo) GetByID(id int) (domain.Customer, error) {
+	var c domain.Customer
+	err := r.s.conn().QueryRow(`SELECT id, name, email, version FROM customers WHERE id = ?`, id).
+		Scan(&c.ID, &c.Name, &c.Email, &c.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Customer{}, domain.ErrInvalidCustomerID
+	}
+	if err != nil {
+		return domain.Customer{}, err
+	}
+	return c, nil
+}
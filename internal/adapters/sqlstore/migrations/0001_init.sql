@@ -0,0 +1,101 @@
+-- Esquema inicial: productos, clientes, carritos, reservas, pedidos y devoluciones.
+-- Los montos se guardan en unidades menores (centavos) junto a su moneda.
+-- Las fechas se guardan como nanosegundos Unix (NULL = sin fecha).
+
+CREATE TABLE products (
+    id             INTEGER PRIMARY KEY,
+    name           TEXT    NOT NULL,
+    price_amount   INTEGER NOT NULL,
+    price_currency TEXT    NOT NULL,
+    stock          INTEGER NOT NULL
+);
+
+CREATE TABLE customers (
+    id    INTEGER PRIMARY KEY,
+    name  TEXT NOT NULL,
+    email TEXT NOT NULL
+);
+
+CREATE TABLE cart_items (
+    customer_id    INTEGER NOT NULL,
+    position       INTEGER NOT NULL,
+    product_id     INTEGER NOT NULL,
+    name           TEXT    NOT NULL,
+    price_amount   INTEGER NOT NULL,
+    price_currency TEXT    NOT NULL,
+    quantity       INTEGER NOT NULL,
+    PRIMARY KEY (customer_id, product_id)
+);
+
+CREATE TABLE reservations (
+    customer_id INTEGER NOT NULL,
+    product_id  INTEGER NOT NULL,
+    quantity    INTEGER NOT NULL,
+    expires_at  INTEGER,
+    PRIMARY KEY (customer_id, product_id)
+);
+
+CREATE INDEX reservations_product ON reservations (product_id);
+
+CREATE TABLE orders (
+    id                TEXT    PRIMARY KEY,
+    customer_id       INTEGER NOT NULL,
+    customer_name     TEXT    NOT NULL,
+    total_amount      INTEGER NOT NULL,
+    total_currency    TEXT    NOT NULL,
+    refunded_amount   INTEGER NOT NULL,
+    refunded_currency TEXT    NOT NULL,
+    payment_id        TEXT    NOT NULL,
+    cancel_reason     TEXT    NOT NULL,
+    status            TEXT    NOT NULL,
+    created_at        INTEGER
+);
+
+CREATE INDEX orders_customer ON orders (customer_id, created_at);
+CREATE INDEX orders_created ON orders (created_at);
+
+CREATE TABLE order_items (
+    order_id          TEXT    NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    position          INTEGER NOT NULL,
+    product_id        INTEGER NOT NULL,
+    name              TEXT    NOT NULL,
+    unit_price_amount INTEGER NOT NULL,
+    line_total_amount INTEGER NOT NULL,
+    currency          TEXT    NOT NULL,
+    quantity          INTEGER NOT NULL,
+    PRIMARY KEY (order_id, position)
+);
+
+CREATE TABLE order_status_history (
+    order_id    TEXT    NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    position    INTEGER NOT NULL,
+    from_status TEXT    NOT NULL,
+    to_status   TEXT    NOT NULL,
+    at          INTEGER,
+    PRIMARY KEY (order_id, position)
+);
+
+CREATE TABLE returns (
+    id              TEXT    PRIMARY KEY,
+    order_id        TEXT    NOT NULL REFERENCES orders (id),
+    customer_id     INTEGER NOT NULL,
+    refund_amount   INTEGER NOT NULL,
+    refund_currency TEXT    NOT NULL,
+    reason          TEXT    NOT NULL,
+    status          TEXT    NOT NULL,
+    restocked       INTEGER NOT NULL,
+    created_at      INTEGER,
+    decided_at      INTEGER
+);
+
+CREATE INDEX returns_order ON returns (order_id, created_at);
+
+CREATE TABLE return_lines (
+    return_id     TEXT    NOT NULL REFERENCES returns (id) ON DELETE CASCADE,
+    position      INTEGER NOT NULL,
+    product_id    INTEGER NOT NULL,
+    name          TEXT    NOT NULL,
+    quantity      INTEGER NOT NULL,
+    refund_amount INTEGER NOT NULL,
+    PRIMARY KEY (return_id, position)
+);
@@ -0,0 +1,13 @@
+-- Versiones para control de concurrencia optimista (Product, Customer, Cart).
+-- Cada Update/Save compara la versión leída con la guardada y la incrementa.
+
+ALTER TABLE products ADD COLUMN version INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE customers ADD COLUMN version INTEGER NOT NULL DEFAULT 0;
+
+-- Un carrito no tenía fila propia (solo líneas en cart_items);
+-- esta tabla guarda su versión.
+CREATE TABLE carts (
+    customer_id INTEGER PRIMARY KEY,
+    version     INTEGER NOT NULL
+);
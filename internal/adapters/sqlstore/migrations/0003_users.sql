@@ -0,0 +1,13 @@
+-- Usuarios para la autenticación de la CLI y la API.
+-- Solo se guardan hashes: la contraseña (PBKDF2) y la clave de API (SHA-256).
+
+CREATE TABLE users (
+    username      TEXT PRIMARY KEY,
+    role          TEXT NOT NULL,
+    customer_id   INTEGER NOT NULL DEFAULT 0, -- 0 si el rol no es customer
+    password_hash TEXT NOT NULL,
+    api_key_hash  TEXT,                       -- NULL si no tiene clave vigente
+    version       INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE UNIQUE INDEX users_api_key_hash ON users (api_key_hash);
@@ -0,0 +1,215 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+/*
+OrderRepo es el repositorio de pedidos.
+
+Implementa usecase.OrderRepository sobre tres tablas:
+- orders: datos generales y estado actual.
+- order_items: líneas del pedido.
+- order_status_history: historial de cambios de estado.
+
+Las escrituras tocan las tres tablas dentro de una misma transacción.
+*/
+type OrderRepo struct {
+	s *Store
+}
+
+// NewOrderRepo crea el repositorio sobre el Store indicado.
+func NewOrderRepo(s *Store) *OrderRepo {
+	return &OrderRepo{s: s}
+}
+
+// Create inserta un pedido; devuelve domain.ErrDuplicateOrderID si el ID ya existe.
+func (r *OrderRepo) Create(o usecase.Order) error {
+	return r.s.inTx(func(s *Store) error {
+		r := NewOrderRepo(s) // el mismo repositorio, dentro de la transacción
+		res, err := r.s.conn().Exec(`
+			INSERT INTO orders (id, customer_id, customer_name, total_amount, total_currency,
+				refunded_amount, refunded_currency, payment_id, cancel_reason, status, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (id) DO NOTHING`,
+			o.ID, o.CustomerID, o.CustomerName, o.Total.Amount, o.Total.Currency,
+			o.Refunded.Amount, o.Refunded.Currency, o.PaymentID, o.CancelReason,
+			string(o.Status), toNanos(o.CreatedAt))
+		if err != nil {
+			return err
+		}
+		if err := expectOne(res, domain.ErrDuplicateOrderID); err != nil {
+			return err
+		}
+		return r.insertChildren(o)
+	})
+}
+
+// Update reemplaza un pedido completo; devuelve domain.ErrOrderNotFound si no existe.
+func (r *OrderRepo) Update(o usecase.Order) error {
+	return r.s.inTx(func(s *Store) error {
+		r := NewOrderRepo(s) // el mismo repositorio, dentro de la transacción
+		res, err := r.s.conn().Exec(`
+			UPDATE orders SET customer_id = ?, customer_name = ?, total_amount = ?, total_currency = ?,
+				refunded_amount = ?, refunded_currency = ?, payment_id = ?, cancel_reason = ?,
+				status = ?, created_at = ?
+			WHERE id = ?`,
+			o.CustomerID, o.CustomerName, o.Total.Amount, o.Total.Currency,
+			o.Refunded.Amount, o.Refunded.Currency, o.PaymentID, o.CancelReason,
+			string(o.Status), toNanos(o.CreatedAt), o.ID)
+		if err != nil {
+			return err
+		}
+		if err := expectOne(res, domain.ErrOrderNotFound); err != nil {
+			return err
+		}
+
+		for _, table := range []string{"order_items", "order_status_history"} {
+			if _, err := r.s.conn().Exec(`DELETE FROM `+table+` WHERE order_id = ?`, o.ID); err != nil {
+				return err
+			}
+		}
+		return r.insertChildren(o)
+	})
+}
+
+// GetByID busca un pedido; devuelve domain.ErrOrderNotFound si no existe.
+func (r *OrderRepo) GetByID(id string) (usecase.Order, error) {
+	var o usecase.Order
+	var status string
+	var created sql.NullInt64
+	err := r.s.conn().QueryRow(`
+		SELECT id, customer_id, customer_name, total_amount, total_currency,
+			refunded_amount, refunded_currency, payment_id, cancel_reason, status, created_at
+		FROM orders WHERE id = ?`, id).
+		Scan(&o.ID, &o.CustomerID, &o.CustomerName, &o.Total.Amount, &o.Total.Currency,
+			&o.Refunded.Amount, &o.Refunded.Currency, &o.PaymentID, &o.CancelReason,
+			&status, &created)
+	if errors.Is(err, sql.ErrNoRows) {
+		return usecase.Order{}, domain.ErrOrderNotFound
+	}
+	if err != nil {
+		return usecase.Order{}, err
+	}
+	o.Status = domain.OrderStatus(status)
+	o.CreatedAt = fromNanos(created)
+
+	if o.Items, err = r.loadItems(o.ID); err != nil {
+		return usecase.Order{}, err
+	}
+	if o.History, err = r.loadHistory(o.ID); err != nil {
+		return usecase.Order{}, err
+	}
+	return o, nil
+}
+
+// ListByCustomer devuelve los pedidos de un cliente ordenados por fecha.
+func (r *OrderRepo) ListByCustomer(customerID int) ([]usecase.Order, error) {
+	return r.list(`WHERE customer_id = ?`, customerID)
+}
+
+// ListByDateRange devuelve los pedidos creados en [from, to) ordenados por fecha.
+func (r *OrderRepo) ListByDateRange(from, to time.Time) ([]usecase.Order, error) {
+	return r.list(`WHERE created_at >= ? AND created_at < ?`, from.UnixNano(), to.UnixNano())
+}
+
+/*
+list busca los IDs que cumplen el filtro y carga cada pedido completo.
+
+Los IDs se leen primero y recién después se cargan los pedidos, para no
+tener dos consultas abiertas a la vez (con SQLite y una sola conexión
+eso bloquearía).
+*/
+func (r *OrderRepo) list(where string, args ...any) ([]usecase.Order, error) {
+	ids, err := r.s.queryIDs(`SELECT id FROM orders `+where+` ORDER BY created_at, id`, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]usecase.Order, 0, len(ids))
+	for _, id := range ids {
+		o, err := r.GetByID(id)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, o)
+	}
+	return out, nil
+}
+
+// insertChildren guarda las líneas y el historial de estados del pedido.
+func (r *OrderRepo) insertChildren(o usecase.Order) error {
+	for i, it := range o.Items {
+		if _, err := r.s.conn().Exec(`
+			INSERT INTO order_items (order_id, position, product_id, name,
+				unit_price_amount, line_total_amount, currency, quantity)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			o.ID, i, it.ProductID, it.Name,
+			it.UnitPrice.Amount, it.LineTotal.Amount, it.UnitPrice.Currency, it.Quantity); err != nil {
+			return err
+		}
+	}
+	for i, ch := range o.History {
+		if _, err := r.s.conn().Exec(`
+			INSERT INTO order_status_history (order_id, position, from_status, to_status, at)
+			VALUES (?, ?, ?, ?, ?)`,
+			o.ID, i, string(ch.From), string(ch.To), toNanos(ch.At)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadItems lee las líneas de un pedido en su orden original.
+func (r *OrderRepo) loadItems(orderID string) ([]usecase.OrderItem, error) {
+	rows, err := r.s.conn().Query(`
+		SELECT product_id, name, unit_price_amount, line_total_amount, currency, quantity
+		FROM order_items WHERE order_id = ? ORDER BY position`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]usecase.OrderItem, 0)
+	for rows.Next() {
+		var it usecase.OrderItem
+		if err := rows.Scan(&it.ProductID, &it.Name, &it.UnitPrice.Amount, &it.LineTotal.Amount,
+			&it.UnitPrice.Currency, &it.Quantity); err != nil {
+			return nil, err
+		}
+		it.LineTotal.Currency = it.UnitPrice.Currency
+		out = append(out, it)
+	}
+	return out, rows.Err()
+}
+
+// loadHistory lee el historial de estados de un pedido en orden cronológico.
+func (r *OrderRepo) loadHistory(orderID string) ([]domain.StatusChange, error) {
+	rows, err := r.s.conn().Query(`
+		SELECT from_status, to_status, at
+		FROM order_status_history WHERE order_id = ? ORDER BY position`, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]domain.StatusChange, 0)
+	for rows.Next() {
+		var from, to string
+		var at sql.NullInt64
+		if err := rows.Scan(&from, &to, &at); err != nil {
+			return nil, err
+		}
+		out = append(out, domain.StatusChange{
+			From: domain.OrderStatus(from),
+			To:   domain.OrderStatus(to),
+			At:   fromNanos(at),
+		})
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,97 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+)
+
+/*
+ProductRepo es el repositorio de productos sobre la tabla products.
+
+Implementa usecase.ProductRepository y usecase.ProductRepositoryForCart,
+con los mismos errores que memory.ProductRepo.
+*/
+type ProductRepo struct {
+	s *Store
+}
+
+// NewProductRepo crea el repositorio sobre el Store indicado.
+func NewProductRepo(s *Store) *ProductRepo {
+	return &ProductRepo{s: s}
+}
+
+// Create inserta un producto; devuelve domain.ErrInvalidID si el ID ya existe.
+func (r *ProductRepo) Create(p domain.Product) error {
+	res, err := r.s.conn().Exec(`
+		INSERT INTO products (id, name, price_amount, price_currency, stock, version)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO NOTHING`,
+		p.ID, p.Name, p.Price.Amount, p.Price.Currency, p.Stock, p.Version)
+	if err != nil {
+		return err
+	}
+	return expectOne(res, domain.ErrInvalidID)
+}
+
+// List devuelve todos los productos ordenados por ID.
+func (r *ProductRepo) List() ([]domain.Product, error) {
+	rows, err := r.s.conn().Query(`
+		SELECT id, name, price_amount, price_currency, stock, version
+		FROM products ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]domain.Product, 0)
+	for rows.Next() {
+		var p domain.Product
+		if err := rows.Scan(&p.ID, &p.Name, &p.Price.Amount, &p.Price.Currency, &p.Stock, &p.Version); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GetByID busca un producto; devuelve domain.ErrInvalidID si no existe.
+func (r *ProductRepo) GetByID(id int) (domain.Product, error) {
+	var p domain.Product
+	err := r.s.conn().QueryRow(`
+		SELECT id, name, price_amount, price_currency, stock, version
+		FROM products WHERE id = ?`, id).
+		Scan(&p.ID, &p.Name, &p.Price.Amount, &p.Price.Currency, &p.Stock, &p.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Product{}, domain.ErrInvalidID
+	}
+	if err != nil {
+		return domain.Product{}, err
+	}
+	return p, nil
+}
+
+/*
+Update reemplaza un producto si p.Version sigue siendo la guardada,
+e incrementa la versión.
+
+Errores:
+- domain.ErrInvalidID si el producto no existe.
+- domain.ErrConcurrentModification si otra operación lo modificó
+  después de leerlo.
+*/
+func (r *ProductRepo) Update(p domain.Product) error {
+	res, err := r.s.conn().Exec(`
+		UPDATE products SET name = ?, price_amount = ?, price_currency = ?, stock = ?,
+			version = version + 1
+		WHERE id = ? AND version = ?`,
+		p.Name, p.Price.Amount, p.Price.Currency, p.Stock, p.ID, p.Version)
+	if err != nil {
+		return err
+	}
+	return r.s.expectVersioned(res, `SELECT 1 FROM products WHERE id = ?`, p.ID, domain.ErrInvalidID)
+}
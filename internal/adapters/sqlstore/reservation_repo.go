@@ -0,0 +1,109 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+)
+
+/*
+ReservationRepo es el repositorio de reservas de stock sobre la tabla reservations.
+
+Implementa usecase.ReservationRepository. Como en la base las reservas
+sobreviven a un reinicio, conviene llamar a ReleaseExpiredReservations
+al arrancar (la CLI lo hace en cada vuelta del menú del carrito).
+*/
+type ReservationRepo struct {
+	s *Store
+}
+
+// NewReservationRepo crea el repositorio sobre el Store indicado.
+func NewReservationRepo(s *Store) *ReservationRepo {
+	return &ReservationRepo{s: s}
+}
+
+// Save crea o reemplaza la reserva de un cliente sobre un producto.
+func (r *ReservationRepo) Save(res domain.Reservation) error {
+	_, err := r.s.conn().Exec(`
+		INSERT INTO reservations (customer_id, product_id, quantity, expires_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT (customer_id, product_id)
+		DO UPDATE SET quantity = excluded.quantity, expires_at = excluded.expires_at`,
+		res.CustomerID, res.ProductID, res.Quantity, toNanos(res.ExpiresAt))
+	return err
+}
+
+// Get devuelve la reserva de un cliente sobre un producto, si existe.
+func (r *ReservationRepo) Get(customerID, productID int) (domain.Reservation, bool, error) {
+	res := domain.Reservation{CustomerID: customerID, ProductID: productID}
+	var expires sql.NullInt64
+	err := r.s.conn().QueryRow(`
+		SELECT quantity, expires_at FROM reservations
+		WHERE customer_id = ? AND product_id = ?`, customerID, productID).
+		Scan(&res.Quantity, &expires)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Reservation{}, false, nil
+	}
+	if err != nil {
+		return domain.Reservation{}, false, err
+	}
+	res.ExpiresAt = fromNanos(expires)
+	return res, true, nil
+}
+
+// Delete elimina la reserva de un cliente sobre un producto.
+func (r *ReservationRepo) Delete(customerID, productID int) error {
+	_, err := r.s.conn().Exec(`
+		DELETE FROM reservations WHERE customer_id = ? AND product_id = ?`, customerID, productID)
+	return err
+}
+
+// DeleteByCustomer elimina todas las reservas de un cliente.
+func (r *ReservationRepo) DeleteByCustomer(customerID int) error {
+	_, err := r.s.conn().Exec(`DELETE FROM reservations WHERE customer_id = ?`, customerID)
+	return err
+}
+
+// ListByProduct devuelve las reservas (vigentes o vencidas) de un producto.
+func (r *ReservationRepo) ListByProduct(productID int) ([]domain.Reservation, error) {
+	rows, err := r.s.conn().Query(`
+		SELECT customer_id, quantity, expires_at FROM reservations
+		WHERE product_id = ? ORDER BY customer_id`, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]domain.Reservation, 0)
+	for rows.Next() {
+		res := domain.Reservation{ProductID: productID}
+		var expires sql.NullInt64
+		if err := rows.Scan(&res.CustomerID, &res.Quantity, &expires); err != nil {
+			return nil, err
+		}
+		res.ExpiresAt = fromNanos(expires)
+		out = append(out, res)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+/*
+DeleteExpired elimina las reservas vencidas en now y devuelve cuántas eran.
+
+Usa el mismo criterio que domain.IsReservationActive: una reserva
+vence cuando now ya no es anterior a ExpiresAt.
+*/
+func (r *ReservationRepo) DeleteExpired(now time.Time) (int, error) {
+	res, err := r.s.conn().Exec(`
+		DELETE FROM reservations WHERE expires_at IS NULL OR expires_at <= ?`, now.UnixNano())
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
@@ -0,0 +1,153 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+/*
+ReturnRepo es el repositorio de devoluciones (RMA).
+
+Implementa usecase.ReturnRepository sobre las tablas returns
+y return_lines.
+*/
+type ReturnRepo struct {
+	s *Store
+}
+
+// NewReturnRepo crea el repositorio sobre el Store indicado.
+func NewReturnRepo(s *Store) *ReturnRepo {
+	return &ReturnRepo{s: s}
+}
+
+// Create inserta una devolución; devuelve domain.ErrInvalidID si el ID ya existe.
+func (r *ReturnRepo) Create(rma usecase.ReturnRequest) error {
+	return r.s.inTx(func(s *Store) error {
+		r := NewReturnRepo(s) // el mismo repositorio, dentro de la transacción
+		res, err := r.s.conn().Exec(`
+			INSERT INTO returns (id, order_id, customer_id, refund_amount, refund_currency,
+				reason, status, restocked, created_at, decided_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (id) DO NOTHING`,
+			rma.ID, rma.OrderID, rma.CustomerID, rma.Refund.Amount, rma.Refund.Currency,
+			rma.Reason, string(rma.Status), rma.Restocked, toNanos(rma.CreatedAt), toNanos(rma.DecidedAt))
+		if err != nil {
+			return err
+		}
+		if err := expectOne(res, domain.ErrInvalidID); err != nil {
+			return err
+		}
+		return r.insertLines(rma)
+	})
+}
+
+// Update reemplaza una devolución; devuelve domain.ErrReturnNotFound si no existe.
+func (r *ReturnRepo) Update(rma usecase.ReturnRequest) error {
+	return r.s.inTx(func(s *Store) error {
+		r := NewReturnRepo(s) // el mismo repositorio, dentro de la transacción
+		res, err := r.s.conn().Exec(`
+			UPDATE returns SET order_id = ?, customer_id = ?, refund_amount = ?, refund_currency = ?,
+				reason = ?, status = ?, restocked = ?, created_at = ?, decided_at = ?
+			WHERE id = ?`,
+			rma.OrderID, rma.CustomerID, rma.Refund.Amount, rma.Refund.Currency,
+			rma.Reason, string(rma.Status), rma.Restocked, toNanos(rma.CreatedAt), toNanos(rma.DecidedAt),
+			rma.ID)
+		if err != nil {
+			return err
+		}
+		if err := expectOne(res, domain.ErrReturnNotFound); err != nil {
+			return err
+		}
+		if _, err := r.s.conn().Exec(`DELETE FROM return_lines WHERE return_id = ?`, rma.ID); err != nil {
+			return err
+		}
+		return r.insertLines(rma)
+	})
+}
+
+// GetByID busca una devolución; devuelve domain.ErrReturnNotFound si no existe.
+func (r *ReturnRepo) GetByID(id string) (usecase.ReturnRequest, error) {
+	var rma usecase.ReturnRequest
+	var status string
+	var created, decided sql.NullInt64
+	err := r.s.conn().QueryRow(`
+		SELECT id, order_id, customer_id, refund_amount, refund_currency,
+			reason, status, restocked, created_at, decided_at
+		FROM returns WHERE id = ?`, id).
+		Scan(&rma.ID, &rma.OrderID, &rma.CustomerID, &rma.Refund.Amount, &rma.Refund.Currency,
+			&rma.Reason, &status, &rma.Restocked, &created, &decided)
+	if errors.Is(err, sql.ErrNoRows) {
+		return usecase.ReturnRequest{}, domain.ErrReturnNotFound
+	}
+	if err != nil {
+		return usecase.ReturnRequest{}, err
+	}
+	rma.Status = domain.ReturnStatus(status)
+	rma.CreatedAt = fromNanos(created)
+	rma.DecidedAt = fromNanos(decided)
+
+	if rma.Lines, err = r.loadLines(rma.ID, rma.Refund.Currency); err != nil {
+		return usecase.ReturnRequest{}, err
+	}
+	return rma, nil
+}
+
+/*
+ListByOrder devuelve las devoluciones de un pedido ordenadas por fecha.
+
+Igual que OrderRepo.list, primero lee los IDs y después carga cada una.
+*/
+func (r *ReturnRepo) ListByOrder(orderID string) ([]usecase.ReturnRequest, error) {
+	ids, err := r.s.queryIDs(`
+		SELECT id FROM returns WHERE order_id = ? ORDER BY created_at, id`, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]usecase.ReturnRequest, 0, len(ids))
+	for _, id := range ids {
+		rma, err := r.GetByID(id)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, rma)
+	}
+	return out, nil
+}
+
+// insertLines guarda las líneas de una devolución.
+func (r *ReturnRepo) insertLines(rma usecase.ReturnRequest) error {
+	for i, line := range rma.Lines {
+		if _, err := r.s.conn().Exec(`
+			INSERT INTO return_lines (return_id, position, product_id, name, quantity, refund_amount)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+			rma.ID, i, line.ProductID, line.Name, line.Quantity, line.Refund.Amount); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadLines lee las líneas de una devolución; todas usan la moneda del reembolso total.
+func (r *ReturnRepo) loadLines(returnID, currency string) ([]usecase.ReturnLine, error) {
+	rows, err := r.s.conn().Query(`
+		SELECT product_id, name, quantity, refund_amount
+		FROM return_lines WHERE return_id = ? ORDER BY position`, returnID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]usecase.ReturnLine, 0)
+	for rows.Next() {
+		line := usecase.ReturnLine{Refund: domain.Money{Currency: currency}}
+		if err := rows.Scan(&line.ProductID, &line.Name, &line.Quantity, &line.Refund.Amount); err != nil {
+			return nil, err
+		}
+		out = append(out, line)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,203 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+/*
+Store es el punto de entrada del almacenamiento SQL.
+
+Responsabilidad:
+- Guardar la conexión (*sql.DB) que comparten todos los repositorios.
+- Implementar usecase.UnitOfWork con transacciones reales de la base:
+  Do abre una transacción y le pasa a fn repositorios ligados a ella.
+
+El SQL está escrito para SQLite (placeholders "?", ON CONFLICT), pero el
+paquete no importa ningún driver: lo elige quien abre la conexión.
+
+Concurrencia:
+- El Store que devuelve New nunca tiene transacción (tx es nil) y se
+  puede usar desde varias goroutines. Cada Do crea otro Store, solo
+  para esa unidad de trabajo, con su propia transacción: lo que haga
+  otra goroutine con los repositorios comunes no entra en ella.
+- Con SQLite conviene abrir la base con db.SetMaxOpenConns(1): así las
+  operaciones de otras goroutines esperan a que termine la transacción.
+*/
+type Store struct {
+	db *sql.DB
+	tx *sql.Tx // Solo en los Store de una unidad de trabajo (ver Do)
+}
+
+/*
+New crea un Store sobre db y aplica las migraciones pendientes.
+
+Es seguro llamarlo en cada arranque: las migraciones ya aplicadas se saltean.
+*/
+func New(db *sql.DB) (*Store, error) {
+	if err := Migrate(db); err != nil {
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+/*
+Do ejecuta fn dentro de una transacción.
+
+Comportamiento:
+- fn recibe repositorios que usan esa transacción.
+- Si fn devuelve error o entra en pánico, se hace rollback.
+- Si fn termina bien, se hace commit (y se devuelve su error, si falla).
+- Llamado sobre el Store de una unidad de trabajo, fn se une a su
+  transacción (así CartRepo.Save puede usar Do dentro o fuera de una).
+*/
+func (s *Store) Do(fn func(tx usecase.Repositories) error) error {
+	return s.inTx(func(txs *Store) error {
+		return fn(txs.repositories())
+	})
+}
+
+// inTx ejecuta fn con un Store ligado a una transacción (ver Do).
+func (s *Store) inTx(fn func(txs *Store) error) (err error) {
+	if s.tx != nil {
+		return fn(s)
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			_ = tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	if err = fn(&Store{db: s.db, tx: tx}); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// repositories devuelve todos los repositorios sobre s (ver Do).
+func (s *Store) repositories() usecase.Repositories {
+	return usecase.Repositories{
+		Products:     NewProductRepo(s),
+		Customers:    NewCustomerRepo(s),
+		Carts:        NewCartRepo(s),
+		Reservations: NewReservationRepo(s),
+		Orders:       NewOrderRepo(s),
+		Returns:      NewReturnRepo(s),
+		Users:        NewUserRepo(s),
+	}
+}
+
+/*
+querier son las operaciones comunes a *sql.DB y *sql.Tx.
+
+Los repositorios siempre consultan a través de conn(), sin saber
+si están dentro de una transacción o no.
+*/
+type querier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// conn devuelve la transacción del Store o, si no tiene, la conexión.
+func (s *Store) conn() querier {
+	if s.tx != nil {
+		return s.tx
+	}
+	return s.db
+}
+
+/*
+expectOne verifica que una sentencia haya modificado exactamente una fila.
+
+Si no modificó ninguna devuelve errNone: por ejemplo domain.ErrInvalidID
+en un INSERT ... ON CONFLICT DO NOTHING (ID duplicado) o en un UPDATE
+de un registro que no existe.
+*/
+func expectOne(res sql.Result, errNone error) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n != 1 {
+		return errNone
+	}
+	return nil
+}
+
+/*
+expectVersioned interpreta el resultado de un UPDATE ... WHERE version = ?.
+
+Si no modificó ninguna fila, hay dos causas posibles y se distinguen
+con existsQuery: el registro no existe (errMissing) o existe con otra
+versión (domain.ErrConcurrentModification).
+*/
+func (s *Store) expectVersioned(res sql.Result, existsQuery string, id any, errMissing error) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 1 {
+		return nil
+	}
+
+	var one int
+	err = s.conn().QueryRow(existsQuery, id).Scan(&one)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errMissing
+	}
+	if err != nil {
+		return err
+	}
+	return domain.ErrConcurrentModification
+}
+
+/*
+queryIDs ejecuta una consulta que devuelve una sola columna de texto
+(los IDs de pedidos o devoluciones) y cierra las filas antes de volver.
+*/
+func (s *Store) queryIDs(query string, args ...any) ([]string, error) {
+	rows, err := s.conn().Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// toNanos convierte una fecha a nanosegundos Unix; la fecha cero se guarda como NULL.
+func toNanos(t time.Time) sql.NullInt64 {
+	if t.IsZero() {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: t.UnixNano(), Valid: true}
+}
+
+// fromNanos es la inversa de toNanos.
+func fromNanos(n sql.NullInt64) time.Time {
+	if !n.Valid {
+		return time.Time{}
+	}
+	return time.Unix(0, n.Int64)
+}
@@ -0,0 +1,201 @@
+package sqlstore_test
+
+import (
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	// Driver SQLite en Go puro (sin cgo): se registra como "sqlite".
+	_ "modernc.org/sqlite"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/fakepay"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/sqlstore"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase/repotest"
+)
+
+/*
+openStore crea una base SQLite nueva en un directorio temporal, con las
+mismas opciones que usa storage.Open (una sola conexión, claves foráneas
+y busy_timeout).
+*/
+func openStore(t *testing.T) *sqlstore.Store {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ecommerce.db")
+	db, err := sql.Open("sqlite", path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = db.Close() })
+
+	s, err := sqlstore.New(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// fixedIDs devuelve siempre el mismo ID (para forzar un pedido duplicado).
+type fixedIDs string
+
+func (f fixedIDs) NewID() string { return string(f) }
+
+/*
+Un checkout que falla al guardar el pedido (el ID ya existe) no debe
+dejar rastros: el stock, el carrito y las reservas quedan como estaban.
+*/
+func TestCheckoutRollback(t *testing.T) {
+	s := openStore(t)
+	products := sqlstore.NewProductRepo(s)
+	customers := sqlstore.NewCustomerRepo(s)
+	carts := sqlstore.NewCartRepo(s)
+	orders := sqlstore.NewOrderRepo(s)
+	reservations := sqlstore.NewReservationRepo(s)
+
+	price := domain.NewMoney(1000, domain.DefaultCurrency)
+	for _, p := range []domain.Product{
+		{ID: 1, Name: "Lápiz", Price: price, Stock: 5},
+		{ID: 2, Name: "Cuaderno", Price: price, Stock: 3},
+	} {
+		if err := products.Create(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := customers.Create(domain.Customer{ID: 1, Name: "Ana", Email: "ana@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cartDeps := usecase.CartDeps{UnitOfWork: s, Carts: carts, Products: products, Reservations: reservations, ReservationTTL: time.Hour}
+	for _, productID := range []int{1, 2} {
+		if _, err := usecase.AddProductToCart(cartDeps, 1, productID, 2); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Un pedido anterior con el ID que va a generar el checkout.
+	existing := usecase.Order{ID: "ORD-1", CustomerID: 1, Total: price, OrderLifecycle: domain.NewOrderLifecycle(time.Now())}
+	if err := orders.Create(existing); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := usecase.Checkout(usecase.CheckoutDeps{
+		UnitOfWork:   s,
+		Carts:        carts,
+		Products:     products,
+		Customers:    customers,
+		Orders:       orders,
+		Payments:     fakepay.NewGateway(fakepay.Config{}),
+		Reservations: reservations,
+		OrderIDs:     fixedIDs("ORD-1"),
+	}, usecase.CheckoutRequest{CustomerID: 1})
+	if !errors.Is(err, domain.ErrDuplicateOrderID) {
+		t.Fatalf("Checkout devolvió %v, se esperaba %v", err, domain.ErrDuplicateOrderID)
+	}
+
+	for id, want := range map[int]int{1: 5, 2: 3} {
+		p, err := products.GetByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.Stock != want {
+			t.Errorf("stock del producto %d = %d, se esperaba %d", id, p.Stock, want)
+		}
+	}
+	cart, err := carts.Get(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cart.Items) != 2 {
+		t.Errorf("el carrito tiene %d líneas, se esperaban 2", len(cart.Items))
+	}
+	if _, ok, err := reservations.Get(1, 1); err != nil || !ok {
+		t.Errorf("Get de la reserva devolvió %v, %v; se esperaba que siguiera", ok, err)
+	}
+	list, err := orders.ListByCustomer(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 {
+		t.Errorf("el cliente tiene %d pedidos, se esperaba solo el anterior", len(list))
+	}
+}
+
+/*
+Varias unidades de trabajo a la vez, mezcladas con escrituras fuera de
+ellas: cada una usa su propia transacción y ninguna se pierde
+(ejecutar con -race).
+*/
+func TestConcurrentUnitsOfWork(t *testing.T) {
+	s := openStore(t)
+	products := sqlstore.NewProductRepo(s)
+	if err := products.Create(domain.Product{ID: 1, Name: "Lápiz", Price: domain.NewMoney(100, domain.DefaultCurrency), Stock: 100}); err != nil {
+		t.Fatal(err)
+	}
+
+	const workers = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*workers)
+	for i := range workers {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			errs <- s.Do(func(tx usecase.Repositories) error {
+				p, err := tx.Products.GetByID(1)
+				if err != nil {
+					return err
+				}
+				p.Stock--
+				return tx.Products.Update(p)
+			})
+		}()
+		go func() {
+			defer wg.Done()
+			// Fuera de una unidad de trabajo el cambio de precio puede chocar
+			// con otra escritura; lo que no puede es pisarla.
+			_, err := usecase.ChangeProductPrice(products, 1, domain.NewMoney(int64(100+i), domain.DefaultCurrency))
+			if errors.Is(err, domain.ErrConcurrentModification) {
+				err = nil
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Error(err)
+		}
+	}
+	p, err := products.GetByID(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Stock != 100-workers {
+		t.Errorf("stock = %d, se esperaba %d", p.Stock, 100-workers)
+	}
+}
+
+// Ejecutar con -race: incluye checkouts simultáneos.
+func TestUnitOfWork(t *testing.T) {
+	err := repotest.UnitOfWork(func() (usecase.Repositories, usecase.UnitOfWork, error) {
+		s := openStore(t)
+		return usecase.Repositories{
+			Products:     sqlstore.NewProductRepo(s),
+			Customers:    sqlstore.NewCustomerRepo(s),
+			Carts:        sqlstore.NewCartRepo(s),
+			Reservations: sqlstore.NewReservationRepo(s),
+			Orders:       sqlstore.NewOrderRepo(s),
+			Returns:      sqlstore.NewReturnRepo(s),
+			Users:        sqlstore.NewUserRepo(s),
+		}, s, nil
+	})
+	if err != nil {
+		t.Error(err)
+	}
+}
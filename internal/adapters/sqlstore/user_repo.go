@@ -0,0 +1,121 @@
+package sqlstore
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+)
+
+/*
+UserRepo es el repositorio de usuarios sobre la tabla users.
+
+Implementa usecase.UserRepository. Un usuario sin clave de API vigente
+guarda api_key_hash en NULL, así el índice único no choca entre ellos.
+*/
+type UserRepo struct {
+	s *Store
+}
+
+// NewUserRepo crea el repositorio sobre el Store indicado.
+func NewUserRepo(s *Store) *UserRepo {
+	return &UserRepo{s: s}
+}
+
+// userColumns son las columnas que lee scanUser, en orden.
+const userColumns = `username, role, customer_id, password_hash, api_key_hash, version`
+
+// Create inserta un usuario; devuelve domain.ErrUsernameTaken si el nombre ya existe.
+func (r *UserRepo) Create(u domain.User) error {
+	res, err := r.s.conn().Exec(`
+		INSERT INTO users (`+userColumns+`) VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (username) DO NOTHING`,
+		u.Username, string(u.Role), u.CustomerID, u.PasswordHash, toNullString(u.APIKeyHash), u.Version)
+	if err != nil {
+		return err
+	}
+	return expectOne(res, domain.ErrUsernameTaken)
+}
+
+/*
+Update reemplaza un usuario si u.Version sigue siendo la guardada,
+e incrementa la versión.
+
+Errores:
+- domain.ErrUserNotFound si el usuario no existe.
+- domain.ErrConcurrentModification si otra operación lo modificó
+  después de leerlo.
+*/
+func (r *UserRepo) Update(u domain.User) error {
+	res, err := r.s.conn().Exec(`
+		UPDATE users SET role = ?, customer_id = ?, password_hash = ?, api_key_hash = ?, version = version + 1
+		WHERE username = ? AND version = ?`,
+		string(u.Role), u.CustomerID, u.PasswordHash, toNullString(u.APIKeyHash), u.Username, u.Version)
+	if err != nil {
+		return err
+	}
+	return r.s.expectVersioned(res, `SELECT 1 FROM users WHERE username = ?`, u.Username, domain.ErrUserNotFound)
+}
+
+// Delete borra un usuario; devuelve domain.ErrUserNotFound si no existe.
+func (r *UserRepo) Delete(username string) error {
+	res, err := r.s.conn().Exec(`DELETE FROM users WHERE username = ?`, username)
+	if err != nil {
+		return err
+	}
+	return expectOne(res, domain.ErrUserNotFound)
+}
+
+// GetByUsername busca un usuario; devuelve domain.ErrUserNotFound si no existe.
+func (r *UserRepo) GetByUsername(username string) (domain.User, error) {
+	return scanUser(r.s.conn().QueryRow(`SELECT `+userColumns+` FROM users WHERE username = ?`, username))
+}
+
+// GetByAPIKeyHash busca al dueño de una clave de API; devuelve domain.ErrUserNotFound si no existe.
+func (r *UserRepo) GetByAPIKeyHash(hash string) (domain.User, error) {
+	return scanUser(r.s.conn().QueryRow(`SELECT `+userColumns+` FROM users WHERE api_key_hash = ?`, hash))
+}
+
+// List devuelve todos los usuarios ordenados por nombre.
+func (r *UserRepo) List() ([]domain.User, error) {
+	rows, err := r.s.conn().Query(`SELECT ` + userColumns + ` FROM users ORDER BY username`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	out := make([]domain.User, 0)
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, u)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// scanUser lee un usuario con las columnas de userColumns.
+func scanUser(row interface{ Scan(...any) error }) (domain.User, error) {
+	var u domain.User
+	var role string
+	var apiKeyHash sql.NullString
+	err := row.Scan(&u.Username, &role, &u.CustomerID, &u.PasswordHash, &apiKeyHash, &u.Version)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.User{}, domain.ErrUserNotFound
+	}
+	if err != nil {
+		return domain.User{}, err
+	}
+	u.Role = domain.Role(role)
+	u.APIKeyHash = apiKeyHash.String
+	return u, nil
+}
+
+// toNullString guarda los textos vacíos como NULL.
+func toNullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,231 @@
+/*
+Package storage abre los repositorios de cualquier almacenamiento
+(memoria, archivos JSON, SQLite o log de eventos) con una sola llamada.
+
+Lo usan los programas de cmd (la CLI y el servidor HTTP), que eligen el
+almacenamiento con sus opciones de línea de comandos.
+*/
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	// Driver SQLite en Go puro (sin cgo): se registra como "sqlite".
+	_ "modernc.org/sqlite"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/dirlock"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/eventlog"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/jsonfile"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/memory"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/sqlstore"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+/*
+Interfaces que deben cumplir los repositorios de cualquier almacenamiento.
+
+Combinan varios contratos de usecase, porque los programas pasan el mismo
+repositorio a casos de uso distintos.
+*/
+type (
+	ProductStore interface {
+		usecase.ProductRepository
+		usecase.ProductRepositoryForCart
+	}
+	CustomerStore interface {
+		usecase.CustomerRepository
+		usecase.CustomerRepositoryForCheckout
+		usecase.CustomerRepositoryForDelete
+	}
+)
+
+/*
+Storage agrupa los repositorios elegidos con la opción -storage,
+junto con la unidad de trabajo que sabe deshacer sus cambios.
+
+Idempotency queda en memoria con cualquier almacenamiento: si el
+programa se reinicia se pierden las claves, pero repetir un checkout
+que ya se confirmó no crea otra orden porque el carrito quedó vacío.
+*/
+type Storage struct {
+	Products     ProductStore
+	Customers    CustomerStore
+	Carts        usecase.CartRepository
+	Orders       usecase.OrderRepository
+	Returns      usecase.ReturnRepository
+	Reservations usecase.ReservationRepository
+	Idempotency  usecase.IdempotencyRepository
+	Users        usecase.UserRepository
+	UnitOfWork   usecase.UnitOfWork
+}
+
+/*
+Open crea los repositorios según kind:
+- "memory": todo se pierde al salir del programa.
+- "file": archivos JSON dentro de dir (se crean si no existen).
+- "sqlite": base SQLite en dbPath, con migraciones y transacciones reales.
+- "eventlog": log de eventos (events.log) y snapshots dentro de dir.
+
+Varios procesos (por ejemplo, el servidor y la CLI a la vez):
+- Solo "sqlite" los admite: las versiones de las entidades se comparan
+  contra la base al guardar.
+- "file" y "eventlog" reservan dir para un solo proceso; si otro ya lo
+  tiene, Open falla con dirlock.ErrLocked y un mensaje que sugiere sqlite.
+*/
+func Open(kind, dir, dbPath string) (Storage, error) {
+	switch kind {
+	case "memory":
+		products := memory.NewProductRepo()
+		customers := memory.NewCustomerRepo()
+		carts := memory.NewCartRepo()
+		orders := memory.NewOrderRepo()
+		returns := memory.NewReturnRepo()
+		reservations := memory.NewReservationRepo()
+		users := memory.NewUserRepo()
+		repos := memory.Repos{
+			Products:     products,
+			Customers:    customers,
+			Carts:        carts,
+			Reservations: reservations,
+			Orders:       orders,
+			Returns:      returns,
+			Users:        users,
+		}
+		return Storage{
+			Products:     products,
+			Customers:    customers,
+			Carts:        carts,
+			Orders:       orders,
+			Returns:      returns,
+			Reservations: reservations,
+			Idempotency:  memory.NewIdempotencyRepo(),
+			Users:        users,
+			UnitOfWork:   memory.NewUnitOfWork(repos, memory.Hooks{}),
+		}, nil
+
+	case "file":
+		return openFileStorage(dir)
+
+	case "sqlite":
+		return openSQLStorage(dbPath)
+
+	case "eventlog":
+		return openEventLogStorage(dir)
+	}
+	return Storage{}, fmt.Errorf("almacenamiento inválido: %s", kind)
+}
+
+/*
+PaymentsFile devuelve el archivo donde el proveedor de pagos falso
+guarda sus pagos para el almacenamiento kind (ver fakepay.Open):
+- "file" y "eventlog": payments.json dentro de dir.
+- "sqlite": junto a la base, con el mismo nombre y ".payments.json".
+- "memory": vacío, porque los pedidos tampoco sobreviven al programa.
+*/
+func PaymentsFile(kind, dir, dbPath string) string {
+	switch kind {
+	case "file", "eventlog":
+		return filepath.Join(dir, "payments.json")
+	case "sqlite":
+		return strings.TrimSuffix(dbPath, filepath.Ext(dbPath)) + ".payments.json"
+	}
+	return ""
+}
+
+/*
+singleProcess agrega a dirlock.ErrLocked qué hacer para compartir los
+datos entre procesos. Los demás errores quedan igual.
+*/
+func singleProcess(err error) error {
+	if errors.Is(err, dirlock.ErrLocked) {
+		return fmt.Errorf("%w; para usar los mismos datos desde varios procesos a la vez, usar el almacenamiento sqlite", err)
+	}
+	return err
+}
+
+/*
+openFileStorage abre los repositorios JSON.
+
+Las reservas de stock quedan en memoria: duran minutos y no tiene
+sentido conservarlas entre ejecuciones.
+*/
+func openFileStorage(dir string) (Storage, error) {
+	s, err := jsonfile.Open(dir)
+	if err != nil {
+		return Storage{}, singleProcess(err)
+	}
+
+	return Storage{
+		Products:     jsonfile.NewProductRepo(s),
+		Customers:    jsonfile.NewCustomerRepo(s),
+		Carts:        jsonfile.NewCartRepo(s),
+		Orders:       jsonfile.NewOrderRepo(s),
+		Returns:      jsonfile.NewReturnRepo(s),
+		Reservations: jsonfile.NewReservationRepo(s),
+		Idempotency:  memory.NewIdempotencyRepo(),
+		Users:        jsonfile.NewUserRepo(s),
+		UnitOfWork:   jsonfile.NewUnitOfWork(s),
+	}, nil
+}
+
+/*
+openSQLStorage abre (o crea) la base SQLite y aplica las migraciones.
+
+Se usa una sola conexión: SQLite no admite escrituras concurrentes y
+así las consultas esperan a que termine la transacción en curso.
+*/
+func openSQLStorage(dbPath string) (Storage, error) {
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return Storage{}, err
+	}
+	db.SetMaxOpenConns(1)
+
+	s, err := sqlstore.New(db)
+	if err != nil {
+		_ = db.Close()
+		return Storage{}, err
+	}
+
+	return Storage{
+		Products:     sqlstore.NewProductRepo(s),
+		Customers:    sqlstore.NewCustomerRepo(s),
+		Carts:        sqlstore.NewCartRepo(s),
+		Orders:       sqlstore.NewOrderRepo(s),
+		Returns:      sqlstore.NewReturnRepo(s),
+		Reservations: sqlstore.NewReservationRepo(s),
+		Idempotency:  memory.NewIdempotencyRepo(),
+		Users:        sqlstore.NewUserRepo(s),
+		UnitOfWork:   s,
+	}, nil
+}
+
+/*
+openEventLogStorage abre el log de eventos y reconstruye el estado
+(último snapshot + eventos posteriores).
+
+Igual que con archivos JSON, las reservas quedan en memoria; la unidad
+de trabajo las incluye para deshacerlas si el checkout falla.
+*/
+func openEventLogStorage(dir string) (Storage, error) {
+	l, err := eventlog.Open(dir, eventlog.Options{})
+	if err != nil {
+		return Storage{}, singleProcess(err)
+	}
+
+	return Storage{
+		Products:     eventlog.NewProductRepo(l),
+		Customers:    eventlog.NewCustomerRepo(l),
+		Carts:        eventlog.NewCartRepo(l),
+		Orders:       eventlog.NewOrderRepo(l),
+		Returns:      eventlog.NewReturnRepo(l),
+		Reservations: eventlog.NewReservationRepo(l),
+		Idempotency:  memory.NewIdempotencyRepo(),
+		Users:        eventlog.NewUserRepo(l),
+		UnitOfWork:   eventlog.NewUnitOfWork(l),
+	}, nil
+}
@@ -0,0 +1,34 @@
+//go:build unix
+
+package storage_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/dirlock"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/storage"
+)
+
+/*
+Con file y eventlog, un segundo Open sobre el mismo directorio (como
+haría otro proceso) falla con dirlock.ErrLocked y sugiere sqlite.
+*/
+func TestOpenSingleProcess(t *testing.T) {
+	for _, kind := range []string{"file", "eventlog"} {
+		t.Run(kind, func(t *testing.T) {
+			dir := t.TempDir()
+			if _, err := storage.Open(kind, dir, ""); err != nil {
+				t.Fatal(err)
+			}
+			_, err := storage.Open(kind, dir, "")
+			if !errors.Is(err, dirlock.ErrLocked) {
+				t.Fatalf("segundo Open devolvió %v, se esperaba %v", err, dirlock.ErrLocked)
+			}
+			if !strings.Contains(err.Error(), "sqlite") {
+				t.Errorf("el error no sugiere sqlite: %v", err)
+			}
+		})
+	}
+}
@@ -1,5 +1,13 @@
 package domain
 
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"slices"
+)
+
 /*
 CartItem representa un ítem dentro del carrito.
 
@@ -11,10 +19,10 @@ Cada CartItem es inmutable desde fuera; las modificaciones se hacen
 a través de funciones de dominio (AddItem / RemoveItem).
 */
 type CartItem struct {
-	ProductID int     // Identificador del producto
-	Name      string  // Nombre del producto (snapshot al momento de agregar)
-	Price     float64 // Precio unitario del producto
-	Quantity  int     // Cantidad agregada al carrito
+	ProductID int    // Identificador del producto
+	Name      string // Nombre del producto (snapshot al momento de agregar)
+	Price     Money  // Precio unitario del producto
+	Quantity  int    // Cantidad agregada al carrito
 }
 
 /*
@@ -30,6 +38,7 @@ Esta entidad vive en el dominio porque modela un concepto central del negocio.
 type Cart struct {
 	CustomerID int        // Identificador del cliente dueño del carrito
 	Items      []CartItem // Ítems actuales del carrito
+	Version    int        // Versión guardada; el repositorio la incrementa en cada Save
 }
 
 /*
@@ -37,6 +46,8 @@ AddItem agrega un producto al carrito.
 
 Comportamiento:
 - Si la cantidad es inválida (<= 0), se retorna un error de dominio.
+- Si la moneda del ítem no coincide con la de los ítems existentes,
+  se retorna ErrCurrencyMismatch (un carrito tiene una sola moneda).
 - Si el producto ya existe en el carrito, se incrementa su cantidad.
 - Si no existe, se agrega como nuevo ítem.
 
@@ -55,6 +66,9 @@ func AddItem(cart Cart, item CartItem) (Cart, error) {
 	updated := false
 
 	for _, it := range cart.Items {
+		if it.Price.Currency != item.Price.Currency {
+			return cart, ErrCurrencyMismatch
+		}
 		if it.ProductID == item.ProductID {
 			// Si el producto ya existe, se acumula la cantidad.
 			it.Quantity += item.Quantity
@@ -72,6 +86,59 @@ func AddItem(cart Cart, item CartItem) (Cart, error) {
 	return cart, nil
 }
 
+/*
+SetItemQuantity fija la cantidad de un producto en el carrito.
+
+Diferencia con AddItem:
+- AddItem ACUMULA (5 + 5 = 10).
+- SetItemQuantity REEMPLAZA (queda exactamente item.Quantity).
+
+Comportamiento:
+- Si la cantidad es inválida (<= 0), se retorna ErrInvalidQuantity
+  (para quitar un producto se usa RemoveItem).
+- Si el producto no estaba en el carrito, se agrega como nuevo ítem.
+- Si la moneda no coincide con la del carrito, se retorna ErrCurrencyMismatch.
+*/
+func SetItemQuantity(cart Cart, item CartItem) (Cart, error) {
+	if item.Quantity <= 0 {
+		return cart, ErrInvalidQuantity
+	}
+
+	newItems := make([]CartItem, 0, len(cart.Items)+1)
+	updated := false
+
+	for _, it := range cart.Items {
+		if it.Price.Currency != item.Price.Currency {
+			return cart, ErrCurrencyMismatch
+		}
+		if it.ProductID == item.ProductID {
+			it.Quantity = item.Quantity
+			updated = true
+		}
+		newItems = append(newItems, it)
+	}
+
+	if !updated {
+		newItems = append(newItems, item)
+	}
+
+	cart.Items = newItems
+	return cart, nil
+}
+
+/*
+ItemQuantity devuelve cuántas unidades de un producto hay en el carrito
+(0 si el producto no está).
+*/
+func ItemQuantity(cart Cart, productID int) int {
+	for _, it := range cart.Items {
+		if it.ProductID == productID {
+			return it.Quantity
+		}
+	}
+	return 0
+}
+
 /*
 RemoveItem elimina un producto del carrito por su ProductID.
 
@@ -99,16 +166,36 @@ Total calcula el valor total del carrito.
 
 Regla:
 - Suma precio * cantidad de cada ítem.
+- Un carrito vacío vale cero en la moneda por defecto.
+- Si hubiera ítems con monedas distintas, se devuelve ErrCurrencyMismatch
+  en vez de sumar montos incomparables.
 
 Este cálculo pertenece al dominio porque define
 qué significa "total" en el negocio.
 */
-func Total(cart Cart) float64 {
-	total := 0.0
+func Total(cart Cart) (Money, error) {
+	if IsEmpty(cart) {
+		return ZeroMoney(DefaultCurrency), nil
+	}
+
+	total := ZeroMoney(cart.Items[0].Price.Currency)
 	for _, it := range cart.Items {
-		total += it.Price * float64(it.Quantity)
+		var err error
+		total, err = AddMoney(total, LineTotal(it))
+		if err != nil {
+			return Money{}, err
+		}
 	}
-	return total
+	return total, nil
+}
+
+/*
+LineTotal calcula el subtotal de un ítem (precio unitario * cantidad).
+
+Se expone para que la CLI y los casos de uso no repitan la multiplicación.
+*/
+func LineTotal(item CartItem) Money {
+	return MulMoney(item.Price, item.Quantity)
 }
 
 /*
@@ -121,3 +208,24 @@ Es una función de conveniencia:
 func IsEmpty(cart Cart) bool {
 	return len(cart.Items) == 0
 }
+
+/*
+CartFingerprint resume el contenido del carrito (productos, cantidades
+y precios) en un texto corto.
+
+Dos carritos con los mismos ítems tienen la misma huella aunque estén en
+otro orden o tengan otra Version. Sirve para saber si un checkout
+repetido (misma clave de idempotencia) se refiere a la misma compra.
+*/
+func CartFingerprint(cart Cart) string {
+	items := slices.SortedFunc(slices.Values(cart.Items), func(a, b CartItem) int {
+		return cmp.Compare(a.ProductID, b.ProductID)
+	})
+
+	h := sha256.New()
+	fmt.Fprintf(h, "customer:%d\n", cart.CustomerID)
+	for _, it := range items {
+		fmt.Fprintf(h, "%d:%d:%d:%s\n", it.ProductID, it.Quantity, it.Price.Amount, it.Price.Currency)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
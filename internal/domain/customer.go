@@ -1,5 +1,7 @@
 package domain
 
+import "fmt"
+
 /*
 Customer representa a un cliente del sistema.
 
@@ -9,9 +11,10 @@ Es una entidad de dominio:
 - Contiene solo datos relevantes del cliente.
 */
 type Customer struct {
-	ID    int    // Identificador único del cliente
-	Name  string // Nombre del cliente
-	Email string // Correo electrónico del cliente
+	ID      int    // Identificador único del cliente
+	Name    string // Nombre del cliente
+	Email   string // Correo electrónico del cliente
+	Version int    // Versión guardada, para detectar escrituras concurrentes
 }
 
 /*
@@ -43,6 +46,27 @@ func ValidateCustomer(c Customer) error {
 	return nil
 }
 
+// AnonymizedCustomerName es el nombre que queda en un cliente anonimizado.
+const AnonymizedCustomerName = "Cliente eliminado"
+
+/*
+AnonymizeCustomer devuelve el cliente sin sus datos personales.
+
+Se usa al eliminar un cliente que tiene pedidos: el ID se conserva
+para que los pedidos sigan apuntando a él, pero el nombre y el email
+se reemplazan por valores genéricos.
+
+Reglas:
+- El resultado sigue cumpliendo ValidateCustomer.
+- El email es único por cliente y usa el dominio reservado ".invalid",
+  así nunca corresponde a un buzón real.
+*/
+func AnonymizeCustomer(c Customer) Customer {
+	c.Name = AnonymizedCustomerName
+	c.Email = fmt.Sprintf("cliente-%d@anonimo.invalid", c.ID)
+	return c
+}
+
 /*
 isValidEmailBasic valida de forma simple el formato de un email.
 
@@ -1,6 +1,9 @@
 package domain
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 /*
 Errores de dominio del sistema.
@@ -41,6 +44,12 @@ var (
 	// Se usa típicamente al buscar por ID en repositorios.
 	ErrProductNotFound = errors.New("producto no encontrado")
 
+	// ErrConcurrentModification indica que se intentó guardar una versión
+	// vieja de una entidad: otra goroutine (o, con SQLite, otro proceso)
+	// la modificó después de leerla (por ejemplo, dos checkouts
+	// descontando el mismo stock).
+	ErrConcurrentModification = errors.New("el registro fue modificado por otra operación")
+
 	// =========================
 	// ERRORES DE CLIENTES
 	// =========================
@@ -55,6 +64,13 @@ var (
 	// ErrInvalidEmail indica que el email no cumple el formato mínimo válido.
 	ErrInvalidEmail = errors.New("email inválido")
 
+	// ErrCustomerNotFound indica que no existe un cliente con el ID buscado.
+	ErrCustomerNotFound = errors.New("cliente no encontrado")
+
+	// ErrCustomerHasOrders indica que se quiso eliminar un cliente que
+	// tiene pedidos: sus datos solo se pueden anonimizar.
+	ErrCustomerHasOrders = errors.New("el cliente tiene pedidos")
+
 	// =========================
 	// ERRORES DE CARRITO
 	// =========================
@@ -66,4 +82,203 @@ var (
 	// ErrEmptyCart indica que se intentó operar sobre un carrito vacío
 	// (por ejemplo, checkout sin productos).
 	ErrEmptyCart = errors.New("carrito vacío")
+
+	// ErrPriceChanged indica que el precio de uno o más productos del carrito
+	// cambió desde que se agregaron y la compra necesita confirmación.
+	ErrPriceChanged = errors.New("cambió el precio de productos del carrito")
+
+	// =========================
+	// ERRORES DE PEDIDOS
+	// =========================
+
+	// ErrOrderNotFound indica que no existe un pedido con el ID buscado.
+	ErrOrderNotFound = errors.New("pedido no encontrado")
+
+	// ErrDuplicateOrderID indica que ya existe un pedido guardado con ese ID.
+	ErrDuplicateOrderID = errors.New("ID de pedido duplicado")
+
+	// ErrInvalidDateRange indica que el rango de fechas de una búsqueda
+	// es incoherente (la fecha "hasta" es anterior a la fecha "desde").
+	ErrInvalidDateRange = errors.New("rango de fechas inválido")
+
+	// ErrInvalidOrderStatus indica que el estado de pedido no existe.
+	ErrInvalidOrderStatus = errors.New("estado de pedido inválido")
+
+	// ErrInvalidStatusTransition indica que el pedido no puede pasar
+	// de su estado actual al estado pedido (por ejemplo, entregar
+	// un pedido que todavía no se envió).
+	ErrInvalidStatusTransition = errors.New("cambio de estado de pedido no permitido")
+
+	// ErrOrderAlreadyShipped indica que el pedido ya salió del depósito
+	// (enviado o entregado) y por eso no se puede cancelar.
+	ErrOrderAlreadyShipped = errors.New("el pedido ya fue enviado")
+
+	// =========================
+	// ERRORES DE DEVOLUCIONES
+	// =========================
+
+	// ErrOrderNotDelivered indica que se pidió una devolución sobre
+	// un pedido que todavía no fue entregado.
+	ErrOrderNotDelivered = errors.New("el pedido no fue entregado")
+
+	// ErrEmptyReturn indica que la solicitud de devolución no tiene líneas.
+	ErrEmptyReturn = errors.New("devolución sin productos")
+
+	// ErrProductNotInOrder indica que se quiso devolver un producto
+	// que no forma parte del pedido.
+	ErrProductNotInOrder = errors.New("el producto no pertenece al pedido")
+
+	// ErrReturnQuantityExceeded indica que se pidió devolver más unidades
+	// de las compradas (descontando las ya devueltas o en trámite).
+	ErrReturnQuantityExceeded = errors.New("cantidad a devolver mayor a la disponible")
+
+	// ErrReturnNotFound indica que no existe la solicitud de devolución buscada.
+	ErrReturnNotFound = errors.New("devolución no encontrada")
+
+	// ErrReturnAlreadyDecided indica que la devolución ya fue aprobada o rechazada.
+	ErrReturnAlreadyDecided = errors.New("la devolución ya fue decidida")
+
+	// =========================
+	// ERRORES DE PAGOS
+	// =========================
+
+	// ErrPaymentDeclined indica que el proveedor rechazó el pago.
+	ErrPaymentDeclined = errors.New("pago rechazado")
+
+	// ErrPaymentTimeout indica que el proveedor no respondió a tiempo.
+	// No se sabe si el pago se procesó, por eso no se confirma el pedido.
+	ErrPaymentTimeout = errors.New("tiempo de espera agotado con el proveedor de pagos")
+
+	// ErrPaymentActionRequired indica que el pago necesita un segundo paso
+	// (por ejemplo, un código de verificación) antes de autorizarse.
+	ErrPaymentActionRequired = errors.New("el pago requiere verificación adicional")
+
+	// ErrPaymentNotFound indica que el proveedor no conoce la autorización.
+	ErrPaymentNotFound = errors.New("pago no encontrado")
+
+	// ErrInvalidPaymentState indica una operación no permitida para el
+	// estado del pago (por ejemplo, capturar una autorización anulada).
+	ErrInvalidPaymentState = errors.New("operación de pago no permitida")
+
+	// =========================
+	// ERRORES DE IDEMPOTENCIA
+	// =========================
+
+	// ErrInvalidIdempotencyKey indica que la clave de idempotencia es
+	// demasiado larga o tiene caracteres no imprimibles.
+	ErrInvalidIdempotencyKey = errors.New("clave de idempotencia inválida")
+
+	// ErrIdempotencyKeyReused indica que la clave ya se usó para el
+	// checkout de otro cliente o de otro carrito.
+	ErrIdempotencyKeyReused = errors.New("la clave de idempotencia ya se usó con otro cliente o carrito")
+
+	// ErrCheckoutInProgress indica que todavía se está procesando un
+	// checkout con la misma clave de idempotencia.
+	ErrCheckoutInProgress = errors.New("hay un checkout en curso con la misma clave de idempotencia")
+
+	// =========================
+	// ERRORES DE USUARIOS
+	// =========================
+
+	// ErrInvalidUsername indica que el nombre de usuario no cumple el
+	// formato (3 a 32 caracteres: minúsculas, dígitos, '.', '_' o '-').
+	ErrInvalidUsername = errors.New("nombre de usuario inválido")
+
+	// ErrInvalidRole indica que el rol no existe.
+	ErrInvalidRole = errors.New("rol inválido")
+
+	// ErrWeakPassword indica que la contraseña es demasiado corta.
+	ErrWeakPassword = errors.New("contraseña demasiado corta")
+
+	// ErrUsernameTaken indica que ya existe un usuario con ese nombre.
+	ErrUsernameTaken = errors.New("el nombre de usuario ya existe")
+
+	// ErrUserNotFound indica que no existe un usuario con ese nombre.
+	ErrUserNotFound = errors.New("usuario no encontrado")
+
+	// ErrInvalidCredentials indica que el usuario o la contraseña no
+	// coinciden. No distingue cuál de los dos falló, a propósito.
+	ErrInvalidCredentials = errors.New("usuario o contraseña incorrectos")
+
+	// ErrUnauthenticated indica que la operación necesita una clave de
+	// API válida y no se indicó ninguna (o no corresponde a un usuario).
+	ErrUnauthenticated = errors.New("se requiere autenticación")
+
+	// ErrForbidden indica que el usuario no tiene permiso para la
+	// operación (por ejemplo, un cliente operando el carrito de otro).
+	ErrForbidden = errors.New("operación no permitida para el usuario")
+
+	// =========================
+	// ERRORES DE DINERO
+	// =========================
+
+	// ErrInvalidAmount indica que un monto no es un número válido
+	// o que no se puede repartir (por ejemplo, ratios inválidos).
+	ErrInvalidAmount = errors.New("monto inválido")
+
+	// ErrInvalidCurrency indica que el código de moneda no tiene
+	// el formato ISO 4217 (tres letras mayúsculas).
+	ErrInvalidCurrency = errors.New("moneda inválida")
+
+	// ErrCurrencyMismatch indica que se intentó operar con montos
+	// de monedas distintas (por ejemplo, sumar USD con EUR).
+	ErrCurrencyMismatch = errors.New("monedas distintas")
 )
+
+/*
+InsufficientStockError detalla un ErrNoStock sobre una línea del carrito.
+
+Por qué existe además de ErrNoStock:
+- Con solo "stock insuficiente" el cliente no sabe cuánto más puede pedir.
+- Este error informa cuántas unidades hay disponibles y cuántas ya tiene
+  en el carrito, para poder decirle cuántas más puede agregar.
+
+Compatibilidad:
+- errors.Is(err, ErrNoStock) sigue siendo true (ver Unwrap).
+*/
+type InsufficientStockError struct {
+	ProductID int // Producto sin stock suficiente
+	Requested int // Cantidad total que se pidió para la línea
+	InCart    int // Unidades que el cliente ya tenía en el carrito
+	Available int // Stock disponible para este cliente
+}
+
+// Error describe el faltante de stock.
+func (e *InsufficientStockError) Error() string {
+	return fmt.Sprintf("%v para el producto %d: pedidas %d, disponibles %d",
+		ErrNoStock, e.ProductID, e.Requested, e.Available)
+}
+
+// Unwrap permite que errors.Is(err, ErrNoStock) funcione.
+func (e *InsufficientStockError) Unwrap() error {
+	return ErrNoStock
+}
+
+/*
+CanAdd devuelve cuántas unidades más se pueden agregar a la línea
+sin superar el stock disponible (nunca negativo).
+*/
+func (e *InsufficientStockError) CanAdd() int {
+	return max(0, e.Available-e.InCart)
+}
+
+/*
+PriceChangedError detalla un ErrPriceChanged con la lista de líneas
+cuyo precio cambió, para que la capa de presentación pueda mostrar
+precio anterior y precio nuevo antes de pedir confirmación.
+
+errors.Is(err, ErrPriceChanged) sigue siendo true (ver Unwrap).
+*/
+type PriceChangedError struct {
+	Changes []PriceChange
+}
+
+// Error describe cuántas líneas cambiaron de precio.
+func (e *PriceChangedError) Error() string {
+	return fmt.Sprintf("%v (%d producto(s))", ErrPriceChanged, len(e.Changes))
+}
+
+// Unwrap permite que errors.Is(err, ErrPriceChanged) funcione.
+func (e *PriceChangedError) Unwrap() error {
+	return ErrPriceChanged
+}
@@ -0,0 +1,336 @@
+package domain
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+/*
+Money representa un monto de dinero exacto.
+
+Por qué no float64:
+- Los float64 no pueden representar exactamente valores como 0.10,
+  y al sumar muchos ítems los totales se desvían por centavos.
+- Money guarda el monto en unidades menores (centavos) como entero,
+  así la suma, resta y multiplicación son exactas.
+
+Reglas importantes:
+- Amount está expresado en unidades menores de la moneda
+  (por ejemplo, 1250 USD = $12.50).
+- Currency es un código ISO 4217 de tres letras mayúsculas (USD, EUR, ...).
+- Operar con monedas distintas es un error de dominio (ErrCurrencyMismatch),
+  nunca una suma silenciosa.
+*/
+type Money struct {
+	Amount   int64  // Monto en unidades menores (centavos)
+	Currency string // Código de moneda ISO 4217
+}
+
+// DefaultCurrency es la moneda que usa el sistema cuando no se indica otra.
+const DefaultCurrency = "USD"
+
+/*
+currencyDecimals indica cuántas unidades menores tiene cada moneda.
+
+Las monedas que no aparecen aquí usan 2 decimales, que es el caso más común.
+*/
+var currencyDecimals = map[string]int{
+	"CLP": 0,
+	"JPY": 0,
+	"KRW": 0,
+	"PYG": 0,
+}
+
+// currencySymbols define el símbolo usado al formatear algunas monedas conocidas.
+var currencySymbols = map[string]string{
+	"USD": "$",
+	"EUR": "€",
+	"GBP": "£",
+}
+
+/*
+NewMoney crea un Money a partir de un monto en unidades menores.
+
+Ejemplo: NewMoney(1250, "USD") representa $12.50.
+*/
+func NewMoney(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// ZeroMoney devuelve un monto cero en la moneda indicada.
+func ZeroMoney(currency string) Money {
+	return Money{Currency: currency}
+}
+
+/*
+ValidateCurrency valida que el código de moneda tenga el formato ISO 4217
+(tres letras mayúsculas).
+
+Nota:
+- No verifica contra la lista oficial completa de monedas; para este
+  proyecto alcanza con el formato.
+*/
+func ValidateCurrency(currency string) error {
+	if len(currency) != 3 {
+		return ErrInvalidCurrency
+	}
+	for _, ch := range currency {
+		if ch < 'A' || ch > 'Z' {
+			return ErrInvalidCurrency
+		}
+	}
+	return nil
+}
+
+// CurrencyDecimals devuelve la cantidad de decimales (unidades menores) de una moneda.
+func CurrencyDecimals(currency string) int {
+	if d, ok := currencyDecimals[currency]; ok {
+		return d
+	}
+	return 2
+}
+
+/*
+ParseMoney convierte un texto decimal (por ejemplo "12.50") en Money.
+
+Reglas:
+- Acepta signo opcional y separador decimal '.' o ','.
+- Si el texto trae más decimales que la moneda, se redondea
+  "half away from zero" (12.345 USD -> 12.35, -12.345 -> -12.35).
+- La conversión es exacta: nunca pasa por float64.
+
+Devuelve ErrInvalidAmount si el texto no es un número válido o si el
+monto (ya redondeado) no entra en int64.
+*/
+func ParseMoney(s string, currency string) (Money, error) {
+	if err := ValidateCurrency(currency); err != nil {
+		return Money{}, err
+	}
+
+	s = strings.TrimSpace(s)
+	negative := false
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		negative = s[0] == '-'
+		s = s[1:]
+	}
+
+	s = strings.Replace(s, ",", ".", 1)
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	if intPart == "" && fracPart == "" {
+		return Money{}, ErrInvalidAmount
+	}
+	if !isDigits(intPart) || !isDigits(fracPart) {
+		return Money{}, ErrInvalidAmount
+	}
+
+	decimals := CurrencyDecimals(currency)
+
+	// Se separan los dígitos que entran en la moneda y el dígito que decide el redondeo.
+	roundUp := false
+	if len(fracPart) > decimals {
+		roundUp = fracPart[decimals] >= '5'
+		fracPart = fracPart[:decimals]
+	}
+	fracPart += strings.Repeat("0", decimals-len(fracPart))
+
+	digits := intPart + fracPart
+	if digits == "" {
+		digits = "0"
+	}
+	amount, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return Money{}, ErrInvalidAmount
+	}
+	if roundUp {
+		// Redondear el máximo representable se saldría de int64.
+		if amount == math.MaxInt64 {
+			return Money{}, ErrInvalidAmount
+		}
+		amount++
+	}
+	if negative {
+		amount = -amount
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// isDigits indica si el texto contiene solo dígitos decimales (vacío es válido).
+func isDigits(s string) bool {
+	for _, ch := range s {
+		if ch < '0' || ch > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+/*
+AddMoney suma dos montos de la misma moneda.
+
+Devuelve ErrCurrencyMismatch si las monedas son distintas.
+*/
+func AddMoney(a, b Money) (Money, error) {
+	if a.Currency != b.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+	return Money{Amount: a.Amount + b.Amount, Currency: a.Currency}, nil
+}
+
+/*
+SubMoney resta b de a (a - b) si ambos montos tienen la misma moneda.
+
+Devuelve ErrCurrencyMismatch si las monedas son distintas.
+*/
+func SubMoney(a, b Money) (Money, error) {
+	if a.Currency != b.Currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+	return Money{Amount: a.Amount - b.Amount, Currency: a.Currency}, nil
+}
+
+/*
+MulMoney multiplica un monto por una cantidad entera.
+
+Es la operación que usa el carrito para calcular precio * cantidad;
+al ser entera, no necesita redondeo.
+*/
+func MulMoney(m Money, n int) Money {
+	return Money{Amount: m.Amount * int64(n), Currency: m.Currency}
+}
+
+/*
+CompareMoney compara dos montos de la misma moneda.
+
+Devuelve:
+- -1 si a < b
+-  0 si a == b
+- +1 si a > b
+
+Devuelve ErrCurrencyMismatch si las monedas son distintas.
+*/
+func CompareMoney(a, b Money) (int, error) {
+	if a.Currency != b.Currency {
+		return 0, ErrCurrencyMismatch
+	}
+	switch {
+	case a.Amount < b.Amount:
+		return -1, nil
+	case a.Amount > b.Amount:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+// IsPositive indica si el monto es mayor que cero.
+func IsPositive(m Money) bool {
+	return m.Amount > 0
+}
+
+// IsZeroMoney indica si el monto es exactamente cero.
+func IsZeroMoney(m Money) bool {
+	return m.Amount == 0
+}
+
+/*
+AllocateMoney reparte un monto en partes proporcionales a ratios
+sin perder ni crear centavos.
+
+Regla de redondeo:
+- Cada parte recibe la porción entera que le corresponde (truncada).
+- Los centavos sobrantes se entregan de a uno a las primeras partes.
+- La suma de las partes es SIEMPRE igual al monto original.
+
+Ejemplo: AllocateMoney($10.00, []int{1, 1, 1}) -> $3.34, $3.33, $3.33
+
+Devuelve ErrInvalidAmount si no hay ratios, alguno es negativo
+o todos suman cero.
+*/
+func AllocateMoney(m Money, ratios []int) ([]Money, error) {
+	totalRatio := 0
+	for _, r := range ratios {
+		if r < 0 {
+			return nil, ErrInvalidAmount
+		}
+		totalRatio += r
+	}
+	if totalRatio == 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	parts := make([]Money, len(ratios))
+	remainder := m.Amount
+	for i, r := range ratios {
+		share := m.Amount * int64(r) / int64(totalRatio)
+		parts[i] = Money{Amount: share, Currency: m.Currency}
+		remainder -= share
+	}
+
+	// El resto se reparte de a una unidad menor, respetando el signo del monto.
+	step := int64(1)
+	if remainder < 0 {
+		step = -1
+	}
+	for i := 0; remainder != 0; i = (i + 1) % len(parts) {
+		if ratios[i] == 0 {
+			continue
+		}
+		parts[i].Amount += step
+		remainder -= step
+	}
+	return parts, nil
+}
+
+/*
+FormatMoney devuelve el monto listo para mostrar.
+
+Formato:
+- Monedas con símbolo conocido: "$12.50", "-€3.00".
+- Resto de monedas: "12.50 ARS".
+*/
+func FormatMoney(m Money) string {
+	amount := FormatAmount(m)
+	sign := ""
+	if m.Amount < 0 {
+		sign = "-"
+		amount = amount[1:]
+	}
+
+	if symbol, ok := currencySymbols[m.Currency]; ok {
+		return sign + symbol + amount
+	}
+	return sign + amount + " " + m.Currency
+}
+
+/*
+FormatAmount devuelve solo el número, sin símbolo ni moneda: "12.50", "-3.00", "1500".
+
+Usa la cantidad de decimales de la moneda y es exacto (no pasa por
+float64); ParseMoney con la misma moneda devuelve el monto original.
+Pensado para formatos que leen otros programas (JSON, CSV).
+*/
+func FormatAmount(m Money) string {
+	decimals := CurrencyDecimals(m.Currency)
+
+	amount := m.Amount
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+
+	digits := strconv.FormatInt(amount, 10)
+	if decimals > 0 {
+		if len(digits) <= decimals {
+			digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+		}
+		digits = digits[:len(digits)-decimals] + "." + digits[len(digits)-decimals:]
+	}
+	return sign + digits
+}
+
+// String permite imprimir un Money directamente con fmt (%s, %v, Println).
+func (m Money) String() string {
+	return FormatMoney(m)
+}
@@ -0,0 +1,179 @@
+package domain_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		in       string
+		currency string
+		want     int64
+		err      error
+	}{
+		{"12.50", "USD", 1250, nil},
+		{"12,5", "USD", 1250, nil},
+		{"  +3  ", "USD", 300, nil},
+		{".5", "USD", 50, nil},
+		{"7.", "USD", 700, nil},
+		{"0", "USD", 0, nil},
+
+		// Redondeo "half away from zero".
+		{"12.345", "USD", 1235, nil},
+		{"12.344", "USD", 1234, nil},
+		{"12.3449", "USD", 1234, nil},
+		{"0.005", "USD", 1, nil},
+		{"0.0049", "USD", 0, nil},
+		{"-12.345", "USD", -1235, nil},
+		{"-12.344", "USD", -1234, nil},
+		{"9.995", "USD", 1000, nil},
+
+		// Monedas sin decimales.
+		{"1500", "JPY", 1500, nil},
+		{"1500.5", "JPY", 1501, nil},
+		{"1500.49", "JPY", 1500, nil},
+		{"-1500.5", "JPY", -1501, nil},
+		{"990,6", "CLP", 991, nil},
+
+		// Límites de int64.
+		{"92233720368547758.07", "USD", 9223372036854775807, nil},
+		{"-92233720368547758.07", "USD", -9223372036854775807, nil},
+		{"92233720368547758.074", "USD", 9223372036854775807, nil},
+		{"92233720368547758.075", "USD", 0, domain.ErrInvalidAmount},
+		{"-92233720368547758.075", "USD", 0, domain.ErrInvalidAmount},
+		{"92233720368547758.08", "USD", 0, domain.ErrInvalidAmount},
+		{"9223372036854775807.5", "JPY", 0, domain.ErrInvalidAmount},
+
+		// Textos inválidos.
+		{"", "USD", 0, domain.ErrInvalidAmount},
+		{"-", "USD", 0, domain.ErrInvalidAmount},
+		{".", "USD", 0, domain.ErrInvalidAmount},
+		{"abc", "USD", 0, domain.ErrInvalidAmount},
+		{"1.2.3", "USD", 0, domain.ErrInvalidAmount},
+		{"1e3", "USD", 0, domain.ErrInvalidAmount},
+		{"--1", "USD", 0, domain.ErrInvalidAmount},
+		{"1", "usd", 0, domain.ErrInvalidCurrency},
+	}
+	for _, tt := range tests {
+		got, err := domain.ParseMoney(tt.in, tt.currency)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("ParseMoney(%q, %s) error = %v, se esperaba %v", tt.in, tt.currency, err, tt.err)
+			continue
+		}
+		if err == nil && got != domain.NewMoney(tt.want, tt.currency) {
+			t.Errorf("ParseMoney(%q, %s) = %+v, se esperaba %d", tt.in, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestAllocateMoney(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount int64
+		ratios []int
+		want   []int64
+	}{
+		{"partes iguales", 1000, []int{1, 1, 1}, []int64{334, 333, 333}},
+		{"resto de dos", 1001, []int{1, 1, 1}, []int64{334, 334, 333}},
+		{"proporcional", 1000, []int{70, 30}, []int64{700, 300}},
+		{"proporcional con resto", 5, []int{3, 7}, []int64{2, 3}},
+		{"ratio cero no recibe resto", 100, []int{0, 1, 1, 1}, []int64{0, 34, 33, 33}},
+		{"negativo", -1000, []int{1, 1, 1}, []int64{-334, -333, -333}},
+		{"negativo con resto de dos", -1001, []int{1, 1, 1}, []int64{-334, -334, -333}},
+		{"negativo con ratio cero", -100, []int{1, 0, 2}, []int64{-34, 0, -66}},
+		{"cero", 0, []int{1, 2}, []int64{0, 0}},
+		{"una sola parte", 999, []int{5}, []int64{999}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parts, err := domain.AllocateMoney(domain.NewMoney(tt.amount, "USD"), tt.ratios)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := make([]int64, len(parts))
+			var sum int64
+			for i, p := range parts {
+				if p.Currency != "USD" {
+					t.Errorf("parte %d en %s, se esperaba USD", i, p.Currency)
+				}
+				got[i] = p.Amount
+				sum += p.Amount
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("AllocateMoney(%d, %v) = %v, se esperaba %v", tt.amount, tt.ratios, got, tt.want)
+			}
+			if sum != tt.amount {
+				t.Errorf("las partes suman %d, se esperaba %d", sum, tt.amount)
+			}
+		})
+	}
+
+	for _, ratios := range [][]int{nil, {}, {0, 0}, {1, -1}} {
+		if _, err := domain.AllocateMoney(domain.NewMoney(100, "USD"), ratios); !errors.Is(err, domain.ErrInvalidAmount) {
+			t.Errorf("AllocateMoney(%v) error = %v, se esperaba %v", ratios, err, domain.ErrInvalidAmount)
+		}
+	}
+}
+
+// Sumar, restar o comparar monedas distintas es un error, nunca un resultado.
+func TestMoneyCurrencyMismatch(t *testing.T) {
+	usd := domain.NewMoney(1000, "USD")
+	eur := domain.NewMoney(1000, "EUR")
+
+	if got, err := domain.AddMoney(usd, eur); !errors.Is(err, domain.ErrCurrencyMismatch) || got != (domain.Money{}) {
+		t.Errorf("AddMoney = %+v, %v; se esperaba %v", got, err, domain.ErrCurrencyMismatch)
+	}
+	if got, err := domain.SubMoney(usd, eur); !errors.Is(err, domain.ErrCurrencyMismatch) || got != (domain.Money{}) {
+		t.Errorf("SubMoney = %+v, %v; se esperaba %v", got, err, domain.ErrCurrencyMismatch)
+	}
+	if _, err := domain.CompareMoney(usd, eur); !errors.Is(err, domain.ErrCurrencyMismatch) {
+		t.Errorf("CompareMoney error = %v, se esperaba %v", err, domain.ErrCurrencyMismatch)
+	}
+
+	if got, err := domain.AddMoney(usd, domain.NewMoney(250, "USD")); err != nil || got != domain.NewMoney(1250, "USD") {
+		t.Errorf("AddMoney = %+v, %v; se esperaba $12.50", got, err)
+	}
+	if got, err := domain.SubMoney(usd, domain.NewMoney(1250, "USD")); err != nil || got != domain.NewMoney(-250, "USD") {
+		t.Errorf("SubMoney = %+v, %v; se esperaba -$2.50", got, err)
+	}
+}
+
+func TestFormatMoney(t *testing.T) {
+	tests := []struct {
+		money  domain.Money
+		format string
+		amount string
+	}{
+		{domain.NewMoney(1250, "USD"), "$12.50", "12.50"},
+		{domain.NewMoney(-300, "EUR"), "-€3.00", "-3.00"},
+		{domain.NewMoney(5, "GBP"), "£0.05", "0.05"},
+		{domain.NewMoney(-5, "USD"), "-$0.05", "-0.05"},
+		{domain.NewMoney(0, "USD"), "$0.00", "0.00"},
+		{domain.NewMoney(150000, "ARS"), "1500.00 ARS", "1500.00"},
+		{domain.NewMoney(-1, "ARS"), "-0.01 ARS", "-0.01"},
+		{domain.NewMoney(1500, "JPY"), "1500 JPY", "1500"},
+		{domain.NewMoney(-1500, "JPY"), "-1500 JPY", "-1500"},
+		{domain.NewMoney(0, "CLP"), "0 CLP", "0"},
+	}
+	for _, tt := range tests {
+		if got := domain.FormatMoney(tt.money); got != tt.format {
+			t.Errorf("FormatMoney(%+v) = %q, se esperaba %q", tt.money, got, tt.format)
+		}
+		if got := tt.money.String(); got != tt.format {
+			t.Errorf("String(%+v) = %q, se esperaba %q", tt.money, got, tt.format)
+		}
+		got := domain.FormatAmount(tt.money)
+		if got != tt.amount {
+			t.Errorf("FormatAmount(%+v) = %q, se esperaba %q", tt.money, got, tt.amount)
+		}
+
+		// FormatAmount y ParseMoney son inversas.
+		if back, err := domain.ParseMoney(got, tt.money.Currency); err != nil || back != tt.money {
+			t.Errorf("ParseMoney(FormatAmount(%+v)) = %+v, %v", tt.money, back, err)
+		}
+	}
+}
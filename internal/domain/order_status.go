@@ -0,0 +1,138 @@
+package domain
+
+import "time"
+
+/*
+OrderStatus representa el estado de un pedido dentro de su ciclo de vida.
+
+Ciclo de vida permitido:
+
+	pending ──► paid ──► shipped ──► delivered
+	   │          │
+	   └──────────┴──► cancelled
+
+- delivered y cancelled son estados finales: no admiten más cambios.
+- Un pedido enviado (shipped) ya no puede cancelarse.
+*/
+type OrderStatus string
+
+const (
+	OrderPending   OrderStatus = "pending"   // Creado en el checkout, pendiente de pago
+	OrderPaid      OrderStatus = "paid"      // Pago confirmado
+	OrderShipped   OrderStatus = "shipped"   // Entregado al transporte
+	OrderDelivered OrderStatus = "delivered" // Recibido por el cliente
+	OrderCancelled OrderStatus = "cancelled" // Anulado antes del envío
+)
+
+/*
+orderTransitions define, para cada estado, a qué estados se puede pasar.
+
+Es la única fuente de verdad de la máquina de estados: CanTransition
+y TransitionOrder la consultan en vez de repetir condiciones.
+*/
+var orderTransitions = map[OrderStatus][]OrderStatus{
+	OrderPending:   {OrderPaid, OrderCancelled},
+	OrderPaid:      {OrderShipped, OrderCancelled},
+	OrderShipped:   {OrderDelivered},
+	OrderDelivered: {},
+	OrderCancelled: {},
+}
+
+/*
+StatusChange registra un cambio de estado y cuándo ocurrió.
+
+El primer registro de cada pedido tiene From vacío (creación del pedido).
+*/
+type StatusChange struct {
+	From OrderStatus // Estado anterior ("" en la creación)
+	To   OrderStatus // Estado nuevo
+	At   time.Time   // Momento del cambio
+}
+
+/*
+OrderLifecycle agrupa el estado actual de un pedido y su historial.
+
+Reglas importantes:
+- Status siempre coincide con el To del último StatusChange.
+- El historial solo crece: nunca se borran registros.
+*/
+type OrderLifecycle struct {
+	Status  OrderStatus    // Estado actual
+	History []StatusChange // Cambios de estado, del más antiguo al más nuevo
+}
+
+/*
+NewOrderLifecycle crea el ciclo de vida de un pedido recién confirmado.
+
+El pedido nace en estado pending, con un único registro de creación en at.
+*/
+func NewOrderLifecycle(at time.Time) OrderLifecycle {
+	return OrderLifecycle{
+		Status:  OrderPending,
+		History: []StatusChange{{To: OrderPending, At: at}},
+	}
+}
+
+/*
+ValidateOrderStatus valida que el estado sea uno de los definidos.
+
+Devuelve ErrInvalidOrderStatus si no lo es.
+*/
+func ValidateOrderStatus(s OrderStatus) error {
+	if _, ok := orderTransitions[s]; !ok {
+		return ErrInvalidOrderStatus
+	}
+	return nil
+}
+
+// CanTransition indica si un pedido puede pasar del estado from al estado to.
+func CanTransition(from, to OrderStatus) bool {
+	for _, next := range orderTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+TransitionOrder cambia el estado de un pedido.
+
+Comportamiento:
+- Si el estado destino no existe, devuelve ErrInvalidOrderStatus.
+- Si la transición no está permitida, devuelve ErrInvalidStatusTransition.
+- Si es válida, registra el cambio con su fecha en el historial.
+
+Diseño:
+- No modifica el ciclo de vida original (estilo funcional, igual que AddItem).
+- Devuelve una nueva versión con el estado y el historial actualizados.
+*/
+func TransitionOrder(lc OrderLifecycle, to OrderStatus, at time.Time) (OrderLifecycle, error) {
+	if err := ValidateOrderStatus(to); err != nil {
+		return lc, err
+	}
+	if !CanTransition(lc.Status, to) {
+		return lc, ErrInvalidStatusTransition
+	}
+
+	// Nuevo slice para no compartir memoria con el historial original.
+	history := make([]StatusChange, 0, len(lc.History)+1)
+	history = append(history, lc.History...)
+	history = append(history, StatusChange{From: lc.Status, To: to, At: at})
+
+	return OrderLifecycle{Status: to, History: history}, nil
+}
+
+/*
+StatusChangedAt devuelve cuándo el pedido llegó al estado indicado.
+
+El segundo valor es false si el pedido nunca pasó por ese estado.
+*/
+func StatusChangedAt(lc OrderLifecycle, status OrderStatus) (time.Time, bool) {
+	for _, ch := range lc.History {
+		if ch.To == status {
+			return ch.At, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,44 @@
+package domain
+
+/*
+PriceChange describe una línea del carrito cuyo precio guardado (snapshot)
+ya no coincide con el precio actual del producto.
+*/
+type PriceChange struct {
+	ProductID int    // Producto afectado
+	Name      string // Nombre actual del producto
+	OldPrice  Money  // Precio guardado en el carrito al agregarlo
+	NewPrice  Money  // Precio actual del producto
+	Quantity  int    // Cantidad de la línea (para mostrar el impacto en el total)
+}
+
+/*
+DetectPriceChange compara una línea del carrito con el producto actual.
+
+Devuelve el cambio y true si el precio (monto o moneda) es distinto;
+si el precio no cambió, devuelve false.
+*/
+func DetectPriceChange(item CartItem, p Product) (PriceChange, bool) {
+	if item.Price == p.Price {
+		return PriceChange{}, false
+	}
+	return PriceChange{
+		ProductID: item.ProductID,
+		Name:      p.Name,
+		OldPrice:  item.Price,
+		NewPrice:  p.Price,
+		Quantity:  item.Quantity,
+	}, true
+}
+
+/*
+RepriceItem actualiza el snapshot de una línea con los datos actuales
+del producto (precio y nombre), conservando la cantidad.
+
+Devuelve una nueva línea; no modifica la original.
+*/
+func RepriceItem(item CartItem, p Product) CartItem {
+	item.Name = p.Name
+	item.Price = p.Price
+	return item
+}
@@ -0,0 +1,55 @@
+package domain_test
+
+import (
+	"testing"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+)
+
+func TestDetectPriceChange(t *testing.T) {
+	item := domain.CartItem{ProductID: 1, Name: "Lápiz", Price: domain.NewMoney(1000, "USD"), Quantity: 3}
+
+	tests := []struct {
+		name    string
+		price   domain.Money
+		changed bool
+	}{
+		{"mismo precio", domain.NewMoney(1000, "USD"), false},
+		{"sube", domain.NewMoney(1500, "USD"), true},
+		{"baja", domain.NewMoney(900, "USD"), true},
+		{"mismo monto en otra moneda", domain.NewMoney(1000, "EUR"), true},
+	}
+	for _, tt := range tests {
+		p := domain.Product{ID: 1, Name: "Lápiz HB", Price: tt.price, Stock: 5}
+		change, changed := domain.DetectPriceChange(item, p)
+		if changed != tt.changed {
+			t.Errorf("%s: changed = %v, se esperaba %v", tt.name, changed, tt.changed)
+			continue
+		}
+		want := domain.PriceChange{}
+		if tt.changed {
+			want = domain.PriceChange{ProductID: 1, Name: "Lápiz HB", OldPrice: item.Price, NewPrice: tt.price, Quantity: 3}
+		}
+		if change != want {
+			t.Errorf("%s: %+v, se esperaba %+v", tt.name, change, want)
+		}
+	}
+}
+
+// RepriceItem toma el precio y el nombre del producto, conserva la cantidad y no modifica la línea original.
+func TestRepriceItem(t *testing.T) {
+	item := domain.CartItem{ProductID: 1, Name: "Lápiz", Price: domain.NewMoney(1000, "USD"), Quantity: 3}
+	p := domain.Product{ID: 1, Name: "Lápiz HB", Price: domain.NewMoney(1500, "USD"), Stock: 5}
+
+	got := domain.RepriceItem(item, p)
+	want := domain.CartItem{ProductID: 1, Name: "Lápiz HB", Price: domain.NewMoney(1500, "USD"), Quantity: 3}
+	if got != want {
+		t.Errorf("RepriceItem = %+v, se esperaba %+v", got, want)
+	}
+	if item.Price.Amount != 1000 || item.Name != "Lápiz" {
+		t.Errorf("la línea original cambió: %+v", item)
+	}
+	if _, changed := domain.DetectPriceChange(got, p); changed {
+		t.Error("después de RepriceItem todavía se detecta un cambio de precio")
+	}
+}
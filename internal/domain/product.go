@@ -9,10 +9,11 @@ Es una entidad de dominio:
 - Contiene solo los datos esenciales del producto.
 */
 type Product struct {
-	ID    int     // Identificador único del producto
-	Name  string  // Nombre del producto
-	Price float64 // Precio unitario del producto
-	Stock int     // Cantidad disponible en inventario
+	ID      int    // Identificador único del producto
+	Name    string // Nombre del producto
+	Price   Money  // Precio unitario del producto
+	Stock   int    // Cantidad disponible en inventario
+	Version int    // Versión guardada, para detectar escrituras concurrentes
 }
 
 /*
@@ -25,7 +26,7 @@ Responsabilidad:
 Reglas de dominio:
 - El ID debe ser mayor que 0.
 - El nombre no puede estar vacío.
-- El precio debe ser mayor que 0.
+- El precio debe tener una moneda válida y ser mayor que 0.
 - El stock no puede ser negativo.
 
 Nota:
@@ -39,7 +40,10 @@ func ValidateProduct(p Product) error {
 	if p.Name == "" {
 		return ErrEmptyName
 	}
-	if p.Price <= 0 {
+	if err := ValidateCurrency(p.Price.Currency); err != nil {
+		return err
+	}
+	if !IsPositive(p.Price) {
 		return ErrInvalidPrice
 	}
 	if p.Stock < 0 {
@@ -47,3 +51,22 @@ func ValidateProduct(p Product) error {
 	}
 	return nil
 }
+
+/*
+Restock devuelve unidades al inventario de un producto.
+
+Uso típico:
+- Cancelación de un pedido o devolución de mercadería.
+
+Comportamiento:
+- Si la cantidad es inválida (<= 0), retorna ErrInvalidQuantity.
+- Devuelve una nueva versión del producto con el stock incrementado
+  (no modifica el original, mismo estilo que AddItem).
+*/
+func Restock(p Product, quantity int) (Product, error) {
+	if quantity <= 0 {
+		return p, ErrInvalidQuantity
+	}
+	p.Stock += quantity
+	return p, nil
+}
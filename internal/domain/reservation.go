@@ -0,0 +1,50 @@
+package domain
+
+import "time"
+
+/*
+Reservation representa stock apartado por un cliente mientras el
+producto está en su carrito.
+
+Reglas importantes:
+- Hay como máximo una reserva por cliente y producto; su cantidad
+  acompaña a la cantidad de la línea del carrito.
+- Una reserva vence en ExpiresAt: a partir de ese momento deja de
+  descontarse del stock disponible para los demás clientes.
+- Reservar no modifica Product.Stock; el stock solo baja en el checkout.
+*/
+type Reservation struct {
+	CustomerID int       // Cliente dueño de la reserva
+	ProductID  int       // Producto reservado
+	Quantity   int       // Unidades apartadas
+	ExpiresAt  time.Time // Vencimiento de la reserva
+}
+
+// IsReservationActive indica si la reserva sigue vigente en el momento now.
+func IsReservationActive(r Reservation, now time.Time) bool {
+	return now.Before(r.ExpiresAt)
+}
+
+/*
+AvailableStock calcula el stock que todavía se puede vender o reservar.
+
+Regla:
+- disponible = stock en depósito - reservas activas del producto.
+- Nunca es negativo.
+
+Nota:
+- reservations debería contener solo las reservas que deben descontarse
+  (por ejemplo, las de OTROS clientes al validar el carrito de uno).
+*/
+func AvailableStock(p Product, reservations []Reservation, now time.Time) int {
+	available := p.Stock
+	for _, r := range reservations {
+		if r.ProductID == p.ID && IsReservationActive(r, now) {
+			available -= r.Quantity
+		}
+	}
+	if available < 0 {
+		return 0
+	}
+	return available
+}
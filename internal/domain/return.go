@@ -0,0 +1,55 @@
+package domain
+
+/*
+ReturnStatus representa el estado de una solicitud de devolución (RMA).
+
+Ciclo de vida:
+
+	requested ──► approved
+	    │
+	    └───────► rejected
+
+- Una solicitud se decide una sola vez: approved y rejected son finales.
+*/
+type ReturnStatus string
+
+const (
+	ReturnRequested ReturnStatus = "requested" // Pedida por el cliente, sin decidir
+	ReturnApproved  ReturnStatus = "approved"  // Aceptada: se reembolsa (y opcionalmente se repone stock)
+	ReturnRejected  ReturnStatus = "rejected"  // Rechazada: no hay reembolso
+)
+
+/*
+DecideReturn valida que una devolución pueda pasar de from a to.
+
+Reglas:
+- Solo se decide una solicitud en estado requested.
+- La decisión solo puede ser approved o rejected.
+
+Devuelve ErrReturnAlreadyDecided o ErrInvalidStatusTransition según el caso.
+*/
+func DecideReturn(from, to ReturnStatus) error {
+	if from != ReturnRequested {
+		return ErrReturnAlreadyDecided
+	}
+	if to != ReturnApproved && to != ReturnRejected {
+		return ErrInvalidStatusTransition
+	}
+	return nil
+}
+
+/*
+ReturnableQuantity calcula cuántas unidades de una línea todavía
+se pueden devolver.
+
+Regla:
+- purchased: unidades compradas en el pedido.
+- alreadyReturned: unidades en devoluciones aprobadas o pendientes
+  (las pendientes cuentan para que no se pidan dos veces las mismas).
+*/
+func ReturnableQuantity(purchased, alreadyReturned int) int {
+	if alreadyReturned >= purchased {
+		return 0
+	}
+	return purchased - alreadyReturned
+}
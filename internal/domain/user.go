@@ -0,0 +1,134 @@
+package domain
+
+/*
+Role es el rol de un usuario: define qué operaciones puede hacer.
+
+- RoleAdmin: todo, incluida la gestión de usuarios.
+- RoleStaff: catálogo, clientes, pedidos y carritos de cualquier cliente.
+- RoleCustomer: solo su propio carrito, sus pedidos y sus devoluciones.
+*/
+type Role string
+
+const (
+	RoleAdmin    Role = "admin"
+	RoleStaff    Role = "staff"
+	RoleCustomer Role = "customer"
+)
+
+/*
+User es una cuenta para entrar al sistema (CLI o API).
+
+Los secretos nunca se guardan en claro:
+- PasswordHash es el resultado de hashear la contraseña con sal.
+- APIKeyHash es el hash de la clave de API vigente (vacío si no tiene).
+*/
+type User struct {
+	Username     string // Nombre de usuario (único)
+	Role         Role   // Rol del usuario
+	CustomerID   int    // Cliente asociado (solo con RoleCustomer)
+	PasswordHash string // Hash de la contraseña
+	APIKeyHash   string // Hash de la clave de API vigente
+	Version      int    // Versión guardada, para detectar escrituras concurrentes
+}
+
+// MinPasswordLen es el largo mínimo de una contraseña.
+const MinPasswordLen = 8
+
+/*
+ValidateUser valida las reglas básicas del dominio para un usuario.
+
+Reglas aplicadas:
+- El nombre de usuario tiene entre 3 y 32 caracteres: letras minúsculas,
+  dígitos, '.', '_' o '-'.
+- El rol es uno de los conocidos.
+- Un usuario con RoleCustomer está asociado a un cliente (CustomerID > 0);
+  los demás roles no lo están.
+
+Nota:
+- La contraseña se valida antes de hashearla (ver ValidatePassword).
+*/
+func ValidateUser(u User) error {
+	if !isValidUsername(u.Username) {
+		return ErrInvalidUsername
+	}
+	switch u.Role {
+	case RoleAdmin, RoleStaff:
+		if u.CustomerID != 0 {
+			return ErrInvalidCustomerID
+		}
+	case RoleCustomer:
+		if u.CustomerID <= 0 {
+			return ErrInvalidCustomerID
+		}
+	default:
+		return ErrInvalidRole
+	}
+	return nil
+}
+
+// ValidatePassword valida que la contraseña tenga al menos MinPasswordLen caracteres.
+func ValidatePassword(password string) error {
+	if len([]rune(password)) < MinPasswordLen {
+		return ErrWeakPassword
+	}
+	return nil
+}
+
+// isValidUsername valida el largo y los caracteres de un nombre de usuario.
+func isValidUsername(name string) bool {
+	if len(name) < 3 || len(name) > 32 {
+		return false
+	}
+	for _, ch := range name {
+		switch {
+		case ch >= 'a' && ch <= 'z', ch >= '0' && ch <= '9', ch == '.', ch == '_', ch == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+/*
+Reglas de autorización.
+
+Las usan los adaptadores de entrada (CLI, API) antes de llamar a los
+casos de uso: los casos de uso no saben quién los llama. Todas devuelven
+ErrForbidden si el usuario no tiene permiso.
+*/
+
+// IsStaff indica si el rol administra la tienda (admin o staff).
+func (r Role) IsStaff() bool {
+	return r == RoleAdmin || r == RoleStaff
+}
+
+// AuthorizeStaff permite la operación solo a admin y staff (catálogo, clientes, pedidos).
+func AuthorizeStaff(u User) error {
+	if !u.Role.IsStaff() {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// AuthorizeAdmin permite la operación solo a admin (gestión de usuarios).
+func AuthorizeAdmin(u User) error {
+	if u.Role != RoleAdmin {
+		return ErrForbidden
+	}
+	return nil
+}
+
+/*
+AuthorizeCustomer permite operar sobre los datos de un cliente (carrito,
+checkout, pedidos): a admin y staff siempre, y a un usuario con
+RoleCustomer solo si es su propio cliente.
+*/
+func AuthorizeCustomer(u User, customerID int) error {
+	if u.Role.IsStaff() {
+		return nil
+	}
+	if u.Role == RoleCustomer && u.CustomerID == customerID {
+		return nil
+	}
+	return ErrForbidden
+}
@@ -1,6 +1,10 @@
 package usecase
 
-import "github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+import (
+	"time"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+)
 
 /*
 CartRepository define el contrato que necesita la capa de casos de uso
@@ -13,13 +17,15 @@ Principio (arquitectura limpia):
 */
 type CartRepository interface {
 	// Get devuelve el carrito del cliente. Si no existe, típicamente devuelve uno vacío.
-	Get(customerID int) domain.Cart
+	// Devuelve error solo si falla el almacenamiento.
+	Get(customerID int) (domain.Cart, error)
 
 	// Save persiste el carrito (estado actual) del cliente.
-	Save(cart domain.Cart)
+	// Devuelve error si el almacenamiento falla (por ejemplo, al escribir a disco).
+	Save(cart domain.Cart) error
 
 	// Clear elimina/vacía el carrito del cliente.
-	Clear(customerID int)
+	Clear(customerID int) error
 }
 
 /*
@@ -43,69 +49,245 @@ Responsabilidad:
 - Devolver el carrito actual del cliente.
 - No aplica reglas complejas: solo delega al repositorio.
 */
-func ViewCart(cartRepo CartRepository, customerID int) domain.Cart {
+func ViewCart(cartRepo CartRepository, customerID int) (domain.Cart, error) {
 	return cartRepo.Get(customerID)
 }
 
+/*
+CartDeps agrupa las dependencias de los casos de uso que modifican el carrito.
+
+Además del carrito y los productos, el carrito administra reservas de stock:
+mientras un producto está en el carrito, sus unidades quedan apartadas
+para ese cliente durante ReservationTTL.
+
+Los casos de uso que cambian el carrito leen, validan y guardan dentro
+de UnitOfWork (con sus repositorios): el stock que se valida es el que
+se reserva, aunque otros clientes agreguen el mismo producto a la vez.
+
+Dependencias opcionales:
+- ReservationTTL: si es 0 se usa DefaultReservationTTL.
+- Clock: si es nil se usa la hora del sistema.
+*/
+type CartDeps struct {
+	UnitOfWork     UnitOfWork
+	Carts          CartRepository
+	Products       ProductRepositoryForCart
+	Reservations   ReservationRepository
+	ReservationTTL time.Duration
+	Clock          Clock
+}
+
+// reservationTTL devuelve la duración configurada o la duración por defecto.
+func (d CartDeps) reservationTTL() time.Duration {
+	if d.ReservationTTL <= 0 {
+		return DefaultReservationTTL
+	}
+	return d.ReservationTTL
+}
+
 /*
 AddProductToCart es un caso de uso de comando (modifica estado).
 
 Responsabilidad:
-- Validar que el producto exista.
+- Validar que el cliente y el producto existan.
 - Validar cantidad.
-- Validar stock suficiente.
+- Validar que la línea COMPLETA (lo que ya había en el carrito más lo
+  que se agrega) no supere el stock disponible para el cliente
+  (stock en depósito menos reservas activas de otros clientes).
 - Agregar/actualizar el item en el carrito.
 - Persistir el carrito actualizado.
+- Reservar las unidades de la línea por deps.ReservationTTL.
 
 Nota de diseño:
 - Aquí se valida stock y cantidad (reglas del negocio en capa aplicación + dominio).
-- No se descuenta stock del producto todavía (eso suele hacerse en "checkout").
+- No se descuenta stock del producto todavía (eso se hace en "checkout");
+  la reserva evita que otro cliente se lleve esas unidades mientras tanto.
+- Todo ocurre dentro de deps.UnitOfWork: la validación de stock y la
+  reserva no pueden intercalarse con las de otro cliente, y si falla el
+  guardado del carrito tampoco queda la reserva.
+- Cada vez que se agrega el producto, la reserva se renueva por otro TTL.
+- Si falta stock, se devuelve *domain.InsufficientStockError, que indica
+  cuántas unidades más se pueden agregar (errors.Is con ErrNoStock sigue funcionando).
+- Si otra operación guardó el carrito entre la lectura y el guardado
+  (domain.ErrConcurrentModification), se reintenta desde el paso 1.
 */
-func AddProductToCart(
-	cartRepo CartRepository,
-	productRepo ProductRepositoryForCart,
-	customerID int,
-	productID int,
-	quantity int,
-) (domain.Cart, error) {
-
-	// 1) Obtener el producto para validar que existe y consultar stock/precio.
-	p, err := productRepo.GetByID(productID)
+func AddProductToCart(deps CartDeps, customerID int, productID int, quantity int) (domain.Cart, error) {
+	return retryOnConflict(func() (domain.Cart, error) {
+		return addProductToCart(deps, customerID, productID, quantity)
+	})
+}
+
+// addProductToCart es un intento de AddProductToCart.
+func addProductToCart(deps CartDeps, customerID int, productID int, quantity int) (domain.Cart, error) {
+	var cart domain.Cart
+	err := deps.UnitOfWork.Do(func(tx Repositories) error {
+
+		// 1) Validar que el cliente exista: el carrito (y la reserva) son suyos.
+		if _, err := GetCustomer(tx.Customers, customerID); err != nil {
+			return err
+		}
+
+		// 2) Obtener el producto para validar que existe y consultar stock/precio.
+		p, err := GetProduct(tx.Products, productID)
+		if err != nil {
+			// Si el repo no encuentra el producto, propagamos el error.
+			return err
+		}
+
+		// 3) Validación de cantidad a nivel de caso de uso (más cerca de la entrada).
+		if quantity <= 0 {
+			return domain.ErrInvalidQuantity
+		}
+
+		// 4) Obtener el carrito actual del cliente.
+		cart, err = tx.Carts.Get(customerID)
+		if err != nil {
+			return err
+		}
+
+		// 5) Validación de stock sobre la cantidad acumulada de la línea.
+		now := clockOrSystem(deps.Clock).Now()
+		inCart := domain.ItemQuantity(cart, productID)
+		if err := checkCartStock(tx.Reservations, p, customerID, inCart, inCart+quantity, now); err != nil {
+			return err
+		}
+
+		// 6) Aplicar la regla de dominio: agregar item (o acumular si ya existía).
+		cart, err = domain.AddItem(cart, domain.CartItem{
+			ProductID: p.ID,
+			Name:      p.Name,
+			Price:     p.Price,
+			Quantity:  quantity,
+		})
+		if err != nil {
+			// Por ejemplo: ErrInvalidQuantity (aunque ya validamos antes).
+			return err
+		}
+
+		// 7) Persistir el carrito y, ya guardado, reservar la línea completa.
+		if err := tx.Carts.Save(cart); err != nil {
+			return err
+		}
+		return reserveLine(deps, tx.Reservations, cart, productID, now)
+	})
 	if err != nil {
-		// Si el repo no encuentra el producto, propagamos el error.
 		return domain.Cart{}, err
 	}
+	return cart, nil
+}
 
-	// 2) Validación de cantidad a nivel de caso de uso (más cerca de la entrada).
-	if quantity <= 0 {
+/*
+SetCartItemQuantity fija la cantidad de un producto en el carrito.
+
+Diferencia con AddProductToCart:
+- AddProductToCart acumula sobre lo que ya había.
+- SetCartItemQuantity reemplaza: la línea queda exactamente con quantity.
+
+Comportamiento:
+- quantity == 0 quita el producto (igual que RemoveProductFromCart).
+- quantity < 0 devuelve domain.ErrInvalidQuantity.
+- Si el producto no estaba en el carrito, se agrega.
+- Valida que quantity no supere el stock disponible para el cliente
+  y renueva la reserva con la nueva cantidad.
+*/
+func SetCartItemQuantity(deps CartDeps, customerID int, productID int, quantity int) (domain.Cart, error) {
+	return retryOnConflict(func() (domain.Cart, error) {
+		return setCartItemQuantity(deps, customerID, productID, quantity)
+	})
+}
+
+// setCartItemQuantity es un intento de SetCartItemQuantity.
+func setCartItemQuantity(deps CartDeps, customerID int, productID int, quantity int) (domain.Cart, error) {
+	if quantity < 0 {
 		return domain.Cart{}, domain.ErrInvalidQuantity
 	}
-
-	// 3) Validación de stock antes de permitir agregar al carrito.
-	if p.Stock < quantity {
-		return domain.Cart{}, domain.ErrNoStock
+	if quantity == 0 {
+		return removeProductFromCart(deps, customerID, productID)
 	}
 
-	// 4) Obtener el carrito actual del cliente.
-	cart := cartRepo.Get(customerID)
+	var cart domain.Cart
+	err := deps.UnitOfWork.Do(func(tx Repositories) error {
+		if _, err := GetCustomer(tx.Customers, customerID); err != nil {
+			return err
+		}
+		p, err := GetProduct(tx.Products, productID)
+		if err != nil {
+			return err
+		}
+
+		cart, err = tx.Carts.Get(customerID)
+		if err != nil {
+			return err
+		}
+		now := clockOrSystem(deps.Clock).Now()
+		inCart := domain.ItemQuantity(cart, productID)
+		if err := checkCartStock(tx.Reservations, p, customerID, inCart, quantity, now); err != nil {
+			return err
+		}
 
-	// 5) Aplicar la regla de dominio: agregar item (o acumular si ya existía).
-	cart, err = domain.AddItem(cart, domain.CartItem{
-		ProductID: p.ID,
-		Name:      p.Name,
-		Price:     p.Price,
-		Quantity:  quantity,
+		cart, err = domain.SetItemQuantity(cart, domain.CartItem{
+			ProductID: p.ID,
+			Name:      p.Name,
+			Price:     p.Price,
+			Quantity:  quantity,
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Carts.Save(cart); err != nil {
+			return err
+		}
+		return reserveLine(deps, tx.Reservations, cart, productID, now)
 	})
 	if err != nil {
-		// Por ejemplo: ErrInvalidQuantity (aunque ya validamos antes).
 		return domain.Cart{}, err
 	}
-
-	// 6) Persistir el carrito actualizado.
-	cartRepo.Save(cart)
 	return cart, nil
 }
 
+/*
+checkCartStock valida que una línea del carrito pueda quedar con
+lineQuantity unidades.
+
+Devuelve *domain.InsufficientStockError con lo disponible y lo que
+ya estaba en el carrito, para que la capa de presentación pueda
+informar cuántas unidades más se pueden agregar.
+*/
+func checkCartStock(reservations ReservationRepository, p domain.Product, customerID int, inCart int, lineQuantity int, now time.Time) error {
+	available, err := availableStockFor(reservations, p, customerID, now)
+	if err != nil {
+		return err
+	}
+	if lineQuantity > available {
+		return &domain.InsufficientStockError{
+			ProductID: p.ID,
+			Requested: lineQuantity,
+			InCart:    inCart,
+			Available: available,
+		}
+	}
+	return nil
+}
+
+/*
+reserveLine guarda (o renueva) en reservations la reserva de una línea
+del carrito con la cantidad total de esa línea.
+*/
+func reserveLine(deps CartDeps, reservations ReservationRepository, cart domain.Cart, productID int, now time.Time) error {
+	for _, it := range cart.Items {
+		if it.ProductID == productID {
+			return reservations.Save(domain.Reservation{
+				CustomerID: cart.CustomerID,
+				ProductID:  productID,
+				Quantity:   it.Quantity,
+				ExpiresAt:  now.Add(deps.reservationTTL()),
+			})
+		}
+	}
+	return nil
+}
+
 /*
 RemoveProductFromCart es un caso de uso de comando.
 
@@ -113,16 +295,37 @@ Responsabilidad:
 - Obtener el carrito.
 - Remover el producto por ID (operación idempotente).
 - Guardar el carrito actualizado.
+- Liberar la reserva de stock de ese producto.
 
 Nota:
-- No retorna error porque remover un producto inexistente no es un fallo;
-  simplemente no cambia el carrito.
+- Remover un producto inexistente no es un fallo; simplemente no cambia
+  el carrito. Solo devuelve error si no se pudo guardar el carrito.
 */
-func RemoveProductFromCart(cartRepo CartRepository, customerID int, productID int) domain.Cart {
-	cart := cartRepo.Get(customerID)
-	cart = domain.RemoveItem(cart, productID)
-	cartRepo.Save(cart)
-	return cart
+func RemoveProductFromCart(deps CartDeps, customerID int, productID int) (domain.Cart, error) {
+	return retryOnConflict(func() (domain.Cart, error) {
+		return removeProductFromCart(deps, customerID, productID)
+	})
+}
+
+// removeProductFromCart es un intento de RemoveProductFromCart.
+func removeProductFromCart(deps CartDeps, customerID int, productID int) (domain.Cart, error) {
+	var cart domain.Cart
+	err := deps.UnitOfWork.Do(func(tx Repositories) error {
+		var err error
+		cart, err = tx.Carts.Get(customerID)
+		if err != nil {
+			return err
+		}
+		cart = domain.RemoveItem(cart, productID)
+		if err := tx.Carts.Save(cart); err != nil {
+			return err
+		}
+		return tx.Reservations.Delete(customerID, productID)
+	})
+	if err != nil {
+		return domain.Cart{}, err
+	}
+	return cart, nil
 }
 
 /*
@@ -130,9 +333,90 @@ ClearCart vacía por completo el carrito del cliente.
 
 Responsabilidad:
 - Delegar al repositorio la operación de "vaciar".
+- Liberar todas las reservas de stock del cliente.
+
+Ambas cosas ocurren dentro de deps.UnitOfWork.
 */
-func ClearCart(cartRepo CartRepository, customerID int) {
-	cartRepo.Clear(customerID)
+func ClearCart(deps CartDeps, customerID int) error {
+	return deps.UnitOfWork.Do(func(tx Repositories) error {
+		return clearCart(tx, customerID)
+	})
+}
+
+// clearCart es ClearCart dentro de una unidad de trabajo ya abierta (la usa también la baja de clientes).
+func clearCart(tx Repositories, customerID int) error {
+	if err := tx.Carts.Clear(customerID); err != nil {
+		return err
+	}
+	return tx.Reservations.DeleteByCustomer(customerID)
+}
+
+/*
+CheckCartPrices es un caso de uso de consulta.
+
+Responsabilidad:
+- Comparar cada línea del carrito con el precio actual del producto.
+- Devolver la lista de líneas cuyo precio cambió (vacía si ninguno cambió).
+
+Se usa antes del checkout para mostrarle al cliente precio anterior
+y precio nuevo, y pedirle confirmación.
+*/
+func CheckCartPrices(deps CartDeps, customerID int) ([]domain.PriceChange, error) {
+	cart, err := deps.Carts.Get(customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]domain.PriceChange, 0)
+	for _, it := range cart.Items {
+		p, err := GetProduct(deps.Products, it.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		if change, changed := domain.DetectPriceChange(it, p); changed {
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+/*
+AcceptCartPrices es un caso de uso de comando.
+
+Responsabilidad:
+- Actualizar el snapshot de cada línea con el precio y nombre actuales.
+- Persistir el carrito.
+
+Es la "confirmación" del cliente: después de llamarlo, un checkout con
+RejectPriceChanges ya no encuentra diferencias.
+*/
+func AcceptCartPrices(deps CartDeps, customerID int) (domain.Cart, error) {
+	return retryOnConflict(func() (domain.Cart, error) {
+		return acceptCartPrices(deps, customerID)
+	})
+}
+
+// acceptCartPrices es un intento de AcceptCartPrices.
+func acceptCartPrices(deps CartDeps, customerID int) (domain.Cart, error) {
+	cart, err := deps.Carts.Get(customerID)
+	if err != nil {
+		return domain.Cart{}, err
+	}
+
+	items := make([]domain.CartItem, 0, len(cart.Items))
+	for _, it := range cart.Items {
+		p, err := GetProduct(deps.Products, it.ProductID)
+		if err != nil {
+			return domain.Cart{}, err
+		}
+		items = append(items, domain.RepriceItem(it, p))
+	}
+
+	cart.Items = items
+	if err := deps.Carts.Save(cart); err != nil {
+		return domain.Cart{}, err
+	}
+	return cart, nil
 }
 
 /*
@@ -145,8 +429,13 @@ Responsabilidad:
 Por qué el cálculo está en domain:
 - "Total" es una regla del negocio (precio * cantidad).
 - Mantenerlo en dominio evita duplicarlo en UI o usecases.
+
+Devuelve ErrCurrencyMismatch si el carrito mezclara monedas.
 */
-func CartTotal(cartRepo CartRepository, customerID int) float64 {
-	cart := cartRepo.Get(customerID)
+func CartTotal(cartRepo CartRepository, customerID int) (domain.Money, error) {
+	cart, err := cartRepo.Get(customerID)
+	if err != nil {
+		return domain.Money{}, err
+	}
 	return domain.Total(cart)
 }
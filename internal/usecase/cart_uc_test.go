@@ -0,0 +1,239 @@
+package usecase_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/memory"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+// slowReservations tarda en listar: abre la ventana entre validar el stock y reservarlo.
+type slowReservations struct {
+	usecase.ReservationRepository
+}
+
+func (r slowReservations) ListByProduct(productID int) ([]domain.Reservation, error) {
+	list, err := r.ReservationRepository.ListByProduct(productID)
+	time.Sleep(5 * time.Millisecond)
+	return list, err
+}
+
+// failingCarts no puede guardar ningún carrito.
+type failingCarts struct {
+	usecase.CartRepository
+}
+
+var errDisk = errors.New("disco lleno")
+
+func (failingCarts) Save(domain.Cart) error { return errDisk }
+
+/*
+cartStore crea repositorios en memoria con un producto (ID 1, stock
+indicado) y los clientes 1..customers, y devuelve las dependencias del
+carrito. wrap decora los repositorios de cada unidad de trabajo (y los
+de deps, para que el caso de prueba no dependa de cuáles use el carrito).
+*/
+func cartStore(t *testing.T, stock, customers int, wrap func(usecase.Repositories) usecase.Repositories) (memory.Repos, usecase.CartDeps) {
+	t.Helper()
+	repos := memory.Repos{
+		Products:     memory.NewProductRepo(),
+		Customers:    memory.NewCustomerRepo(),
+		Carts:        memory.NewCartRepo(),
+		Reservations: memory.NewReservationRepo(),
+		Orders:       memory.NewOrderRepo(),
+		Returns:      memory.NewReturnRepo(),
+		Users:        memory.NewUserRepo(),
+	}
+	if err := repos.Products.Create(domain.Product{ID: 1, Name: "Lápiz", Price: domain.NewMoney(1000, domain.DefaultCurrency), Stock: stock}); err != nil {
+		t.Fatal(err)
+	}
+	for id := 1; id <= customers; id++ {
+		if err := repos.Customers.Create(domain.Customer{ID: id, Name: "Cliente", Email: fmt.Sprintf("c%d@example.com", id)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	outside := wrap(usecase.Repositories{Carts: repos.Carts, Products: repos.Products, Reservations: repos.Reservations})
+	return repos, usecase.CartDeps{
+		UnitOfWork:   memory.NewUnitOfWork(repos, memory.Hooks{Wrap: wrap}),
+		Carts:        outside.Carts,
+		Products:     outside.Products,
+		Reservations: outside.Reservations,
+	}
+}
+
+// Muchos clientes agregando a la vez el mismo producto no reservan más que el stock.
+func TestAddProductToCartConcurrent(t *testing.T) {
+	const customers, stock = 20, 5
+	repos, deps := cartStore(t, stock, customers, func(tx usecase.Repositories) usecase.Repositories {
+		tx.Reservations = slowReservations{tx.Reservations}
+		return tx
+	})
+
+	var added atomic.Int32
+	var wg sync.WaitGroup
+	for id := 1; id <= customers; id++ {
+		wg.Go(func() {
+			_, err := usecase.AddProductToCart(deps, id, 1, 1)
+			switch {
+			case err == nil:
+				added.Add(1)
+			case !errors.Is(err, domain.ErrNoStock):
+				t.Error(err)
+			}
+		})
+	}
+	wg.Wait()
+
+	if n := added.Load(); n != stock {
+		t.Errorf("se agregaron %d carritos, se esperaban %d", n, stock)
+	}
+	reservations, err := repos.Reservations.ListByProduct(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reservations) != stock {
+		t.Errorf("hay %d reservas, se esperaban %d", len(reservations), stock)
+	}
+}
+
+// Si el carrito no se puede guardar, tampoco queda la reserva.
+func TestAddProductToCartSaveFails(t *testing.T) {
+	repos, deps := cartStore(t, 5, 1, func(tx usecase.Repositories) usecase.Repositories {
+		tx.Carts = failingCarts{tx.Carts}
+		return tx
+	})
+
+	if _, err := usecase.AddProductToCart(deps, 1, 1, 2); !errors.Is(err, errDisk) {
+		t.Fatalf("AddProductToCart devolvió %v, se esperaba %v", err, errDisk)
+	}
+	if _, ok, err := repos.Reservations.Get(1, 1); err != nil || ok {
+		t.Errorf("Get de la reserva devolvió %v, %v; se esperaba que no existiera", ok, err)
+	}
+}
+
+// No se puede armar el carrito de un cliente que no existe.
+func TestAddProductToCartUnknownCustomer(t *testing.T) {
+	repos, deps := cartStore(t, 5, 1, noWrap)
+
+	if _, err := usecase.AddProductToCart(deps, 77, 1, 1); !errors.Is(err, domain.ErrCustomerNotFound) {
+		t.Fatalf("AddProductToCart devolvió %v, se esperaba %v", err, domain.ErrCustomerNotFound)
+	}
+	if _, ok, err := repos.Reservations.Get(77, 1); err != nil || ok {
+		t.Errorf("Get de la reserva devolvió %v, %v; se esperaba que no existiera", ok, err)
+	}
+}
+
+// noWrap deja los repositorios de la unidad de trabajo sin decorar.
+func noWrap(tx usecase.Repositories) usecase.Repositories { return tx }
+
+// Cantidad 0 quita la línea y libera su reserva; una cantidad negativa es un error.
+func TestSetCartItemQuantityZero(t *testing.T) {
+	repos, deps := cartStore(t, 5, 1, noWrap)
+	if _, err := usecase.AddProductToCart(deps, 1, 1, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := usecase.SetCartItemQuantity(deps, 1, 1, -1); !errors.Is(err, domain.ErrInvalidQuantity) {
+		t.Errorf("cantidad negativa: %v, se esperaba %v", err, domain.ErrInvalidQuantity)
+	}
+
+	cart, err := usecase.SetCartItemQuantity(deps, 1, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cart.Items) != 0 {
+		t.Errorf("el carrito quedó con %+v, se esperaba vacío", cart.Items)
+	}
+	if saved, err := repos.Carts.Get(1); err != nil || len(saved.Items) != 0 {
+		t.Errorf("carrito guardado = %+v (%v), se esperaba vacío", saved.Items, err)
+	}
+	if _, ok, err := repos.Reservations.Get(1, 1); err != nil || ok {
+		t.Errorf("Get de la reserva devolvió %v, %v; se esperaba que no existiera", ok, err)
+	}
+
+	// Quitar algo que no está en el carrito no es un error.
+	if _, err := usecase.SetCartItemQuantity(deps, 1, 1, 0); err != nil {
+		t.Errorf("cantidad 0 sin la línea en el carrito: %v", err)
+	}
+}
+
+/*
+El stock se valida contra la cantidad nueva de la línea, no contra la
+diferencia con la anterior: con 5 en el carrito y stock 5, pedir 6 falla
+aunque sea "una más", y las reservas de otros clientes se descuentan.
+*/
+func TestSetCartItemQuantityChecksTotal(t *testing.T) {
+	repos, deps := cartStore(t, 5, 2, noWrap)
+	if _, err := usecase.AddProductToCart(deps, 1, 1, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := usecase.SetCartItemQuantity(deps, 1, 1, 5); err != nil {
+		t.Fatalf("subir a todo el stock: %v", err)
+	}
+	_, err := usecase.SetCartItemQuantity(deps, 1, 1, 6)
+	var stockErr *domain.InsufficientStockError
+	if !errors.As(err, &stockErr) {
+		t.Fatalf("subir por encima del stock: %v, se esperaba *domain.InsufficientStockError", err)
+	}
+	if stockErr.Requested != 6 || stockErr.InCart != 5 || stockErr.Available != 5 {
+		t.Errorf("InsufficientStockError = %+v, se esperaba Requested 6, InCart 5, Available 5", *stockErr)
+	}
+	if cart, _ := repos.Carts.Get(1); domain.ItemQuantity(cart, 1) != 5 {
+		t.Errorf("después del error la línea tiene %d, se esperaba 5", domain.ItemQuantity(cart, 1))
+	}
+
+	// Bajar libera stock para el cliente 2, que no puede pasar de lo que queda.
+	if _, err := usecase.SetCartItemQuantity(deps, 1, 1, 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := usecase.SetCartItemQuantity(deps, 2, 1, 4); !errors.Is(err, domain.ErrNoStock) {
+		t.Errorf("cliente 2 con 4 (quedan 3): %v, se esperaba %v", err, domain.ErrNoStock)
+	}
+	if _, err := usecase.SetCartItemQuantity(deps, 2, 1, 3); err != nil {
+		t.Errorf("cliente 2 con 3: %v", err)
+	}
+}
+
+// La reserva pasa a la cantidad nueva de la línea (al subir y al bajar) y se renueva su vencimiento.
+func TestSetCartItemQuantityReservation(t *testing.T) {
+	const ttl = 15 * time.Minute
+	repos, deps := cartStore(t, 10, 1, noWrap)
+	clock := &fixedClock{time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	deps.Clock, deps.ReservationTTL = clock, ttl
+
+	assertReservation := func(quantity int) {
+		t.Helper()
+		r, ok, err := repos.Reservations.Get(1, 1)
+		if err != nil || !ok {
+			t.Fatalf("Get de la reserva devolvió %v, %v", ok, err)
+		}
+		if r.Quantity != quantity || !r.ExpiresAt.Equal(clock.t.Add(ttl)) {
+			t.Errorf("reserva = %d hasta %v, se esperaba %d hasta %v", r.Quantity, r.ExpiresAt, quantity, clock.t.Add(ttl))
+		}
+	}
+
+	if _, err := usecase.SetCartItemQuantity(deps, 1, 1, 4); err != nil {
+		t.Fatal(err)
+	}
+	assertReservation(4)
+
+	clock.t = clock.t.Add(10 * time.Minute)
+	if _, err := usecase.SetCartItemQuantity(deps, 1, 1, 7); err != nil {
+		t.Fatal(err)
+	}
+	assertReservation(7)
+
+	clock.t = clock.t.Add(10 * time.Minute)
+	if _, err := usecase.SetCartItemQuantity(deps, 1, 1, 2); err != nil {
+		t.Fatal(err)
+	}
+	assertReservation(2)
+}
@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"errors"
 	"time"
 
 	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
@@ -13,22 +14,30 @@ Cada item corresponde a un producto comprado.
 type OrderItem struct {
 	ProductID int
 	Name      string
-	UnitPrice float64
+	UnitPrice domain.Money
 	Quantity  int
-	LineTotal float64 // UnitPrice * Quantity
+	LineTotal domain.Money // UnitPrice * Quantity
 }
 
 /*
 Order representa el comprobante final de la compra (checkout).
 Incluye datos del cliente, detalle de productos y total.
+
+El ciclo de vida (estado actual e historial de cambios) se incluye
+embebido, así order.Status se puede leer directamente.
 */
 type Order struct {
 	ID           string
 	CustomerID   int
 	CustomerName string
 	Items        []OrderItem
-	Total        float64
+	Total        domain.Money
 	CreatedAt    time.Time
+	Refunded     domain.Money // Total reembolsado por devoluciones aprobadas
+	PaymentID    string       // ID de la autorización en el proveedor de pagos
+	CancelReason string       // Motivo de la cancelación (vacío si no se canceló)
+
+	domain.OrderLifecycle
 }
 
 /*
@@ -39,6 +48,91 @@ type CustomerRepositoryForCheckout interface {
 	GetByID(id int) (domain.Customer, error)
 }
 
+/*
+PricePolicy define qué hace el checkout cuando el precio guardado en el
+carrito (snapshot al agregar el producto) difiere del precio actual.
+*/
+type PricePolicy int
+
+const (
+	// HonorSnapshotPrice cobra el precio que vio el cliente al agregar el producto.
+	HonorSnapshotPrice PricePolicy = iota
+
+	// RepriceAtCheckout cobra siempre el precio actual del producto.
+	RepriceAtCheckout
+
+	// RejectPriceChanges rechaza el checkout con *domain.PriceChangedError
+	// si algún precio cambió; el cliente debe confirmar los precios nuevos
+	// (AcceptCartPrices) y volver a intentar.
+	RejectPriceChanges
+)
+
+/*
+CheckoutDeps agrupa las dependencias que necesita Checkout.
+
+Por qué un struct y no parámetros sueltos:
+- El checkout coordina muchos repositorios y servicios; pasarlos
+  agrupados mantiene la firma legible y permite agregar dependencias
+  opcionales sin romper a los llamadores.
+
+Dependencias opcionales:
+- PricePolicy: el valor cero es HonorSnapshotPrice.
+- OrderIDs: si es nil se usa un generador ULID con prefijo "ORD-".
+- Clock: si es nil se usa la hora del sistema.
+- Idempotency: solo hace falta para checkouts con IdempotencyKey.
+- IdempotencyTTL: si es 0 se usa DefaultIdempotencyTTL.
+*/
+type CheckoutDeps struct {
+	UnitOfWork     UnitOfWork
+	Carts          CartRepository
+	Products       ProductRepositoryForCart
+	Customers      CustomerRepositoryForCheckout
+	Orders         OrderRepository
+	Payments       PaymentGateway
+	Reservations   ReservationRepository
+	PricePolicy    PricePolicy
+	OrderIDs       IDGenerator
+	Clock          Clock
+	Idempotency    IdempotencyRepository
+	IdempotencyTTL time.Duration
+}
+
+// orderIDs devuelve el generador configurado o el generador por defecto.
+func (d CheckoutDeps) orderIDs() IDGenerator {
+	return idsOrDefault(d.OrderIDs, defaultOrderIDs)
+}
+
+// clock devuelve el reloj configurado o el reloj del sistema.
+func (d CheckoutDeps) clock() Clock {
+	return clockOrSystem(d.Clock)
+}
+
+// idempotencyTTL devuelve la duración configurada o la duración por defecto.
+func (d CheckoutDeps) idempotencyTTL() time.Duration {
+	if d.IdempotencyTTL <= 0 {
+		return DefaultIdempotencyTTL
+	}
+	return d.IdempotencyTTL
+}
+
+/*
+CheckoutRequest contiene los datos de entrada de un checkout.
+*/
+type CheckoutRequest struct {
+	CustomerID int
+
+	// PaymentCode es el código de verificación del pago. Se deja vacío en
+	// el primer intento y se completa solo si Checkout devolvió
+	// domain.ErrPaymentActionRequired.
+	PaymentCode string
+
+	// IdempotencyKey identifica el intento de compra (opcional). Si el
+	// pedido se repite con la misma clave (por ejemplo, un reintento por
+	// un corte de red), se devuelve el resultado del primero en lugar de
+	// crear otra orden. Ver idempotentCheckout.
+	IdempotencyKey string
+}
+
 /*
 Checkout confirma la compra de un cliente.
 
@@ -46,56 +140,221 @@ Responsabilidades:
 1) Obtener el cliente
 2) Obtener el carrito
 3) Validar carrito no vacío
-4) Validar y descontar stock producto por producto
-5) Construir el detalle del comprobante
-6) Vaciar el carrito
-7) Devolver la orden final
+4) Armar el detalle del comprobante validando productos, stock y precios
+   según deps.PricePolicy (sin modificar nada)
+5) Autorizar el pago por el total (PaymentGateway)
+6) Descontar stock producto por producto
+7) Vaciar el carrito y convertir sus reservas en venta (se eliminan)
+8) Guardar la orden en el historial (OrderRepository), en estado paid
+9) Capturar el pago autorizado
+10) Devolver la orden final
+
+Atomicidad:
+- El stock solo se toca si la autorización del paso 5 fue exitosa.
+- El stock disponible para el cliente es el stock en depósito menos las
+  reservas activas de OTROS clientes (las propias ya son de este carrito).
+- Los pasos 6 a 9 se ejecutan dentro de deps.UnitOfWork.
+- Si cualquier paso falla (sin stock, error al actualizar, captura rechazada),
+  se deshacen los cambios, el carrito queda intacto y se anula (Void)
+  la autorización para no retener el dinero del cliente.
+- Si la captura ya se hizo y lo que falla es la confirmación de la
+  unidad de trabajo, el pago no se puede anular: se reembolsa el total.
+
+Concurrencia:
+- Si otro checkout modificó un producto o el carrito entre la lectura y
+  el guardado (domain.ErrConcurrentModification), el intento se deshace
+  como cualquier otro fallo (incluida la anulación del pago) y el
+  checkout completo se reintenta, releyendo carrito, stock y precios.
+
+Idempotencia:
+- Con req.IdempotencyKey, las repeticiones del mismo checkout devuelven
+  la orden (o el error) del primero durante deps.IdempotencyTTL, sin
+  volver a cobrar ni descontar stock.
 */
-func Checkout(
-	cartRepo CartRepository,
-	productRepo ProductRepositoryForCart,
-	customerRepo CustomerRepositoryForCheckout,
-	customerID int,
-) (Order, error) {
-
-	// Obtener cliente
-	customer, err := customerRepo.GetByID(customerID)
+func Checkout(deps CheckoutDeps, req CheckoutRequest) (Order, error) {
+	if req.IdempotencyKey != "" {
+		return idempotentCheckout(deps, req)
+	}
+	return retryOnConflict(func() (Order, error) {
+		return checkout(deps, req)
+	})
+}
+
+// checkout es un intento de Checkout.
+func checkout(deps CheckoutDeps, req CheckoutRequest) (Order, error) {
+
+	// Obtener cliente (domain.ErrCustomerNotFound si no existe)
+	customer, err := GetCustomer(deps.Customers, req.CustomerID)
 	if err != nil {
 		return Order{}, err
 	}
 
 	// Obtener carrito
-	cart := cartRepo.Get(customerID)
+	cart, err := deps.Carts.Get(req.CustomerID)
+	if err != nil {
+		return Order{}, err
+	}
 	if domain.IsEmpty(cart) {
 		return Order{}, domain.ErrEmptyCart
 	}
 
+	// Armar el detalle antes de cobrar: si falta stock, no se molesta al proveedor de pagos.
+	items, total, err := buildOrderItems(deps, cart)
+	if err != nil {
+		return Order{}, err
+	}
+
+	orderID := deps.orderIDs().NewID()
+
+	// Autorizar el pago. Hasta aquí no se modificó ningún dato.
+	auth, err := deps.Payments.Authorize(PaymentRequest{
+		OrderID:          orderID,
+		CustomerID:       customer.ID,
+		Amount:           total,
+		VerificationCode: req.PaymentCode,
+	})
+	if err != nil {
+		return Order{}, err
+	}
+
+	var order Order
+	captured := false
+
+	// Todo lo que modifica estado ocurre dentro de la unidad de trabajo:
+	// o se confirma completo, o no se confirma nada.
+	err = deps.UnitOfWork.Do(func(tx Repositories) error {
+		// Descontar stock (se vuelve a validar: pudo cambiar desde el paso 4)
+		for _, it := range items {
+			p, err := GetProduct(tx.Products, it.ProductID)
+			if err != nil {
+				return err
+			}
+
+			if err := checkLineStock(deps, tx.Reservations, p, req.CustomerID, it.Quantity); err != nil {
+				return err
+			}
+
+			p.Stock -= it.Quantity
+			if err := tx.Products.Update(p); err != nil {
+				return err
+			}
+		}
+
+		// Vaciar carrito al completar la compra; sus reservas ya se convirtieron en venta.
+		// Se guarda vacío con la versión leída: si el cliente lo modificó mientras
+		// tanto, lo que se cobró ya no es su carrito y el intento falla.
+		emptied := cart
+		emptied.Items = []domain.CartItem{}
+		if err := tx.Carts.Save(emptied); err != nil {
+			return err
+		}
+		if err := tx.Reservations.DeleteByCustomer(req.CustomerID); err != nil {
+			return err
+		}
+
+		// Construir orden final: nace pendiente y pasa a pagada con el cobro.
+		now := deps.clock().Now()
+		lc, err := domain.TransitionOrder(domain.NewOrderLifecycle(now), domain.OrderPaid, now)
+		if err != nil {
+			return err
+		}
+		order = Order{
+			ID:           orderID,
+			CustomerID:   customer.ID,
+			CustomerName: customer.Name,
+			Items:        items,
+			Total:        total,
+			Refunded:     domain.ZeroMoney(total.Currency),
+			PaymentID:    auth.ID,
+			CreatedAt:    now,
+
+			OrderLifecycle: lc,
+		}
+
+		// Guardar la orden para poder consultarla después
+		if err := tx.Orders.Create(order); err != nil {
+			return err
+		}
+
+		// El cobro es lo último: si falla, se deshace todo lo anterior.
+		if err := deps.Payments.Capture(auth.ID, total); err != nil {
+			return err
+		}
+		captured = true
+		return nil
+	})
+	if err != nil {
+		// Devolver el dinero; si además falla, se informan ambos errores.
+		if refundErr := releasePayment(deps.Payments, auth.ID, orderID, total, captured); refundErr != nil {
+			return Order{}, errors.Join(err, refundErr)
+		}
+		return Order{}, err
+	}
+
+	return order, nil
+}
+
+/*
+releasePayment devuelve el dinero de un checkout que no se confirmó.
+
+Si el cobro no llegó a capturarse, alcanza con anular la autorización.
+Si se capturó y después falló la confirmación de la unidad de trabajo
+(por ejemplo, al escribir en disco), ya no se puede anular: se
+reembolsa el total.
+*/
+func releasePayment(payments PaymentGateway, authID, orderID string, total domain.Money, captured bool) error {
+	if !captured {
+		return payments.Void(authID)
+	}
+	return payments.Refund(authID, "CHECKOUT-"+orderID, total)
+}
+
+/*
+buildOrderItems arma el detalle del pedido a partir del carrito.
+
+Valida, sin modificar nada:
+- que cada producto exista,
+- que cada cantidad sea válida,
+- que haya stock disponible suficiente en este momento,
+- que los precios respeten deps.PricePolicy.
+
+Devuelve también el total del pedido.
+*/
+func buildOrderItems(deps CheckoutDeps, cart domain.Cart) ([]OrderItem, domain.Money, error) {
 	items := make([]OrderItem, 0, len(cart.Items))
-	total := 0.0
+	changes := make([]domain.PriceChange, 0)
+	var total domain.Money
 
-	// Procesar cada producto del carrito
-	for _, it := range cart.Items {
-		p, err := productRepo.GetByID(it.ProductID)
+	for i, it := range cart.Items {
+		p, err := GetProduct(deps.Products, it.ProductID)
 		if err != nil {
-			return Order{}, err
+			return nil, domain.Money{}, err
 		}
 
 		if it.Quantity <= 0 {
-			return Order{}, domain.ErrInvalidQuantity
+			return nil, domain.Money{}, domain.ErrInvalidQuantity
 		}
 
-		if p.Stock < it.Quantity {
-			return Order{}, domain.ErrNoStock
+		if err := checkLineStock(deps, deps.Reservations, p, cart.CustomerID, it.Quantity); err != nil {
+			return nil, domain.Money{}, err
 		}
 
-		// Descontar stock
-		p.Stock -= it.Quantity
-		if err := productRepo.Update(p); err != nil {
-			return Order{}, err
+		// Comparar el precio guardado en el carrito con el precio actual.
+		if change, changed := domain.DetectPriceChange(it, p); changed {
+			changes = append(changes, change)
+			if deps.PricePolicy == RepriceAtCheckout {
+				it = domain.RepriceItem(it, p)
+			}
 		}
 
-		lineTotal := it.Price * float64(it.Quantity)
-		total += lineTotal
+		lineTotal := domain.LineTotal(it)
+		if i == 0 {
+			total = domain.ZeroMoney(lineTotal.Currency)
+		}
+		total, err = domain.AddMoney(total, lineTotal)
+		if err != nil {
+			return nil, domain.Money{}, err
+		}
 
 		items = append(items, OrderItem{
 			ProductID: it.ProductID,
@@ -106,20 +365,31 @@ func Checkout(
 		})
 	}
 
-	// Vaciar carrito al completar la compra
-	cartRepo.Clear(customerID)
+	if deps.PricePolicy == RejectPriceChanges && len(changes) > 0 {
+		return nil, domain.Money{}, &domain.PriceChangedError{Changes: changes}
+	}
+	return items, total, nil
+}
 
-	now := time.Now()
+/*
+checkLineStock valida que haya stock disponible para una línea completa
+del carrito; si no lo hay, devuelve un *domain.InsufficientStockError.
 
-	// Construir orden final
-	order := Order{
-		ID:           now.Format("20060102150405"),
-		CustomerID:   customer.ID,
-		CustomerName: customer.Name,
-		Items:        items,
-		Total:        total,
-		CreatedAt:    now,
+Las reservas se leen de reservations: deps.Reservations o, dentro de
+la unidad de trabajo, las de la transacción.
+*/
+func checkLineStock(deps CheckoutDeps, reservations ReservationRepository, p domain.Product, customerID int, quantity int) error {
+	available, err := availableStockFor(reservations, p, customerID, deps.clock().Now())
+	if err != nil {
+		return err
 	}
-
-	return order, nil
+	if quantity > available {
+		return &domain.InsufficientStockError{
+			ProductID: p.ID,
+			Requested: quantity,
+			InCart:    quantity,
+			Available: available,
+		}
+	}
+	return nil
 }
@@ -0,0 +1,248 @@
+package usecase_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/fakepay"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/memory"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+// productStore es el tipo de Repositories.Products.
+type productStore interface {
+	usecase.ProductRepository
+	usecase.ProductRepositoryForCart
+}
+
+// failingProducts falla en la actualización número failOn (contando desde 1).
+type failingProducts struct {
+	productStore
+	updates *int
+	failOn  int
+}
+
+func (p failingProducts) Update(product domain.Product) error {
+	*p.updates++
+	if *p.updates == p.failOn {
+		return errDisk
+	}
+	return p.productStore.Update(product)
+}
+
+// lastAuthorization recuerda el ID de la última autorización aprobada.
+type lastAuthorization struct {
+	*fakepay.Gateway
+	id string
+}
+
+func (g *lastAuthorization) Authorize(req usecase.PaymentRequest) (usecase.PaymentAuthorization, error) {
+	auth, err := g.Gateway.Authorize(req)
+	g.id = auth.ID
+	return auth, err
+}
+
+/*
+Si falla el descuento de stock de cualquier producto, el checkout no
+descuenta nada: ni los productos anteriores al que falló ni los
+siguientes. Tampoco crea el pedido ni vacía el carrito, y anula el pago.
+*/
+func TestCheckoutUpdateFails(t *testing.T) {
+	const products, stock = 3, 10
+	for failOn := 1; failOn <= products; failOn++ {
+		t.Run(fmt.Sprintf("falla la actualización %d", failOn), func(t *testing.T) {
+			repos, _ := newRepos()
+			updates := 0
+			uow := memory.NewUnitOfWork(repos, memory.Hooks{Wrap: func(tx usecase.Repositories) usecase.Repositories {
+				tx.Products = failingProducts{productStore: tx.Products, updates: &updates, failOn: failOn}
+				return tx
+			}})
+
+			price := domain.NewMoney(1000, domain.DefaultCurrency)
+			cart := domain.Cart{CustomerID: 1}
+			for id := 1; id <= products; id++ {
+				if err := repos.Products.Create(domain.Product{ID: id, Name: "Producto", Price: price, Stock: stock}); err != nil {
+					t.Fatal(err)
+				}
+				cart.Items = append(cart.Items, domain.CartItem{ProductID: id, Name: "Producto", Price: price, Quantity: 2})
+			}
+			if err := repos.Customers.Create(domain.Customer{ID: 1, Name: "Ana", Email: "ana@example.com"}); err != nil {
+				t.Fatal(err)
+			}
+			if err := repos.Carts.Save(cart); err != nil {
+				t.Fatal(err)
+			}
+
+			payments := &countingPayments{}
+			_, err := usecase.Checkout(usecase.CheckoutDeps{
+				UnitOfWork:   uow,
+				Carts:        repos.Carts,
+				Products:     repos.Products,
+				Customers:    repos.Customers,
+				Orders:       repos.Orders,
+				Payments:     payments,
+				Reservations: repos.Reservations,
+			}, usecase.CheckoutRequest{CustomerID: 1})
+			if !errors.Is(err, errDisk) {
+				t.Fatalf("Checkout devolvió %v, se esperaba %v", err, errDisk)
+			}
+
+			for id := 1; id <= products; id++ {
+				p, err := repos.Products.GetByID(id)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if p.Stock != stock {
+					t.Errorf("producto %d: Stock = %d, se esperaba %d (sin cambios)", id, p.Stock, stock)
+				}
+			}
+			if orders, err := repos.Orders.ListByCustomer(1); err != nil || len(orders) != 0 {
+				t.Errorf("ListByCustomer = %+v, %v; se esperaba ningún pedido", orders, err)
+			}
+			if got, err := repos.Carts.Get(1); err != nil || len(got.Items) != products {
+				t.Errorf("el carrito quedó con %+v (%v), se esperaba intacto", got.Items, err)
+			}
+			if n := payments.voids.Load(); n != 1 {
+				t.Errorf("se anuló el pago %d veces, se esperaba 1", n)
+			}
+		})
+	}
+}
+
+/*
+Si la captura salió bien pero falla la confirmación de la unidad de
+trabajo (por ejemplo, al escribir en disco), el pedido no existe: el
+cobro ya no se puede anular, así que se reembolsa completo.
+*/
+func TestCheckoutCommitFailsAfterCapture(t *testing.T) {
+	repos, _ := newRepos()
+	uow := memory.NewUnitOfWork(repos, memory.Hooks{Commit: func([]any) error { return errDisk }})
+
+	price := domain.NewMoney(1000, domain.DefaultCurrency)
+	if err := repos.Products.Create(domain.Product{ID: 1, Name: "Lápiz", Price: price, Stock: 10}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repos.Customers.Create(domain.Customer{ID: 1, Name: "Ana", Email: "ana@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repos.Carts.Save(domain.Cart{CustomerID: 1, Items: []domain.CartItem{{ProductID: 1, Name: "Lápiz", Price: price, Quantity: 2}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	payments := &lastAuthorization{Gateway: fakepay.NewGateway(fakepay.Config{})}
+	_, err := usecase.Checkout(usecase.CheckoutDeps{
+		UnitOfWork:   uow,
+		Carts:        repos.Carts,
+		Products:     repos.Products,
+		Customers:    repos.Customers,
+		Orders:       repos.Orders,
+		Payments:     payments,
+		Reservations: repos.Reservations,
+	}, usecase.CheckoutRequest{CustomerID: 1})
+	if !errors.Is(err, errDisk) {
+		t.Fatalf("Checkout devolvió %v, se esperaba %v", err, errDisk)
+	}
+
+	if orders, err := repos.Orders.ListByCustomer(1); err != nil || len(orders) != 0 {
+		t.Errorf("ListByCustomer = %+v, %v; se esperaba ningún pedido", orders, err)
+	}
+	if p, err := repos.Products.GetByID(1); err != nil || p.Stock != 10 {
+		t.Errorf("Stock = %d (%v), se esperaba 10 (sin cambios)", p.Stock, err)
+	}
+	refunded, err := payments.Refunded(payments.id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := domain.NewMoney(2000, domain.DefaultCurrency); refunded != want {
+		t.Errorf("reembolsado = %v, se esperaba %v (el total cobrado)", refunded, want)
+	}
+}
+
+/*
+El precio de un producto cambia después de agregarlo al carrito
+($10.00 -> $15.00). Según deps.PricePolicy, el checkout cobra el precio
+que vio el cliente, el precio actual, o se rechaza con price_changed
+sin tocar nada hasta que el cliente acepte los precios nuevos.
+*/
+func TestCheckoutPricePolicies(t *testing.T) {
+	oldPrice := domain.NewMoney(1000, domain.DefaultCurrency)
+	newPrice := domain.NewMoney(1500, domain.DefaultCurrency)
+
+	tests := []struct {
+		name      string
+		policy    usecase.PricePolicy
+		unitPrice domain.Money // Precio cobrado; cero si se rechaza
+	}{
+		{"honrar el precio del carrito", usecase.HonorSnapshotPrice, oldPrice},
+		{"cobrar el precio actual", usecase.RepriceAtCheckout, newPrice},
+		{"rechazar", usecase.RejectPriceChanges, domain.Money{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repos, deps, _ := checkoutStore(t, tt.policy)
+			cartDeps := usecase.CartDeps{UnitOfWork: deps.UnitOfWork, Carts: repos.Carts, Products: repos.Products, Reservations: repos.Reservations}
+			if _, err := usecase.AddProductToCart(cartDeps, 2, 1, 3); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := usecase.ChangeProductPrice(repos.Products, 1, newPrice); err != nil {
+				t.Fatal(err)
+			}
+
+			order, err := usecase.Checkout(deps, usecase.CheckoutRequest{CustomerID: 2})
+			if tt.policy != usecase.RejectPriceChanges {
+				if err != nil {
+					t.Fatalf("Checkout: %v", err)
+				}
+				if got := order.Items[0].UnitPrice; got != tt.unitPrice {
+					t.Errorf("precio cobrado = %v, se esperaba %v", got, tt.unitPrice)
+				}
+				if want := domain.MulMoney(tt.unitPrice, 3); order.Total != want {
+					t.Errorf("Total = %v, se esperaba %v", order.Total, want)
+				}
+				assertOrders(t, repos, 2, 1, 7)
+				return
+			}
+
+			var priceErr *domain.PriceChangedError
+			if !errors.As(err, &priceErr) || !errors.Is(err, domain.ErrPriceChanged) {
+				t.Fatalf("Checkout devolvió %v, se esperaba *domain.PriceChangedError", err)
+			}
+			want := domain.PriceChange{ProductID: 1, Name: "Lápiz", OldPrice: oldPrice, NewPrice: newPrice, Quantity: 3}
+			if len(priceErr.Changes) != 1 || priceErr.Changes[0] != want {
+				t.Errorf("Changes = %+v, se esperaba [%+v]", priceErr.Changes, want)
+			}
+			assertOrders(t, repos, 2, 0, 10)
+			if cart, _ := repos.Carts.Get(2); domain.ItemQuantity(cart, 1) != 3 || cart.Items[0].Price != oldPrice {
+				t.Errorf("el carrito cambió: %+v", cart.Items)
+			}
+
+			// Después de aceptar los precios, el checkout cobra el precio nuevo.
+			if _, err := usecase.AcceptCartPrices(cartDeps, 2); err != nil {
+				t.Fatal(err)
+			}
+			order, err = usecase.Checkout(deps, usecase.CheckoutRequest{CustomerID: 2})
+			if err != nil {
+				t.Fatalf("Checkout después de aceptar los precios: %v", err)
+			}
+			if want := domain.MulMoney(newPrice, 3); order.Total != want {
+				t.Errorf("Total = %v, se esperaba %v", order.Total, want)
+			}
+			assertOrders(t, repos, 2, 1, 7)
+		})
+	}
+}
+
+// Si ningún precio cambió, RejectPriceChanges no rechaza nada.
+func TestCheckoutRejectPriceChangesUnchanged(t *testing.T) {
+	repos, deps, _ := checkoutStore(t, usecase.RejectPriceChanges)
+	order, err := usecase.Checkout(deps, usecase.CheckoutRequest{CustomerID: 1})
+	if err != nil {
+		t.Fatalf("Checkout: %v", err)
+	}
+	if want := domain.NewMoney(2000, domain.DefaultCurrency); order.Total != want {
+		t.Errorf("Total = %v, se esperaba %v", order.Total, want)
+	}
+	assertOrders(t, repos, 1, 1, 8)
+}
@@ -0,0 +1,165 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"sync"
+	"time"
+)
+
+/*
+Clock abstrae la obtención de la hora actual.
+
+Por qué existe:
+- Los casos de uso que registran fechas (por ejemplo Order.CreatedAt)
+  no deberían llamar a time.Now() directamente.
+- Inyectando un Clock, las pruebas pueden fijar la hora y obtener
+  resultados deterministas.
+*/
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock es el Clock real: devuelve la hora del sistema.
+type SystemClock struct{}
+
+// Now devuelve time.Now().
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// clockOrSystem devuelve c, o SystemClock si c es nil.
+func clockOrSystem(c Clock) Clock {
+	if c == nil {
+		return SystemClock{}
+	}
+	return c
+}
+
+/*
+IDGenerator genera identificadores únicos (pedidos, devoluciones, etc.).
+
+Reglas esperadas de cualquier implementación:
+- Nunca repetir un ID (ni siquiera con dos checkouts en el mismo instante).
+- Generar IDs que, ordenados como texto, respeten el orden de creación.
+*/
+type IDGenerator interface {
+	NewID() string
+}
+
+/*
+ULIDGenerator es el generador de IDs por defecto.
+
+Formato:
+- prefijo + ULID (26 caracteres en base32 de Crockford).
+- Los primeros 10 caracteres codifican el tiempo en milisegundos
+  y los 16 restantes son aleatorios.
+
+Propiedades:
+- Ordenable: los IDs ordenados alfabéticamente quedan en orden de creación.
+- Monótono: si dos IDs se generan en el mismo milisegundo, la parte aleatoria
+  del segundo es la del primero + 1, así el orden se mantiene.
+- Sin colisiones entre procesos: la parte aleatoria (80 bits) hace
+  prácticamente imposible que dos procesos generen el mismo ID.
+
+Es seguro para uso concurrente.
+*/
+type ULIDGenerator struct {
+	prefix string
+	clock  Clock
+
+	mu      sync.Mutex
+	lastMs  uint64
+	entropy [10]byte
+}
+
+/*
+NewULIDGenerator crea un generador con el prefijo indicado (por ejemplo "ORD-").
+
+Conviene crear un generador por tipo de entidad, para que el prefijo
+indique a qué corresponde cada ID.
+
+Si clock es nil se usa SystemClock.
+*/
+func NewULIDGenerator(prefix string, clock Clock) *ULIDGenerator {
+	return &ULIDGenerator{prefix: prefix, clock: clockOrSystem(clock)}
+}
+
+// NewID devuelve un nuevo ID único y ordenable.
+func (g *ULIDGenerator) NewID() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ms := uint64(g.clock.Now().UnixMilli())
+	if ms <= g.lastMs {
+		// Mismo milisegundo (o reloj que retrocede): se incrementa la parte
+		// aleatoria para mantener el orden en vez de generar una nueva.
+		ms = g.lastMs
+		if !incrementEntropy(&g.entropy) {
+			ms++
+			_, _ = rand.Read(g.entropy[:])
+		}
+	} else {
+		_, _ = rand.Read(g.entropy[:])
+	}
+	g.lastMs = ms
+
+	var id [16]byte
+	for i := 0; i < 6; i++ {
+		id[i] = byte(ms >> (40 - 8*i))
+	}
+	copy(id[6:], g.entropy[:])
+
+	return g.prefix + encodeULID(id)
+}
+
+// incrementEntropy suma 1 a la parte aleatoria; devuelve false si desborda.
+func incrementEntropy(e *[10]byte) bool {
+	for i := len(e) - 1; i >= 0; i-- {
+		e[i]++
+		if e[i] != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// crockford es el alfabeto base32 de Crockford (sin I, L, O, U).
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+/*
+encodeULID codifica los 128 bits del ULID en 26 caracteres base32.
+
+26 caracteres * 5 bits = 130 bits, así que el primer carácter
+solo usa los 3 bits más altos (los 2 primeros son siempre cero).
+*/
+func encodeULID(id [16]byte) string {
+	out := make([]byte, 26)
+	for i := range out {
+		// Posición del bit más alto del grupo de 5 bits, contando
+		// desde el inicio de los 130 bits (2 bits de relleno incluidos).
+		start := i*5 - 2
+		v := 0
+		for b := start; b < start+5; b++ {
+			v <<= 1
+			if b >= 0 && id[b/8]&(0x80>>(b%8)) != 0 {
+				v |= 1
+			}
+		}
+		out[i] = crockford[v]
+	}
+	return string(out)
+}
+
+// Generadores por defecto, usados cuando el caso de uso no recibe uno.
+var (
+	defaultOrderIDs  = NewULIDGenerator("ORD-", nil)
+	defaultReturnIDs = NewULIDGenerator("RMA-", nil)
+)
+
+// idsOrDefault devuelve g, o def si g es nil.
+func idsOrDefault(g IDGenerator, def IDGenerator) IDGenerator {
+	if g == nil {
+		return def
+	}
+	return g
+}
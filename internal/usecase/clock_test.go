@@ -0,0 +1,118 @@
+package usecase_test
+
+import (
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+// fixedClock devuelve siempre la misma hora: todos los IDs caen en el mismo milisegundo.
+type fixedClock struct{ t time.Time }
+
+func (c *fixedClock) Now() time.Time { return c.t }
+
+// crockford es el alfabeto base32 de Crockford (sin I, L, O, U).
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// decodeULID devuelve el número que codifican los caracteres base32 de s.
+func decodeULID(t *testing.T, s string) *big.Int {
+	t.Helper()
+	n := new(big.Int)
+	for _, ch := range s {
+		v := strings.IndexRune(crockford, ch)
+		if v < 0 {
+			t.Fatalf("%q no es base32 de Crockford (en %q)", ch, s)
+		}
+		n.Lsh(n, 5).Or(n, big.NewInt(int64(v)))
+	}
+	return n
+}
+
+// Un ID es el prefijo y 26 caracteres de Crockford; los 10 primeros son el tiempo en milisegundos.
+func TestULIDFormat(t *testing.T) {
+	// Ejemplo de la especificación de ULID: 1469918176385 ms -> "01ARYZ6S41".
+	at := time.UnixMilli(1469918176385)
+	g := usecase.NewULIDGenerator("ORD-", &fixedClock{at})
+
+	id := g.NewID()
+	ulid, ok := strings.CutPrefix(id, "ORD-")
+	if !ok {
+		t.Fatalf("%q no empieza con el prefijo ORD-", id)
+	}
+	if len(ulid) != 26 {
+		t.Fatalf("%q tiene %d caracteres después del prefijo, se esperaban 26", id, len(ulid))
+	}
+	if ulid[:10] != "01ARYZ6S41" {
+		t.Errorf("tiempo codificado = %q, se esperaba 01ARYZ6S41", ulid[:10])
+	}
+	if ms := decodeULID(t, ulid[:10]).Int64(); ms != at.UnixMilli() {
+		t.Errorf("tiempo decodificado = %d, se esperaba %d", ms, at.UnixMilli())
+	}
+	decodeULID(t, ulid[10:]) // Solo caracteres del alfabeto.
+
+	// El primer carácter solo lleva 3 bits: el máximo de 48 bits de tiempo es "7ZZZZZZZZZ".
+	last := usecase.NewULIDGenerator("", &fixedClock{time.UnixMilli(1<<48 - 1)}).NewID()
+	if last[:10] != "7ZZZZZZZZZ" {
+		t.Errorf("tiempo máximo codificado = %q, se esperaba 7ZZZZZZZZZ", last[:10])
+	}
+	if len(usecase.NewULIDGenerator("", nil).NewID()) != 26 {
+		t.Error("sin prefijo, el ID no tiene 26 caracteres")
+	}
+}
+
+/*
+En el mismo milisegundo, cada ID es el anterior + 1 en la parte
+aleatoria: el tiempo no cambia y el orden alfabético se mantiene.
+Si el reloj retrocede, se sigue contando desde el último milisegundo.
+*/
+func TestULIDMonotonic(t *testing.T) {
+	clock := &fixedClock{time.UnixMilli(1700000000000)}
+	g := usecase.NewULIDGenerator("RMA-", clock)
+
+	prev := g.NewID()
+	for i := range 1000 {
+		if i == 500 {
+			clock.t = clock.t.Add(-time.Second)
+		}
+		id := g.NewID()
+		if id <= prev {
+			t.Fatalf("ID %d: %q no es mayor que %q", i, id, prev)
+		}
+		if id[:14] != prev[:14] {
+			t.Fatalf("ID %d: el tiempo cambió de %q a %q", i, prev[4:14], id[4:14])
+		}
+		diff := new(big.Int).Sub(decodeULID(t, id[14:]), decodeULID(t, prev[14:]))
+		if diff.Cmp(big.NewInt(1)) != 0 {
+			t.Fatalf("ID %d: la parte aleatoria avanzó %v, se esperaba 1", i, diff)
+		}
+		prev = id
+	}
+
+	// Un milisegundo nuevo empieza con otra parte aleatoria y un tiempo mayor.
+	clock.t = time.UnixMilli(1700000000001)
+	if id := g.NewID(); id <= prev || id[4:14] == prev[4:14] {
+		t.Errorf("en el milisegundo siguiente: %q después de %q", id, prev)
+	}
+}
+
+// Desde varias goroutines, con el reloj del sistema, los IDs no se repiten.
+func TestULIDConcurrent(t *testing.T) {
+	g := usecase.NewULIDGenerator("PAY-", nil)
+	var seen sync.Map
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			for range 1000 {
+				id := g.NewID()
+				if _, dup := seen.LoadOrStore(id, true); dup {
+					t.Errorf("ID repetido: %s", id)
+				}
+			}
+		})
+	}
+	wg.Wait()
+}
@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+/*
+Hash de contraseñas: PBKDF2 con SHA-256.
+
+Se guarda como "pbkdf2-sha256$<iteraciones>$<sal>$<hash>" (sal y hash en
+base64). Las iteraciones quedan en el propio hash: si en el futuro se
+aumentan, los hashes viejos se siguen pudiendo verificar.
+*/
+const (
+	passwordScheme     = "pbkdf2-sha256"
+	passwordIterations = 600_000
+	passwordSaltLen    = 16
+	passwordKeyLen     = 32
+)
+
+// hashPassword devuelve el hash de password con una sal aleatoria nueva.
+func hashPassword(password string) (string, error) {
+	salt := make([]byte, passwordSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key, err := pbkdf2.Key(sha256.New, password, salt, passwordIterations, passwordKeyLen)
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawStdEncoding
+	return fmt.Sprintf("%s$%d$%s$%s", passwordScheme, passwordIterations,
+		enc.EncodeToString(salt), enc.EncodeToString(key)), nil
+}
+
+/*
+verifyPassword indica si password corresponde al hash guardado.
+
+Un hash con formato desconocido (o vacío) nunca coincide. La comparación
+final es de tiempo constante.
+*/
+func verifyPassword(hash, password string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != passwordScheme {
+		return false
+	}
+	iter, err := strconv.Atoi(parts[1])
+	if err != nil || iter <= 0 {
+		return false
+	}
+	enc := base64.RawStdEncoding
+	salt, err := enc.DecodeString(parts[2])
+	if err != nil {
+		return false
+	}
+	want, err := enc.DecodeString(parts[3])
+	if err != nil || len(want) == 0 {
+		return false
+	}
+	got, err := pbkdf2.Key(sha256.New, password, salt, iter, len(want))
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(got, want) == 1
+}
+
+/*
+dummyPasswordHash se verifica cuando el usuario no existe, para que
+Login tarde lo mismo y no revele qué nombres de usuario existen.
+*/
+var dummyPasswordHash = fmt.Sprintf("%s$%d$%s$%s", passwordScheme, passwordIterations,
+	base64.RawStdEncoding.EncodeToString(make([]byte, passwordSaltLen)),
+	base64.RawStdEncoding.EncodeToString(make([]byte, passwordKeyLen)))
+
+/*
+Claves de API.
+
+Son 32 bytes aleatorios en hexadecimal. Se guarda solo su SHA-256: como
+la clave ya es aleatoria, no hace falta un hash lento como con las
+contraseñas, y así se la puede buscar directamente por su hash.
+*/
+const apiKeyBytes = 32
+
+// newAPIKey genera una clave de API nueva y devuelve la clave y su hash.
+func newAPIKey() (key, hash string, err error) {
+	b := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	key = hex.EncodeToString(b)
+	return key, hashAPIKey(key), nil
+}
+
+// hashAPIKey devuelve el hash con el que se guarda una clave de API.
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
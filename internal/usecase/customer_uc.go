@@ -1,6 +1,14 @@
 package usecase
 
-import "github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+)
 
 /*
 CustomerRepository define el contrato que necesita la capa de casos de uso
@@ -15,7 +23,43 @@ type CustomerRepository interface {
 	Create(c domain.Customer) error
 
 	// List devuelve todos los clientes registrados.
-	List() []domain.Customer
+	List() ([]domain.Customer, error)
+}
+
+/*
+CustomerRepositoryForUpdate define lo que se necesita para modificar
+clientes existentes.
+
+Update compara c.Version con la versión guardada (control de
+concurrencia optimista): si otra operación modificó el cliente después
+de leerlo, devuelve domain.ErrConcurrentModification.
+*/
+type CustomerRepositoryForUpdate interface {
+	GetByID(id int) (domain.Customer, error)
+	Update(c domain.Customer) error
+}
+
+/*
+CustomerRepositoryForDelete agrega a CustomerRepositoryForUpdate la baja
+de clientes.
+
+Delete devuelve domain.ErrInvalidCustomerID si el cliente no existe,
+igual que GetByID y Update.
+*/
+type CustomerRepositoryForDelete interface {
+	CustomerRepositoryForUpdate
+	Delete(id int) error
+}
+
+/*
+CustomerDeps agrupa las dependencias de la baja de clientes.
+
+Además del cliente, la baja toca su carrito (y sus reservas de stock),
+sus pedidos y los usuarios asociados, así que todo se hace dentro de
+UnitOfWork, con los repositorios de la transacción.
+*/
+type CustomerDeps struct {
+	UnitOfWork UnitOfWork
 }
 
 /*
@@ -55,6 +99,195 @@ Este tipo de función es deliberadamente simple:
 - consulta datos
 - no modifica estado
 */
-func ListCustomers(repo CustomerRepository) []domain.Customer {
+func ListCustomers(repo CustomerRepository) ([]domain.Customer, error) {
 	return repo.List()
 }
+
+/*
+GetCustomer es un caso de uso de consulta: devuelve un cliente por su ID.
+
+Los repositorios informan un cliente inexistente con
+domain.ErrInvalidCustomerID (el mismo error que un ID duplicado); aquí
+se traduce a domain.ErrCustomerNotFound para poder distinguirlos.
+*/
+func GetCustomer(repo CustomerRepositoryForCheckout, id int) (domain.Customer, error) {
+	c, err := repo.GetByID(id)
+	return c, customerNotFound(err)
+}
+
+// customerNotFound traduce el "no existe" de los repositorios de clientes (ver GetCustomer).
+func customerNotFound(err error) error {
+	if errors.Is(err, domain.ErrInvalidCustomerID) {
+		return domain.ErrCustomerNotFound
+	}
+	return err
+}
+
+/*
+UpdateCustomerRequest contiene los cambios de un cliente.
+
+Un campo vacío conserva el valor actual (un nombre o un email vacíos
+no son válidos, así que no hay ambigüedad).
+*/
+type UpdateCustomerRequest struct {
+	Name  string
+	Email string
+}
+
+/*
+UpdateCustomer es un caso de uso de comando: cambia el nombre y/o el
+email de un cliente.
+
+Reglas:
+- El cliente resultante se vuelve a validar con domain.ValidateCustomer.
+- Los pedidos ya confirmados conservan el nombre con el que se hicieron.
+- Si otra operación modificó el cliente al mismo tiempo, se reintenta
+  (ver retryOnConflict).
+
+Errores:
+- domain.ErrCustomerNotFound si el cliente no existe.
+- Los de domain.ValidateCustomer si los datos nuevos no son válidos.
+*/
+func UpdateCustomer(repo CustomerRepositoryForUpdate, id int, req UpdateCustomerRequest) (domain.Customer, error) {
+	return retryOnConflict(func() (domain.Customer, error) {
+		c, err := repo.GetByID(id)
+		if err != nil {
+			return domain.Customer{}, customerNotFound(err)
+		}
+		if req.Name != "" {
+			c.Name = req.Name
+		}
+		if req.Email != "" {
+			c.Email = req.Email
+		}
+		if err := domain.ValidateCustomer(c); err != nil {
+			return domain.Customer{}, err
+		}
+		if err := repo.Update(c); err != nil {
+			return domain.Customer{}, customerNotFound(err)
+		}
+		c.Version++
+		return c, nil
+	})
+}
+
+/*
+SearchCustomers es un caso de uso de consulta: devuelve los clientes
+cuyo nombre o email contienen query, ordenados por ID.
+
+La comparación no distingue mayúsculas de minúsculas. Un query vacío
+devuelve todos los clientes.
+*/
+func SearchCustomers(repo CustomerRepository, query string) ([]domain.Customer, error) {
+	all, err := repo.List()
+	if err != nil {
+		return nil, err
+	}
+
+	query = strings.ToLower(strings.TrimSpace(query))
+	out := make([]domain.Customer, 0)
+	for _, c := range all {
+		if strings.Contains(strings.ToLower(c.Name), query) || strings.Contains(strings.ToLower(c.Email), query) {
+			out = append(out, c)
+		}
+	}
+	slices.SortFunc(out, func(a, b domain.Customer) int { return cmp.Compare(a.ID, b.ID) })
+	return out, nil
+}
+
+/*
+CustomerDeletion es el resultado de DeleteCustomer.
+
+Anonymized indica si el cliente se anonimizó (tenía pedidos) en lugar
+de borrarse; DeletedUsers son los usuarios asociados que se borraron.
+*/
+type CustomerDeletion struct {
+	CustomerID   int
+	Anonymized   bool
+	DeletedUsers []string
+}
+
+/*
+DeleteCustomer es un caso de uso de comando: da de baja un cliente.
+
+Flujo:
+1) Si el cliente tiene pedidos y anonymize es false, no hace nada y
+   devuelve domain.ErrCustomerHasOrders.
+2) Vacía su carrito y libera sus reservas de stock.
+3) Borra los usuarios con rol customer asociados al cliente, para que
+   nadie pueda volver a operar en su nombre.
+4) Sin pedidos, borra el cliente. Con pedidos (y anonymize), lo
+   reemplaza por domain.AnonymizeCustomer y quita su nombre de los
+   pedidos: los pedidos y las devoluciones se conservan para la
+   contabilidad, pero sin datos personales.
+
+Todos los pasos se ejecutan dentro de deps.UnitOfWork: si alguno falla,
+no cambia nada, y un pedido confirmado mientras tanto no puede quedar
+con el nombre de un cliente ya borrado.
+
+Errores:
+- domain.ErrCustomerNotFound si el cliente no existe.
+- domain.ErrCustomerHasOrders (ver paso 1).
+*/
+func DeleteCustomer(deps CustomerDeps, id int, anonymize bool) (CustomerDeletion, error) {
+	return retryOnConflict(func() (CustomerDeletion, error) {
+		return deleteCustomer(deps, id, anonymize)
+	})
+}
+
+// deleteCustomer es un intento de DeleteCustomer.
+func deleteCustomer(deps CustomerDeps, id int, anonymize bool) (CustomerDeletion, error) {
+	var result CustomerDeletion
+	err := deps.UnitOfWork.Do(func(tx Repositories) error {
+		c, err := tx.Customers.GetByID(id)
+		if err != nil {
+			return customerNotFound(err)
+		}
+		orders, err := tx.Orders.ListByCustomer(id)
+		if err != nil {
+			return err
+		}
+		if len(orders) > 0 && !anonymize {
+			return fmt.Errorf("%w (%d pedido(s)): solo se puede anonimizar", domain.ErrCustomerHasOrders, len(orders))
+		}
+		result = CustomerDeletion{CustomerID: id, Anonymized: len(orders) > 0, DeletedUsers: []string{}}
+
+		if err := clearCart(tx, id); err != nil {
+			return err
+		}
+
+		users, err := tx.Users.List()
+		if err != nil {
+			return err
+		}
+		for _, u := range users {
+			if u.Role != domain.RoleCustomer || u.CustomerID != id {
+				continue
+			}
+			if err := tx.Users.Delete(u.Username); err != nil {
+				return err
+			}
+			result.DeletedUsers = append(result.DeletedUsers, u.Username)
+		}
+
+		if !result.Anonymized {
+			return customerNotFound(tx.Customers.Delete(id))
+		}
+		anonymous := domain.AnonymizeCustomer(c)
+		if err := tx.Customers.Update(anonymous); err != nil {
+			return customerNotFound(err)
+		}
+		for _, o := range orders {
+			o.CustomerName = anonymous.Name
+			if err := tx.Orders.Update(o); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return CustomerDeletion{}, err
+	}
+	slices.Sort(result.DeletedUsers)
+	return result, nil
+}
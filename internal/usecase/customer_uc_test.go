@@ -0,0 +1,223 @@
+package usecase_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/memory"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+// createCustomers guarda los clientes en repos.
+func createCustomers(t *testing.T, repos memory.Repos, customers ...domain.Customer) {
+	t.Helper()
+	for _, c := range customers {
+		if err := repos.Customers.Create(c); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// customerIDs devuelve los IDs de customers, en orden.
+func customerIDs(customers []domain.Customer) []int {
+	ids := make([]int, 0, len(customers))
+	for _, c := range customers {
+		ids = append(ids, c.ID)
+	}
+	return ids
+}
+
+// SearchCustomers busca por fragmento de nombre o email, sin distinguir mayúsculas, ordenado por ID.
+func TestSearchCustomers(t *testing.T) {
+	repos, _ := newRepos()
+	createCustomers(t, repos,
+		domain.Customer{ID: 3, Name: "Carla Ruiz", Email: "carla@ejemplo.com"},
+		domain.Customer{ID: 1, Name: "Ana Pérez", Email: "ana@example.com"},
+		domain.Customer{ID: 2, Name: "Beto Gómez", Email: "beto@example.com"},
+	)
+
+	tests := []struct {
+		query string
+		want  []int
+	}{
+		{"", []int{1, 2, 3}},
+		{"  ", []int{1, 2, 3}},
+		{"ana", []int{1}},
+		{"PÉREZ", []int{1}},
+		{"example.com", []int{1, 2}},
+		{"  beto@  ", []int{2}},
+		{"r", []int{1, 3}},
+		{"nadie", []int{}},
+	}
+	for _, tt := range tests {
+		got, err := usecase.SearchCustomers(repos.Customers, tt.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ids := customerIDs(got); !slices.Equal(ids, tt.want) {
+			t.Errorf("SearchCustomers(%q) = %v, se esperaba %v", tt.query, ids, tt.want)
+		}
+	}
+}
+
+// UpdateCustomer cambia solo los campos indicados y vuelve a validar el resultado.
+func TestUpdateCustomer(t *testing.T) {
+	repos, _ := newRepos()
+	createCustomers(t, repos, domain.Customer{ID: 1, Name: "Ana", Email: "ana@example.com"})
+
+	got, err := usecase.UpdateCustomer(repos.Customers, 1, usecase.UpdateCustomerRequest{Name: "Ana María"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "Ana María" || got.Email != "ana@example.com" {
+		t.Errorf("UpdateCustomer(nombre) = %+v, se esperaba el nombre nuevo y el email de antes", got)
+	}
+
+	got, err = usecase.UpdateCustomer(repos.Customers, 1, usecase.UpdateCustomerRequest{Email: "ana.maria@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Name != "Ana María" || got.Email != "ana.maria@example.com" {
+		t.Errorf("UpdateCustomer(email) = %+v, se esperaba el email nuevo y el nombre de antes", got)
+	}
+	if stored, err := repos.Customers.GetByID(1); err != nil || stored != got {
+		t.Errorf("guardado = %+v (%v), UpdateCustomer devolvió %+v", stored, err, got)
+	}
+
+	if _, err := usecase.UpdateCustomer(repos.Customers, 1, usecase.UpdateCustomerRequest{Email: "sin-arroba"}); !errors.Is(err, domain.ErrInvalidEmail) {
+		t.Errorf("email inválido: error = %v, se esperaba %v", err, domain.ErrInvalidEmail)
+	}
+	if stored, _ := repos.Customers.GetByID(1); stored.Email != "ana.maria@example.com" {
+		t.Errorf("un cambio inválido modificó el cliente: %+v", stored)
+	}
+
+	if _, err := usecase.UpdateCustomer(repos.Customers, 99, usecase.UpdateCustomerRequest{Name: "Nadie"}); !errors.Is(err, domain.ErrCustomerNotFound) {
+		t.Errorf("cliente inexistente: error = %v, se esperaba %v", err, domain.ErrCustomerNotFound)
+	}
+}
+
+/*
+DeleteCustomer:
+- sin pedidos, borra el cliente, su carrito y sus usuarios;
+- con pedidos, se niega salvo que se pida anonimizar;
+- al anonimizar, conserva el cliente (sin datos personales), sus pedidos
+  (sin su nombre) y sus devoluciones, y borra su carrito y sus usuarios.
+*/
+func TestDeleteCustomer(t *testing.T) {
+	setup := func(t *testing.T) (memory.Repos, usecase.CustomerDeps) {
+		repos, uow := newRepos()
+		createCustomers(t, repos, domain.Customer{ID: 1, Name: "Ana", Email: "ana@example.com"})
+		for _, u := range []domain.User{
+			{Username: "ana", Role: domain.RoleCustomer, CustomerID: 1},
+			{Username: "admin", Role: domain.RoleAdmin},
+		} {
+			if err := repos.Users.Create(u); err != nil {
+				t.Fatal(err)
+			}
+		}
+		cart := domain.Cart{CustomerID: 1, Items: []domain.CartItem{{ProductID: 1, Name: "Lápiz", Price: domain.NewMoney(1000, domain.DefaultCurrency), Quantity: 1}}}
+		if err := repos.Carts.Save(cart); err != nil {
+			t.Fatal(err)
+		}
+		return repos, usecase.CustomerDeps{UnitOfWork: uow}
+	}
+	assertUsers := func(t *testing.T, repos memory.Repos, want ...string) {
+		t.Helper()
+		users, err := repos.Users.List()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, u := range users {
+			names = append(names, u.Username)
+		}
+		slices.Sort(names)
+		if !slices.Equal(names, want) {
+			t.Errorf("usuarios = %v, se esperaba %v", names, want)
+		}
+	}
+
+	t.Run("sin pedidos", func(t *testing.T) {
+		repos, deps := setup(t)
+		result, err := usecase.DeleteCustomer(deps, 1, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Anonymized || !slices.Equal(result.DeletedUsers, []string{"ana"}) {
+			t.Errorf("DeleteCustomer = %+v", result)
+		}
+		if _, err := usecase.GetCustomer(repos.Customers, 1); !errors.Is(err, domain.ErrCustomerNotFound) {
+			t.Errorf("GetCustomer después de borrar: %v, se esperaba %v", err, domain.ErrCustomerNotFound)
+		}
+		if cart, err := repos.Carts.Get(1); err != nil || len(cart.Items) != 0 {
+			t.Errorf("carrito = %+v (%v), se esperaba vacío", cart, err)
+		}
+		assertUsers(t, repos, "admin")
+	})
+
+	t.Run("con pedidos, sin anonimizar", func(t *testing.T) {
+		repos, deps := setup(t)
+		order := paidOrder(t, repos)
+		if _, err := usecase.DeleteCustomer(deps, 1, false); !errors.Is(err, domain.ErrCustomerHasOrders) {
+			t.Fatalf("error = %v, se esperaba %v", err, domain.ErrCustomerHasOrders)
+		}
+		if c, err := repos.Customers.GetByID(1); err != nil || c.Name != "Ana" {
+			t.Errorf("cliente = %+v (%v), no debería cambiar", c, err)
+		}
+		if got, err := repos.Orders.GetByID(order.ID); err != nil || got.CustomerName != order.CustomerName {
+			t.Errorf("pedido = %+v (%v), no debería cambiar", got, err)
+		}
+		if cart, _ := repos.Carts.Get(1); len(cart.Items) != 1 {
+			t.Errorf("el carrito se vació: %+v", cart)
+		}
+		assertUsers(t, repos, "admin", "ana")
+	})
+
+	t.Run("con pedidos, anonimizando", func(t *testing.T) {
+		repos, deps := setup(t)
+		order := paidOrder(t, repos)
+		order.CustomerName = "Ana"
+		if err := repos.Orders.Update(order); err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := usecase.DeleteCustomer(deps, 1, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.Anonymized || !slices.Equal(result.DeletedUsers, []string{"ana"}) {
+			t.Errorf("DeleteCustomer = %+v", result)
+		}
+
+		c, err := usecase.GetCustomer(repos.Customers, 1)
+		if err != nil {
+			t.Fatalf("el cliente anonimizado debe seguir existiendo: %v", err)
+		}
+		if want := domain.AnonymizeCustomer(domain.Customer{ID: 1}); c.Name != want.Name || c.Email != want.Email {
+			t.Errorf("cliente = %+v, se esperaba anonimizado", c)
+		}
+		orders, err := repos.Orders.ListByCustomer(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(orders) != 1 || orders[0].ID != order.ID || orders[0].Total != order.Total {
+			t.Fatalf("pedidos = %+v, se esperaba el pedido %s intacto", orders, order.ID)
+		}
+		if orders[0].CustomerName != domain.AnonymizedCustomerName {
+			t.Errorf("CustomerName del pedido = %q, se esperaba %q", orders[0].CustomerName, domain.AnonymizedCustomerName)
+		}
+		if cart, err := repos.Carts.Get(1); err != nil || len(cart.Items) != 0 {
+			t.Errorf("carrito = %+v (%v), se esperaba vacío", cart, err)
+		}
+		assertUsers(t, repos, "admin")
+	})
+
+	t.Run("inexistente", func(t *testing.T) {
+		_, deps := setup(t)
+		if _, err := usecase.DeleteCustomer(deps, 99, true); !errors.Is(err, domain.ErrCustomerNotFound) {
+			t.Errorf("error = %v, se esperaba %v", err, domain.ErrCustomerNotFound)
+		}
+	})
+}
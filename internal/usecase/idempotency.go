@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"errors"
+	"time"
+	"unicode"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+)
+
+/*
+IdempotencyRecord es el resultado guardado de un checkout hecho con
+clave de idempotencia.
+
+Mientras el checkout está en curso, Done es false; al terminar se
+guarda la orden o el error que devolvió, para responder lo mismo a las
+repeticiones del pedido hasta ExpiresAt.
+*/
+type IdempotencyRecord struct {
+	Key         string
+	CustomerID  int
+	Fingerprint string // domain.CartFingerprint del carrito que se quiso comprar
+	ExpiresAt   time.Time
+
+	Done  bool
+	Order Order
+	Err   error
+}
+
+/*
+IdempotencyRepository define el contrato para guardar los resultados de
+los checkouts con clave de idempotencia.
+
+Principio aplicado:
+- usecase define la interfaz; adapters la implementan.
+- El repositorio solo guarda: qué cuenta como repetición y qué
+  resultados se conservan lo decide Checkout.
+*/
+type IdempotencyRepository interface {
+	// Claim guarda rec si su clave está libre y devuelve true.
+	// Si la clave ya tiene un registro, lo devuelve sin cambios y false.
+	// Debe ser atómico: de dos Claim simultáneos con la misma clave, gana uno.
+	Claim(rec IdempotencyRecord) (IdempotencyRecord, bool)
+
+	// Save reemplaza el registro de rec.Key.
+	Save(rec IdempotencyRecord)
+
+	// Delete libera una clave.
+	Delete(key string)
+
+	// DeleteExpired elimina los registros vencidos en now y devuelve cuántos eran.
+	DeleteExpired(now time.Time) int
+}
+
+// DefaultIdempotencyTTL es lo que se recuerda el resultado de un checkout si no se configura otro valor.
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// maxIdempotencyKeyLen es el largo máximo de una clave de idempotencia.
+const maxIdempotencyKeyLen = 255
+
+/*
+retryableCheckoutErrors son los errores que no se recuerdan: el cliente
+tiene que poder repetir el checkout con la misma clave.
+
+- ErrPaymentActionRequired: el segundo intento trae el código de verificación.
+- ErrPaymentTimeout: no se cobró nada y el pedido no se confirmó.
+- ErrConcurrentModification: se agotaron los reintentos internos.
+- ErrPriceChanged: el cliente acepta los precios nuevos (AcceptCartPrices)
+  y repite el checkout; la huella del carrito cambia con los precios, así
+  que si se recordara, la repetición se rechazaría como otra compra.
+*/
+var retryableCheckoutErrors = []error{
+	domain.ErrPaymentActionRequired,
+	domain.ErrPaymentTimeout,
+	domain.ErrConcurrentModification,
+	domain.ErrPriceChanged,
+}
+
+// validateIdempotencyKey valida el largo y los caracteres de una clave.
+func validateIdempotencyKey(key string) error {
+	if len(key) > maxIdempotencyKeyLen {
+		return domain.ErrInvalidIdempotencyKey
+	}
+	for _, r := range key {
+		if !unicode.IsPrint(r) {
+			return domain.ErrInvalidIdempotencyKey
+		}
+	}
+	return nil
+}
+
+/*
+idempotentCheckout es Checkout con req.IdempotencyKey.
+
+Flujo:
+1) Reservar la clave con la huella del carrito actual (Claim).
+2) Si la clave ya estaba usada, es una repetición:
+   - otro cliente, o un carrito con otros ítems: ErrIdempotencyKeyReused;
+   - el primer checkout sigue en curso: ErrCheckoutInProgress;
+   - si no, se devuelve el resultado guardado sin volver a cobrar.
+3) Si no, hacer el checkout y guardar su resultado por deps.IdempotencyTTL
+   (los errores de retryableCheckoutErrors liberan la clave).
+
+Nota:
+- Después de un checkout exitoso el carrito queda vacío; una repetición
+  con el carrito vacío devuelve la orden original. Si el cliente ya armó
+  otro carrito, la misma clave se rechaza: es otra compra.
+*/
+func idempotentCheckout(deps CheckoutDeps, req CheckoutRequest) (Order, error) {
+	if err := validateIdempotencyKey(req.IdempotencyKey); err != nil {
+		return Order{}, err
+	}
+	if deps.Idempotency == nil {
+		return Order{}, errors.New("checkout: falta deps.Idempotency para usar IdempotencyKey")
+	}
+
+	now := deps.clock().Now()
+	deps.Idempotency.DeleteExpired(now)
+
+	cart, err := deps.Carts.Get(req.CustomerID)
+	if err != nil {
+		return Order{}, err
+	}
+	rec := IdempotencyRecord{
+		Key:         req.IdempotencyKey,
+		CustomerID:  req.CustomerID,
+		Fingerprint: domain.CartFingerprint(cart),
+		ExpiresAt:   now.Add(deps.idempotencyTTL()),
+	}
+
+	if prev, claimed := deps.Idempotency.Claim(rec); !claimed {
+		return replayCheckout(prev, rec, cart)
+	}
+
+	order, err := retryOnConflict(func() (Order, error) {
+		return checkout(deps, req)
+	})
+	for _, retryable := range retryableCheckoutErrors {
+		if errors.Is(err, retryable) {
+			deps.Idempotency.Delete(rec.Key)
+			return Order{}, err
+		}
+	}
+
+	rec.Done, rec.Order, rec.Err = true, order, err
+	deps.Idempotency.Save(rec)
+	return order, err
+}
+
+// replayCheckout responde una repetición de un checkout con el resultado guardado en prev.
+func replayCheckout(prev, current IdempotencyRecord, cart domain.Cart) (Order, error) {
+	if prev.CustomerID != current.CustomerID {
+		return Order{}, domain.ErrIdempotencyKeyReused
+	}
+	if !domain.IsEmpty(cart) && prev.Fingerprint != current.Fingerprint {
+		return Order{}, domain.ErrIdempotencyKeyReused
+	}
+	if !prev.Done {
+		return Order{}, domain.ErrCheckoutInProgress
+	}
+	return prev.Order, prev.Err
+}
@@ -0,0 +1,190 @@
+package usecase_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/fakepay"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/memory"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+/*
+checkoutStore crea repositorios en memoria con un producto (ID 1, $10.00,
+stock 10), los clientes 1 y 2, y 2 unidades del producto en el carrito
+del cliente 1. Devuelve las dependencias del checkout, con claves de
+idempotencia en memoria y un proveedor de pagos fakepay.
+*/
+func checkoutStore(t *testing.T, policy usecase.PricePolicy) (memory.Repos, usecase.CheckoutDeps, *fakepay.Gateway) {
+	t.Helper()
+	repos, uow := newRepos()
+	price := domain.NewMoney(1000, domain.DefaultCurrency)
+	if err := repos.Products.Create(domain.Product{ID: 1, Name: "Lápiz", Price: price, Stock: 10}); err != nil {
+		t.Fatal(err)
+	}
+	createCustomers(t, repos,
+		domain.Customer{ID: 1, Name: "Ana", Email: "ana@example.com"},
+		domain.Customer{ID: 2, Name: "Beto", Email: "beto@example.com"},
+	)
+	if err := repos.Carts.Save(domain.Cart{CustomerID: 1, Items: []domain.CartItem{{ProductID: 1, Name: "Lápiz", Price: price, Quantity: 2}}}); err != nil {
+		t.Fatal(err)
+	}
+
+	payments := fakepay.NewGateway(fakepay.Config{})
+	return repos, usecase.CheckoutDeps{
+		UnitOfWork:   uow,
+		Carts:        repos.Carts,
+		Products:     repos.Products,
+		Customers:    repos.Customers,
+		Orders:       repos.Orders,
+		Payments:     payments,
+		Reservations: repos.Reservations,
+		PricePolicy:  policy,
+		Idempotency:  memory.NewIdempotencyRepo(),
+	}, payments
+}
+
+// assertOrders verifica cuántos pedidos tiene el cliente y el stock del producto 1.
+func assertOrders(t *testing.T, repos memory.Repos, customerID, orders, stock int) {
+	t.Helper()
+	if list, err := repos.Orders.ListByCustomer(customerID); err != nil || len(list) != orders {
+		t.Errorf("cliente %d: %d pedidos (%v), se esperaba %d", customerID, len(list), err, orders)
+	}
+	if p, err := repos.Products.GetByID(1); err != nil || p.Stock != stock {
+		t.Errorf("Stock = %d (%v), se esperaba %d", p.Stock, err, stock)
+	}
+}
+
+/*
+Repetir un checkout terminado devuelve la misma orden sin volver a
+cobrar ni descontar stock, con el carrito ya vacío. Si el cliente armó
+otro carrito, la misma clave es otra compra y se rechaza.
+*/
+func TestCheckoutIdempotentReplay(t *testing.T) {
+	repos, deps, _ := checkoutStore(t, usecase.HonorSnapshotPrice)
+	req := usecase.CheckoutRequest{CustomerID: 1, IdempotencyKey: "compra-1"}
+
+	first, err := usecase.Checkout(deps, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 3 {
+		again, err := usecase.Checkout(deps, req)
+		if err != nil {
+			t.Fatalf("repetición: %v", err)
+		}
+		if again.ID != first.ID || again.PaymentID != first.PaymentID || again.Total != first.Total {
+			t.Errorf("repetición = %+v, se esperaba la orden %s", again, first.ID)
+		}
+	}
+	assertOrders(t, repos, 1, 1, 8)
+
+	other, err := repos.Carts.Get(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other.Items = []domain.CartItem{{ProductID: 1, Name: "Lápiz", Price: first.Items[0].UnitPrice, Quantity: 1}}
+	if err := repos.Carts.Save(other); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := usecase.Checkout(deps, req); !errors.Is(err, domain.ErrIdempotencyKeyReused) {
+		t.Errorf("misma clave con otro carrito: %v, se esperaba %v", err, domain.ErrIdempotencyKeyReused)
+	}
+	assertOrders(t, repos, 1, 1, 8)
+}
+
+// Una clave usada por un cliente no sirve para otro, aunque el primer checkout haya terminado.
+func TestCheckoutIdempotencyKeyOtherCustomer(t *testing.T) {
+	repos, deps, _ := checkoutStore(t, usecase.HonorSnapshotPrice)
+	if _, err := usecase.Checkout(deps, usecase.CheckoutRequest{CustomerID: 1, IdempotencyKey: "compra-1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	cart := domain.Cart{CustomerID: 2, Items: []domain.CartItem{{ProductID: 1, Name: "Lápiz", Price: domain.NewMoney(1000, domain.DefaultCurrency), Quantity: 2}}}
+	if err := repos.Carts.Save(cart); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := usecase.Checkout(deps, usecase.CheckoutRequest{CustomerID: 2, IdempotencyKey: "compra-1"}); !errors.Is(err, domain.ErrIdempotencyKeyReused) {
+		t.Fatalf("Checkout del cliente 2 devolvió %v, se esperaba %v", err, domain.ErrIdempotencyKeyReused)
+	}
+	assertOrders(t, repos, 2, 0, 8)
+
+	// Con su propia clave, el cliente 2 compra normalmente.
+	if _, err := usecase.Checkout(deps, usecase.CheckoutRequest{CustomerID: 2, IdempotencyKey: "compra-2"}); err != nil {
+		t.Fatal(err)
+	}
+	assertOrders(t, repos, 2, 1, 6)
+}
+
+/*
+Un error definitivo (pago rechazado) se recuerda: repetir con el mismo
+carrito lo devuelve otra vez sin llamar al proveedor, aunque ahora
+aprobaría. Repetir con otro carrito (huella distinta) se rechaza.
+*/
+func TestCheckoutIdempotencyFingerprintMismatch(t *testing.T) {
+	repos, deps, payments := checkoutStore(t, usecase.HonorSnapshotPrice)
+	req := usecase.CheckoutRequest{CustomerID: 1, IdempotencyKey: "compra-1"}
+
+	payments.SetMode(fakepay.Decline)
+	if _, err := usecase.Checkout(deps, req); !errors.Is(err, domain.ErrPaymentDeclined) {
+		t.Fatalf("Checkout devolvió %v, se esperaba %v", err, domain.ErrPaymentDeclined)
+	}
+
+	payments.SetMode(fakepay.Approve)
+	if _, err := usecase.Checkout(deps, req); !errors.Is(err, domain.ErrPaymentDeclined) {
+		t.Errorf("repetición con el mismo carrito: %v, se esperaba el error guardado %v", err, domain.ErrPaymentDeclined)
+	}
+
+	cart, err := repos.Carts.Get(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cart.Items[0].Quantity = 3
+	if err := repos.Carts.Save(cart); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := usecase.Checkout(deps, req); !errors.Is(err, domain.ErrIdempotencyKeyReused) {
+		t.Errorf("repetición con otro carrito: %v, se esperaba %v", err, domain.ErrIdempotencyKeyReused)
+	}
+	assertOrders(t, repos, 1, 0, 10)
+}
+
+/*
+Con RejectPriceChanges, price_changed no se recuerda: el cliente acepta
+los precios nuevos y repite el checkout con la misma clave, que ahora
+compra al precio nuevo. Las repeticiones siguientes devuelven esa orden.
+*/
+func TestCheckoutIdempotentAfterPriceChange(t *testing.T) {
+	repos, deps, _ := checkoutStore(t, usecase.RejectPriceChanges)
+	req := usecase.CheckoutRequest{CustomerID: 1, IdempotencyKey: "compra-1"}
+
+	p, err := repos.Products.GetByID(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Price = domain.NewMoney(1200, domain.DefaultCurrency)
+	if err := repos.Products.Update(p); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := usecase.Checkout(deps, req); !errors.Is(err, domain.ErrPriceChanged) {
+		t.Fatalf("Checkout devolvió %v, se esperaba %v", err, domain.ErrPriceChanged)
+	}
+	if _, err := usecase.AcceptCartPrices(usecase.CartDeps{Carts: repos.Carts, Products: repos.Products}, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	order, err := usecase.Checkout(deps, req)
+	if err != nil {
+		t.Fatalf("reintento con la misma clave después de aceptar los precios: %v", err)
+	}
+	if want := domain.NewMoney(2400, domain.DefaultCurrency); order.Total != want {
+		t.Errorf("Total = %v, se esperaba %v (precio nuevo)", order.Total, want)
+	}
+	again, err := usecase.Checkout(deps, req)
+	if err != nil || again.ID != order.ID {
+		t.Errorf("repetición = %s (%v), se esperaba la orden %s", again.ID, err, order.ID)
+	}
+	assertOrders(t, repos, 1, 1, 8)
+}
@@ -0,0 +1,284 @@
+package usecase
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+)
+
+/*
+ImportMode define qué hace una importación masiva con las filas cuyo
+ID ya existe.
+*/
+type ImportMode int
+
+const (
+	// ImportCreateOnly solo da de alta: una fila con un ID existente es un error.
+	ImportCreateOnly ImportMode = iota
+
+	// ImportUpsert da de alta los IDs nuevos y actualiza los existentes.
+	ImportUpsert
+)
+
+/*
+ImportOptions configura una importación masiva.
+
+- Mode: el valor cero es ImportCreateOnly.
+- DryRun: valida todas las filas y arma el reporte, pero no guarda nada.
+*/
+type ImportOptions struct {
+	Mode   ImportMode
+	DryRun bool
+}
+
+/*
+ImportRow es una fila leída de un archivo: la entidad y la línea del
+archivo donde estaba, para poder informar errores con número de línea.
+*/
+type ImportRow[T any] struct {
+	Line  int
+	Value T
+}
+
+/*
+RowError es el error de una fila de una importación.
+
+errors.Is con el error de dominio sigue funcionando (por ejemplo,
+domain.ErrInvalidPrice).
+*/
+type RowError struct {
+	Line int
+	Err  error
+}
+
+// Error implementa la interfaz error.
+func (e *RowError) Error() string {
+	return fmt.Sprintf("línea %d: %v", e.Line, e.Err)
+}
+
+// Unwrap permite usar errors.Is / errors.As con el error de la fila.
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+/*
+ImportReport resume una importación.
+
+Created y Updated cuentan las filas guardadas (o que se guardarían,
+si DryRun es true). Errors tiene un RowError por cada fila rechazada,
+en el orden del archivo.
+*/
+type ImportReport struct {
+	Created int
+	Updated int
+	Errors  []*RowError
+	DryRun  bool
+}
+
+/*
+ProductImportRepository reúne el alta, la búsqueda y la actualización
+de productos (lo que usa el menú de productos de la CLI).
+*/
+type ProductImportRepository interface {
+	ProductRepository
+	ProductRepositoryForCart
+}
+
+/*
+CustomerImportRepository reúne el alta, la búsqueda y la actualización
+de clientes (lo que usa el menú de clientes de la CLI).
+*/
+type CustomerImportRepository interface {
+	CustomerRepository
+	CustomerRepositoryForUpdate
+}
+
+/*
+importBatchSize es cuántas filas se guardan en cada unidad de trabajo.
+
+Con jsonfile cada confirmación reescribe el archivo completo: guardar
+fila por fila haría la importación cuadrática. Por lotes, el costo es
+una escritura cada importBatchSize filas, y un lote fallido no se lleva
+puesto lo ya guardado en los anteriores.
+*/
+const importBatchSize = 500
+
+/*
+importOps describe cómo importar un tipo de entidad: así productos y
+clientes comparten las reglas de la importación. exists, create y
+update usan los repositorios de la unidad de trabajo en curso.
+*/
+type importOps[T any] struct {
+	id        func(T) int
+	validate  func(T) error
+	errID     error // error de dominio para IDs repetidos o existentes
+	exists    func(tx Repositories, id int) bool
+	create    func(tx Repositories, v T) error
+	update    func(tx Repositories, v T) error
+	entityTag string // "producto" o "cliente", para los mensajes
+}
+
+/*
+ImportProducts da de alta (o actualiza, en modo upsert) los productos
+de rows.
+
+Reglas:
+- Cada fila se valida con domain.ValidateProduct.
+- Un ID repetido dentro del mismo archivo es un error (se conserva la
+  primera fila con ese ID).
+- En ImportCreateOnly, un ID que ya existe es un error.
+- En ImportUpsert, un ID existente reemplaza nombre, precio y stock.
+- Las filas son independientes: una fila inválida no detiene la
+  importación; se informa en el reporte y se sigue con la siguiente.
+- Las filas válidas se guardan por lotes, cada uno en una unidad de
+  trabajo (ver importBatchSize). Si un lote no se puede confirmar, sus
+  filas se informan con ese error y no se guarda ninguna de ellas.
+*/
+func ImportProducts(uow UnitOfWork, rows []ImportRow[domain.Product], opts ImportOptions) ImportReport {
+	return importRows(uow, rows, opts, importOps[domain.Product]{
+		id:       func(p domain.Product) int { return p.ID },
+		validate: domain.ValidateProduct,
+		errID:    domain.ErrInvalidID,
+		exists: func(tx Repositories, id int) bool {
+			_, err := tx.Products.GetByID(id)
+			return err == nil
+		},
+		create: func(tx Repositories, p domain.Product) error { return tx.Products.Create(p) },
+		update: func(tx Repositories, p domain.Product) error {
+			current, err := tx.Products.GetByID(p.ID)
+			if err != nil {
+				return err
+			}
+			p.Version = current.Version
+			return tx.Products.Update(p)
+		},
+		entityTag: "producto",
+	})
+}
+
+/*
+ImportCustomers da de alta (o actualiza, en modo upsert) los clientes
+de rows, con las mismas reglas que ImportProducts.
+
+Cada fila se valida con domain.ValidateCustomer; en modo upsert se
+reemplazan nombre y email.
+*/
+func ImportCustomers(uow UnitOfWork, rows []ImportRow[domain.Customer], opts ImportOptions) ImportReport {
+	return importRows(uow, rows, opts, importOps[domain.Customer]{
+		id:       func(c domain.Customer) int { return c.ID },
+		validate: domain.ValidateCustomer,
+		errID:    domain.ErrInvalidCustomerID,
+		exists: func(tx Repositories, id int) bool {
+			_, err := tx.Customers.GetByID(id)
+			return err == nil
+		},
+		create: func(tx Repositories, c domain.Customer) error { return tx.Customers.Create(c) },
+		update: func(tx Repositories, c domain.Customer) error {
+			current, err := tx.Customers.GetByID(c.ID)
+			if err != nil {
+				return err
+			}
+			c.Version = current.Version
+			return tx.Customers.Update(c)
+		},
+		entityTag: "cliente",
+	})
+}
+
+/*
+importRows aplica las reglas de ImportProducts / ImportCustomers.
+
+Primero descarta las filas inválidas o repetidas en el archivo (no
+dependen de lo guardado); después guarda el resto por lotes.
+*/
+func importRows[T any](uow UnitOfWork, rows []ImportRow[T], opts ImportOptions, ops importOps[T]) ImportReport {
+	report := ImportReport{Errors: []*RowError{}, DryRun: opts.DryRun}
+	firstLine := map[int]int{} // ID -> primera línea del archivo con ese ID
+	valid := make([]ImportRow[T], 0, len(rows))
+
+	for _, row := range rows {
+		if err := ops.validate(row.Value); err != nil {
+			report.Errors = append(report.Errors, &RowError{Line: row.Line, Err: err})
+			continue
+		}
+
+		id := ops.id(row.Value)
+		if line, seen := firstLine[id]; seen {
+			report.Errors = append(report.Errors, &RowError{
+				Line: row.Line,
+				Err:  fmt.Errorf("%w: %d repetido (ya aparece en la línea %d)", ops.errID, id, line),
+			})
+			continue
+		}
+		firstLine[id] = row.Line
+		valid = append(valid, row)
+	}
+
+	for batch := range slices.Chunk(valid, importBatchSize) {
+		result, err := retryOnConflict(func() (ImportReport, error) {
+			return importBatch(uow, batch, opts, ops)
+		})
+		if err != nil {
+			// No se confirmó nada del lote: cada fila que se iba a guardar falla con err.
+			for _, row := range batch {
+				if !slices.ContainsFunc(result.Errors, func(e *RowError) bool { return e.Line == row.Line }) {
+					result.Errors = append(result.Errors, &RowError{Line: row.Line, Err: err})
+				}
+			}
+			result.Created, result.Updated = 0, 0
+		}
+		report.Created += result.Created
+		report.Updated += result.Updated
+		report.Errors = append(report.Errors, result.Errors...)
+	}
+
+	slices.SortStableFunc(report.Errors, func(a, b *RowError) int { return cmp.Compare(a.Line, b.Line) })
+	return report
+}
+
+/*
+importBatch guarda un lote de filas ya validadas en una unidad de trabajo.
+
+Una fila que falla (ID existente sin upsert, error del repositorio) se
+informa en el reporte y no impide guardar las demás. Con DryRun no se
+escribe nada, pero se consulta lo guardado igual.
+*/
+func importBatch[T any](uow UnitOfWork, batch []ImportRow[T], opts ImportOptions, ops importOps[T]) (ImportReport, error) {
+	var result ImportReport
+	err := uow.Do(func(tx Repositories) error {
+		result = ImportReport{}
+		for _, row := range batch {
+			fail := func(err error) {
+				result.Errors = append(result.Errors, &RowError{Line: row.Line, Err: err})
+			}
+
+			id := ops.id(row.Value)
+			exists := ops.exists(tx, id)
+			if exists && opts.Mode != ImportUpsert {
+				fail(fmt.Errorf("%w: el %s %d ya existe", ops.errID, ops.entityTag, id))
+				continue
+			}
+
+			if !opts.DryRun {
+				save := ops.create
+				if exists {
+					save = ops.update
+				}
+				if err := save(tx, row.Value); err != nil {
+					fail(err)
+					continue
+				}
+			}
+
+			if exists {
+				result.Updated++
+			} else {
+				result.Created++
+			}
+		}
+		return nil
+	})
+	return result, err
+}
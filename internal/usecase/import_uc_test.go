@@ -0,0 +1,228 @@
+package usecase_test
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/memory"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+// productRows arma filas de importación numeradas desde la línea 2 (la 1 es el encabezado).
+func productRows(products ...domain.Product) []usecase.ImportRow[domain.Product] {
+	rows := make([]usecase.ImportRow[domain.Product], len(products))
+	for i, p := range products {
+		rows[i] = usecase.ImportRow[domain.Product]{Line: i + 2, Value: p}
+	}
+	return rows
+}
+
+// pencil devuelve un producto válido con el ID, el precio (en centavos) y el stock indicados.
+func pencil(id int, cents int64, stock int) domain.Product {
+	return domain.Product{ID: id, Name: fmt.Sprintf("Lápiz %d", id), Price: domain.NewMoney(cents, domain.DefaultCurrency), Stock: stock}
+}
+
+// errorLines devuelve las líneas de los errores del reporte, en orden.
+func errorLines(report usecase.ImportReport) []int {
+	lines := make([]int, len(report.Errors))
+	for i, e := range report.Errors {
+		lines[i] = e.Line
+	}
+	return lines
+}
+
+/*
+Cada fila inválida se informa con su línea y su error de dominio, en el
+orden del archivo, sin impedir que se guarden las demás.
+*/
+func TestImportProductsRowErrors(t *testing.T) {
+	repos, uow := newRepos()
+	if err := repos.Products.Create(pencil(5, 100, 1)); err != nil {
+		t.Fatal(err)
+	}
+
+	rows := productRows(
+		pencil(1, 1000, 10), // 2: ok
+		pencil(2, 0, 1),     // 3: sin precio
+		pencil(3, 500, -1),  // 4: stock negativo
+		pencil(1, 700, 2),   // 5: ID repetido en el archivo
+		pencil(5, 900, 9),   // 6: ya existe
+		pencil(6, 1200, 3),  // 7: ok
+		pencil(0, 100, 1),   // 8: sin ID
+	)
+	report := usecase.ImportProducts(uow, rows, usecase.ImportOptions{})
+
+	if report.Created != 2 || report.Updated != 0 {
+		t.Errorf("Created = %d, Updated = %d; se esperaba 2 y 0", report.Created, report.Updated)
+	}
+	if lines := errorLines(report); !slices.Equal(lines, []int{3, 4, 5, 6, 8}) {
+		t.Fatalf("líneas con error = %v, se esperaba [3 4 5 6 8]", lines)
+	}
+	wantErrs := []error{domain.ErrInvalidPrice, domain.ErrInvalidStock, domain.ErrInvalidID, domain.ErrInvalidID, domain.ErrInvalidID}
+	for i, want := range wantErrs {
+		if !errors.Is(report.Errors[i], want) {
+			t.Errorf("línea %d: %v, se esperaba %v", report.Errors[i].Line, report.Errors[i], want)
+		}
+	}
+
+	for _, id := range []int{1, 6} {
+		if _, err := repos.Products.GetByID(id); err != nil {
+			t.Errorf("producto %d no se guardó: %v", id, err)
+		}
+	}
+	if p, _ := repos.Products.GetByID(1); p.Price.Amount != 1000 {
+		t.Errorf("producto 1 con precio %v: la fila repetida no debe pisar a la primera", p.Price)
+	}
+	if p, _ := repos.Products.GetByID(5); p.Stock != 1 {
+		t.Errorf("producto 5 con stock %d: sin upsert no se modifica", p.Stock)
+	}
+}
+
+// Con upsert, los IDs existentes se actualizan (conservando la versión) y los nuevos se crean.
+func TestImportProductsUpsert(t *testing.T) {
+	repos, uow := newRepos()
+	if err := repos.Products.Create(pencil(1, 100, 1)); err != nil {
+		t.Fatal(err)
+	}
+	p, _ := repos.Products.GetByID(1)
+	p.Stock = 2
+	if err := repos.Products.Update(p); err != nil {
+		t.Fatal(err)
+	}
+
+	report := usecase.ImportProducts(uow, productRows(pencil(1, 1500, 20), pencil(2, 800, 5)), usecase.ImportOptions{Mode: usecase.ImportUpsert})
+	if report.Created != 1 || report.Updated != 1 || len(report.Errors) != 0 {
+		t.Fatalf("reporte = %+v, se esperaba 1 creado y 1 actualizado", report)
+	}
+	if got, _ := repos.Products.GetByID(1); got.Price.Amount != 1500 || got.Stock != 20 || got.Name != "Lápiz 1" {
+		t.Errorf("producto 1 = %+v, se esperaba actualizado", got)
+	}
+	if _, err := repos.Products.GetByID(2); err != nil {
+		t.Errorf("producto 2 no se creó: %v", err)
+	}
+}
+
+// DryRun informa lo mismo que la importación real, pero no guarda nada.
+func TestImportProductsDryRun(t *testing.T) {
+	for _, mode := range []usecase.ImportMode{usecase.ImportCreateOnly, usecase.ImportUpsert} {
+		repos, _ := newRepos()
+		commits := 0
+		uow := memory.NewUnitOfWork(repos, memory.Hooks{Commit: func(changed []any) error {
+			if len(changed) > 0 {
+				commits++
+			}
+			return nil
+		}})
+		if err := repos.Products.Create(pencil(1, 100, 1)); err != nil {
+			t.Fatal(err)
+		}
+		rows := productRows(pencil(1, 1500, 20), pencil(2, 800, 5), pencil(3, 0, 5))
+
+		dry := usecase.ImportProducts(uow, rows, usecase.ImportOptions{Mode: mode, DryRun: true})
+		if !dry.DryRun {
+			t.Error("DryRun = false en el reporte")
+		}
+		if commits != 0 {
+			t.Errorf("modo %d: DryRun confirmó %d cambios", mode, commits)
+		}
+		if got, _ := repos.Products.GetByID(1); got.Stock != 1 {
+			t.Errorf("modo %d: DryRun modificó el producto 1: %+v", mode, got)
+		}
+		if _, err := repos.Products.GetByID(2); !errors.Is(err, domain.ErrInvalidID) {
+			t.Errorf("modo %d: DryRun creó el producto 2 (%v)", mode, err)
+		}
+
+		real := usecase.ImportProducts(uow, rows, usecase.ImportOptions{Mode: mode})
+		if dry.Created != real.Created || dry.Updated != real.Updated || !slices.Equal(errorLines(dry), errorLines(real)) {
+			t.Errorf("modo %d: DryRun = %+v, la importación real = %+v", mode, dry, real)
+		}
+	}
+}
+
+// ImportCustomers sigue las mismas reglas, con el error de ID de clientes.
+func TestImportCustomers(t *testing.T) {
+	repos, uow := newRepos()
+	createCustomers(t, repos, domain.Customer{ID: 1, Name: "Ana", Email: "ana@example.com"})
+	rows := []usecase.ImportRow[domain.Customer]{
+		{Line: 2, Value: domain.Customer{ID: 1, Name: "Ana María", Email: "ana@example.com"}},
+		{Line: 3, Value: domain.Customer{ID: 2, Name: "Beto", Email: "sin-arroba"}},
+		{Line: 4, Value: domain.Customer{ID: 3, Name: "Carla", Email: "carla@example.com"}},
+	}
+
+	report := usecase.ImportCustomers(uow, rows, usecase.ImportOptions{})
+	if report.Created != 1 || !slices.Equal(errorLines(report), []int{2, 3}) {
+		t.Fatalf("reporte = %+v", report)
+	}
+	if !errors.Is(report.Errors[0], domain.ErrInvalidCustomerID) || !errors.Is(report.Errors[1], domain.ErrInvalidEmail) {
+		t.Errorf("errores = %v", report.Errors)
+	}
+
+	report = usecase.ImportCustomers(uow, rows, usecase.ImportOptions{Mode: usecase.ImportUpsert})
+	if report.Updated != 2 || report.Created != 0 || len(report.Errors) != 1 {
+		t.Fatalf("upsert: reporte = %+v", report)
+	}
+	if c, _ := repos.Customers.GetByID(1); c.Name != "Ana María" {
+		t.Errorf("cliente 1 = %+v, se esperaba actualizado", c)
+	}
+}
+
+/*
+Las filas se guardan por lotes: una confirmación cada 500 filas, no una
+por fila. Si un lote no se puede confirmar, sus filas se informan con
+ese error y los demás lotes se guardan igual.
+*/
+func TestImportProductsBatches(t *testing.T) {
+	const n = 1200
+	products := make([]domain.Product, n)
+	for i := range products {
+		products[i] = pencil(i+1, 100, 1)
+	}
+
+	t.Run("una confirmación por lote", func(t *testing.T) {
+		repos, _ := newRepos()
+		commits := 0
+		uow := memory.NewUnitOfWork(repos, memory.Hooks{Commit: func([]any) error {
+			commits++
+			return nil
+		}})
+		report := usecase.ImportProducts(uow, productRows(products...), usecase.ImportOptions{})
+		if report.Created != n || len(report.Errors) != 0 {
+			t.Fatalf("reporte: %d creados, %d errores", report.Created, len(report.Errors))
+		}
+		if commits != 3 {
+			t.Errorf("%d confirmaciones, se esperaban 3", commits)
+		}
+	})
+
+	t.Run("falla un lote", func(t *testing.T) {
+		repos, _ := newRepos()
+		commits := 0
+		uow := memory.NewUnitOfWork(repos, memory.Hooks{Commit: func([]any) error {
+			commits++
+			if commits == 2 {
+				return errDisk
+			}
+			return nil
+		}})
+		report := usecase.ImportProducts(uow, productRows(products...), usecase.ImportOptions{})
+		if report.Created != n-500 || len(report.Errors) != 500 {
+			t.Fatalf("reporte: %d creados, %d errores; se esperaba %d y 500", report.Created, len(report.Errors), n-500)
+		}
+		if first, last := report.Errors[0], report.Errors[499]; first.Line != 502 || last.Line != 1001 || !errors.Is(first, errDisk) {
+			t.Errorf("errores de la línea %d (%v) a la %d, se esperaba de la 502 a la 1001 con %v", first.Line, first, last.Line, errDisk)
+		}
+		list, err := repos.Products.List()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(list) != n-500 {
+			t.Errorf("%d productos guardados, se esperaba %d", len(list), n-500)
+		}
+		if _, err := repos.Products.GetByID(501); err == nil {
+			t.Error("el producto 501 (del lote fallido) quedó guardado")
+		}
+	})
+}
@@ -0,0 +1,281 @@
+package usecase
+
+import (
+	"slices"
+	"time"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+)
+
+/*
+OrderRepository define el contrato que necesita la capa de casos de uso
+para guardar y consultar pedidos (historial de compras).
+
+Principio aplicado:
+- usecase define la interfaz.
+- adapters (memory, db, etc.) la implementan.
+*/
+type OrderRepository interface {
+	// Create guarda un pedido nuevo. Falla si el ID ya existe.
+	Create(o Order) error
+
+	// Update reemplaza un pedido existente. Falla si el ID no existe.
+	Update(o Order) error
+
+	// GetByID devuelve un pedido por su ID o domain.ErrOrderNotFound.
+	GetByID(id string) (Order, error)
+
+	// ListByCustomer devuelve los pedidos de un cliente, del más antiguo al más nuevo.
+	ListByCustomer(customerID int) ([]Order, error)
+
+	// ListByDateRange devuelve los pedidos creados en [from, to),
+	// del más antiguo al más nuevo.
+	ListByDateRange(from, to time.Time) ([]Order, error)
+}
+
+/*
+GetOrder es un caso de uso de consulta.
+
+Responsabilidad:
+- Devolver un pedido ya confirmado a partir de su ID.
+- Si no existe, propaga domain.ErrOrderNotFound.
+*/
+func GetOrder(repo OrderRepository, id string) (Order, error) {
+	return repo.GetByID(id)
+}
+
+/*
+ListOrdersByCustomer es un caso de uso de consulta.
+
+Responsabilidad:
+- Devolver el historial de compras de un cliente.
+- Retorna slice vacío si el cliente no tiene pedidos (nunca nil).
+*/
+func ListOrdersByCustomer(repo OrderRepository, customerID int) ([]Order, error) {
+	return repo.ListByCustomer(customerID)
+}
+
+/*
+ListOrdersByDateRange es un caso de uso de consulta.
+
+Responsabilidad:
+- Validar que el rango tenga sentido (from <= to).
+- Devolver los pedidos creados desde from (inclusive) hasta to (exclusive).
+
+Nota:
+- El rango semiabierto permite pedir "un día completo" como
+  [día 00:00, día siguiente 00:00) sin solapar con el día siguiente.
+*/
+func ListOrdersByDateRange(repo OrderRepository, from, to time.Time) ([]Order, error) {
+	if to.Before(from) {
+		return nil, domain.ErrInvalidDateRange
+	}
+	return repo.ListByDateRange(from, to)
+}
+
+/*
+changeOrderStatus es la base de los casos de uso MarkOrder*.
+
+Responsabilidad:
+- Obtener el pedido.
+- Aplicar la regla de dominio de transición (domain.TransitionOrder),
+  que registra la fecha del cambio según deps.Clock.
+- Persistir el pedido actualizado.
+
+Errores:
+- domain.ErrOrderNotFound si el pedido no existe.
+- domain.ErrInvalidStatusTransition si el cambio no está permitido.
+
+Atomicidad:
+- La lectura, la transición y el guardado ocurren dentro de
+  deps.UnitOfWork: un cambio de estado simultáneo con una cancelación
+  no puede pisarla (por ejemplo, dejar enviado un pedido cuyo stock ya
+  se repuso y cuyo pago ya se devolvió).
+
+Nota:
+- No se exporta porque cancelar NO es solo un cambio de estado:
+  también repone stock. Para eso existe CancelOrder.
+*/
+func changeOrderStatus(deps OrderDeps, orderID string, to domain.OrderStatus) (Order, error) {
+	var order Order
+	err := deps.UnitOfWork.Do(func(tx Repositories) error {
+		var err error
+		order, err = tx.Orders.GetByID(orderID)
+		if err != nil {
+			return err
+		}
+
+		lc, err := domain.TransitionOrder(order.OrderLifecycle, to, clockOrSystem(deps.Clock).Now())
+		if err != nil {
+			return err
+		}
+		order.OrderLifecycle = lc
+		return tx.Orders.Update(order)
+	})
+	if err != nil {
+		return Order{}, err
+	}
+	return order, nil
+}
+
+// MarkOrderPaid marca un pedido pendiente como pagado.
+func MarkOrderPaid(deps OrderDeps, orderID string) (Order, error) {
+	return changeOrderStatus(deps, orderID, domain.OrderPaid)
+}
+
+// MarkOrderShipped marca un pedido pagado como enviado.
+func MarkOrderShipped(deps OrderDeps, orderID string) (Order, error) {
+	return changeOrderStatus(deps, orderID, domain.OrderShipped)
+}
+
+// MarkOrderDelivered marca un pedido enviado como entregado.
+func MarkOrderDelivered(deps OrderDeps, orderID string) (Order, error) {
+	return changeOrderStatus(deps, orderID, domain.OrderDelivered)
+}
+
+/*
+OrderDeps agrupa las dependencias de los casos de uso que, además de
+cambiar el pedido, modifican inventario (cancelaciones, devoluciones).
+
+Dependencias opcionales:
+- Payments: si es nil no se reembolsa ni anula nada en el proveedor
+  (útil para pedidos cobrados fuera del sistema).
+- Clock: si es nil se usa la hora del sistema.
+*/
+type OrderDeps struct {
+	UnitOfWork UnitOfWork
+	Orders     OrderRepository
+	Payments   PaymentGateway
+	Clock      Clock
+}
+
+/*
+CancelOrder cancela un pedido y devuelve su stock al inventario.
+
+Responsabilidad:
+- Rechazar la cancelación si el pedido ya fue enviado o entregado
+  (domain.ErrOrderAlreadyShipped).
+- Reponer, para cada OrderItem, las unidades descontadas en el checkout.
+- Registrar el motivo y la fecha de la cancelación.
+- Devolver el dinero: reembolso del neto si el pedido estaba pagado,
+  o anulación de la autorización si todavía no se había cobrado.
+
+Idempotencia:
+- Si el pedido ya estaba cancelado, se devuelve tal cual, sin error
+  y SIN volver a reponer stock. La devolución del dinero se vuelve a
+  pedir (el proveedor no la repite): así, si falló la primera vez,
+  cancelar de nuevo la completa.
+
+Atomicidad:
+- La lectura del pedido, la reposición de stock y su actualización
+  ocurren dentro de deps.UnitOfWork: si falla un producto, no se repone
+  nada y el pedido sigue como estaba; y dos cancelaciones simultáneas
+  no reponen dos veces.
+- Si un producto fue modificado por otra operación mientras tanto
+  (domain.ErrConcurrentModification), se deshace y se reintenta.
+- El dinero se devuelve recién después de confirmar la unidad de
+  trabajo: un intento deshecho (o reintentado) nunca reembolsa. Si el
+  proveedor falla, el pedido ya quedó cancelado y se devuelve el error.
+*/
+func CancelOrder(deps OrderDeps, orderID string, reason string) (Order, error) {
+	order, err := retryOnConflict(func() (Order, error) {
+		return cancelOrder(deps, orderID, reason)
+	})
+	if err != nil {
+		return Order{}, err
+	}
+	if err := settleCancelledPayment(deps.Payments, order); err != nil {
+		return Order{}, err
+	}
+	return order, nil
+}
+
+// cancelOrder es un intento de CancelOrder, sin la devolución del dinero.
+func cancelOrder(deps OrderDeps, orderID string, reason string) (Order, error) {
+	var order Order
+
+	// El pedido se lee y se valida dentro de la unidad de trabajo: dos
+	// cancelaciones simultáneas no pueden ver ambas el pedido sin cancelar
+	// (y reponer stock dos veces).
+	err := deps.UnitOfWork.Do(func(tx Repositories) error {
+		var err error
+		order, err = tx.Orders.GetByID(orderID)
+		if err != nil {
+			return err
+		}
+
+		switch order.Status {
+		case domain.OrderCancelled:
+			return nil
+		case domain.OrderShipped, domain.OrderDelivered:
+			return domain.ErrOrderAlreadyShipped
+		}
+
+		lc, err := domain.TransitionOrder(order.OrderLifecycle, domain.OrderCancelled, clockOrSystem(deps.Clock).Now())
+		if err != nil {
+			return err
+		}
+		order.OrderLifecycle = lc
+		order.CancelReason = reason
+
+		// Reponer stock producto por producto
+		for _, it := range order.Items {
+			p, err := tx.Products.GetByID(it.ProductID)
+			if err != nil {
+				return err
+			}
+			p, err = domain.Restock(p, it.Quantity)
+			if err != nil {
+				return err
+			}
+			if err := tx.Products.Update(p); err != nil {
+				return err
+			}
+		}
+
+		return tx.Orders.Update(order)
+	})
+	if err != nil {
+		return Order{}, err
+	}
+	return order, nil
+}
+
+/*
+settleCancelledPayment devuelve al cliente el dinero de un pedido cancelado.
+
+Reglas:
+- Sin proveedor o sin pago asociado, no hay nada que hacer.
+- Si el pedido estaba pagado al cancelarlo, se reembolsa el neto (total
+  menos lo ya reembolsado por devoluciones), con el refundID
+  "CANCEL-<pedido>": pedirlo de nuevo no reembolsa dos veces.
+- Si no estaba pagado, se anula la autorización pendiente (anular dos
+  veces tampoco es error).
+*/
+func settleCancelledPayment(payments PaymentGateway, order Order) error {
+	if payments == nil || order.PaymentID == "" {
+		return nil
+	}
+	if statusBeforeCancel(order) != domain.OrderPaid {
+		return payments.Void(order.PaymentID)
+	}
+
+	net, err := OrderNetTotal(order)
+	if err != nil {
+		return err
+	}
+	if !domain.IsPositive(net) {
+		return nil
+	}
+	return payments.Refund(order.PaymentID, "CANCEL-"+order.ID, net)
+}
+
+// statusBeforeCancel devuelve el estado del pedido antes de su cancelación.
+func statusBeforeCancel(order Order) domain.OrderStatus {
+	for _, ch := range slices.Backward(order.History) {
+		if ch.To == domain.OrderCancelled {
+			return ch.From
+		}
+	}
+	return order.Status
+}
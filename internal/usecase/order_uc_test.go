@@ -0,0 +1,211 @@
+package usecase_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/memory"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+/*
+countingPayments aprueba todo y cuenta los reembolsos y las anulaciones.
+
+Igual que un proveedor real, un refundID repetido no reembolsa de nuevo;
+a diferencia de fakepay, no rechaza un reembolso de más: así un segundo
+reembolso del mismo pedido se nota en la cuenta en lugar de fallar.
+*/
+type countingPayments struct {
+	refunds   atomic.Int32
+	voids     atomic.Int32
+	refundIDs sync.Map
+}
+
+func (*countingPayments) Authorize(req usecase.PaymentRequest) (usecase.PaymentAuthorization, error) {
+	return usecase.PaymentAuthorization{ID: "PAY-" + req.OrderID, Amount: req.Amount}, nil
+}
+func (*countingPayments) Capture(string, domain.Money) error { return nil }
+func (p *countingPayments) Void(string) error {
+	p.voids.Add(1)
+	return nil
+}
+func (p *countingPayments) Refund(_, refundID string, _ domain.Money) error {
+	if _, repeated := p.refundIDs.LoadOrStore(refundID, true); !repeated {
+		p.refunds.Add(1)
+	}
+	return nil
+}
+
+// slowClock tarda en responder: abre la ventana entre leer el pedido y guardarlo.
+type slowClock struct{}
+
+func (slowClock) Now() time.Time {
+	time.Sleep(10 * time.Millisecond)
+	return time.Now()
+}
+
+// newRepos crea repositorios en memoria vacíos y su unidad de trabajo.
+func newRepos() (memory.Repos, *memory.UnitOfWork) {
+	repos := memory.Repos{
+		Products:     memory.NewProductRepo(),
+		Customers:    memory.NewCustomerRepo(),
+		Carts:        memory.NewCartRepo(),
+		Reservations: memory.NewReservationRepo(),
+		Orders:       memory.NewOrderRepo(),
+		Returns:      memory.NewReturnRepo(),
+		Users:        memory.NewUserRepo(),
+	}
+	return repos, memory.NewUnitOfWork(repos, memory.Hooks{})
+}
+
+/*
+paidOrder deja en repos un producto (ID 1) y un pedido pagado
+de 2 unidades de ese producto (el stock ya descontado: queda en 8).
+*/
+func paidOrder(t *testing.T, repos memory.Repos) usecase.Order {
+	t.Helper()
+	price := domain.NewMoney(1000, domain.DefaultCurrency)
+	if err := repos.Products.Create(domain.Product{ID: 1, Name: "Lápiz", Price: price, Stock: 8}); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	lc, err := domain.TransitionOrder(domain.NewOrderLifecycle(now), domain.OrderPaid, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := domain.NewMoney(2000, domain.DefaultCurrency)
+	order := usecase.Order{
+		ID:             "ORD-1",
+		CustomerID:     1,
+		Items:          []usecase.OrderItem{{ProductID: 1, Name: "Lápiz", UnitPrice: price, Quantity: 2, LineTotal: total}},
+		Total:          total,
+		Refunded:       domain.ZeroMoney(domain.DefaultCurrency),
+		PaymentID:      "PAY-ORD-1",
+		CreatedAt:      now,
+		OrderLifecycle: lc,
+	}
+	if err := repos.Orders.Create(order); err != nil {
+		t.Fatal(err)
+	}
+	return order
+}
+
+// Cancelar el mismo pedido varias veces a la vez repone el stock y reembolsa una sola vez.
+func TestCancelOrderConcurrent(t *testing.T) {
+	repos, uow := newRepos()
+	order := paidOrder(t, repos)
+	payments := &countingPayments{}
+	deps := usecase.OrderDeps{UnitOfWork: uow, Orders: repos.Orders, Payments: payments, Clock: slowClock{}}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			if _, err := usecase.CancelOrder(deps, order.ID, "arrepentido"); err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	wg.Wait()
+
+	p, err := repos.Products.GetByID(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Stock != 10 {
+		t.Errorf("stock = %d, se esperaba 10 (repuesto una sola vez)", p.Stock)
+	}
+	if n := payments.refunds.Load(); n != 1 {
+		t.Errorf("se hicieron %d reembolsos, se esperaba 1", n)
+	}
+}
+
+/*
+Si la cancelación no se confirma (falla la escritura), no se devuelve
+dinero: el pedido sigue pagado. Al reintentar con éxito se reembolsa
+una sola vez.
+*/
+func TestCancelOrderCommitFails(t *testing.T) {
+	repos, _ := newRepos()
+	order := paidOrder(t, repos)
+	fail := true
+	uow := memory.NewUnitOfWork(repos, memory.Hooks{Commit: func([]any) error {
+		if fail {
+			return errDisk
+		}
+		return nil
+	}})
+	payments := &countingPayments{}
+	deps := usecase.OrderDeps{UnitOfWork: uow, Orders: repos.Orders, Payments: payments}
+
+	if _, err := usecase.CancelOrder(deps, order.ID, "arrepentido"); !errors.Is(err, errDisk) {
+		t.Fatalf("CancelOrder devolvió %v, se esperaba %v", err, errDisk)
+	}
+	if n := payments.refunds.Load(); n != 0 {
+		t.Errorf("se hicieron %d reembolsos de una cancelación deshecha, se esperaba 0", n)
+	}
+	if got, err := repos.Orders.GetByID(order.ID); err != nil || got.Status != domain.OrderPaid {
+		t.Errorf("Status = %s (%v), se esperaba %s", got.Status, err, domain.OrderPaid)
+	}
+
+	fail = false
+	for range 2 {
+		if _, err := usecase.CancelOrder(deps, order.ID, "arrepentido"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := payments.refunds.Load(); n != 1 {
+		t.Errorf("se hicieron %d reembolsos, se esperaba 1", n)
+	}
+}
+
+/*
+Enviar y cancelar el mismo pedido a la vez deja un resultado coherente:
+o queda cancelado (stock repuesto y pago devuelto) o queda enviado (sin
+reponer ni devolver nada), nunca una mezcla.
+*/
+func TestShipAndCancelConcurrent(t *testing.T) {
+	for range 20 {
+		repos, uow := newRepos()
+		order := paidOrder(t, repos)
+		payments := &countingPayments{}
+		deps := usecase.OrderDeps{UnitOfWork: uow, Orders: repos.Orders, Payments: payments, Clock: slowClock{}}
+
+		var shipErr, cancelErr error
+		var wg sync.WaitGroup
+		wg.Go(func() { _, shipErr = usecase.MarkOrderShipped(deps, order.ID) })
+		wg.Go(func() { _, cancelErr = usecase.CancelOrder(deps, order.ID, "arrepentido") })
+		wg.Wait()
+
+		got, err := repos.Orders.GetByID(order.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, err := repos.Products.GetByID(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch got.Status {
+		case domain.OrderCancelled:
+			if !errors.Is(shipErr, domain.ErrInvalidStatusTransition) {
+				t.Errorf("MarkOrderShipped devolvió %v, se esperaba %v", shipErr, domain.ErrInvalidStatusTransition)
+			}
+			if p.Stock != 10 || payments.refunds.Load() != 1 || got.CancelReason == "" {
+				t.Errorf("cancelado con stock %d, %d reembolsos y motivo %q; se esperaba 10, 1 y el motivo",
+					p.Stock, payments.refunds.Load(), got.CancelReason)
+			}
+		case domain.OrderShipped:
+			if !errors.Is(cancelErr, domain.ErrOrderAlreadyShipped) {
+				t.Errorf("CancelOrder devolvió %v, se esperaba %v", cancelErr, domain.ErrOrderAlreadyShipped)
+			}
+			if p.Stock != 8 || payments.refunds.Load() != 0 {
+				t.Errorf("enviado con stock %d y %d reembolsos; se esperaba 8 y 0", p.Stock, payments.refunds.Load())
+			}
+		default:
+			t.Fatalf("el pedido quedó %s", got.Status)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package usecase
+
+import "github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+
+/*
+PaymentRequest contiene los datos que se envían al proveedor de pagos
+para autorizar el cobro de un pedido.
+*/
+type PaymentRequest struct {
+	OrderID    string
+	CustomerID int
+	Amount     domain.Money
+
+	// VerificationCode es el segundo paso de autenticación (por ejemplo
+	// 3-D Secure). Vacío en el primer intento; si el proveedor responde
+	// domain.ErrPaymentActionRequired, se reintenta con el código.
+	VerificationCode string
+}
+
+/*
+PaymentAuthorization es la respuesta de una autorización exitosa.
+
+ID identifica la autorización en el proveedor y se usa después
+para capturar, anular o reembolsar.
+*/
+type PaymentAuthorization struct {
+	ID     string
+	Amount domain.Money
+}
+
+/*
+PaymentGateway define el contrato con el proveedor de pagos.
+
+Principio (arquitectura limpia):
+- usecase define el puerto.
+- adapters implementan el proveedor real o uno falso para pruebas.
+
+Flujo típico:
+1) Authorize: reserva el monto en el medio de pago del cliente.
+2) Capture: cobra efectivamente lo autorizado.
+3) Void: libera una autorización que no se va a capturar.
+4) Refund: devuelve al cliente (total o parcialmente) un monto capturado.
+
+Idempotencia (para poder reintentar sin cobrar ni devolver de más):
+- Void de una autorización ya anulada no es error.
+- Refund recibe refundID, que identifica el reembolso: repetirlo con el
+  mismo refundID no vuelve a devolver el dinero.
+
+Errores esperados (todos en domain/errors.go):
+- ErrPaymentDeclined, ErrPaymentTimeout, ErrPaymentActionRequired
+  al autorizar.
+- ErrPaymentNotFound, ErrInvalidPaymentState, ErrInvalidAmount
+  en el resto de operaciones.
+*/
+type PaymentGateway interface {
+	Authorize(req PaymentRequest) (PaymentAuthorization, error)
+	Capture(authorizationID string, amount domain.Money) error
+	Void(authorizationID string) error
+	Refund(authorizationID, refundID string, amount domain.Money) error
+}
@@ -1,6 +1,11 @@
 package usecase
 
-import "github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+)
 
 /*
 ProductRepository define el contrato que necesita la capa de casos de uso
@@ -16,7 +21,7 @@ type ProductRepository interface {
 	Create(p domain.Product) error
 
 	// List devuelve todos los productos registrados.
-	List() []domain.Product
+	List() ([]domain.Product, error)
 }
 
 /*
@@ -56,6 +61,61 @@ Diseño:
 - Función simple y directa.
 - Retorna slice vacío si no hay productos (nunca nil).
 */
-func ListProducts(repo ProductRepository) []domain.Product {
+func ListProducts(repo ProductRepository) ([]domain.Product, error) {
 	return repo.List()
 }
+
+/*
+GetProduct es un caso de uso de consulta.
+
+Responsabilidad:
+- Devolver un producto a partir de su ID.
+- Si no existe, devuelve domain.ErrProductNotFound (los repositorios
+  responden domain.ErrInvalidID, que aquí sería ambiguo).
+*/
+func GetProduct(repo ProductRepositoryForCart, id int) (domain.Product, error) {
+	p, err := repo.GetByID(id)
+	if errors.Is(err, domain.ErrInvalidID) {
+		return domain.Product{}, fmt.Errorf("%w: %d", domain.ErrProductNotFound, id)
+	}
+	return p, err
+}
+
+/*
+ChangeProductPrice es un caso de uso de comando (modifica estado).
+
+Responsabilidad:
+- Obtener el producto.
+- Validar el producto con el precio nuevo (reglas de dominio).
+- Persistir el cambio.
+
+Nota:
+- Los carritos guardan el precio al momento de agregar el producto;
+  cambiar el precio aquí no los modifica. El checkout decide qué hacer
+  con esa diferencia según su PricePolicy.
+- Si el producto cambió entre la lectura y el guardado (por ejemplo,
+  un checkout descontó stock), se vuelve a leer y se reintenta.
+*/
+func ChangeProductPrice(repo ProductRepositoryForCart, productID int, price domain.Money) (domain.Product, error) {
+	return retryOnConflict(func() (domain.Product, error) {
+		return changeProductPrice(repo, productID, price)
+	})
+}
+
+// changeProductPrice es un intento de ChangeProductPrice.
+func changeProductPrice(repo ProductRepositoryForCart, productID int, price domain.Money) (domain.Product, error) {
+	p, err := GetProduct(repo, productID)
+	if err != nil {
+		return domain.Product{}, err
+	}
+
+	p.Price = price
+	if err := domain.ValidateProduct(p); err != nil {
+		return domain.Product{}, err
+	}
+
+	if err := repo.Update(p); err != nil {
+		return domain.Product{}, err
+	}
+	return p, nil
+}
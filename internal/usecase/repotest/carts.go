@@ -0,0 +1,221 @@
+package repotest
+
+import (
+	"time"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+// sampleItem devuelve una línea de carrito válida.
+func sampleItem(productID, quantity int) domain.CartItem {
+	return domain.CartItem{
+		ProductID: productID,
+		Name:      "Producto de prueba",
+		Price:     domain.NewMoney(1250, domain.DefaultCurrency),
+		Quantity:  quantity,
+	}
+}
+
+/*
+Carts verifica el contrato de un repositorio de carritos.
+
+Reglas:
+- Get de un cliente sin carrito devuelve un carrito vacío (Items no nil,
+  versión 0) con su CustomerID, no un error.
+- Save + Get devuelve las mismas líneas, en el mismo orden.
+- Save con la versión leída funciona; con una versión vieja devuelve
+  domain.ErrConcurrentModification y no cambia nada.
+- Clear deja el carrito vacío e invalida la versión leída antes.
+*/
+func Carts(newRepo func() (usecase.CartRepository, error)) error {
+	s := &suite[usecase.CartRepository]{prefix: "carritos", newRepo: newRepo}
+
+	s.run("Get sin carrito", func(c *check, repo usecase.CartRepository) {
+		cart, err := repo.Get(7)
+		if !c.must(err, "Get") {
+			return
+		}
+		if cart.CustomerID != 7 || cart.Items == nil || len(cart.Items) != 0 || cart.Version != 0 {
+			c.errorf("Get devolvió %#v, se esperaba un carrito vacío del cliente 7", cart)
+		}
+	})
+
+	s.run("Save y Get", func(c *check, repo usecase.CartRepository) {
+		cart, err := repo.Get(1)
+		if !c.must(err, "Get") {
+			return
+		}
+		cart.Items = []domain.CartItem{sampleItem(2, 1), sampleItem(1, 3)}
+		if !c.must(repo.Save(cart), "Save") {
+			return
+		}
+		got, err := repo.Get(1)
+		if !c.must(err, "Get después de Save") {
+			return
+		}
+		if len(got.Items) != 2 || got.Items[0] != cart.Items[0] || got.Items[1] != cart.Items[1] {
+			c.errorf("Get devolvió %+v, se esperaba %+v", got.Items, cart.Items)
+		}
+		if got.Version <= cart.Version {
+			c.errorf("Version = %d después de Save, se esperaba mayor que %d", got.Version, cart.Version)
+		}
+		other, err := repo.Get(2)
+		if c.must(err, "Get de otro cliente") && len(other.Items) != 0 {
+			c.errorf("el carrito del cliente 2 tiene %d líneas, se esperaba vacío", len(other.Items))
+		}
+	})
+
+	s.run("Save con versión vieja", func(c *check, repo usecase.CartRepository) {
+		read, err := repo.Get(1)
+		if !c.must(err, "Get") {
+			return
+		}
+		first := read
+		first.Items = []domain.CartItem{sampleItem(1, 1)}
+		if !c.must(repo.Save(first), "Save") {
+			return
+		}
+
+		stale := read
+		stale.Items = []domain.CartItem{sampleItem(1, 5)}
+		c.expectErr(repo.Save(stale), domain.ErrConcurrentModification, "Save con versión vieja")
+		got, err := repo.Get(1)
+		if c.must(err, "Get después del Save rechazado") && (len(got.Items) != 1 || got.Items[0].Quantity != 1) {
+			c.errorf("Get devolvió %+v después de un Save rechazado", got.Items)
+		}
+	})
+
+	s.run("Clear", func(c *check, repo usecase.CartRepository) {
+		cart, err := repo.Get(1)
+		if !c.must(err, "Get") {
+			return
+		}
+		cart.Items = []domain.CartItem{sampleItem(1, 2)}
+		if !c.must(repo.Save(cart), "Save") {
+			return
+		}
+		read, err := repo.Get(1)
+		if !c.must(err, "Get después de Save") || !c.must(repo.Clear(1), "Clear") {
+			return
+		}
+		got, err := repo.Get(1)
+		if c.must(err, "Get después de Clear") && (got.Items == nil || len(got.Items) != 0) {
+			c.errorf("Get devolvió %#v después de Clear, se esperaba un carrito vacío", got.Items)
+		}
+		c.expectErr(repo.Save(read), domain.ErrConcurrentModification, "Save con la versión anterior a Clear")
+		c.must(repo.Clear(99), "Clear de un cliente sin carrito")
+	})
+
+	return s.err()
+}
+
+/*
+Reservations verifica el contrato de un repositorio de reservas de stock.
+
+Reglas:
+- Save crea o reemplaza la reserva de un cliente sobre un producto.
+- Get de una reserva inexistente devuelve false.
+- ListByProduct nunca devuelve nil y solo incluye ese producto.
+- Delete, DeleteByCustomer y DeleteExpired eliminan solo lo que corresponde.
+*/
+func Reservations(newRepo func() (usecase.ReservationRepository, error)) error {
+	s := &suite[usecase.ReservationRepository]{prefix: "reservas", newRepo: newRepo}
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	reservation := func(customerID, productID, quantity int, ttl time.Duration) domain.Reservation {
+		return domain.Reservation{CustomerID: customerID, ProductID: productID, Quantity: quantity, ExpiresAt: now.Add(ttl)}
+	}
+	// saveAll guarda las reservas en orden; corta en el primer error.
+	saveAll := func(c *check, repo usecase.ReservationRepository, rs ...domain.Reservation) bool {
+		for _, r := range rs {
+			if !c.must(repo.Save(r), "Save") {
+				return false
+			}
+		}
+		return true
+	}
+	// exists informa si hay una reserva del cliente sobre el producto.
+	exists := func(c *check, repo usecase.ReservationRepository, customerID, productID int) bool {
+		_, ok, err := repo.Get(customerID, productID)
+		c.must(err, "Get")
+		return ok
+	}
+
+	s.run("Get y ListByProduct vacíos", func(c *check, repo usecase.ReservationRepository) {
+		if exists(c, repo, 1, 1) {
+			c.errorf("Get encontró una reserva en un repositorio vacío")
+		}
+		list, err := repo.ListByProduct(1)
+		if c.must(err, "ListByProduct") && (list == nil || len(list) != 0) {
+			c.errorf("ListByProduct devolvió %#v, se esperaba un slice vacío", list)
+		}
+	})
+
+	s.run("Save reemplaza", func(c *check, repo usecase.ReservationRepository) {
+		if !saveAll(c, repo,
+			reservation(1, 1, 2, time.Minute),
+			reservation(1, 1, 5, time.Hour),
+			reservation(2, 1, 1, time.Minute),
+			reservation(1, 2, 1, time.Minute)) {
+			return
+		}
+
+		got, ok, err := repo.Get(1, 1)
+		if c.must(err, "Get") && (!ok || got.Quantity != 5 || !got.ExpiresAt.Equal(now.Add(time.Hour))) {
+			c.errorf("Get(1, 1) devolvió %+v, %v; se esperaba la segunda reserva", got, ok)
+		}
+		list, err := repo.ListByProduct(1)
+		if c.must(err, "ListByProduct") && len(list) != 2 {
+			c.errorf("ListByProduct(1) devolvió %d reservas, se esperaban 2", len(list))
+		}
+	})
+
+	s.run("Delete y DeleteByCustomer", func(c *check, repo usecase.ReservationRepository) {
+		if !saveAll(c, repo,
+			reservation(1, 1, 1, time.Minute),
+			reservation(1, 2, 1, time.Minute),
+			reservation(2, 1, 1, time.Minute)) {
+			return
+		}
+
+		if !c.must(repo.Delete(1, 1), "Delete") || !c.must(repo.Delete(9, 9), "Delete de una reserva inexistente") {
+			return
+		}
+		if exists(c, repo, 1, 1) {
+			c.errorf("Get(1, 1) encontró la reserva después de Delete")
+		}
+
+		if !c.must(repo.DeleteByCustomer(1), "DeleteByCustomer") {
+			return
+		}
+		if exists(c, repo, 1, 2) {
+			c.errorf("Get(1, 2) encontró la reserva después de DeleteByCustomer(1)")
+		}
+		if !exists(c, repo, 2, 1) {
+			c.errorf("DeleteByCustomer(1) borró la reserva del cliente 2")
+		}
+	})
+
+	s.run("DeleteExpired", func(c *check, repo usecase.ReservationRepository) {
+		if !saveAll(c, repo,
+			reservation(1, 1, 1, -time.Minute),
+			reservation(2, 1, 1, 0),
+			reservation(3, 1, 1, time.Minute)) {
+			return
+		}
+
+		n, err := repo.DeleteExpired(now)
+		if !c.must(err, "DeleteExpired") {
+			return
+		}
+		if n != 2 {
+			c.errorf("DeleteExpired devolvió %d, se esperaba 2", n)
+		}
+		list, err := repo.ListByProduct(1)
+		if c.must(err, "ListByProduct") && (len(list) != 1 || list[0].CustomerID != 3) {
+			c.errorf("ListByProduct devolvió %+v, se esperaba solo la reserva vigente", list)
+		}
+	})
+
+	return s.err()
+}
@@ -0,0 +1,224 @@
+package repotest
+
+import (
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+/*
+ProductRepository es lo que la aplicación espera de un repositorio de
+productos: el contrato de usecase.ProductRepository (crear, listar) más
+el de usecase.ProductRepositoryForCart (buscar por ID, actualizar).
+*/
+type ProductRepository interface {
+	usecase.ProductRepository
+	usecase.ProductRepositoryForCart
+}
+
+/*
+CustomerRepository es lo que la aplicación espera de un repositorio de
+clientes: usecase.CustomerRepository, usecase.CustomerRepositoryForCheckout
+y usecase.CustomerRepositoryForDelete.
+*/
+type CustomerRepository interface {
+	usecase.CustomerRepository
+	usecase.CustomerRepositoryForCheckout
+	usecase.CustomerRepositoryForDelete
+}
+
+// sampleProduct devuelve un producto válido con el ID indicado.
+func sampleProduct(id int) domain.Product {
+	return domain.Product{
+		ID:    id,
+		Name:  "Producto de prueba",
+		Price: domain.NewMoney(1250, domain.DefaultCurrency),
+		Stock: 10,
+	}
+}
+
+/*
+Products verifica el contrato de un repositorio de productos.
+
+Reglas:
+- List de un repositorio vacío devuelve un slice vacío, no nil.
+- Create + GetByID devuelve el mismo producto.
+- Create con un ID repetido devuelve domain.ErrInvalidID.
+- GetByID y Update de un ID inexistente devuelven domain.ErrInvalidID.
+- Update con la versión leída guarda los cambios e incrementa la versión;
+  con una versión vieja devuelve domain.ErrConcurrentModification y no
+  cambia nada.
+*/
+func Products(newRepo func() (ProductRepository, error)) error {
+	s := &suite[ProductRepository]{prefix: "productos", newRepo: newRepo}
+
+	s.run("List vacío", func(c *check, repo ProductRepository) {
+		if list, err := repo.List(); c.must(err, "List") && (list == nil || len(list) != 0) {
+			c.errorf("List devolvió %#v, se esperaba un slice vacío", list)
+		}
+	})
+
+	s.run("Create y GetByID", func(c *check, repo ProductRepository) {
+		want := sampleProduct(1)
+		if !c.must(repo.Create(want), "Create") {
+			return
+		}
+		got, err := repo.GetByID(1)
+		if !c.must(err, "GetByID") {
+			return
+		}
+		if got.ID != want.ID || got.Name != want.Name || got.Price != want.Price || got.Stock != want.Stock {
+			c.errorf("GetByID devolvió %+v, se esperaba %+v", got, want)
+		}
+		if list, err := repo.List(); c.must(err, "List") && (len(list) != 1 || list[0].ID != want.ID) {
+			c.errorf("List devolvió %+v, se esperaba solo el producto %d", list, want.ID)
+		}
+	})
+
+	s.run("Create con ID repetido", func(c *check, repo ProductRepository) {
+		if !c.must(repo.Create(sampleProduct(1)), "Create") {
+			return
+		}
+		c.expectErr(repo.Create(sampleProduct(1)), domain.ErrInvalidID, "segundo Create")
+		if list, err := repo.List(); c.must(err, "List") && len(list) != 1 {
+			c.errorf("List devolvió %d productos, se esperaba 1", len(list))
+		}
+	})
+
+	s.run("ID inexistente", func(c *check, repo ProductRepository) {
+		_, err := repo.GetByID(99)
+		c.expectErr(err, domain.ErrInvalidID, "GetByID")
+		c.expectErr(repo.Update(sampleProduct(99)), domain.ErrInvalidID, "Update")
+	})
+
+	s.run("Update con versión", func(c *check, repo ProductRepository) {
+		if !c.must(repo.Create(sampleProduct(1)), "Create") {
+			return
+		}
+		read, err := repo.GetByID(1)
+		if !c.must(err, "GetByID") {
+			return
+		}
+
+		changed := read
+		changed.Stock = 3
+		if !c.must(repo.Update(changed), "Update") {
+			return
+		}
+		got, err := repo.GetByID(1)
+		if !c.must(err, "GetByID después de Update") {
+			return
+		}
+		if got.Stock != 3 {
+			c.errorf("Stock = %d después de Update, se esperaba 3", got.Stock)
+		}
+		if got.Version <= read.Version {
+			c.errorf("Version = %d después de Update, se esperaba mayor que %d", got.Version, read.Version)
+		}
+
+		stale := read
+		stale.Stock = 7
+		c.expectErr(repo.Update(stale), domain.ErrConcurrentModification, "Update con versión vieja")
+		if got, err := repo.GetByID(1); err == nil && got.Stock != 3 {
+			c.errorf("Stock = %d después de un Update rechazado, se esperaba 3", got.Stock)
+		}
+	})
+
+	return s.err()
+}
+
+/*
+Customers verifica el contrato de un repositorio de clientes.
+
+Reglas:
+- List de un repositorio vacío devuelve un slice vacío, no nil.
+- Create + GetByID devuelve el mismo cliente.
+- Create con un ID repetido devuelve domain.ErrInvalidCustomerID.
+- GetByID, Update y Delete de un ID inexistente devuelven domain.ErrInvalidCustomerID.
+- Update con la versión leída guarda los cambios e incrementa la versión;
+  con una versión vieja devuelve domain.ErrConcurrentModification.
+- Delete quita el cliente de GetByID y de List, y el ID se puede volver a usar.
+*/
+func Customers(newRepo func() (CustomerRepository, error)) error {
+	s := &suite[CustomerRepository]{prefix: "clientes", newRepo: newRepo}
+	sample := domain.Customer{ID: 1, Name: "Ana", Email: "ana@example.com"}
+
+	s.run("List vacío", func(c *check, repo CustomerRepository) {
+		if list, err := repo.List(); c.must(err, "List") && (list == nil || len(list) != 0) {
+			c.errorf("List devolvió %#v, se esperaba un slice vacío", list)
+		}
+	})
+
+	s.run("Create y GetByID", func(c *check, repo CustomerRepository) {
+		if !c.must(repo.Create(sample), "Create") {
+			return
+		}
+		got, err := repo.GetByID(sample.ID)
+		if !c.must(err, "GetByID") {
+			return
+		}
+		if got.ID != sample.ID || got.Name != sample.Name || got.Email != sample.Email {
+			c.errorf("GetByID devolvió %+v, se esperaba %+v", got, sample)
+		}
+		if list, err := repo.List(); c.must(err, "List") && (len(list) != 1 || list[0].ID != sample.ID) {
+			c.errorf("List devolvió %+v, se esperaba solo el cliente %d", list, sample.ID)
+		}
+	})
+
+	s.run("Create con ID repetido", func(c *check, repo CustomerRepository) {
+		if !c.must(repo.Create(sample), "Create") {
+			return
+		}
+		c.expectErr(repo.Create(sample), domain.ErrInvalidCustomerID, "segundo Create")
+	})
+
+	s.run("ID inexistente", func(c *check, repo CustomerRepository) {
+		_, err := repo.GetByID(99)
+		c.expectErr(err, domain.ErrInvalidCustomerID, "GetByID")
+		missing := sample
+		missing.ID = 99
+		c.expectErr(repo.Update(missing), domain.ErrInvalidCustomerID, "Update")
+		c.expectErr(repo.Delete(99), domain.ErrInvalidCustomerID, "Delete")
+	})
+
+	s.run("Delete", func(c *check, repo CustomerRepository) {
+		if !c.must(repo.Create(sample), "Create") || !c.must(repo.Delete(sample.ID), "Delete") {
+			return
+		}
+		_, err := repo.GetByID(sample.ID)
+		c.expectErr(err, domain.ErrInvalidCustomerID, "GetByID después de Delete")
+		if list, err := repo.List(); c.must(err, "List") && len(list) != 0 {
+			c.errorf("List devolvió %+v después de Delete, se esperaba un slice vacío", list)
+		}
+		c.must(repo.Create(sample), "Create con el ID de un cliente borrado")
+	})
+
+	s.run("Update con versión", func(c *check, repo CustomerRepository) {
+		if !c.must(repo.Create(sample), "Create") {
+			return
+		}
+		read, err := repo.GetByID(sample.ID)
+		if !c.must(err, "GetByID") {
+			return
+		}
+
+		changed := read
+		changed.Email = "ana.nueva@example.com"
+		if !c.must(repo.Update(changed), "Update") {
+			return
+		}
+		got, err := repo.GetByID(sample.ID)
+		if !c.must(err, "GetByID después de Update") {
+			return
+		}
+		if got.Email != changed.Email || got.Version <= read.Version {
+			c.errorf("GetByID devolvió %+v después de Update, se esperaba el email nuevo y una versión mayor a %d",
+				got, read.Version)
+		}
+
+		stale := read
+		stale.Name = "Otra"
+		c.expectErr(repo.Update(stale), domain.ErrConcurrentModification, "Update con versión vieja")
+	})
+
+	return s.err()
+}
@@ -0,0 +1,263 @@
+package repotest
+
+import (
+	"slices"
+	"time"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+// sampleOrder devuelve un pedido pagado del cliente indicado, creado en at.
+func sampleOrder(id string, customerID int, at time.Time) usecase.Order {
+	price := domain.NewMoney(1250, domain.DefaultCurrency)
+	lc, _ := domain.TransitionOrder(domain.NewOrderLifecycle(at), domain.OrderPaid, at)
+	return usecase.Order{
+		ID:           id,
+		CustomerID:   customerID,
+		CustomerName: "Ana",
+		Items: []usecase.OrderItem{{
+			ProductID: 1,
+			Name:      "Producto de prueba",
+			UnitPrice: price,
+			Quantity:  2,
+			LineTotal: domain.NewMoney(2500, domain.DefaultCurrency),
+		}},
+		Total:     domain.NewMoney(2500, domain.DefaultCurrency),
+		Refunded:  domain.ZeroMoney(domain.DefaultCurrency),
+		PaymentID: "PAY-" + id,
+		CreatedAt: at,
+
+		OrderLifecycle: lc,
+	}
+}
+
+// orderIDs devuelve los IDs de los pedidos, en orden.
+func orderIDs(orders []usecase.Order) []string {
+	ids := make([]string, 0, len(orders))
+	for _, o := range orders {
+		ids = append(ids, o.ID)
+	}
+	return ids
+}
+
+/*
+Orders verifica el contrato de un repositorio de pedidos.
+
+Reglas:
+- Create + GetByID devuelve el mismo pedido (detalle, total, estado e historial).
+- Create con un ID repetido devuelve domain.ErrDuplicateOrderID.
+- GetByID y Update de un ID inexistente devuelven domain.ErrOrderNotFound.
+- ListByCustomer y ListByDateRange nunca devuelven nil y ordenan del
+  más antiguo al más nuevo; el rango es [from, to).
+*/
+func Orders(newRepo func() (usecase.OrderRepository, error)) error {
+	s := &suite[usecase.OrderRepository]{prefix: "pedidos", newRepo: newRepo}
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	s.run("listas vacías", func(c *check, repo usecase.OrderRepository) {
+		if list, err := repo.ListByCustomer(1); c.must(err, "ListByCustomer") && (list == nil || len(list) != 0) {
+			c.errorf("ListByCustomer devolvió %#v, se esperaba un slice vacío", list)
+		}
+		if list, err := repo.ListByDateRange(t0, t0.Add(time.Hour)); c.must(err, "ListByDateRange") && (list == nil || len(list) != 0) {
+			c.errorf("ListByDateRange devolvió %#v, se esperaba un slice vacío", list)
+		}
+	})
+
+	s.run("Create y GetByID", func(c *check, repo usecase.OrderRepository) {
+		want := sampleOrder("ORD-1", 1, t0)
+		if !c.must(repo.Create(want), "Create") {
+			return
+		}
+		got, err := repo.GetByID(want.ID)
+		if !c.must(err, "GetByID") {
+			return
+		}
+		if got.CustomerID != want.CustomerID || got.Total != want.Total || got.PaymentID != want.PaymentID ||
+			got.Status != want.Status || !got.CreatedAt.Equal(want.CreatedAt) {
+			c.errorf("GetByID devolvió %+v, se esperaba %+v", got, want)
+		}
+		if len(got.Items) != 1 || got.Items[0] != want.Items[0] {
+			c.errorf("Items = %+v, se esperaba %+v", got.Items, want.Items)
+		}
+		if len(got.History) != len(want.History) {
+			c.errorf("History tiene %d cambios, se esperaban %d", len(got.History), len(want.History))
+		}
+	})
+
+	s.run("Create con ID repetido", func(c *check, repo usecase.OrderRepository) {
+		if !c.must(repo.Create(sampleOrder("ORD-1", 1, t0)), "Create") {
+			return
+		}
+		c.expectErr(repo.Create(sampleOrder("ORD-1", 2, t0)), domain.ErrDuplicateOrderID, "segundo Create")
+	})
+
+	s.run("ID inexistente", func(c *check, repo usecase.OrderRepository) {
+		_, err := repo.GetByID("ORD-X")
+		c.expectErr(err, domain.ErrOrderNotFound, "GetByID")
+		c.expectErr(repo.Update(sampleOrder("ORD-X", 1, t0)), domain.ErrOrderNotFound, "Update")
+	})
+
+	s.run("Update", func(c *check, repo usecase.OrderRepository) {
+		o := sampleOrder("ORD-1", 1, t0)
+		if !c.must(repo.Create(o), "Create") {
+			return
+		}
+		lc, err := domain.TransitionOrder(o.OrderLifecycle, domain.OrderCancelled, t0.Add(time.Minute))
+		if !c.must(err, "TransitionOrder") {
+			return
+		}
+		o.OrderLifecycle = lc
+		o.CancelReason = "prueba"
+		if !c.must(repo.Update(o), "Update") {
+			return
+		}
+		got, err := repo.GetByID(o.ID)
+		if !c.must(err, "GetByID") {
+			return
+		}
+		if got.Status != domain.OrderCancelled || got.CancelReason != "prueba" || len(got.History) != len(o.History) {
+			c.errorf("GetByID devolvió %+v después de Update, se esperaba %+v", got, o)
+		}
+	})
+
+	s.run("listas ordenadas", func(c *check, repo usecase.OrderRepository) {
+		// Se crean desordenados a propósito.
+		for _, o := range []usecase.Order{
+			sampleOrder("ORD-C", 1, t0.Add(2*time.Hour)),
+			sampleOrder("ORD-A", 1, t0),
+			sampleOrder("ORD-B", 2, t0.Add(time.Hour)),
+		} {
+			if !c.must(repo.Create(o), "Create "+o.ID) {
+				return
+			}
+		}
+
+		byCustomer, err := repo.ListByCustomer(1)
+		if got := orderIDs(byCustomer); c.must(err, "ListByCustomer") && !slices.Equal(got, []string{"ORD-A", "ORD-C"}) {
+			c.errorf("ListByCustomer(1) = %v, se esperaba [ORD-A ORD-C]", got)
+		}
+		byDate, err := repo.ListByDateRange(t0, t0.Add(2*time.Hour))
+		if got := orderIDs(byDate); c.must(err, "ListByDateRange") && !slices.Equal(got, []string{"ORD-A", "ORD-B"}) {
+			c.errorf("ListByDateRange = %v, se esperaba [ORD-A ORD-B] (to no se incluye)", got)
+		}
+	})
+
+	return s.err()
+}
+
+/*
+Returns verifica el contrato de un repositorio de devoluciones.
+
+newRepos devuelve el repositorio de devoluciones junto con el de pedidos
+del mismo almacenamiento: una devolución siempre es de un pedido que ya
+existe (y algunos almacenamientos lo exigen).
+
+Reglas:
+- Create + GetByID devuelve la misma devolución.
+- Create con un ID repetido devuelve domain.ErrInvalidID.
+- GetByID y Update de un ID inexistente devuelven domain.ErrReturnNotFound.
+- ListByOrder nunca devuelve nil y ordena de la más antigua a la más nueva.
+*/
+func Returns(newRepos func() (usecase.ReturnRepository, usecase.OrderRepository, error)) error {
+	type repos struct {
+		returns usecase.ReturnRepository
+		orders  usecase.OrderRepository
+	}
+	s := &suite[repos]{prefix: "devoluciones", newRepo: func() (repos, error) {
+		returns, orders, err := newRepos()
+		return repos{returns, orders}, err
+	}}
+	t0 := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	sample := func(id string, at time.Time) usecase.ReturnRequest {
+		refund := domain.NewMoney(1250, domain.DefaultCurrency)
+		return usecase.ReturnRequest{
+			ID:         id,
+			OrderID:    "ORD-1",
+			CustomerID: 1,
+			Lines:      []usecase.ReturnLine{{ProductID: 1, Name: "Producto de prueba", Quantity: 1, Refund: refund}},
+			Refund:     refund,
+			Reason:     "prueba",
+			Status:     domain.ReturnRequested,
+			CreatedAt:  at,
+		}
+	}
+	// withOrder crea el pedido ORD-1 al que pertenecen las devoluciones.
+	withOrder := func(c *check, r repos) bool {
+		return c.must(r.orders.Create(sampleOrder("ORD-1", 1, t0)), "Create del pedido")
+	}
+
+	s.run("ListByOrder vacío", func(c *check, r repos) {
+		if list, err := r.returns.ListByOrder("ORD-1"); c.must(err, "ListByOrder") && (list == nil || len(list) != 0) {
+			c.errorf("ListByOrder devolvió %#v, se esperaba un slice vacío", list)
+		}
+	})
+
+	s.run("Create y GetByID", func(c *check, r repos) {
+		if !withOrder(c, r) {
+			return
+		}
+		want := sample("RMA-1", t0)
+		if !c.must(r.returns.Create(want), "Create") {
+			return
+		}
+		got, err := r.returns.GetByID(want.ID)
+		if !c.must(err, "GetByID") {
+			return
+		}
+		if got.OrderID != want.OrderID || got.Refund != want.Refund || got.Status != want.Status ||
+			!got.CreatedAt.Equal(want.CreatedAt) || !got.DecidedAt.IsZero() {
+			c.errorf("GetByID devolvió %+v, se esperaba %+v", got, want)
+		}
+		if len(got.Lines) != 1 || got.Lines[0] != want.Lines[0] {
+			c.errorf("Lines = %+v, se esperaba %+v", got.Lines, want.Lines)
+		}
+	})
+
+	s.run("Create con ID repetido", func(c *check, r repos) {
+		if !withOrder(c, r) || !c.must(r.returns.Create(sample("RMA-1", t0)), "Create") {
+			return
+		}
+		c.expectErr(r.returns.Create(sample("RMA-1", t0)), domain.ErrInvalidID, "segundo Create")
+	})
+
+	s.run("ID inexistente", func(c *check, r repos) {
+		_, err := r.returns.GetByID("RMA-X")
+		c.expectErr(err, domain.ErrReturnNotFound, "GetByID")
+		c.expectErr(r.returns.Update(sample("RMA-X", t0)), domain.ErrReturnNotFound, "Update")
+	})
+
+	s.run("Update y ListByOrder", func(c *check, r repos) {
+		if !withOrder(c, r) {
+			return
+		}
+		for _, rma := range []usecase.ReturnRequest{sample("RMA-B", t0.Add(time.Hour)), sample("RMA-A", t0)} {
+			if !c.must(r.returns.Create(rma), "Create "+rma.ID) {
+				return
+			}
+		}
+
+		decided := sample("RMA-A", t0)
+		decided.Status = domain.ReturnApproved
+		decided.Restocked = true
+		decided.DecidedAt = t0.Add(2 * time.Hour)
+		if !c.must(r.returns.Update(decided), "Update") {
+			return
+		}
+
+		list, err := r.returns.ListByOrder("ORD-1")
+		if !c.must(err, "ListByOrder") {
+			return
+		}
+		if len(list) != 2 || list[0].ID != "RMA-A" || list[1].ID != "RMA-B" {
+			c.errorf("ListByOrder devolvió %+v, se esperaba [RMA-A RMA-B]", list)
+			return
+		}
+		if got := list[0]; got.Status != domain.ReturnApproved || !got.Restocked || !got.DecidedAt.Equal(decided.DecidedAt) {
+			c.errorf("RMA-A = %+v después de Update, se esperaba %+v", got, decided)
+		}
+	})
+
+	return s.err()
+}
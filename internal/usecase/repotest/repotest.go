@@ -0,0 +1,85 @@
+/*
+Package repotest verifica que un repositorio cumpla el contrato de las
+interfaces de usecase, tal como lo cumplen los adaptadores de memory.
+
+Responsabilidad:
+- Que cada almacenamiento nuevo (archivos, SQL, log de eventos, ...)
+  no tenga que volver a demostrar a mano las mismas reglas:
+  - Get de un carrito inexistente devuelve un carrito vacío, no un error.
+  - Create rechaza IDs repetidos con el error de dominio que corresponde.
+  - List y los ListBy... nunca devuelven nil.
+  - Update/Save rechazan versiones viejas con domain.ErrConcurrentModification.
+
+Uso:
+- Cada función (Products, Customers, Carts, ...) recibe una fábrica que
+  devuelve un repositorio VACÍO; se llama una vez por cada caso, así los
+  casos no dependen entre sí.
+- Devuelve nil si el repositorio cumple el contrato, o un error con
+  todos los casos que fallaron (igual que testing/fstest.TestFS).
+- No depende del paquete testing: se puede llamar desde un test
+  (if err := repotest.Products(...); err != nil { t.Fatal(err) })
+  o desde cualquier otro programa.
+*/
+package repotest
+
+import (
+	"errors"
+	"fmt"
+)
+
+// check acumula los incumplimientos de un caso del contrato.
+type check struct {
+	name string
+	errs []error
+}
+
+// errorf registra un incumplimiento, prefijado con el nombre del caso.
+func (c *check) errorf(format string, args ...any) {
+	c.errs = append(c.errs, fmt.Errorf("%s: %s", c.name, fmt.Sprintf(format, args...)))
+}
+
+// must registra err (si no es nil) y devuelve false para cortar el caso.
+func (c *check) must(err error, what string) bool {
+	if err != nil {
+		c.errorf("%s: error inesperado: %v", what, err)
+		return false
+	}
+	return true
+}
+
+// expectErr registra un incumplimiento si err no es (errors.Is) want.
+func (c *check) expectErr(err, want error, what string) {
+	if !errors.Is(err, want) {
+		c.errorf("%s: se esperaba %q, se obtuvo %v", what, want, err)
+	}
+}
+
+/*
+suite ejecuta cada caso con un repositorio nuevo creado por newRepo
+y junta los incumplimientos de todos.
+
+Si la fábrica falla, el caso se informa como fallido y se sigue
+con el siguiente.
+*/
+type suite[R any] struct {
+	prefix  string
+	newRepo func() (R, error)
+	errs    []error
+}
+
+// run ejecuta un caso del contrato.
+func (s *suite[R]) run(name string, fn func(c *check, repo R)) {
+	c := &check{name: s.prefix + "/" + name}
+	repo, err := s.newRepo()
+	if err != nil {
+		c.errorf("no se pudo crear el repositorio: %v", err)
+	} else {
+		fn(c, repo)
+	}
+	s.errs = append(s.errs, c.errs...)
+}
+
+// err devuelve todos los incumplimientos juntos (nil si no hubo).
+func (s *suite[R]) err() error {
+	return errors.Join(s.errs...)
+}
@@ -0,0 +1,247 @@
+package repotest
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+// errAbort es el error con el que los casos hacen fallar una unidad de trabajo a propósito.
+var errAbort = errors.New("unidad de trabajo abortada por la prueba")
+
+// okPayments es una pasarela que aprueba todo (Checkout necesita una).
+type okPayments struct{}
+
+func (okPayments) Authorize(req usecase.PaymentRequest) (usecase.PaymentAuthorization, error) {
+	return usecase.PaymentAuthorization{ID: "AUTH-" + req.OrderID, Amount: req.Amount}, nil
+}
+func (okPayments) Capture(string, domain.Money) error        { return nil }
+func (okPayments) Void(string) error                         { return nil }
+func (okPayments) Refund(string, string, domain.Money) error { return nil }
+
+/*
+UnitOfWork verifica el contrato de una unidad de trabajo junto con los
+repositorios que agrupa.
+
+newStore devuelve los repositorios VACÍOS que se usan fuera de la unidad
+de trabajo y la unidad de trabajo que los agrupa.
+
+Reglas:
+- Si fn devuelve error, no queda ninguno de sus cambios, en ningún
+  repositorio; si termina bien, quedan todos.
+- Checkouts simultáneos de 20 clientes sobre un producto con stock 5:
+  se confirman exactamente 5, el resto falla por falta de stock y el
+  stock queda en 0.
+- Deshacer una unidad de trabajo no pisa lo que se escribió fuera de
+  ella mientras duraba.
+
+Los casos concurrentes conviene ejecutarlos con -race.
+*/
+func UnitOfWork(newStore func() (usecase.Repositories, usecase.UnitOfWork, error)) error {
+	type store struct {
+		repos usecase.Repositories
+		uow   usecase.UnitOfWork
+	}
+	s := &suite[store]{prefix: "unidad de trabajo", newRepo: func() (store, error) {
+		repos, uow, err := newStore()
+		return store{repos, uow}, err
+	}}
+
+	// write hace cambios en todos los repositorios (menos usuarios) y devuelve fail.
+	write := func(tx usecase.Repositories, fail error) error {
+		p, err := tx.Products.GetByID(1)
+		if err != nil {
+			return err
+		}
+		p.Stock = 3
+		if err := tx.Products.Update(p); err != nil {
+			return err
+		}
+		if err := tx.Customers.Create(domain.Customer{ID: 1, Name: "Ana", Email: "ana@example.com"}); err != nil {
+			return err
+		}
+		cart, err := tx.Carts.Get(1)
+		if err != nil {
+			return err
+		}
+		cart.Items = []domain.CartItem{sampleItem(1, 2)}
+		if err := tx.Carts.Save(cart); err != nil {
+			return err
+		}
+		if err := tx.Orders.Create(sampleOrder("ORD-1", 1, time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC))); err != nil {
+			return err
+		}
+		return fail
+	}
+
+	s.run("Do con error no deja cambios", func(c *check, st store) {
+		if !c.must(st.repos.Products.Create(sampleProduct(1)), "Create") {
+			return
+		}
+		c.expectErr(st.uow.Do(func(tx usecase.Repositories) error { return write(tx, errAbort) }), errAbort, "Do")
+
+		if p, err := st.repos.Products.GetByID(1); c.must(err, "GetByID") && p.Stock != 10 {
+			c.errorf("Stock = %d después de deshacer, se esperaba 10", p.Stock)
+		}
+		if list, err := st.repos.Customers.List(); c.must(err, "List de clientes") && len(list) != 0 {
+			c.errorf("List de clientes devolvió %+v después de deshacer, se esperaba un slice vacío", list)
+		}
+		if cart, err := st.repos.Carts.Get(1); c.must(err, "Get del carrito") && len(cart.Items) != 0 {
+			c.errorf("el carrito tiene %+v después de deshacer, se esperaba vacío", cart.Items)
+		}
+		_, err := st.repos.Orders.GetByID("ORD-1")
+		c.expectErr(err, domain.ErrOrderNotFound, "GetByID del pedido después de deshacer")
+	})
+
+	s.run("Do sin error confirma todo", func(c *check, st store) {
+		if !c.must(st.repos.Products.Create(sampleProduct(1)), "Create") {
+			return
+		}
+		if !c.must(st.uow.Do(func(tx usecase.Repositories) error { return write(tx, nil) }), "Do") {
+			return
+		}
+
+		if p, err := st.repos.Products.GetByID(1); c.must(err, "GetByID") && p.Stock != 3 {
+			c.errorf("Stock = %d, se esperaba 3", p.Stock)
+		}
+		_, err := st.repos.Customers.GetByID(1)
+		c.must(err, "GetByID del cliente")
+		if cart, err := st.repos.Carts.Get(1); c.must(err, "Get del carrito") && len(cart.Items) != 1 {
+			c.errorf("el carrito tiene %+v, se esperaba una línea", cart.Items)
+		}
+		_, err = st.repos.Orders.GetByID("ORD-1")
+		c.must(err, "GetByID del pedido")
+	})
+
+	s.run("Checkouts simultáneos", func(c *check, st store) {
+		const customers, stock = 20, 5
+
+		p := sampleProduct(1)
+		p.Stock = stock
+		if !c.must(st.repos.Products.Create(p), "Create") {
+			return
+		}
+		for id := 1; id <= customers; id++ {
+			if !c.must(st.repos.Customers.Create(domain.Customer{ID: id, Name: "Cliente", Email: fmt.Sprintf("c%d@example.com", id)}), "Create del cliente") {
+				return
+			}
+			cart, err := st.repos.Carts.Get(id)
+			if !c.must(err, "Get del carrito") {
+				return
+			}
+			cart.Items = []domain.CartItem{sampleItem(1, 1)}
+			if !c.must(st.repos.Carts.Save(cart), "Save del carrito") {
+				return
+			}
+		}
+
+		deps := usecase.CheckoutDeps{
+			UnitOfWork:   st.uow,
+			Carts:        st.repos.Carts,
+			Products:     st.repos.Products,
+			Customers:    st.repos.Customers,
+			Orders:       st.repos.Orders,
+			Payments:     okPayments{},
+			Reservations: st.repos.Reservations,
+		}
+		errs := make([]error, customers)
+		var wg sync.WaitGroup
+		for i := range customers {
+			wg.Go(func() {
+				_, errs[i] = usecase.Checkout(deps, usecase.CheckoutRequest{CustomerID: i + 1})
+			})
+		}
+		wg.Wait()
+
+		confirmed := 0
+		for i, err := range errs {
+			switch {
+			case err == nil:
+				confirmed++
+			case !errors.Is(err, domain.ErrNoStock):
+				c.errorf("Checkout del cliente %d: error inesperado: %v", i+1, err)
+			}
+		}
+		if confirmed != stock {
+			c.errorf("se confirmaron %d checkouts, se esperaban %d", confirmed, stock)
+		}
+		if p, err := st.repos.Products.GetByID(1); c.must(err, "GetByID") && p.Stock != 0 {
+			c.errorf("Stock = %d después de los checkouts, se esperaba 0", p.Stock)
+		}
+		orders := 0
+		for id := 1; id <= customers; id++ {
+			list, err := st.repos.Orders.ListByCustomer(id)
+			if !c.must(err, "ListByCustomer") {
+				return
+			}
+			orders += len(list)
+		}
+		if orders != stock {
+			c.errorf("hay %d pedidos, se esperaban %d", orders, stock)
+		}
+	})
+
+	s.run("Deshacer no pisa escrituras de afuera", func(c *check, st store) {
+		const n = 20
+
+		if !c.must(st.repos.Products.Create(sampleProduct(1)), "Create") || !c.must(st.repos.Products.Create(sampleProduct(2)), "Create") {
+			return
+		}
+
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		for range n {
+			wg.Go(func() {
+				// Descuenta del producto 1 y se deshace.
+				err := st.uow.Do(func(tx usecase.Repositories) error {
+					p, err := tx.Products.GetByID(1)
+					if err != nil {
+						return err
+					}
+					p.Stock--
+					if err := tx.Products.Update(p); err != nil {
+						return err
+					}
+					return errAbort
+				})
+				if !errors.Is(err, errAbort) {
+					mu.Lock()
+					c.errorf("Do devolvió %v, se esperaba %v", err, errAbort)
+					mu.Unlock()
+				}
+			})
+			wg.Go(func() {
+				// Suma al producto 2 fuera de la unidad de trabajo, releyendo si choca.
+				for {
+					p, err := st.repos.Products.GetByID(2)
+					if err == nil {
+						p.Stock++
+						err = st.repos.Products.Update(p)
+					}
+					if !errors.Is(err, domain.ErrConcurrentModification) {
+						if err != nil {
+							mu.Lock()
+							c.errorf("Update fuera de la unidad de trabajo: error inesperado: %v", err)
+							mu.Unlock()
+						}
+						return
+					}
+				}
+			})
+		}
+		wg.Wait()
+
+		if p, err := st.repos.Products.GetByID(1); c.must(err, "GetByID") && p.Stock != 10 {
+			c.errorf("Stock del producto 1 = %d, se esperaba 10 (todo se deshizo)", p.Stock)
+		}
+		if p, err := st.repos.Products.GetByID(2); c.must(err, "GetByID") && p.Stock != 10+n {
+			c.errorf("Stock del producto 2 = %d, se esperaba %d (ninguna escritura perdida)", p.Stock, 10+n)
+		}
+	})
+
+	return s.err()
+}
@@ -0,0 +1,126 @@
+package repotest
+
+import (
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+/*
+Users verifica el contrato de un repositorio de usuarios.
+
+Reglas:
+- List de un repositorio vacío devuelve un slice vacío, no nil.
+- Create + GetByUsername devuelve el mismo usuario.
+- Create con un nombre repetido devuelve domain.ErrUsernameTaken.
+- GetByUsername, Update y Delete de un nombre inexistente devuelven domain.ErrUserNotFound.
+- GetByAPIKeyHash encuentra al dueño de la clave; una clave que nadie
+  tiene (o vacía) devuelve domain.ErrUserNotFound.
+- Update con la versión leída guarda los cambios e incrementa la versión;
+  con una versión vieja devuelve domain.ErrConcurrentModification.
+- Delete quita el usuario de GetByUsername, GetByAPIKeyHash y List.
+*/
+func Users(newRepo func() (usecase.UserRepository, error)) error {
+	s := &suite[usecase.UserRepository]{prefix: "usuarios", newRepo: newRepo}
+	sample := domain.User{Username: "ana", Role: domain.RoleCustomer, CustomerID: 1, PasswordHash: "hash-ana"}
+	staff := domain.User{Username: "bruno", Role: domain.RoleStaff, PasswordHash: "hash-bruno"}
+
+	s.run("List vacío", func(c *check, repo usecase.UserRepository) {
+		if list, err := repo.List(); c.must(err, "List") && (list == nil || len(list) != 0) {
+			c.errorf("List devolvió %#v, se esperaba un slice vacío", list)
+		}
+	})
+
+	s.run("Create y GetByUsername", func(c *check, repo usecase.UserRepository) {
+		if !c.must(repo.Create(sample), "Create") {
+			return
+		}
+		got, err := repo.GetByUsername(sample.Username)
+		if !c.must(err, "GetByUsername") {
+			return
+		}
+		if got != sample {
+			c.errorf("GetByUsername devolvió %+v, se esperaba %+v", got, sample)
+		}
+		if list, err := repo.List(); c.must(err, "List") && (len(list) != 1 || list[0].Username != sample.Username) {
+			c.errorf("List devolvió %+v, se esperaba solo el usuario %s", list, sample.Username)
+		}
+	})
+
+	s.run("Create con nombre repetido", func(c *check, repo usecase.UserRepository) {
+		if !c.must(repo.Create(sample), "Create") {
+			return
+		}
+		c.expectErr(repo.Create(sample), domain.ErrUsernameTaken, "segundo Create")
+	})
+
+	s.run("Usuario inexistente", func(c *check, repo usecase.UserRepository) {
+		_, err := repo.GetByUsername("nadie")
+		c.expectErr(err, domain.ErrUserNotFound, "GetByUsername")
+		missing := sample
+		missing.Username = "nadie"
+		c.expectErr(repo.Update(missing), domain.ErrUserNotFound, "Update")
+		c.expectErr(repo.Delete("nadie"), domain.ErrUserNotFound, "Delete")
+	})
+
+	s.run("Delete", func(c *check, repo usecase.UserRepository) {
+		withKey := staff
+		withKey.APIKeyHash = "clave-bruno"
+		if !c.must(repo.Create(sample), "Create") || !c.must(repo.Create(withKey), "Create con clave") ||
+			!c.must(repo.Delete(withKey.Username), "Delete") {
+			return
+		}
+		_, err := repo.GetByUsername(withKey.Username)
+		c.expectErr(err, domain.ErrUserNotFound, "GetByUsername después de Delete")
+		_, err = repo.GetByAPIKeyHash(withKey.APIKeyHash)
+		c.expectErr(err, domain.ErrUserNotFound, "GetByAPIKeyHash después de Delete")
+		if list, err := repo.List(); c.must(err, "List") && (len(list) != 1 || list[0].Username != sample.Username) {
+			c.errorf("List devolvió %+v después de Delete, se esperaba solo el usuario %s", list, sample.Username)
+		}
+	})
+
+	s.run("GetByAPIKeyHash", func(c *check, repo usecase.UserRepository) {
+		withKey := staff
+		withKey.APIKeyHash = "clave-bruno"
+		if !c.must(repo.Create(sample), "Create sin clave") || !c.must(repo.Create(withKey), "Create con clave") {
+			return
+		}
+		got, err := repo.GetByAPIKeyHash(withKey.APIKeyHash)
+		if c.must(err, "GetByAPIKeyHash") && got.Username != withKey.Username {
+			c.errorf("GetByAPIKeyHash devolvió %s, se esperaba %s", got.Username, withKey.Username)
+		}
+		_, err = repo.GetByAPIKeyHash("otra-clave")
+		c.expectErr(err, domain.ErrUserNotFound, "GetByAPIKeyHash de una clave desconocida")
+		_, err = repo.GetByAPIKeyHash("")
+		c.expectErr(err, domain.ErrUserNotFound, "GetByAPIKeyHash de una clave vacía")
+	})
+
+	s.run("Update con versión", func(c *check, repo usecase.UserRepository) {
+		if !c.must(repo.Create(sample), "Create") {
+			return
+		}
+		read, err := repo.GetByUsername(sample.Username)
+		if !c.must(err, "GetByUsername") {
+			return
+		}
+
+		changed := read
+		changed.APIKeyHash = "clave-nueva"
+		if !c.must(repo.Update(changed), "Update") {
+			return
+		}
+		got, err := repo.GetByAPIKeyHash(changed.APIKeyHash)
+		if !c.must(err, "GetByAPIKeyHash después de Update") {
+			return
+		}
+		if got.Username != sample.Username || got.Version <= read.Version {
+			c.errorf("GetByAPIKeyHash devolvió %+v después de Update, se esperaba %s con una versión mayor a %d",
+				got, sample.Username, read.Version)
+		}
+
+		stale := read
+		stale.APIKeyHash = "otra-clave"
+		c.expectErr(repo.Update(stale), domain.ErrConcurrentModification, "Update con versión vieja")
+	})
+
+	return s.err()
+}
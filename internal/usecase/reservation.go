@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+)
+
+/*
+ReservationRepository define el contrato para guardar reservas de stock.
+
+Principio aplicado:
+- usecase define la interfaz; adapters la implementan.
+- El repositorio solo guarda: la vigencia y el cálculo de stock
+  disponible son reglas de dominio (domain.AvailableStock).
+*/
+type ReservationRepository interface {
+	// Save crea o reemplaza la reserva de un cliente sobre un producto.
+	Save(r domain.Reservation) error
+
+	// Get devuelve la reserva de un cliente sobre un producto, si existe.
+	Get(customerID, productID int) (domain.Reservation, bool, error)
+
+	// Delete elimina la reserva de un cliente sobre un producto.
+	Delete(customerID, productID int) error
+
+	// DeleteByCustomer elimina todas las reservas de un cliente.
+	DeleteByCustomer(customerID int) error
+
+	// ListByProduct devuelve las reservas (vigentes o vencidas) de un producto.
+	ListByProduct(productID int) ([]domain.Reservation, error)
+
+	// DeleteExpired elimina las reservas vencidas en now y devuelve cuántas eran.
+	DeleteExpired(now time.Time) (int, error)
+}
+
+// DefaultReservationTTL es lo que dura una reserva si no se configura otro valor.
+const DefaultReservationTTL = 15 * time.Minute
+
+/*
+othersReservations filtra las reservas de un producto dejando solo
+las de clientes distintos a customerID.
+
+Se usa para calcular cuánto stock le queda disponible a un cliente:
+sus propias reservas no le quitan stock a él mismo.
+*/
+func othersReservations(reservations []domain.Reservation, customerID int) []domain.Reservation {
+	out := make([]domain.Reservation, 0, len(reservations))
+	for _, r := range reservations {
+		if r.CustomerID != customerID {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+/*
+availableStockFor calcula el stock disponible de un producto para un cliente:
+stock en depósito menos las reservas activas de los demás clientes.
+*/
+func availableStockFor(reservations ReservationRepository, p domain.Product, customerID int, now time.Time) (int, error) {
+	all, err := reservations.ListByProduct(p.ID)
+	if err != nil {
+		return 0, err
+	}
+	return domain.AvailableStock(p, othersReservations(all, customerID), now), nil
+}
+
+/*
+ReleaseExpiredReservations elimina las reservas vencidas.
+
+No es obligatorio llamarlo para que el sistema funcione (las reservas
+vencidas ya no cuentan como reservadas), pero evita que se acumulen.
+Devuelve cuántas reservas se liberaron.
+*/
+func ReleaseExpiredReservations(repo ReservationRepository, clock Clock) (int, error) {
+	return repo.DeleteExpired(clockOrSystem(clock).Now())
+}
@@ -0,0 +1,32 @@
+package usecase
+
+import (
+	"errors"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+)
+
+// maxConflictAttempts es cuántas veces se intenta un caso de uso ante conflictos de versión.
+const maxConflictAttempts = 3
+
+/*
+retryOnConflict ejecuta fn y, si devuelve domain.ErrConcurrentModification,
+la vuelve a ejecutar desde el principio (hasta maxConflictAttempts veces).
+
+Reglas:
+- fn debe releer todo lo que modifica: el conflicto significa que otra
+  operación guardó una versión más nueva de alguna entidad.
+- Cualquier otro error (o el éxito) se devuelve sin reintentar.
+- Si se agotan los intentos, se devuelve el último conflicto.
+*/
+func retryOnConflict[T any](fn func() (T, error)) (T, error) {
+	var out T
+	var err error
+	for range maxConflictAttempts {
+		out, err = fn()
+		if !errors.Is(err, domain.ErrConcurrentModification) {
+			return out, err
+		}
+	}
+	return out, err
+}
@@ -0,0 +1,378 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+)
+
+/*
+ReturnLine representa una línea de una devolución:
+cuántas unidades de un producto del pedido se devuelven y cuánto se reembolsa.
+*/
+type ReturnLine struct {
+	ProductID int
+	Name      string
+	Quantity  int
+	Refund    domain.Money // UnitPrice del pedido * Quantity
+}
+
+/*
+ReturnRequest representa una solicitud de devolución (RMA) sobre
+un pedido entregado.
+
+Un pedido puede tener varias devoluciones, cada una con un subconjunto
+de sus líneas y cantidades.
+*/
+type ReturnRequest struct {
+	ID         string
+	OrderID    string
+	CustomerID int
+	Lines      []ReturnLine
+	Refund     domain.Money // Suma de los reembolsos de cada línea
+	Reason     string
+	Status     domain.ReturnStatus
+	Restocked  bool // true si al aprobarla se repuso el stock
+	CreatedAt  time.Time
+	DecidedAt  time.Time // Cero mientras está en estado requested
+}
+
+/*
+ReturnItem es lo que pide el cliente al iniciar una devolución:
+un producto del pedido y la cantidad a devolver.
+*/
+type ReturnItem struct {
+	ProductID int
+	Quantity  int
+}
+
+/*
+ReturnRepository define el contrato para guardar y consultar devoluciones.
+*/
+type ReturnRepository interface {
+	// Create guarda una devolución nueva.
+	Create(r ReturnRequest) error
+
+	// Update reemplaza una devolución existente.
+	Update(r ReturnRequest) error
+
+	// GetByID devuelve una devolución o domain.ErrReturnNotFound.
+	GetByID(id string) (ReturnRequest, error)
+
+	// ListByOrder devuelve las devoluciones de un pedido, de la más antigua a la más nueva.
+	ListByOrder(orderID string) ([]ReturnRequest, error)
+}
+
+/*
+ReturnDeps agrupa las dependencias de los casos de uso de devoluciones.
+
+Dependencias opcionales:
+- Payments: si es nil el reembolso solo se registra en el pedido
+  (por ejemplo, si se devuelve el dinero fuera del sistema).
+- ReturnIDs: si es nil se usa un generador ULID con prefijo "RMA-".
+- Clock: si es nil se usa la hora del sistema.
+*/
+type ReturnDeps struct {
+	UnitOfWork UnitOfWork
+	Orders     OrderRepository
+	Returns    ReturnRepository
+	Payments   PaymentGateway
+	ReturnIDs  IDGenerator
+	Clock      Clock
+}
+
+/*
+RequestReturn registra una solicitud de devolución para un pedido entregado.
+
+Responsabilidad:
+- Validar que el pedido exista y esté entregado.
+- Validar cada línea: el producto debe estar en el pedido y la cantidad
+  no puede superar lo comprado menos lo ya devuelto o en trámite.
+- Calcular el reembolso de cada línea con el precio unitario del pedido
+  (no con el precio actual del producto).
+- Guardar la solicitud en estado requested.
+
+Nota:
+- Si el mismo producto aparece varias veces en items, las cantidades se suman.
+- Todavía no se repone stock ni se reembolsa: eso ocurre al aprobarla.
+- El pedido y sus devoluciones anteriores se leen dentro de
+  deps.UnitOfWork, junto con el alta.
+*/
+func RequestReturn(deps ReturnDeps, orderID string, items []ReturnItem, reason string) (ReturnRequest, error) {
+	// Agrupar cantidades pedidas por producto, respetando el orden de ingreso.
+	requested := make(map[int]int)
+	productOrder := make([]int, 0, len(items))
+	for _, it := range items {
+		if it.Quantity <= 0 {
+			return ReturnRequest{}, domain.ErrInvalidQuantity
+		}
+		if _, seen := requested[it.ProductID]; !seen {
+			productOrder = append(productOrder, it.ProductID)
+		}
+		requested[it.ProductID] += it.Quantity
+	}
+	if len(productOrder) == 0 {
+		return ReturnRequest{}, domain.ErrEmptyReturn
+	}
+
+	var rma ReturnRequest
+
+	// Las devoluciones anteriores se leen dentro de la unidad de trabajo:
+	// dos solicitudes simultáneas no pueden devolver entre ambas más de
+	// lo comprado.
+	err := deps.UnitOfWork.Do(func(tx Repositories) error {
+		order, err := tx.Orders.GetByID(orderID)
+		if err != nil {
+			return err
+		}
+		if order.Status != domain.OrderDelivered {
+			return domain.ErrOrderNotDelivered
+		}
+
+		previous, err := tx.Returns.ListByOrder(orderID)
+		if err != nil {
+			return err
+		}
+		returned := returnedQuantities(previous)
+
+		lines := make([]ReturnLine, 0, len(productOrder))
+		refund := domain.ZeroMoney(order.Total.Currency)
+		for _, productID := range productOrder {
+			item, ok := findOrderItem(order, productID)
+			if !ok {
+				return domain.ErrProductNotInOrder
+			}
+
+			qty := requested[productID]
+			if qty > domain.ReturnableQuantity(item.Quantity, returned[productID]) {
+				return domain.ErrReturnQuantityExceeded
+			}
+
+			lineRefund := domain.MulMoney(item.UnitPrice, qty)
+			refund, err = domain.AddMoney(refund, lineRefund)
+			if err != nil {
+				return err
+			}
+
+			lines = append(lines, ReturnLine{
+				ProductID: productID,
+				Name:      item.Name,
+				Quantity:  qty,
+				Refund:    lineRefund,
+			})
+		}
+
+		rma = ReturnRequest{
+			ID:         idsOrDefault(deps.ReturnIDs, defaultReturnIDs).NewID(),
+			OrderID:    order.ID,
+			CustomerID: order.CustomerID,
+			Lines:      lines,
+			Refund:     refund,
+			Reason:     reason,
+			Status:     domain.ReturnRequested,
+			CreatedAt:  clockOrSystem(deps.Clock).Now(),
+		}
+		return tx.Returns.Create(rma)
+	})
+	if err != nil {
+		return ReturnRequest{}, err
+	}
+	return rma, nil
+}
+
+/*
+ApproveReturn aprueba una devolución pendiente.
+
+Responsabilidad:
+- Si restock es true, devolver al inventario las unidades de cada línea.
+- Sumar el reembolso al total reembolsado del pedido (Order.Refunded).
+- Marcar la devolución como aprobada.
+- Reembolsar el monto en el proveedor de pagos (si el pedido tiene pago).
+
+Idempotencia:
+- Si la devolución ya estaba aprobada, se devuelve tal cual, sin volver
+  a reponer stock (restock se ignora). El reembolso se vuelve a pedir
+  con el ID de la devolución como refundID (el proveedor no lo repite):
+  así, si falló la primera vez, aprobar de nuevo lo completa.
+- Una devolución rechazada no se puede aprobar
+  (domain.ErrReturnAlreadyDecided).
+
+Atomicidad:
+- La lectura de la devolución, el stock y el pedido se resuelven dentro
+  de deps.UnitOfWork: dos aprobaciones simultáneas no reponen dos veces.
+- Ante domain.ErrConcurrentModification al reponer stock se deshace
+  todo y se reintenta.
+- El reembolso se pide recién después de confirmar la unidad de
+  trabajo: un intento deshecho (o reintentado) nunca reembolsa. Si el
+  proveedor falla, la devolución ya quedó aprobada y se devuelve el error.
+*/
+func ApproveReturn(deps ReturnDeps, returnID string, restock bool) (ReturnRequest, error) {
+	var paymentID string
+	rma, err := retryOnConflict(func() (ReturnRequest, error) {
+		rma, id, err := approveReturn(deps, returnID, restock)
+		paymentID = id
+		return rma, err
+	})
+	if err != nil {
+		return ReturnRequest{}, err
+	}
+
+	if deps.Payments != nil && paymentID != "" {
+		if err := deps.Payments.Refund(paymentID, rma.ID, rma.Refund); err != nil {
+			return ReturnRequest{}, err
+		}
+	}
+	return rma, nil
+}
+
+/*
+approveReturn es un intento de ApproveReturn, sin el reembolso.
+
+Devuelve también el pago del pedido, para reembolsar después de confirmar.
+*/
+func approveReturn(deps ReturnDeps, returnID string, restock bool) (ReturnRequest, string, error) {
+	var rma ReturnRequest
+	var paymentID string
+
+	// La devolución se lee y se valida dentro de la unidad de trabajo: dos
+	// aprobaciones simultáneas no pueden reponer dos veces.
+	err := deps.UnitOfWork.Do(func(tx Repositories) error {
+		var err error
+		rma, err = tx.Returns.GetByID(returnID)
+		if err != nil {
+			return err
+		}
+		order, err := tx.Orders.GetByID(rma.OrderID)
+		if err != nil {
+			return err
+		}
+		paymentID = order.PaymentID
+
+		if rma.Status == domain.ReturnApproved {
+			return nil
+		}
+		if err := domain.DecideReturn(rma.Status, domain.ReturnApproved); err != nil {
+			return err
+		}
+
+		if restock {
+			for _, line := range rma.Lines {
+				p, err := tx.Products.GetByID(line.ProductID)
+				if err != nil {
+					return err
+				}
+				p, err = domain.Restock(p, line.Quantity)
+				if err != nil {
+					return err
+				}
+				if err := tx.Products.Update(p); err != nil {
+					return err
+				}
+			}
+		}
+
+		order.Refunded, err = domain.AddMoney(refundedSoFar(order), rma.Refund)
+		if err != nil {
+			return err
+		}
+		if err := tx.Orders.Update(order); err != nil {
+			return err
+		}
+
+		rma.Status = domain.ReturnApproved
+		rma.Restocked = restock
+		rma.DecidedAt = clockOrSystem(deps.Clock).Now()
+		return tx.Returns.Update(rma)
+	})
+	if err != nil {
+		return ReturnRequest{}, "", err
+	}
+	return rma, paymentID, nil
+}
+
+/*
+RejectReturn rechaza una devolución pendiente.
+
+No modifica stock ni el pedido: solo registra la decisión.
+*/
+func RejectReturn(deps ReturnDeps, returnID string) (ReturnRequest, error) {
+	var rma ReturnRequest
+
+	// Igual que al aprobar: leer y decidir en la misma unidad de trabajo,
+	// para no rechazar una devolución que otro ya aprobó.
+	err := deps.UnitOfWork.Do(func(tx Repositories) error {
+		var err error
+		rma, err = tx.Returns.GetByID(returnID)
+		if err != nil {
+			return err
+		}
+		if err := domain.DecideReturn(rma.Status, domain.ReturnRejected); err != nil {
+			return err
+		}
+
+		rma.Status = domain.ReturnRejected
+		rma.DecidedAt = clockOrSystem(deps.Clock).Now()
+		return tx.Returns.Update(rma)
+	})
+	if err != nil {
+		return ReturnRequest{}, err
+	}
+	return rma, nil
+}
+
+/*
+ListReturnsByOrder es un caso de uso de consulta.
+
+Devuelve todas las devoluciones de un pedido (slice vacío si no hay).
+*/
+func ListReturnsByOrder(repo ReturnRepository, orderID string) ([]ReturnRequest, error) {
+	return repo.ListByOrder(orderID)
+}
+
+/*
+OrderNetTotal calcula lo que finalmente pagó el cliente por un pedido:
+el total menos lo reembolsado por devoluciones aprobadas.
+*/
+func OrderNetTotal(order Order) (domain.Money, error) {
+	return domain.SubMoney(order.Total, refundedSoFar(order))
+}
+
+/*
+refundedSoFar devuelve el total reembolsado del pedido.
+
+Si Refunded nunca se inicializó (moneda vacía), se considera cero
+en la moneda del pedido, para poder operar sin ErrCurrencyMismatch.
+*/
+func refundedSoFar(order Order) domain.Money {
+	if order.Refunded.Currency == "" {
+		return domain.ZeroMoney(order.Total.Currency)
+	}
+	return order.Refunded
+}
+
+/*
+returnedQuantities suma, por producto, las unidades de devoluciones
+aprobadas o pendientes. Las rechazadas no cuentan.
+*/
+func returnedQuantities(returns []ReturnRequest) map[int]int {
+	out := make(map[int]int)
+	for _, r := range returns {
+		if r.Status == domain.ReturnRejected {
+			continue
+		}
+		for _, line := range r.Lines {
+			out[line.ProductID] += line.Quantity
+		}
+	}
+	return out
+}
+
+// findOrderItem busca la línea del pedido correspondiente a un producto.
+func findOrderItem(order Order, productID int) (OrderItem, bool) {
+	for _, it := range order.Items {
+		if it.ProductID == productID {
+			return it, true
+		}
+	}
+	return OrderItem{}, false
+}
@@ -0,0 +1,118 @@
+package usecase_test
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/adapters/memory"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/usecase"
+)
+
+// deliveredOrder es paidOrder con el pedido ya enviado y entregado.
+func deliveredOrder(t *testing.T, repos memory.Repos, uow usecase.UnitOfWork) usecase.Order {
+	t.Helper()
+	order := paidOrder(t, repos)
+	deps := usecase.OrderDeps{UnitOfWork: uow, Orders: repos.Orders}
+	if _, err := usecase.MarkOrderShipped(deps, order.ID); err != nil {
+		t.Fatal(err)
+	}
+	order, err := usecase.MarkOrderDelivered(deps, order.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return order
+}
+
+// Varias solicitudes a la vez no pueden devolver entre todas más de lo comprado.
+func TestRequestReturnConcurrent(t *testing.T) {
+	repos, uow := newRepos()
+	order := deliveredOrder(t, repos, uow)
+	deps := usecase.ReturnDeps{UnitOfWork: uow, Orders: repos.Orders, Returns: repos.Returns, Clock: slowClock{}}
+
+	var accepted atomic.Int32
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			_, err := usecase.RequestReturn(deps, order.ID, []usecase.ReturnItem{{ProductID: 1, Quantity: 2}}, "no lo uso")
+			switch {
+			case err == nil:
+				accepted.Add(1)
+			case !errors.Is(err, domain.ErrReturnQuantityExceeded):
+				t.Error(err)
+			}
+		})
+	}
+	wg.Wait()
+
+	if n := accepted.Load(); n != 1 {
+		t.Errorf("se aceptaron %d devoluciones de las 2 unidades compradas, se esperaba 1", n)
+	}
+}
+
+// Aprobar la misma devolución varias veces a la vez repone y reembolsa una sola vez.
+func TestApproveReturnConcurrent(t *testing.T) {
+	repos, uow := newRepos()
+	order := deliveredOrder(t, repos, uow)
+	payments := &countingPayments{}
+	deps := usecase.ReturnDeps{UnitOfWork: uow, Orders: repos.Orders, Returns: repos.Returns, Payments: payments, Clock: slowClock{}}
+
+	rma, err := usecase.RequestReturn(deps, order.ID, []usecase.ReturnItem{{ProductID: 1, Quantity: 2}}, "no lo uso")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Aprobar es idempotente: todas las llamadas devuelven la devolución aprobada.
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			got, err := usecase.ApproveReturn(deps, rma.ID, true)
+			if err != nil {
+				t.Error(err)
+			} else if got.Status != domain.ReturnApproved {
+				t.Errorf("Status = %s, se esperaba %s", got.Status, domain.ReturnApproved)
+			}
+		})
+	}
+	wg.Wait()
+
+	if got, err := repos.Orders.GetByID(order.ID); err != nil || got.Refunded != rma.Refund {
+		t.Errorf("Refunded del pedido = %v (%v), se esperaba %v (sumado una sola vez)", got.Refunded, err, rma.Refund)
+	}
+	p, err := repos.Products.GetByID(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Stock != 10 {
+		t.Errorf("stock = %d, se esperaba 10 (repuesto una sola vez)", p.Stock)
+	}
+	if n := payments.refunds.Load(); n != 1 {
+		t.Errorf("se hicieron %d reembolsos, se esperaba 1", n)
+	}
+}
+
+// Si la aprobación no se confirma (falla la escritura), no se reembolsa nada.
+func TestApproveReturnCommitFails(t *testing.T) {
+	repos, uow := newRepos()
+	order := deliveredOrder(t, repos, uow)
+	payments := &countingPayments{}
+	deps := usecase.ReturnDeps{UnitOfWork: uow, Orders: repos.Orders, Returns: repos.Returns, Payments: payments}
+
+	rma, err := usecase.RequestReturn(deps, order.ID, []usecase.ReturnItem{{ProductID: 1, Quantity: 2}}, "no lo uso")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deps.UnitOfWork = memory.NewUnitOfWork(repos, memory.Hooks{Commit: func([]any) error { return errDisk }})
+	if _, err := usecase.ApproveReturn(deps, rma.ID, true); !errors.Is(err, errDisk) {
+		t.Fatalf("ApproveReturn devolvió %v, se esperaba %v", err, errDisk)
+	}
+	if n := payments.refunds.Load(); n != 0 {
+		t.Errorf("se hicieron %d reembolsos de una aprobación deshecha, se esperaba 0", n)
+	}
+	if got, err := repos.Returns.GetByID(rma.ID); err != nil || got.Status == domain.ReturnApproved {
+		t.Errorf("Status = %s (%v), no debería estar aprobada", got.Status, err)
+	}
+}
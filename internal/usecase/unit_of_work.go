@@ -0,0 +1,50 @@
+package usecase
+
+/*
+UnitOfWork define el contrato de una unidad de trabajo (transacción).
+
+Responsabilidad:
+- Ejecutar un bloque de operaciones sobre varios repositorios
+  como si fueran una sola operación atómica.
+- Si fn devuelve error, TODOS los cambios hechos dentro del bloque
+  se deshacen (rollback).
+- Si fn termina sin error, los cambios quedan confirmados (commit).
+
+Principio (arquitectura limpia):
+- usecase define el contrato.
+- adapters (memory, db, etc.) deciden cómo implementar el rollback
+  (registro de cambios en memoria, transacciones SQL, etc.).
+
+Regla para los casos de uso:
+- Dentro de fn solo se usan los repositorios de tx. Son los únicos que
+  participan de la transacción: lo que se lea o escriba con otros
+  repositorios queda fuera de ella (y con SQLite, que tiene una sola
+  conexión, esperaría a que termine la transacción).
+*/
+type UnitOfWork interface {
+	// Do ejecuta fn dentro de la unidad de trabajo.
+	// Devuelve el mismo error que fn (si lo hubo) después de deshacer los cambios.
+	Do(fn func(tx Repositories) error) error
+}
+
+/*
+Repositories son los repositorios de una unidad de trabajo en curso.
+
+Cada adaptador los crea al empezar Do, ligados a esa unidad de trabajo
+(por ejemplo, a una transacción SQL); no sirven fuera de fn.
+*/
+type Repositories struct {
+	Products interface {
+		ProductRepository
+		ProductRepositoryForCart
+	}
+	Customers interface {
+		CustomerRepository
+		CustomerRepositoryForDelete
+	}
+	Carts        CartRepository
+	Reservations ReservationRepository
+	Orders       OrderRepository
+	Returns      ReturnRepository
+	Users        UserRepository
+}
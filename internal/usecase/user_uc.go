@@ -0,0 +1,178 @@
+package usecase
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+
+	"github.com/aguirrethub/s-gestion-ecommerce/internal/domain"
+)
+
+/*
+UserRepository define el contrato para guardar usuarios.
+
+Principio aplicado:
+- usecase define la interfaz; adapters la implementan.
+- El repositorio no hashea ni valida: recibe los hashes ya calculados.
+
+Update compara u.Version con la versión guardada (control de
+concurrencia optimista), igual que CustomerRepositoryForUpdate.
+*/
+type UserRepository interface {
+	// Create guarda un usuario nuevo; devuelve domain.ErrUsernameTaken si el nombre ya existe.
+	Create(u domain.User) error
+
+	// Update reemplaza un usuario; devuelve domain.ErrUserNotFound si no existe.
+	Update(u domain.User) error
+
+	// GetByUsername busca un usuario; devuelve domain.ErrUserNotFound si no existe.
+	GetByUsername(username string) (domain.User, error)
+
+	// GetByAPIKeyHash busca al dueño de una clave de API por su hash;
+	// devuelve domain.ErrUserNotFound si ningún usuario la tiene.
+	GetByAPIKeyHash(hash string) (domain.User, error)
+
+	// Delete borra un usuario; devuelve domain.ErrUserNotFound si no existe.
+	Delete(username string) error
+
+	// List devuelve todos los usuarios.
+	List() ([]domain.User, error)
+}
+
+/*
+UserDeps agrupa las dependencias de la gestión de usuarios.
+
+Customers se usa para verificar que exista el cliente asociado a un
+usuario con domain.RoleCustomer.
+*/
+type UserDeps struct {
+	Users     UserRepository
+	Customers CustomerRepositoryForCheckout
+}
+
+/*
+NewUserRequest contiene los datos para crear un usuario.
+
+Password llega en claro y solo se guarda su hash.
+*/
+type NewUserRequest struct {
+	Username   string
+	Password   string
+	Role       domain.Role
+	CustomerID int
+}
+
+/*
+CreateUser es un caso de uso de comando (modifica estado).
+
+Flujo:
+1) Valida usuario y contraseña con las reglas del dominio.
+2) Si el rol es customer, verifica que el cliente exista.
+3) Guarda el usuario con el hash de la contraseña (sin clave de API:
+   se emite aparte con IssueAPIKey o al iniciar sesión).
+
+Nota:
+- Quién puede crear usuarios (solo admin) lo controla el adaptador de
+  entrada con domain.AuthorizeAdmin.
+*/
+func CreateUser(deps UserDeps, req NewUserRequest) (domain.User, error) {
+	u := domain.User{Username: req.Username, Role: req.Role, CustomerID: req.CustomerID}
+	if err := domain.ValidateUser(u); err != nil {
+		return domain.User{}, err
+	}
+	if err := domain.ValidatePassword(req.Password); err != nil {
+		return domain.User{}, err
+	}
+	if u.Role == domain.RoleCustomer {
+		if _, err := deps.Customers.GetByID(u.CustomerID); err != nil {
+			return domain.User{}, err
+		}
+	}
+
+	hash, err := hashPassword(req.Password)
+	if err != nil {
+		return domain.User{}, err
+	}
+	u.PasswordHash = hash
+	if err := deps.Users.Create(u); err != nil {
+		return domain.User{}, err
+	}
+	return u, nil
+}
+
+// ListUsers devuelve los usuarios ordenados por nombre.
+func ListUsers(users UserRepository) ([]domain.User, error) {
+	list, err := users.List()
+	if err != nil {
+		return nil, err
+	}
+	slices.SortFunc(list, func(a, b domain.User) int {
+		return cmp.Compare(a.Username, b.Username)
+	})
+	return list, nil
+}
+
+/*
+Login verifica usuario y contraseña y devuelve el usuario.
+
+Si el usuario no existe o la contraseña no coincide devuelve
+domain.ErrInvalidCredentials en ambos casos (y tarda lo mismo), para no
+revelar qué nombres de usuario existen.
+*/
+func Login(users UserRepository, username, password string) (domain.User, error) {
+	u, err := users.GetByUsername(username)
+	if errors.Is(err, domain.ErrUserNotFound) {
+		verifyPassword(dummyPasswordHash, password)
+		return domain.User{}, domain.ErrInvalidCredentials
+	}
+	if err != nil {
+		return domain.User{}, err
+	}
+	if !verifyPassword(u.PasswordHash, password) {
+		return domain.User{}, domain.ErrInvalidCredentials
+	}
+	return u, nil
+}
+
+/*
+IssueAPIKey genera una clave de API nueva para el usuario y la devuelve.
+
+Reglas:
+- Cada usuario tiene una sola clave vigente: emitir una nueva invalida
+  la anterior.
+- La clave solo se conoce en este momento; se guarda su hash.
+*/
+func IssueAPIKey(users UserRepository, username string) (string, error) {
+	return retryOnConflict(func() (string, error) {
+		u, err := users.GetByUsername(username)
+		if err != nil {
+			return "", err
+		}
+		key, hash, err := newAPIKey()
+		if err != nil {
+			return "", err
+		}
+		u.APIKeyHash = hash
+		if err := users.Update(u); err != nil {
+			return "", err
+		}
+		return key, nil
+	})
+}
+
+/*
+AuthenticateAPIKey devuelve el usuario dueño de la clave de API.
+
+Devuelve domain.ErrUnauthenticated si la clave está vacía o no
+corresponde a ningún usuario.
+*/
+func AuthenticateAPIKey(users UserRepository, key string) (domain.User, error) {
+	if key == "" {
+		return domain.User{}, domain.ErrUnauthenticated
+	}
+	u, err := users.GetByAPIKeyHash(hashAPIKey(key))
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return domain.User{}, domain.ErrUnauthenticated
+	}
+	return u, err
+}